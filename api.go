@@ -0,0 +1,178 @@
+package main
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// apiRoute describes an HTTP API endpoint, along with the role required to
+// access it. Tokens can also be granted access to a route by its scope.
+type apiRoute struct {
+	path    string
+	scope   string
+	role    int
+	handler http.HandlerFunc
+}
+
+// coreHealth is the body returned by the HTTP health endpoint
+type coreHealth struct {
+	Healthy    bool                     `json:"healthy"`
+	Version    string                   `json:"version"`
+	Uptime     string                   `json:"uptime"`
+	Goroutines int                      `json:"goroutines"`
+	HeapAlloc  uint64                   `json:"heap_alloc"`
+	Subsystems []ifaces.SubsystemHealth `json:"subsystems"`
+}
+
+// serverStatus is the body returned by the HTTP status endpoint
+type serverStatus struct {
+	Name          string `json:"name"`
+	Status        int    `json:"status"`
+	PlayersOnline int    `json:"players_online"`
+	TotalPlayers  int    `json:"total_players"`
+	Alliances     int    `json:"alliances"`
+	Sectors       int    `json:"sectors"`
+}
+
+// statusRecorder wraps an http.ResponseWriter to keep track of the status code
+// that was written, so that it can be logged
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.code = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+// ServeAPI starts the HTTP API if an address is configured, and shuts it down
+// once exit is closed. TLS is used if a certificate or autocert domains have
+// been configured.
+func (c *Core) ServeAPI(wg *sync.WaitGroup, exit chan struct{}) {
+	addr := config.HealthAddress()
+	if addr == "" {
+		return
+	}
+
+	// Placeholder credentials are public, so the API would be open to anyone
+	// that has read the example configuration
+	if found := config.APIPlaceholders(); len(found) > 0 {
+		logger.LogError(c, "Refusing to serve the HTTP API, placeholder "+
+			"credentials are configured for: "+strings.Join(found, ", "))
+		return
+	}
+
+	routes := []apiRoute{
+		{"/health", "health", apiReadOnly, c.handleHealth},
+		{"/status", "status", apiReadOnly, c.handleStatus}}
+
+	providers := []apiAuthProvider{tokenAuth{}, basicAuth{}}
+	if app, ok := config.APIOAuth(); ok {
+		o := newOAuthAuth(app, c.bot)
+		providers = append(providers, o)
+		routes = append(routes,
+			apiRoute{"/auth/login", "", apiPublic, o.handleLogin},
+			apiRoute{"/auth/callback", "", apiPublic, o.handleCallback},
+			apiRoute{"/auth/logout", "", apiPublic, o.handleLogout})
+	}
+
+	// Profiles can expose memory contents, so they are only served to admins
+	if config.PProfEnabled() {
+		routes = append(routes,
+			apiRoute{"/debug/pprof/", "pprof", apiAdmin, pprof.Index},
+			apiRoute{"/debug/pprof/cmdline", "pprof", apiAdmin, pprof.Cmdline},
+			apiRoute{"/debug/pprof/profile", "pprof", apiAdmin, pprof.Profile},
+			apiRoute{"/debug/pprof/symbol", "pprof", apiAdmin, pprof.Symbol},
+			apiRoute{"/debug/pprof/trace", "pprof", apiAdmin, pprof.Trace})
+	}
+
+	mux := http.NewServeMux()
+	for _, rt := range routes {
+		mux.HandleFunc(rt.path, c.authorize(rt, providers))
+	}
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+	cert, key := config.TLSFiles()
+	domains, cache := config.AutocertDomains()
+
+	if len(domains) > 0 {
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(domains...),
+			Cache:      autocert.DirCache(cache)}
+		srv.TLSConfig = m.TLSConfig()
+		cert, key = "", ""
+	}
+
+	wg.Add(1)
+	go func() {
+		defer shutdown.Recover()
+		defer wg.Done()
+		<-exit
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}()
+
+	go func() {
+		defer shutdown.Recover()
+		var err error
+		if srv.TLSConfig != nil || cert != "" {
+			logger.LogInit(c, "Serving HTTPS API on "+addr)
+			err = srv.ListenAndServeTLS(cert, key)
+		} else {
+			logger.LogInit(c, "Serving HTTP API on "+addr)
+			err = srv.ListenAndServe()
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			logger.LogError(c, "HTTP API: "+err.Error())
+		}
+	}()
+}
+
+func (c *Core) handleHealth(w http.ResponseWriter, r *http.Request) {
+	stats := c.Stats()
+	h := coreHealth{
+		Healthy:    true,
+		Version:    c.Version(),
+		Uptime:     c.Uptime().Round(time.Second).String(),
+		Goroutines: stats.Goroutines,
+		HeapAlloc:  stats.HeapAlloc,
+		Subsystems: c.Health()}
+
+	for _, sub := range h.Subsystems {
+		if !sub.Healthy {
+			h.Healthy = false
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !h.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(h)
+}
+
+func (c *Core) handleStatus(w http.ResponseWriter, r *http.Request) {
+	st := c.server.Status()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(serverStatus{
+		Name:          st.Name,
+		Status:        st.Status,
+		PlayersOnline: st.PlayersOnline,
+		TotalPlayers:  st.TotalPlayers,
+		Alliances:     st.Alliances,
+		Sectors:       st.Sectors})
+}
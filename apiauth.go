@@ -0,0 +1,425 @@
+package main
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/randstring"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Access levels of the HTTP API. A route requiring apiPublic can be used
+// without authenticating, the others match the roles in ifaces.APIRoles.
+const (
+	apiPublic = iota
+	apiReadOnly
+	apiModerator
+	apiAdmin
+)
+
+const (
+	discordAuthorizeURL = "https://discord.com/api/oauth2/authorize"
+	discordTokenURL     = "https://discord.com/api/oauth2/token"
+	discordUserURL      = "https://discord.com/api/users/@me"
+
+	oauthCookie     = "avocontrol_session"
+	oauthSessionTTL = 12 * time.Hour
+	oauthStateTTL   = 10 * time.Minute
+)
+
+var errBadCredentials = errors.New("invalid credentials")
+
+// apiPrincipal is the identity that an HTTP API request was authenticated as
+type apiPrincipal struct {
+	name     string
+	provider string
+	role     int
+	scopes   []string
+}
+
+func (p *apiPrincipal) String() string {
+	return p.provider + ":" + p.name
+}
+
+// allows returns true if the principal has the role that a route requires, or
+// was granted the routes scope directly
+func (p *apiPrincipal) allows(rt apiRoute) bool {
+	return p.role >= rt.role || hasScope(p.scopes, rt.scope)
+}
+
+// apiAuthProvider authenticates HTTP API requests. If a request doesn't carry
+// credentials that the provider handles, it returns a nil principal and no
+// error so that the next provider can be tried.
+type apiAuthProvider interface {
+	authenticate(r *http.Request) (*apiPrincipal, error)
+}
+
+// apiRole returns the access level of a role from ifaces.APIRoles, or
+// apiPublic if the role isn't known
+func apiRole(name string) int {
+	for i, r := range ifaces.APIRoles {
+		if r == name {
+			return i + 1
+		}
+	}
+	return apiPublic
+}
+
+/**************/
+/* API Tokens */
+/**************/
+
+// tokenAuth authenticates requests that carry an API token in their
+// Authorization header. Tokens may list roles, route scopes, or both.
+type tokenAuth struct{}
+
+func (tokenAuth) authenticate(r *http.Request) (*apiPrincipal, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" || strings.HasPrefix(header, "Basic ") {
+		return nil, nil
+	}
+
+	token := strings.TrimPrefix(header, "Bearer ")
+	scopes, ok := config.APITokenScopes(token)
+	if !ok {
+		return nil, errBadCredentials
+	}
+
+	// Tokens are logged by a short fingerprint rather than their value
+	sum := sha256.Sum256([]byte(token))
+	p := &apiPrincipal{
+		name:     hex.EncodeToString(sum[:4]),
+		provider: "token",
+		scopes:   scopes}
+
+	for _, s := range scopes {
+		if l := apiRole(s); l > p.role {
+			p.role = l
+		}
+	}
+
+	return p, nil
+}
+
+/**************/
+/* Basic Auth */
+/**************/
+
+// basicAuth authenticates requests using HTTP basic auth against the
+// configured API users
+type basicAuth struct{}
+
+func (basicAuth) authenticate(r *http.Request) (*apiPrincipal, error) {
+	name, pass, ok := r.BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+
+	u, ok := config.APIUser(name)
+	if !ok || bcrypt.CompareHashAndPassword([]byte(u.Password),
+		[]byte(pass)) != nil {
+		return nil, errBadCredentials
+	}
+
+	return &apiPrincipal{name: name, provider: "basic", role: apiRole(u.Role)},
+		nil
+}
+
+/*****************/
+/* Discord OAuth */
+/*****************/
+
+type oauthSession struct {
+	principal *apiPrincipal
+	expires   time.Time
+}
+
+// oauthAuth authenticates requests by a session cookie, which is issued once a
+// user has logged in through Discord. The users role is decided by the auth
+// level of their Discord roles when they log in.
+type oauthAuth struct {
+	mutex    sync.Mutex
+	app      ifaces.APIOAuth
+	bot      ifaces.IBotAuthorizer
+	client   *http.Client
+	sessions map[string]*oauthSession
+	states   map[string]time.Time
+}
+
+func newOAuthAuth(app ifaces.APIOAuth, bot ifaces.IBotAuthorizer) *oauthAuth {
+	return &oauthAuth{
+		app:      app,
+		bot:      bot,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		sessions: make(map[string]*oauthSession),
+		states:   make(map[string]time.Time)}
+}
+
+func (o *oauthAuth) authenticate(r *http.Request) (*apiPrincipal, error) {
+	cookie, err := r.Cookie(oauthCookie)
+	if err != nil {
+		return nil, nil
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	sess, ok := o.sessions[cookie.Value]
+	if !ok || time.Now().After(sess.expires) {
+		delete(o.sessions, cookie.Value)
+		return nil, errBadCredentials
+	}
+
+	return sess.principal, nil
+}
+
+// expire removes sessions and login states that are no longer valid. It must
+// be called with the mutex held.
+func (o *oauthAuth) expire(now time.Time) {
+	for id, sess := range o.sessions {
+		if now.After(sess.expires) {
+			delete(o.sessions, id)
+		}
+	}
+
+	for state, expires := range o.states {
+		if now.After(expires) {
+			delete(o.states, state)
+		}
+	}
+}
+
+// roleFor returns the highest API role that a Discord auth level grants
+func (o *oauthAuth) roleFor(authlvl int) int {
+	role := apiPublic
+	for name, lvl := range o.app.Levels {
+		if authlvl >= lvl && apiRole(name) > role {
+			role = apiRole(name)
+		}
+	}
+	return role
+}
+
+// handleLogin redirects the user to Discord to authorize the bot
+func (o *oauthAuth) handleLogin(w http.ResponseWriter, r *http.Request) {
+	state := randstring.New(32)
+
+	o.mutex.Lock()
+	o.expire(time.Now())
+	o.states[state] = time.Now().Add(oauthStateTTL)
+	o.mutex.Unlock()
+
+	q := url.Values{
+		"client_id":     {o.app.ClientID},
+		"redirect_uri":  {o.app.RedirectURL},
+		"response_type": {"code"},
+		"scope":         {"identify"},
+		"state":         {state}}
+
+	http.Redirect(w, r, discordAuthorizeURL+"?"+q.Encode(), http.StatusFound)
+}
+
+// handleCallback completes a Discord login, and issues a session cookie if the
+// user has been granted an API role
+func (o *oauthAuth) handleCallback(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+
+	o.mutex.Lock()
+	expires, ok := o.states[state]
+	delete(o.states, state)
+	o.mutex.Unlock()
+
+	if !ok || time.Now().After(expires) {
+		http.Error(w, "login expired, please try again", http.StatusBadRequest)
+		return
+	}
+
+	uid, name, err := o.identify(r.URL.Query().Get("code"))
+	if err != nil {
+		http.Error(w, "failed to log in with Discord", http.StatusBadGateway)
+		return
+	}
+
+	authlvl, err := o.bot.MemberAuthLevel(uid)
+	if err != nil {
+		http.Error(w, "unable to check your Discord roles",
+			http.StatusForbidden)
+		return
+	}
+
+	role := o.roleFor(authlvl)
+	if role == apiPublic {
+		http.Error(w, "your Discord roles do not grant API access",
+			http.StatusForbidden)
+		return
+	}
+
+	id := randstring.New(48)
+	o.mutex.Lock()
+	o.sessions[id] = &oauthSession{
+		principal: &apiPrincipal{
+			name:     fmt.Sprintf("%s(%s)", name, uid),
+			provider: "discord",
+			role:     role},
+		expires: time.Now().Add(oauthSessionTTL)}
+	o.mutex.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(oauthSessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode})
+
+	fmt.Fprintf(w, "Logged in as %s with the %s role\n", name,
+		ifaces.APIRoles[role-1])
+}
+
+// handleLogout ends the session of the user
+func (o *oauthAuth) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(oauthCookie); err == nil {
+		o.mutex.Lock()
+		delete(o.sessions, cookie.Value)
+		o.mutex.Unlock()
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: oauthCookie, Path: "/", MaxAge: -1})
+	fmt.Fprintln(w, "Logged out")
+}
+
+// identify exchanges an authorization code for the Discord user that granted
+// it, returning their ID and username
+func (o *oauthAuth) identify(code string) (string, string, error) {
+	var (
+		token struct {
+			AccessToken string `json:"access_token"`
+		}
+		user struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+		}
+	)
+
+	resp, err := o.client.PostForm(discordTokenURL, url.Values{
+		"client_id":     {o.app.ClientID},
+		"client_secret": {o.app.ClientSecret},
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {o.app.RedirectURL}})
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("token exchange failed: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", "", err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, discordUserURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	uresp, err := o.client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer uresp.Body.Close()
+
+	if uresp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("user lookup failed: %s", uresp.Status)
+	}
+
+	if err := json.NewDecoder(uresp.Body).Decode(&user); err != nil {
+		return "", "", err
+	}
+
+	return user.ID, user.Username, nil
+}
+
+/**************/
+/* Middleware */
+/**************/
+
+// authorize wraps a route handler to authenticate the request with the first
+// provider that recognizes its credentials, check that the principal has the
+// access the route requires, and log the request
+func (c *Core) authorize(rt apiRoute,
+	providers []apiAuthProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			rec = &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+			p   *apiPrincipal
+			err error
+		)
+
+		defer func() {
+			name := ""
+			if p != nil {
+				name = p.String()
+			}
+			logger.LogHTTP(c, rec.code, r, name)
+		}()
+
+		if rt.role == apiPublic {
+			rt.handler(rec, r)
+			return
+		}
+
+		for _, prov := range providers {
+			if p, err = prov.authenticate(r); err != nil || p != nil {
+				break
+			}
+		}
+
+		switch {
+		case err != nil:
+			http.Error(rec, err.Error(), http.StatusUnauthorized)
+			return
+
+		// Without any way to authenticate configured, the API is open to read
+		case p == nil && !config.APIAuthEnabled():
+			p = &apiPrincipal{name: "anonymous", provider: "open",
+				role: apiReadOnly}
+
+		case p == nil:
+			rec.Header().Set("WWW-Authenticate", `Basic realm="avorioncontrol"`)
+			http.Error(rec, "authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		if !p.allows(rt) {
+			http.Error(rec, "access to "+rt.path+" is not permitted",
+				http.StatusForbidden)
+			return
+		}
+
+		rt.handler(rec, r)
+	}
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope || s == "*" {
+			return true
+		}
+	}
+	return false
+}
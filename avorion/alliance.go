@@ -14,6 +14,7 @@ type Alliance struct {
 	name     string
 	leader   *Player
 	members  []*Player
+	lead     ifaces.AllianceLeadership
 	loglevel int
 	server   *Server
 
@@ -41,17 +42,49 @@ func (a *Alliance) Update() error {
 	return nil
 }
 
-// UpdateFromData updates the alliances information using the data from
-//	a successful reAllianceData match
-func (a *Alliance) UpdateFromData(d [13]string) error {
+// UpdateFromData updates the alliances information using the data reported by
+//	getplayerdata
+func (a *Alliance) UpdateFromData(d ifaces.AllianceData) error {
+	if d.Index != a.index {
+		return fmt.Errorf("alliance data is for %s, not %s", d.Index, a.index)
+	}
+
+	a.name = d.Name
+	a.resources = d.Resources
 	return nil
 }
 
+// Leadership returns the last known leadership of the alliance
+func (a *Alliance) Leadership() ifaces.AllianceLeadership {
+	return a.lead
+}
+
+// SetLeadership updates the leadership of the alliance, and resolves the
+// leader and members to the players that we are tracking
+func (a *Alliance) SetLeadership(l ifaces.AllianceLeadership) {
+	a.lead = l
+	a.leader = nil
+	a.members = make([]*Player, 0)
+
+	for _, index := range l.Members {
+		if p, ok := a.server.Player(index).(*Player); ok && p != nil {
+			a.members = append(a.members, p)
+		}
+	}
+
+	if p, ok := a.server.Player(l.Leader).(*Player); ok && p != nil {
+		a.leader = p
+	}
+}
+
 // AddJump registers a jump that a player took into a system
 func (a *Alliance) AddJump(sc ifaces.ShipCoordData) {
-	sc.Time = time.Now()
+	// Jumps from the mod event file carry the time that the jump happened
+	if sc.Time.IsZero() {
+		sc.Time = time.Now()
+	}
 	a.jumphistory = append(a.jumphistory, sc)
-	if len(a.jumphistory) > 1000 {
+	if len(a.jumphistory) > jumpHistoryLimit {
 		a.jumphistory = a.jumphistory[1:]
 	}
 
@@ -72,9 +105,12 @@ func (a *Alliance) AddJump(sc ifaces.ShipCoordData) {
 	s.Jumphistory = append(s.Jumphistory, jump)
 
 	id, _ := strconv.Atoi(a.Index())
-	a.server.tracking.AddJump(s.Index, int64(id), 1, *jump)
+	a.server.trackingDB().AddJump(s.Index, int64(id), 1, *jump)
+	a.server.TrackShip(a.index, sc)
 
 	logger.LogDebug(a, "Updated jumphistory")
+	a.server.checkJumpAnomaly(a, a.Name(), a.jumphistory, sc)
+	a.server.checkAllianceAlerts(a.index, a.Name(), sc)
 }
 
 /************************/
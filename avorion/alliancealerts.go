@@ -0,0 +1,269 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Alerts about the same ship and place are only sent once in this long, so
+	// that a ship moving around inside a region doesn't flood the channel
+	allianceAlertCooldown = 10 * time.Minute
+
+	alertContested = "%s**Contested Region**: `%s` jumped into **%s** " +
+		"at `%d:%d`"
+	alertHostile = "%s**Hostile Ship**: `%s` (_%s_) jumped into claimed " +
+		"sector `%d:%d`"
+)
+
+// allianceAlerts holds the alert settings and sector claims of the alliances,
+// and when alerts were last sent so that they can be rate limited
+type allianceAlerts struct {
+	lock   sync.Mutex
+	alerts map[string]*ifaces.AllianceAlerts
+	claims map[ifaces.SectorCoord]string
+	sent   map[string]time.Time
+}
+
+// loadAllianceAlerts loads the alliance alert settings from the tracking DB
+func (s *Server) loadAllianceAlerts() {
+	aa := &s.alliancealerts
+	aa.lock.Lock()
+	defer aa.lock.Unlock()
+
+	aa.alerts = make(map[string]*ifaces.AllianceAlerts)
+	aa.claims = make(map[ifaces.SectorCoord]string)
+	aa.sent = make(map[string]time.Time)
+
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return
+	}
+
+	alerts, err := tracking.AllianceAlerts()
+	if err != nil {
+		logger.LogError(s, "AllianceAlerts: "+err.Error())
+		return
+	}
+
+	for i := range alerts {
+		a := alerts[i]
+		aa.alerts[a.Alliance] = &a
+		for _, c := range a.Claims {
+			aa.claims[c] = a.Alliance
+		}
+	}
+}
+
+// allianceOf returns the index of the alliance that a faction belongs to: the
+// alliance itself, or the alliance that a player is a member of. An empty
+// string is returned for players that aren't in an alliance.
+func (s *Server) allianceOf(index string) string {
+	if s.Alliance(index) != nil {
+		return index
+	}
+
+	for _, a := range s.alliances {
+		lead := a.Leadership()
+		if lead.Leader == index || lead.Founder == index {
+			return a.index
+		}
+		for _, m := range lead.Members {
+			if m == index {
+				return a.index
+			}
+		}
+	}
+
+	return ""
+}
+
+// checkAllianceAlerts alerts the alliance that a ship belongs to if it jumped
+// into a contested region, and the alliance that claimed the sector if the
+// ship doesn't belong to it
+func (s *Server) checkAllianceAlerts(index, owner string,
+	sc ifaces.ShipCoordData) {
+	aa := &s.alliancealerts
+	aa.lock.Lock()
+	defer aa.lock.Unlock()
+
+	if len(aa.alerts) == 0 {
+		return
+	}
+
+	ally := s.allianceOf(index)
+	now := s.clock.Now()
+
+	if a, ok := aa.alerts[ally]; ok && a.Channel != "" {
+		for _, r := range s.config.ContestedRegions() {
+			if r.Contains(sc.X, sc.Y) {
+				key := ally + ":" + sc.Name + ":" + r.Name
+				if aa.ready(key, now) {
+					s.sendAllianceAlert(a, sprintf(alertContested, mentionRole(a),
+						sc.Name, r.Name, sc.X, sc.Y))
+				}
+				break
+			}
+		}
+	}
+
+	claimant, ok := aa.claims[ifaces.SectorCoord{X: sc.X, Y: sc.Y}]
+	if !ok || claimant == ally {
+		return
+	}
+
+	if a, ok := aa.alerts[claimant]; ok && a.Channel != "" {
+		key := claimant + ":" + index + ":" + sc.Name + sprintf(":%d:%d", sc.X,
+			sc.Y)
+		if aa.ready(key, now) {
+			s.sendAllianceAlert(a, sprintf(alertHostile, mentionRole(a), sc.Name,
+				owner, sc.X, sc.Y))
+		}
+	}
+}
+
+// ready returns true if an alert with the given key hasn't been sent within
+// the cooldown, and records that it is being sent. aa.lock must be held.
+func (aa *allianceAlerts) ready(key string, now time.Time) bool {
+	if last, ok := aa.sent[key]; ok && now.Sub(last) < allianceAlertCooldown {
+		return false
+	}
+
+	for k, last := range aa.sent {
+		if now.Sub(last) >= allianceAlertCooldown {
+			delete(aa.sent, k)
+		}
+	}
+
+	aa.sent[key] = now
+	return true
+}
+
+// mentionRole returns a mention of the role that an alliance is alerted with,
+// followed by a newline, or an empty string if it hasn't set one
+func mentionRole(a *ifaces.AllianceAlerts) string {
+	if a.Role == "" {
+		return ""
+	}
+	return "<@&" + a.Role + ">\n"
+}
+
+// sendAllianceAlert publishes an alert for the channel linked to an alliance
+func (s *Server) sendAllianceAlert(a *ifaces.AllianceAlerts, msg string) {
+	logger.LogDebug(s, sprintf("Alliance alert for %s: %s", a.Alliance,
+		strings.ReplaceAll(msg, "\n", " ")))
+	s.bus.Publish(ifaces.EventTopicAlliance, ifaces.ChatData{
+		Channel: a.Channel, Msg: msg}, 0)
+}
+
+/*************************************/
+/* IFace ifaces.IAllianceAlertServer */
+/*************************************/
+
+// AllianceAlerts returns the alert settings and claimed sectors of an alliance
+func (s *Server) AllianceAlerts(index string) (ifaces.AllianceAlerts, bool) {
+	aa := &s.alliancealerts
+	aa.lock.Lock()
+	defer aa.lock.Unlock()
+
+	a, ok := aa.alerts[index]
+	if !ok {
+		return ifaces.AllianceAlerts{Alliance: index}, false
+	}
+
+	out := *a
+	out.Claims = append([]ifaces.SectorCoord{}, a.Claims...)
+	return out, true
+}
+
+// SetAllianceAlerts sets the Discord channel and role that an alliance is
+// alerted in. An empty channel turns the alerts off, but keeps the claims.
+func (s *Server) SetAllianceAlerts(in ifaces.AllianceAlerts) error {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return ifaces.ErrDataUnavailable
+	}
+
+	aa := &s.alliancealerts
+	aa.lock.Lock()
+	defer aa.lock.Unlock()
+
+	if err := tracking.SetAllianceAlerts(in); err != nil {
+		return err
+	}
+
+	a, ok := aa.alerts[in.Alliance]
+	if !ok {
+		a = &ifaces.AllianceAlerts{Alliance: in.Alliance,
+			Claims: make([]ifaces.SectorCoord, 0)}
+		aa.alerts[in.Alliance] = a
+	}
+
+	a.Channel, a.Role = in.Channel, in.Role
+	return nil
+}
+
+// ClaimSector claims a sector for an alliance, or removes its claim. A sector
+// can only be claimed by one alliance at a time.
+func (s *Server) ClaimSector(index string, x, y int, claim bool) error {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return ifaces.ErrDataUnavailable
+	}
+
+	aa := &s.alliancealerts
+	aa.lock.Lock()
+	defer aa.lock.Unlock()
+
+	coord := ifaces.SectorCoord{X: x, Y: y}
+	claimant, claimed := aa.claims[coord]
+
+	a, ok := aa.alerts[index]
+	if !ok {
+		a = &ifaces.AllianceAlerts{Alliance: index,
+			Claims: make([]ifaces.SectorCoord, 0)}
+		aa.alerts[index] = a
+	}
+
+	if !claim {
+		if !claimed || claimant != index {
+			return errors.New("that sector isn't claimed by the alliance")
+		}
+
+		if err := tracking.UnclaimSector(index, x, y); err != nil {
+			return err
+		}
+
+		delete(aa.claims, coord)
+		for i, c := range a.Claims {
+			if c == coord {
+				a.Claims = append(a.Claims[:i], a.Claims[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}
+
+	if claimed {
+		if claimant == index {
+			return nil
+		}
+		name := claimant
+		if other := s.Alliance(claimant); other != nil {
+			name = other.Name()
+		}
+		return errors.New("that sector is already claimed by " + name)
+	}
+
+	if err := tracking.ClaimSector(index, x, y); err != nil {
+		return err
+	}
+
+	aa.claims[coord] = index
+	a.Claims = append(a.Claims, coord)
+	return nil
+}
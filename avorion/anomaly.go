@@ -0,0 +1,71 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	anomalyWindow   = time.Minute
+	anomalyCooldown = 5 * time.Minute
+
+	warnJumpAnomaly   = `implausible travel by ship [%s]: %.1f sectors in %s`
+	noticeJumpAnomaly = "**Moderation Alert**: Possible teleport or speed hack\n" +
+		"**Owner:** `%s`\n**Ship:** `%s`\n**Distance:** _%.1f sectors in %s_\n" +
+		"**Evidence:**\n%s"
+)
+
+// checkJumpAnomaly reviews the recent jumps made by a single ship and raises a
+// moderation alert if the distance that it covered within the anomaly window
+// exceeds the configured rate. Alerts for a ship are rate limited so that a
+// single incident doesn't flood the log channel.
+func (s *Server) checkJumpAnomaly(owner logger.ILogger, name string,
+	history []ifaces.ShipCoordData, sc ifaces.ShipCoordData) {
+	rate := s.config.JumpAnomalyRate()
+	if rate < 1 {
+		return
+	}
+
+	key := owner.UUID() + ":" + sc.Name
+	if last, ok := s.anomalies[key]; ok && time.Since(last) < anomalyCooldown {
+		return
+	}
+
+	// Gather the jumps that this ship made within the window (oldest first)
+	trail := make([]ifaces.ShipCoordData, 0)
+	for _, j := range history {
+		if j.Name == sc.Name && sc.Time.Sub(j.Time) <= anomalyWindow {
+			trail = append(trail, j)
+		}
+	}
+
+	if len(trail) < 2 {
+		return
+	}
+
+	dist := float64(0)
+	for i := 1; i < len(trail); i++ {
+		dx := float64(trail[i].X - trail[i-1].X)
+		dy := float64(trail[i].Y - trail[i-1].Y)
+		dist += math.Sqrt(dx*dx + dy*dy)
+	}
+
+	if dist <= float64(rate) {
+		return
+	}
+
+	elapsed := trail[len(trail)-1].Time.Sub(trail[0].Time).Round(time.Second)
+	evidence := make([]string, 0)
+	for _, j := range trail {
+		evidence = append(evidence, sprintf("> `%s` %d:%d",
+			j.Time.Format("15:04:05"), j.X, j.Y))
+	}
+
+	s.anomalies[key] = time.Now()
+	logger.LogWarning(owner, sprintf(warnJumpAnomaly, sc.Name, dist, elapsed))
+	s.SendLog(ifaces.ChatData{Msg: sprintf(noticeJumpAnomaly, name, sc.Name,
+		dist, elapsed, strings.Join(evidence, "\n"))})
+}
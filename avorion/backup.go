@@ -0,0 +1,437 @@
+package avorion
+
+import (
+	"archive/tar"
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupTimeFormat = "20060102-150405"
+
+	noticeBackupFailed = "**Server Warning**: Failed to back up the galaxy: %s"
+	noticeRestored     = "**Server Notice**: Restored the galaxy from the " +
+		"backup `%s`. The galaxy as it was before the restore was saved as `%s`"
+)
+
+// Backups are named after their galaxy, the time they were taken, and why
+var reBackupName = regexp.MustCompile(
+	`^(.+)-([0-9]{8}-[0-9]{6})-([a-z]+)\.tar(\.gz)?$`)
+
+// backupManager takes backups of the galaxy directory on a schedule, and
+// makes sure that only one backup or restore is writing at a time
+type backupManager struct {
+	lock sync.Mutex
+
+	// Guards the fields below
+	state   sync.Mutex
+	next    time.Time
+	running bool
+}
+
+// galaxyDir returns the directory of the configured galaxy
+func (s *Server) galaxyDir() string {
+	return strings.TrimSuffix(s.config.DataPath(), "/") + "/" + s.config.Galaxy()
+}
+
+// scheduleBackup sets the time of the next scheduled backup, or clears it if
+// scheduled backups are disabled
+func (s *Server) scheduleBackup() {
+	s.backup.state.Lock()
+	defer s.backup.state.Unlock()
+
+	s.backup.next = time.Time{}
+	if d := s.config.BackupInterval(); d > 0 {
+		s.backup.next = s.clock.Now().Add(d)
+	}
+}
+
+// nextBackup returns when the next scheduled backup will be taken
+func (s *Server) nextBackup() (time.Time, bool) {
+	s.backup.state.Lock()
+	defer s.backup.state.Unlock()
+	return s.backup.next, !s.backup.next.IsZero()
+}
+
+// checkBackup starts a scheduled backup if one is due. The backup runs in the
+// background, since a large galaxy can take a while to archive.
+func (s *Server) checkBackup() {
+	s.backup.state.Lock()
+	due := !s.backup.next.IsZero() && !s.clock.Now().Before(s.backup.next)
+	if !due || s.backup.running {
+		s.backup.state.Unlock()
+		return
+	}
+	s.backup.running = true
+	s.backup.state.Unlock()
+
+	s.scheduleBackup()
+
+	go func() {
+		defer shutdown.Recover()
+		defer func() {
+			s.backup.state.Lock()
+			s.backup.running = false
+			s.backup.state.Unlock()
+		}()
+
+		if _, err := s.Backup(ifaces.BackupScheduled); err != nil {
+			logger.LogError(s, "Scheduled backup: "+err.Error())
+			s.SendLog(ifaces.ChatData{Msg: sprintf(noticeBackupFailed,
+				err.Error())})
+		}
+	}()
+}
+
+// backupGalaxy writes a backup of the galaxy directory, and removes the backups
+// that are no longer retained. s.backup.lock must be held.
+func (s *Server) backupGalaxy(reason string) (ifaces.BackupInfo, error) {
+	dir := s.config.BackupPath()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return ifaces.BackupInfo{}, err
+	}
+
+	// Make sure that the files on disk are current before archiving them
+	if s.IsUp() {
+		if _, err := s.RunCommandPriority("save",
+			ifaces.CommandPriorityBackground); err != nil {
+			logger.LogWarning(s, "Failed to save the galaxy before the backup: "+
+				err.Error())
+		}
+	}
+
+	now := s.clock.Now()
+	name := sprintf("%s-%s-%s.tar", s.config.Galaxy(), now.Format(backupTimeFormat),
+		reason)
+	if s.config.BackupCompress() {
+		name += ".gz"
+	}
+
+	file := dir + "/" + name
+	start := time.Now()
+	logger.LogInfo(s, "Backing up the galaxy to "+file)
+
+	if err := writeBackup(s.galaxyDir(), file+".partial",
+		s.config.BackupCompress()); err != nil {
+		os.Remove(file + ".partial")
+		return ifaces.BackupInfo{}, err
+	}
+
+	if err := os.Rename(file+".partial", file); err != nil {
+		os.Remove(file + ".partial")
+		return ifaces.BackupInfo{}, err
+	}
+
+	info := ifaces.BackupInfo{Name: name, Time: now, Reason: reason}
+	if fi, err := os.Stat(file); err == nil {
+		info.Size = fi.Size()
+	}
+
+	logger.LogInfo(s, sprintf("Backed up the galaxy in %s (%d bytes)",
+		time.Since(start).Round(time.Second), info.Size))
+
+	s.rotateBackups()
+	return info, nil
+}
+
+// rotateBackups removes the backups of the galaxy that are past the configured
+// count or age. s.backup.lock must be held.
+func (s *Server) rotateBackups() {
+	backups, err := s.listBackups()
+	if err != nil {
+		logger.LogError(s, "Failed to list backups: "+err.Error())
+		return
+	}
+
+	keep, age := s.config.BackupRetention()
+	now := s.clock.Now()
+
+	for i, b := range backups {
+		if i < keep && (age == 0 || now.Sub(b.Time) < age) {
+			continue
+		}
+
+		if err := os.Remove(s.config.BackupPath() + "/" + b.Name); err != nil {
+			logger.LogError(s, "Failed to remove old backup: "+err.Error())
+			continue
+		}
+		logger.LogInfo(s, "Removed old backup "+b.Name)
+	}
+}
+
+// listBackups returns the backups of the galaxy, newest first
+func (s *Server) listBackups() ([]ifaces.BackupInfo, error) {
+	files, err := ioutil.ReadDir(s.config.BackupPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ifaces.BackupInfo{}, nil
+		}
+		return nil, err
+	}
+
+	backups := make([]ifaces.BackupInfo, 0)
+	for _, f := range files {
+		m := reBackupName.FindStringSubmatch(f.Name())
+		if f.IsDir() || m == nil || m[1] != s.config.Galaxy() {
+			continue
+		}
+
+		t, err := time.ParseInLocation(backupTimeFormat, m[2], time.Local)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, ifaces.BackupInfo{
+			Name: f.Name(), Time: t, Reason: m[3], Size: f.Size()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+
+	return backups, nil
+}
+
+// writeBackup archives the contents of a directory into a tar file
+func writeBackup(dir, file string, compress bool) error {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		w  io.Writer = f
+		gz *gzip.Writer
+	)
+
+	if compress {
+		gz = gzip.NewWriter(f)
+		w = gz
+	}
+
+	tw := tar.NewWriter(w)
+
+	err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		// Sockets and the like can't be restored, so only keep what can be
+		if !fi.Mode().IsRegular() && !fi.IsDir() {
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if fi.IsDir() {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+
+	return f.Sync()
+}
+
+// extractBackup extracts a backup written by writeBackup into a directory
+func extractBackup(file, dir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(file, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Don't let a tampered archive write outside of the directory
+		path := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return errors.New("backup contains an invalid path: " + hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0700); err != nil {
+				return err
+			}
+
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return err
+			}
+
+			out, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+				os.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+/******************************/
+/* IFace ifaces.IBackupServer */
+/******************************/
+
+// Backup writes a backup of the galaxy directory. If the server is running, the
+// galaxy is saved first.
+func (s *Server) Backup(reason string) (ifaces.BackupInfo, error) {
+	s.backup.lock.Lock()
+	defer s.backup.lock.Unlock()
+	return s.backupGalaxy(reason)
+}
+
+// Backups returns the backups of the galaxy, newest first
+func (s *Server) Backups() ([]ifaces.BackupInfo, error) {
+	return s.listBackups()
+}
+
+// RestoreBackup replaces the galaxy directory with the contents of a backup.
+// The server has to be stopped, and the galaxy is backed up before it is
+// replaced so that the restore can be undone.
+func (s *Server) RestoreBackup(name string) error {
+	return state.run(s, lifecycleRestoring, false, func() error {
+		if s.IsUp() {
+			return errors.New("the server has to be stopped first")
+		}
+
+		s.backup.lock.Lock()
+		defer s.backup.lock.Unlock()
+
+		// Only backups in the list can be restored, which keeps the name from
+		// pointing anywhere else
+		backups, err := s.listBackups()
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, b := range backups {
+			if b.Name == name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return errors.New("no backup named " + name)
+		}
+
+		galaxy := s.galaxyDir()
+		tmp := galaxy + ".restore"
+		os.RemoveAll(tmp)
+
+		logger.LogInfo(s, "Restoring the galaxy from "+name)
+		if err := extractBackup(s.config.BackupPath()+"/"+name, tmp); err != nil {
+			os.RemoveAll(tmp)
+			return err
+		}
+
+		// The backup being restored has already been extracted, so rotating
+		// the backups here can't remove it from under us
+		safety, err := s.backupGalaxy(ifaces.BackupPreRestore)
+		if err != nil {
+			os.RemoveAll(tmp)
+			return errors.New("failed to back up the current galaxy: " +
+				err.Error())
+		}
+
+		old := sprintf("%s.old-%d", galaxy, s.clock.Now().Unix())
+		if err := os.Rename(galaxy, old); err != nil && !os.IsNotExist(err) {
+			os.RemoveAll(tmp)
+			return err
+		}
+
+		if err := os.Rename(tmp, galaxy); err != nil {
+			os.Rename(old, galaxy)
+			return err
+		}
+		os.RemoveAll(old)
+
+		if err := s.config.LoadGameConfig(); err != nil {
+			logger.LogWarning(s, "Failed to reload server.ini: "+err.Error())
+		}
+
+		logger.LogInfo(s, "Restored the galaxy from "+name)
+		s.SendLog(ifaces.ChatData{Msg: sprintf(noticeRestored, name,
+			safety.Name)})
+		return nil
+	})
+}
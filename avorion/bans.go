@@ -0,0 +1,176 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	rconUnban = `unban %s`
+
+	noticeTempBanLifted = "**Moderation Action**: Temporary ban lifted\n" +
+		"**Player:** `%s`"
+	reasonTempBan = "%s (banned until %s)"
+)
+
+// How often temporary bans are checked for expiry
+const banCheckInterval = time.Minute
+
+// liftBans lifts temporary bans as they expire, for as long as the server is
+// running
+func (s *Server) liftBans(closech chan struct{}) {
+	for {
+		select {
+		case <-closech:
+			return
+		case <-s.exit:
+			return
+		case <-s.clock.After(banCheckInterval):
+		}
+
+		s.liftExpiredBans()
+	}
+}
+
+// liftExpiredBans unbans players whose temporary bans have expired
+func (s *Server) liftExpiredBans() {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return
+	}
+
+	bans, err := tracking.ExpiredBans(s.clock.Now())
+	if err != nil {
+		logger.LogError(s, "ExpiredBans: "+err.Error())
+		return
+	}
+
+	for _, b := range bans {
+		if err := s.unban(b); err != nil {
+			logger.LogError(s, sprintf("Failed to lift temporary ban for %s: %s",
+				b.Name, err.Error()))
+			continue
+		}
+
+		logger.LogInfo(s, "Lifted temporary ban for "+b.Name)
+		s.SendLog(ifaces.ChatData{Msg: sprintf(noticeTempBanLifted, b.Name)})
+	}
+}
+
+// unban lifts a ban in the game and removes its record. Players are unbanned
+// by their Steam ID when it's known, and by name otherwise.
+func (s *Server) unban(b ifaces.BanRecord) error {
+	target := b.Steam64
+	if target == "" || target == "0" {
+		target = b.Name
+	}
+
+	if _, err := s.RunCommand(sprintf(rconUnban, target)); err != nil {
+		return err
+	}
+
+	tracking := s.trackingDB()
+	if tracking != nil {
+		if err := tracking.RemoveBan(b.Index); err != nil {
+			logger.LogError(s, "RemoveBan: "+err.Error())
+		}
+	}
+
+	return nil
+}
+
+/***************************/
+/* IFace ifaces.IBanServer */
+/***************************/
+
+// BanPlayer bans a player and records who banned them and why. A ban with a
+// duration is lifted automatically once it expires, while a duration of zero
+// bans the player permanently. Temporary bans are refused if they can't be
+// recorded.
+func (s *Server) BanPlayer(p ifaces.IPlayer, author, reason string,
+	d time.Duration) (ifaces.BanRecord, error) {
+	now := s.clock.Now()
+	b := ifaces.BanRecord{
+		Index:   p.Index(),
+		Name:    p.Name(),
+		Steam64: strconv.FormatInt(p.SteamUID(), 10),
+		Author:  author,
+		Reason:  reason,
+		Time:    now}
+
+	if d > 0 {
+		b.Expires = now.Add(d)
+		reason = sprintf(reasonTempBan, reason,
+			b.Expires.In(s.config.Location()).Format("2006-01-02 15:04 MST"))
+	}
+
+	err := ifaces.ErrDataUnavailable
+	tracking := s.trackingDB()
+	if tracking != nil {
+		err = tracking.AddBan(b)
+	}
+
+	// A temporary ban that can't be recorded would never be lifted, but a
+	// permanent ban is still issued and the error returned
+	if err != nil && d > 0 {
+		return b, err
+	}
+
+	p.Ban(reason)
+	logger.LogInfo(s, sprintf("%s banned %s: %s", author, b.Name, reason))
+	return b, err
+}
+
+// UnbanPlayer lifts the ban of a player, given by index or name. Players that
+// were banned without the bot can be unbanned as long as the bot knows them.
+func (s *Server) UnbanPlayer(ref string) (ifaces.BanRecord, error) {
+	bans, err := s.Bans()
+	if err != nil && err != ifaces.ErrDataUnavailable {
+		return ifaces.BanRecord{}, err
+	}
+
+	var (
+		b     ifaces.BanRecord
+		found bool
+	)
+
+	for _, ban := range bans {
+		if ban.Index == ref || strings.EqualFold(ban.Name, ref) {
+			b, found = ban, true
+			break
+		}
+	}
+
+	if !found {
+		p := s.Player(ref)
+		if p == nil {
+			p = s.PlayerFromName(ref)
+		}
+		if p == nil {
+			return b, errors.New("there is no recorded ban for " + ref)
+		}
+
+		b = ifaces.BanRecord{Index: p.Index(), Name: p.Name(),
+			Steam64: strconv.FormatInt(p.SteamUID(), 10)}
+	}
+
+	if err := s.unban(b); err != nil {
+		return b, err
+	}
+
+	logger.LogInfo(s, "Lifted the ban of "+b.Name)
+	return b, nil
+}
+
+// Bans returns the bans issued through the bot, newest first
+func (s *Server) Bans() ([]ifaces.BanRecord, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return nil, ifaces.ErrDataUnavailable
+	}
+	return tracking.Bans()
+}
@@ -0,0 +1,119 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	queueDepthWarning = 10
+	errQueueTimeout   = `%w after %s (queue depth: %d)`
+)
+
+// queueTimeouts is the maximum amount of time that a caller of a given priority
+// will wait in the queue before giving up on running its command
+var queueTimeouts = map[int]time.Duration{
+	ifaces.CommandPriorityHealth:     30 * time.Second,
+	ifaces.CommandPriorityUser:       time.Minute,
+	ifaces.CommandPriorityBackground: 5 * time.Minute}
+
+// commandQueue serializes access to RCON. Rather than letting every caller
+// contend for a single mutex, waiters are queued by priority and handed the
+// lock in order (health checks, then user commands, then background refreshes).
+type commandQueue struct {
+	mutex   *sync.Mutex
+	busy    bool
+	waiting [ifaces.CommandPriorityBackground + 1][]chan struct{}
+}
+
+func newCommandQueue() *commandQueue {
+	return &commandQueue{mutex: new(sync.Mutex)}
+}
+
+// acquire blocks until the caller has exclusive access to RCON, or the timeout
+// for its priority has passed
+func (q *commandQueue) acquire(p int) error {
+	if p < ifaces.CommandPriorityHealth || p > ifaces.CommandPriorityBackground {
+		p = ifaces.CommandPriorityUser
+	}
+
+	q.mutex.Lock()
+	if !q.busy {
+		q.busy = true
+		q.mutex.Unlock()
+		return nil
+	}
+
+	ch := make(chan struct{})
+	q.waiting[p] = append(q.waiting[p], ch)
+	q.mutex.Unlock()
+
+	timeout := queueTimeouts[p]
+	select {
+	case <-ch:
+		return nil
+
+	case <-time.After(timeout):
+		q.mutex.Lock()
+		for i, w := range q.waiting[p] {
+			if w == ch {
+				q.waiting[p] = append(q.waiting[p][:i], q.waiting[p][i+1:]...)
+				depth := q.depth()
+				q.mutex.Unlock()
+				return fmt.Errorf(errQueueTimeout, ifaces.ErrRCONTimeout, timeout, depth)
+			}
+		}
+		q.mutex.Unlock()
+
+		// We were handed the lock while timing out, so pass it along
+		q.release()
+		return fmt.Errorf(errQueueTimeout, ifaces.ErrRCONTimeout, timeout, 0)
+	}
+}
+
+// release hands RCON access to the next waiter with the highest priority
+func (q *commandQueue) release() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for p := range q.waiting {
+		if len(q.waiting[p]) > 0 {
+			ch := q.waiting[p][0]
+			q.waiting[p] = q.waiting[p][1:]
+			close(ch)
+			return
+		}
+	}
+
+	q.busy = false
+}
+
+// Depth returns the number of callers waiting at each priority level
+func (q *commandQueue) Depth() []int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	depth := make([]int, len(q.waiting))
+	for p := range q.waiting {
+		depth[p] = len(q.waiting[p])
+	}
+	return depth
+}
+
+// total returns the total number of callers waiting for RCON access
+func (q *commandQueue) total() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.depth()
+}
+
+// depth returns the total number of waiting callers. The mutex must be held.
+func (q *commandQueue) depth() int {
+	total := 0
+	for p := range q.waiting {
+		total += len(q.waiting[p])
+	}
+	return total
+}
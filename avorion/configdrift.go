@@ -0,0 +1,191 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	dbInfoSettings = `settings`
+
+	// Drift reports are cut short so that they fit in a Discord message
+	configDriftMaxLines = 15
+
+	noticeConfigDrift = "**Server Notice**: server.ini has changed since the " +
+		"last start\n%s"
+)
+
+// configSettingNames gives readable names to the settings that staff are most
+// likely to care about. Other settings are reported by their ini key.
+var configSettingNames = map[string]string{
+	"Game.Difficulty":              "Difficulty",
+	"Game.PlayerToPlayerDamage":    "PVP",
+	"Game.CollisionDamage":         "Collision damage",
+	"Game.MaximumBlocksPerCraft":   "Block limit",
+	"Game.MaximumVolumePerShip":    "Volume limit",
+	"Game.MaximumPlayerShips":      "Max player ships",
+	"Game.MaximumPlayerStations":   "Max player stations",
+	"Game.MaximumAllianceShips":    "Max alliance ships",
+	"Game.MaximumAllianceStations": "Max alliance stations",
+	"Administration.maxPlayers":    "Max players",
+	"Administration.name":          "Server name",
+}
+
+// checkConfigDrift compares the server.ini that the server was started with
+// against the one it was last started with, and reports the settings that
+// changed to the log channel. This catches edits that were made directly on
+// the filesystem rather than through the bot.
+func (s *Server) checkConfigDrift() {
+	settings := s.config.GameSettings()
+	if len(settings) == 0 {
+		return
+	}
+
+	// Secrets are stored as a hash, which is enough to tell that they changed
+	current := make(map[string]string, len(settings))
+	for k, v := range settings {
+		if secretSetting(k) {
+			sum := sha256.Sum256([]byte(v))
+			v = hex.EncodeToString(sum[:])
+		}
+		current[k] = v
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		logger.LogError(s, "Failed to snapshot server.ini: "+err.Error())
+		return
+	}
+
+	tracking := s.trackingDB()
+	last, err := tracking.ServerInfo(dbInfoSettings)
+	if err != nil {
+		logger.LogError(s, "GameDB: "+err.Error())
+		return
+	}
+
+	if err := tracking.SetServerInfo(dbInfoSettings, string(data)); err != nil {
+		logger.LogError(s, "GameDB: "+err.Error())
+	}
+
+	// Nothing to compare against on the first start
+	if last == "" {
+		return
+	}
+
+	previous := make(map[string]string)
+	if err := json.Unmarshal([]byte(last), &previous); err != nil {
+		logger.LogError(s, "Failed to read the last server.ini snapshot: "+
+			err.Error())
+		return
+	}
+
+	changes := configDrift(previous, current)
+	if len(changes) == 0 {
+		return
+	}
+
+	for _, c := range changes {
+		logger.LogInfo(s, "server.ini changed: "+c)
+	}
+
+	if len(changes) > configDriftMaxLines {
+		more := len(changes) - configDriftMaxLines
+		changes = append(changes[:configDriftMaxLines],
+			sprintf("_and %d more_", more))
+	}
+
+	s.SendLog(ifaces.ChatData{Msg: sprintf(noticeConfigDrift,
+		"> "+strings.Join(changes, "\n> "))})
+}
+
+// configDrift returns a description of each setting that differs between two
+// server.ini snapshots. Named settings are listed first.
+func configDrift(previous, current map[string]string) []string {
+	keys := make([]string, 0)
+	for k, v := range current {
+		// A changed seed is already reported by checkGalaxyIdentity
+		if k == "Game.Seed" {
+			continue
+		}
+
+		if old, ok := previous[k]; !ok || old != v {
+			keys = append(keys, k)
+		}
+	}
+
+	for k := range previous {
+		if _, ok := current[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		_, ni := configSettingNames[keys[i]]
+		_, nj := configSettingNames[keys[j]]
+		if ni != nj {
+			return ni
+		}
+		return keys[i] < keys[j]
+	})
+
+	changes := make([]string, 0, len(keys))
+	for _, k := range keys {
+		old, hadOld := previous[k]
+		val, hasVal := current[k]
+
+		name, ok := configSettingNames[k]
+		if !ok {
+			name = "`" + k + "`"
+		}
+
+		switch {
+		case !hadOld:
+			changes = append(changes, sprintf("%s was added: `%s`", name,
+				configSettingValue(k, val)))
+		case !hasVal:
+			changes = append(changes, sprintf("%s was removed (was `%s`)", name,
+				configSettingValue(k, old)))
+		default:
+			changes = append(changes, sprintf("%s changed: `%s` → `%s`", name,
+				configSettingValue(k, old), configSettingValue(k, val)))
+		}
+	}
+
+	return changes
+}
+
+// configSettingValue formats a setting for display. Secrets are masked, since
+// the log channel is more widely visible than the server files.
+func configSettingValue(key, val string) string {
+	if secretSetting(key) {
+		return "********"
+	}
+
+	switch key {
+	case "Game.Difficulty":
+		if d, err := strconv.Atoi(val); err == nil {
+			return ifaces.Difficulty(d)
+		}
+	case "Game.PlayerToPlayerDamage":
+		if b, err := strconv.ParseBool(val); err == nil {
+			if b {
+				return "Enabled"
+			}
+			return "Disabled"
+		}
+	}
+
+	return val
+}
+
+func secretSetting(key string) bool {
+	lower := strings.ToLower(key)
+	return strings.Contains(lower, "password") || strings.Contains(lower, "token")
+}
@@ -0,0 +1,166 @@
+package avorion
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField is the set of values that a field of a cron schedule matches
+type cronField uint64
+
+// cronSchedule is a parsed cron schedule with the usual five fields of
+// "minute hour day-of-month month day-of-week"
+type cronSchedule struct {
+	spec string
+
+	minute, hour, dom, month, dow cronField
+
+	// When both day fields are restricted, a day matching either one matches
+	domAny, dowAny bool
+}
+
+// cronBounds are the lowest and highest values of each field
+var cronBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// parseCron parses a cron schedule. Each field is a comma separated list of
+// values, ranges (1-5), and steps (*/15 or 8-18/2), or * to match anything.
+func parseCron(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.New("expected five fields (minute hour " +
+			"day-of-month month day-of-week)")
+	}
+
+	var parsed [5]cronField
+	for i, f := range fields {
+		v, err := parseCronField(f, cronBounds[i][0], cronBounds[i][1])
+		if err != nil {
+			return nil, errors.New(sprintf("invalid field `%s`: %s", f,
+				err.Error()))
+		}
+		parsed[i] = v
+	}
+
+	// Both 0 and 7 are Sunday
+	if parsed[4]&(1<<7) != 0 {
+		parsed[4] |= 1
+	}
+
+	return &cronSchedule{
+		spec:   spec,
+		minute: parsed[0], hour: parsed[1], dom: parsed[2], month: parsed[3],
+		dow:    parsed[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*"}, nil
+}
+
+// parseCronField parses a single field of a cron schedule
+func parseCronField(f string, min, max int) (cronField, error) {
+	var field cronField
+
+	for _, part := range strings.Split(f, ",") {
+		var (
+			rng  = part
+			step = 1
+			err  error
+		)
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, errors.New("invalid step")
+			}
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.New("invalid range")
+			}
+			if hi, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, errors.New("invalid range")
+			}
+		default:
+			if lo, err = strconv.Atoi(rng); err != nil {
+				return 0, errors.New("invalid value")
+			}
+			hi = lo
+			if step > 1 {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, errors.New(sprintf("values have to be between %d and %d",
+				min, max))
+		}
+
+		for v := lo; v <= hi; v += step {
+			field |= 1 << uint(v)
+		}
+	}
+
+	return field, nil
+}
+
+// has returns whether or not a field matches a value
+func (f cronField) has(v int) bool {
+	return f&(1<<uint(v)) != 0
+}
+
+// matchesDay returns whether or not the schedule runs on the day of t
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	dom := c.dom.has(t.Day())
+	dow := c.dow.has(int(t.Weekday()))
+
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// next returns the first time after t that the schedule runs, in the location
+// of t. A zero time is returned if it doesn't run within the next four years,
+// which only happens for dates such as the 31st of February.
+func (c *cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(4, 0, 0)
+
+	for t.Before(limit) {
+		if !c.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !c.hour.has(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if !c.minute.has(t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
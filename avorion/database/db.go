@@ -4,6 +4,7 @@ import (
 	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -18,45 +19,175 @@ var (
 		"player", "alliance", "npc"}
 )
 
+// sqliteOptions enables write-ahead logging so that readers don't block the
+// writer, and makes writers wait on a lock instead of failing immediately
+const sqliteOptions = "?_journal_mode=WAL&_busy_timeout=5000"
+
 // TrackingDB describes a database of tracked playerdata
 type TrackingDB struct {
 	dbpath   string
 	loglevel int
+
+	db       *sql.DB
+	stmtlock sync.Mutex
+	stmts    map[string]*sql.Stmt
 }
 
 // New returns a reference to a TrackingDB object given a
 //	valid path to a sqlite database (or a filepath to a file that doesn't
 //	exist)
 func New(file string) (*TrackingDB, error) {
-	var (
-		db  *sql.DB
-		err error
-	)
+	db, err := sql.Open("sqlite3", file+sqliteOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TrackingDB{
+		dbpath: file,
+		db:     db,
+		stmts:  make(map[string]*sql.Stmt)}, nil
+}
+
+// NewMemory returns a reference to a TrackingDB object that is only kept in
+//	memory, for when the database file can't be used. Nothing stored in it
+//	survives a restart.
+func NewMemory() (*TrackingDB, error) {
+	// Every connection in the pool has to share the same in-memory database
+	db, err := sql.Open("sqlite3", "file:tracking?mode=memory&cache=shared"+
+		"&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+
+	// Writers on different connections to a shared cache fail with
+	// SQLITE_LOCKED rather than waiting, which the busy timeout doesn't cover,
+	// so every query goes through the one connection
+	db.SetMaxOpenConns(1)
+
+	return &TrackingDB{
+		db:    db,
+		stmts: make(map[string]*sql.Stmt)}, nil
+}
+
+// Path returns the path to the database file, which is empty for a database
+//	that is only kept in memory
+func (t *TrackingDB) Path() string {
+	return t.dbpath
+}
+
+// Check runs a quick integrity check of the database, and returns an error if
+//	it is corrupt or can't be read
+func (t *TrackingDB) Check() error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var result string
+	if err := db.DB.QueryRow(`PRAGMA quick_check(1);`).Scan(&result); err != nil {
+		return err
+	}
+
+	if result != "ok" {
+		return errors.New("database is corrupt: " + result)
+	}
+	return nil
+}
+
+// Close closes the prepared statements and the database handle
+func (t *TrackingDB) Close() error {
+	t.stmtlock.Lock()
+	defer t.stmtlock.Unlock()
+
+	for q, stmt := range t.stmts {
+		stmt.Close()
+		delete(t.stmts, q)
+	}
+
+	return t.db.Close()
+}
+
+// open returns the shared database handle. This used to open a new handle
+// for every query, which made concurrent writers fight over the lock.
+func (t *TrackingDB) open() (*conn, error) {
+	if t.db == nil {
+		return nil, errors.New("tracking database has not been opened")
+	}
+	return &conn{DB: t.db, t: t}, nil
+}
+
+// prepare returns a prepared statement for a query, preparing and caching it
+// the first time that the query is used
+func (t *TrackingDB) prepare(q string) (*sql.Stmt, error) {
+	t.stmtlock.Lock()
+	defer t.stmtlock.Unlock()
+
+	if stmt, ok := t.stmts[q]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := t.db.Prepare(q)
+	if err != nil {
+		return nil, err
+	}
+
+	t.stmts[q] = stmt
+	return stmt, nil
+}
+
+// conn wraps the shared database handle so that queries run through cached
+// prepared statements
+type conn struct {
+	*sql.DB
+	t *TrackingDB
+}
+
+// Exec runs a query that doesn't return rows using a cached statement
+func (c *conn) Exec(q string, args ...interface{}) (sql.Result, error) {
+	stmt, err := c.t.prepare(q)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Exec(args...)
+}
 
-	t := &TrackingDB{dbpath: file}
-	db, err = sql.Open("sqlite3", file)
+// Query runs a query that returns rows using a cached statement
+func (c *conn) Query(q string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := c.t.prepare(q)
 	if err != nil {
 		return nil, err
 	}
+	return stmt.Query(args...)
+}
 
-	defer db.Close()
-	return t, nil
+// QueryRow runs a query that returns at most one row using a cached statement.
+// If the statement can't be prepared, the query is run directly so that the
+// error is reported when the row is scanned.
+func (c *conn) QueryRow(q string, args ...interface{}) *sql.Row {
+	stmt, err := c.t.prepare(q)
+	if err != nil {
+		return c.DB.QueryRow(q, args...)
+	}
+	return stmt.QueryRow(args...)
 }
 
 // Init initializes a TrackingDB object provided it has been assigned
 //	a database file
 func (t *TrackingDB) Init() ([]*ifaces.Sector, error) {
 	var (
-		db  *sql.DB
+		db  *conn
 		err error
 	)
 
-	db, err = sql.Open("sqlite3", t.dbpath)
+	if err = t.Check(); err != nil {
+		return nil, err
+	}
+
+	db, err = t.open()
 	if err != nil {
 		return nil, err
 	}
 
-	defer db.Close()
 
 	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "factions" (
 		"ID"     INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -94,6 +225,245 @@ func (t *TrackingDB) Init() ([]*ifaces.Sector, error) {
 		return nil, err
 	}
 
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "territory" (
+		"X"       INTEGER,
+		"Y"       INTEGER,
+		"FACTION" INTEGER,
+		"NAME"    TEXT,
+		"TIME"    REAL,
+		PRIMARY KEY ("X", "Y"));`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "privacy" (
+		"GAMEID" INTEGER PRIMARY KEY,
+		"HIDDEN" INTEGER);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "chatlog" (
+		"ID"     INTEGER PRIMARY KEY AUTOINCREMENT,
+		"TIME"   INTEGER,
+		"NAME"   TEXT,
+		"MSG"    TEXT,
+		"SOURCE" TEXT);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS "chatlog_fts"
+		USING fts4("MSG");`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "commandstats" (
+		"GUILD"    TEXT,
+		"NAME"     TEXT,
+		"USES"     INTEGER,
+		"FAILURES" INTEGER,
+		PRIMARY KEY ("GUILD", "NAME"));`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "kills" (
+		"ID"          INTEGER PRIMARY KEY AUTOINCREMENT,
+		"TIME"        INTEGER,
+		"FACTION"     INTEGER,
+		"KILLER"      INTEGER,
+		"NAME"        TEXT,
+		"CLASS"       TEXT,
+		"VOLUME"      REAL,
+		"KILLERCLASS" TEXT,
+		"X"           INTEGER,
+		"Y"           INTEGER);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "tempbans" (
+		"GAMEID"    INTEGER PRIMARY KEY,
+		"NAME"      TEXT,
+		"STEAM64ID" TEXT,
+		"EXPIRES"   INTEGER);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "ships" (
+		"GAMEID" INTEGER,
+		"NAME"   TEXT,
+		"X"      INTEGER,
+		"Y"      INTEGER,
+		"TIME"   INTEGER,
+		PRIMARY KEY ("GAMEID", "NAME"));`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "reviews" (
+		"GAMEID"  INTEGER PRIMARY KEY,
+		"NAME"    TEXT,
+		"DISCORD" TEXT,
+		"REASON"  TEXT,
+		"TIME"    INTEGER);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "allianceleaders" (
+		"ALLIANCE" INTEGER PRIMARY KEY,
+		"FOUNDER"  INTEGER,
+		"LEADER"   INTEGER,
+		"MEMBERS"  TEXT,
+		"UPDATED"  INTEGER);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "donations" (
+		"ALLIANCE" INTEGER,
+		"PLAYER"   INTEGER,
+		"RESOURCE" TEXT,
+		"AMOUNT"   INTEGER,
+		"COUNT"    INTEGER,
+		"LAST"     INTEGER,
+		PRIMARY KEY ("ALLIANCE", "PLAYER", "RESOURCE"));`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "staffmessages" (
+		"ID"     INTEGER PRIMARY KEY AUTOINCREMENT,
+		"GAMEID" INTEGER,
+		"NAME"   TEXT,
+		"MSG"    TEXT,
+		"TIME"   INTEGER);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "serverinfo" (
+		"KEY"   TEXT PRIMARY KEY,
+		"VALUE" TEXT);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "steamids" (
+		"GAMEID"  INTEGER PRIMARY KEY,
+		"STEAMID" INTEGER);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "lastseen" (
+		"GAMEID" INTEGER PRIMARY KEY,
+		"TIME"   INTEGER);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "jumphours" (
+		"SECTOR" INTEGER,
+		"HOUR"   INTEGER,
+		"JUMPS"  INTEGER,
+		PRIMARY KEY ("SECTOR", "HOUR"));`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "alliancealerts" (
+		"ALLIANCE" INTEGER PRIMARY KEY,
+		"CHANNEL"  TEXT,
+		"ROLE"     TEXT);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "allianceclaims" (
+		"X"        INTEGER,
+		"Y"        INTEGER,
+		"ALLIANCE" INTEGER,
+		PRIMARY KEY ("X", "Y"));`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "gameconfigs" (
+		"VERSION" INTEGER PRIMARY KEY AUTOINCREMENT,
+		"TIME"    INTEGER,
+		"AUTHOR"  TEXT,
+		"REASON"  TEXT,
+		"CONTENT" TEXT);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "serverexits" (
+		"TIME"   INTEGER,
+		"CODE"   INTEGER,
+		"SIGNAL" TEXT,
+		"CLASS"  TEXT);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "oneshotactions" (
+		"ID"     INTEGER PRIMARY KEY AUTOINCREMENT,
+		"ACTION" TEXT,
+		"TIME"   INTEGER,
+		"AUTHOR" TEXT);`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS "bans" (
+		"GAMEID"    INTEGER PRIMARY KEY,
+		"NAME"      TEXT,
+		"STEAM64ID" TEXT,
+		"AUTHOR"    TEXT,
+		"REASON"    TEXT,
+		"TIME"      INTEGER,
+		"EXPIRES"   INTEGER);`)
+	if err != nil {
+		return nil, err
+	}
+
+	// Temporary bans were recorded on their own before every ban was recorded,
+	// and were only ever issued by the chat filter
+	_, err = db.Exec(`INSERT OR IGNORE INTO bans ("GAMEID","NAME","STEAM64ID",
+		"AUTHOR","REASON","TIME","EXPIRES")
+		SELECT "GAMEID", "NAME", "STEAM64ID", 'Chat filter',
+		'Repeated chat filter violations', 0, "EXPIRES" FROM tempbans;`)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = db.Exec(`DELETE FROM tempbans;`); err != nil {
+		return nil, err
+	}
+
+	// Jumps that were recorded before hourly counts were kept are counted once,
+	// the first time that the table is empty
+	var buckets int64
+	if err = db.QueryRow(`SELECT COUNT(*) FROM jumphours;`).Scan(&buckets); err != nil {
+		return nil, err
+	}
+
+	if buckets == 0 {
+		logger.LogInit(t, "Counting recorded jumps into hourly totals")
+		_, err = db.Exec(`INSERT INTO jumphours ("SECTOR","HOUR","JUMPS")
+			SELECT "SECTOR", CAST("TIME" / 3600 AS INTEGER) * 3600, COUNT(*)
+			FROM jumps GROUP BY 1, 2;`)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Get all of the sectors that have been tracked
 	sectors := make([]*ifaces.Sector, 0)
 
@@ -105,22 +475,25 @@ func (t *TrackingDB) Init() ([]*ifaces.Sector, error) {
 		Kind  int
 	}, 0)
 
+	// Each result is read and closed before the next query, since a database
+	// kept in memory only has the one connection
 	srows, err := db.Query(`select * from sectors;`)
 	if err != nil {
 		return nil, err
 	}
 
-	frows, err := db.Query(`select * from factions;`)
-	if err != nil {
-		return nil, err
-	}
-
 	for srows.Next() {
 		sec := &ifaces.Sector{
 			Jumphistory: make([]*ifaces.JumpInfo, 0)}
 		srows.Scan(&sec.Index, &sec.X, &sec.Y)
 		sectors = append(sectors, sec)
 	}
+	srows.Close()
+
+	frows, err := db.Query(`select * from factions;`)
+	if err != nil {
+		return nil, err
+	}
 
 	for frows.Next() {
 		f := struct {
@@ -133,163 +506,330 @@ func (t *TrackingDB) Init() ([]*ifaces.Sector, error) {
 		frows.Scan(&f.Index, &f.Name, &f.Kind, &f.ID)
 		factions = append(factions, f)
 	}
-
-	srows.Close()
 	frows.Close()
 
-	var (
-		jumpid      int64
-		sectorid    int
-		factionid   int
-		jumptime    float64
-		kind        int
-		name        string
-		sectorcount int64
-	)
+	// Apply the last known controlling faction to each of the tracked sectors
+	trows, err := db.Query(`SELECT "X", "Y", "FACTION", "NAME" FROM territory;`)
+	if err != nil {
+		return nil, err
+	}
 
-	logger.LogInit(t, "Loading sectors into memory. This *will* take a while on larger DBs")
-	twg := &sync.WaitGroup{}
+	territory := make(map[[2]int]*ifaces.Sector, len(sectors))
 	for _, sec := range sectors {
-		sectorcount++
+		territory[[2]int{sec.X, sec.Y}] = sec
+	}
 
-		// Pull the last 100 jumps from the db for that sector
-		jrows, err := db.Query(`SELECT * FROM jumps WHERE SECTOR=?
-			ORDER BY rowid ASC LIMIT 100;`, sec.Index)
+	for trows.Next() {
+		var (
+			x, y, fid int
+			fname     string
+		)
 
-		if err != nil {
-			return nil, err
+		trows.Scan(&x, &y, &fid, &fname)
+		if sec, ok := territory[[2]int{x, y}]; ok {
+			sec.Faction = fid
+			sec.FactionName = fname
 		}
+	}
 
-		go func(sector *ifaces.Sector) {
-			twg.Add(1)
-			defer twg.Done()
-			for jrows.Next() {
-				jrows.Scan(&jumpid, &sectorid, &factionid, &name, &jumptime, &kind)
+	trows.Close()
 
-				if err = jrows.Err(); err != nil {
-					jrows.Close()
-					logger.LogError(t, err.Error())
-					return
-				}
+	index := make(map[int64]*ifaces.Sector, len(sectors))
+	for _, sec := range sectors {
+		index[sec.Index] = sec
+	}
 
-				if kind > len(factionKind) || kind < 0 {
-					kind = 3
-				}
+	// Pull the first 100 jumps for every sector in one pass, rather than
+	// querying each sector on its own
+	logger.LogInit(t, "Loading sectors into memory. This *will* take a while on larger DBs")
+	jrows, err := db.Query(`SELECT "SECTOR", "FACTION", "SHIP NAME", "TIME", "KIND"
+		FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY "SECTOR" ORDER BY "ID")
+			AS "N" FROM jumps)
+		WHERE "N" <= 100 ORDER BY "SECTOR", "ID";`)
+	if err != nil {
+		return nil, err
+	}
+	defer jrows.Close()
+
+	for jrows.Next() {
+		var (
+			sectorid  int64
+			factionid int
+			jumptime  float64
+			kind      int
+			name      string
+		)
+
+		if err := jrows.Scan(&sectorid, &factionid, &name, &jumptime,
+			&kind); err != nil {
+			return nil, err
+		}
 
-				j := &ifaces.JumpInfo{
-					Time: time.Unix(int64(jumptime), 0),
-					Name: name,
-					FID:  factionid,
-					X:    sector.X,
-					Y:    sector.Y,
-					Kind: factionKind[kind]}
+		sector, ok := index[sectorid]
+		if !ok {
+			continue
+		}
 
-				sector.Jumphistory = append(sector.Jumphistory, j)
-			}
+		if kind >= len(factionKind) || kind < 0 {
+			kind = len(factionKind) - 1
+		}
 
-			if err := jrows.Close(); err != nil {
-				logger.LogError(t, err.Error())
-			}
+		sector.Jumphistory = append(sector.Jumphistory, &ifaces.JumpInfo{
+			Time: time.Unix(int64(jumptime), 0),
+			Name: name,
+			FID:  factionid,
+			X:    sector.X,
+			Y:    sector.Y,
+			Kind: factionKind[kind]})
+	}
 
-			if err := jrows.Err(); err != nil {
-				return
-			}
-		}(sec)
+	if err := jrows.Err(); err != nil {
+		return nil, err
 	}
 
-	twg.Wait()
 	return sectors, nil
 }
 
-// AddJump adds a jump to the tracking DB
-func (t *TrackingDB) AddJump(si, fi, k int64, j ifaces.JumpInfo) error {
-	var (
-		s   *sql.Stmt
-		db  *sql.DB
-		err error
-	)
-
-	db, err = sql.Open("sqlite3", t.dbpath)
+// JumpCount returns the number of jumps that have been recorded
+func (t *TrackingDB) JumpCount() (int64, error) {
+	db, err := t.open()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	q := `INSERT INTO jumps ("SECTOR","FACTION","SHIP NAME","TIME","KIND")
-		VALUES(?,?,?,?,?);`
-
-	defer db.Close()
+	var count int64
+	err = db.QueryRow(`SELECT COUNT(*) FROM jumps;`).Scan(&count)
+	return count, err
+}
 
-	if s, err = db.Prepare(q); err != nil {
-		logger.LogError(t, fmt.Sprintf("AddJump: %s",
-			err.Error()))
-		return err
+// FactionJumps returns up to limit jumps made by tracked players and alliances
+// that were recorded after the jump with the given ID, along with the ID of
+// the last jump returned. Pass the returned ID back in to load the next batch.
+func (t *TrackingDB) FactionJumps(after int64,
+	limit int) ([]*ifaces.JumpInfo, int64, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, after, err
 	}
 
-	defer s.Close()
+	rows, err := db.Query(`SELECT j."ID", j."FACTION", j."SHIP NAME", j."TIME",
+			s."X", s."Y"
+		FROM jumps j
+		JOIN sectors s ON s."ID" = j."SECTOR"
+		JOIN (SELECT DISTINCT "GAMEID" FROM factions) f
+			ON f."GAMEID" = j."FACTION"
+		WHERE j."ID" > ?
+		ORDER BY j."ID" ASC LIMIT ?;`, after, limit)
+	if err != nil {
+		return nil, after, err
+	}
+	defer rows.Close()
+
+	jumps := make([]*ifaces.JumpInfo, 0, limit)
+	for rows.Next() {
+		var (
+			j        = &ifaces.JumpInfo{}
+			jumptime float64
+		)
+
+		if err := rows.Scan(&after, &j.FID, &j.Name, &jumptime, &j.X,
+			&j.Y); err != nil {
+			return nil, after, err
+		}
 
-	if _, err = s.Exec(si, fi, j.Name, j.Time.Unix(), k); err != nil {
-		logger.LogError(t, fmt.Sprintf("AddJump: %s",
-			err.Error()))
-		return err
+		j.Time = time.Unix(int64(jumptime), 0)
+		jumps = append(jumps, j)
 	}
 
-	logger.LogDebug(t, "AddJump: Success")
-	return nil
+	return jumps, after, rows.Err()
 }
 
-// TrackSector add a sector to the DB of tracked sector instances
-func (t *TrackingDB) TrackSector(sec *ifaces.Sector) error {
-	var (
-		db  *sql.DB
-		err error
-		id  int64
-	)
+// JumpBuckets returns the number of jumps into each sector per hour, for the
+// hours starting at or after since
+func (t *TrackingDB) JumpBuckets(since time.Time) ([]ifaces.JumpBucket, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
 
-	db, err = sql.Open("sqlite3", t.dbpath)
+	rows, err := db.Query(`SELECT s."X", s."Y", h."HOUR", h."JUMPS"
+		FROM jumphours h
+		JOIN sectors s ON s."ID" = h."SECTOR"
+		WHERE h."HOUR" >= ?;`, since.Unix()-since.Unix()%3600)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	defer db.Close()
+	buckets := make([]ifaces.JumpBucket, 0)
+	for rows.Next() {
+		var (
+			b    ifaces.JumpBucket
+			hour int64
+		)
 
-	db.QueryRow(`SELECT ID FROM sectors WHERE	"X" = "?" AND "Y" = "?"
-		LIMIT	1`, sec.X, sec.Y).Scan(&id)
+		if err := rows.Scan(&b.X, &b.Y, &hour, &b.Jumps); err != nil {
+			return nil, err
+		}
 
-	if id != 0 {
-		sec.Index = id
-		return nil
+		b.Hour = time.Unix(hour, 0)
+		buckets = append(buckets, b)
 	}
 
-	p, err := db.Prepare(`INSERT INTO sectors ("X", "Y") VALUES(?,?)`)
+	return buckets, rows.Err()
+}
+
+// PruneJumps deletes the raw jumps that were recorded before the given time,
+// and returns how many were deleted. Their hourly totals are kept.
+func (t *TrackingDB) PruneJumps(before time.Time) (int64, error) {
+	db, err := t.open()
 	if err != nil {
-		logger.LogError(t, fmt.Sprintf("TrackSector: %s",
-			err.Error()))
-		return err
+		return 0, err
 	}
 
-	_, err = p.Exec(sec.X, sec.Y)
+	res, err := db.Exec(`DELETE FROM jumps WHERE "TIME" < ?;`, before.Unix())
 	if err != nil {
-		logger.LogError(t, fmt.Sprintf("TrackSector: %s",
-			err.Error()))
-		return err
+		logger.LogError(t, fmt.Sprintf("PruneJumps: %s", err.Error()))
+		return 0, err
 	}
 
-	row := db.QueryRow(`SELECT MAX(ID) FROM sectors;`)
-	row.Scan(&id)
+	return res.RowsAffected()
+}
+
+// AddJump adds a jump to the tracking DB
+func (t *TrackingDB) AddJump(si, fi, k int64, j ifaces.JumpInfo) error {
+	var (
+		db  *conn
+		err error
+	)
+
+	db, err = t.open()
+	if err != nil {
+		return err
+	}
+
+	var (
+		ts   = j.Time.Unix()
+		hour = ts - ts%3600
+
+		q = `INSERT INTO jumps ("SECTOR","FACTION","SHIP NAME","TIME","KIND")
+			VALUES(?,?,?,?,?);`
+		addQ = `INSERT OR IGNORE INTO jumphours ("SECTOR","HOUR","JUMPS")
+			VALUES (?,?,0);`
+		incQ = `UPDATE jumphours SET "JUMPS"="JUMPS"+1 WHERE "SECTOR"=? AND "HOUR"=?;`
+	)
+
+	// The hourly totals are kept with the raw jump, so that they still count
+	// jumps once the raw rows have been pruned
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range []struct {
+		q    string
+		args []interface{}
+	}{
+		{q, []interface{}{si, fi, j.Name, ts, k}},
+		{addQ, []interface{}{si, hour}},
+		{incQ, []interface{}{si, hour}}} {
+		if _, err = tx.Exec(stmt.q, stmt.args...); err != nil {
+			tx.Rollback()
+			logger.LogError(t, fmt.Sprintf("AddJump: %s",
+				err.Error()))
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.LogDebug(t, "AddJump: Success")
+	return nil
+}
+
+// TrackSector add a sector to the DB of tracked sector instances
+func (t *TrackingDB) TrackSector(sec *ifaces.Sector) error {
+	var (
+		db  *conn
+		err error
+		id  int64
+	)
+
+	db, err = t.open()
+	if err != nil {
+		return err
+	}
+
+
+	db.QueryRow(`SELECT ID FROM sectors WHERE	"X" = "?" AND "Y" = "?"
+		LIMIT	1`, sec.X, sec.Y).Scan(&id)
+
+	if id != 0 {
+		sec.Index = id
+		return nil
+	}
+
+	_, err = db.Exec(`INSERT INTO sectors ("X", "Y") VALUES(?,?)`, sec.X, sec.Y)
+	if err != nil {
+		logger.LogError(t, fmt.Sprintf("TrackSector: %s",
+			err.Error()))
+		return err
+	}
+
+	row := db.QueryRow(`SELECT MAX(ID) FROM sectors;`)
+	row.Scan(&id)
 	sec.Index = id
 	logger.LogDebug(t, "TrackSector: Added sector to DB")
 
 	return nil
 }
 
+// TrackSectors adds a batch of sectors to the DB of tracked sector instances
+//	in a single transaction, assigning each of them its index
+func (t *TrackingDB) TrackSectors(secs []*ifaces.Sector) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, sec := range secs {
+		res, err := tx.Exec(`INSERT INTO sectors ("X", "Y") VALUES(?,?)`,
+			sec.X, sec.Y)
+		if err != nil {
+			tx.Rollback()
+			logger.LogError(t, fmt.Sprintf("TrackSectors: %s", err.Error()))
+			return err
+		}
+
+		if sec.Index, err = res.LastInsertId(); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.LogDebug(t, fmt.Sprintf("TrackSectors: Added %d sectors to DB",
+		len(secs)))
+	return nil
+}
+
 // TrackPlayer adds a player to the tracking DB
 func (t *TrackingDB) TrackPlayer(p ifaces.IPlayer) error {
-	db, err := sql.Open("sqlite3", t.dbpath)
+	db, err := t.open()
 	if err != nil {
 		return err
 	}
 
-	defer db.Close()
 
 	var (
 		fid int64
@@ -335,12 +875,11 @@ func (t *TrackingDB) TrackPlayer(p ifaces.IPlayer) error {
 
 // TrackAlliance adds an alliance to the tracking DB
 func (t *TrackingDB) TrackAlliance(a ifaces.IAlliance) error {
-	db, err := sql.Open("sqlite3", t.dbpath)
+	db, err := t.open()
 	if err != nil {
 		return err
 	}
 
-	defer db.Close()
 
 	var (
 		fid int64
@@ -386,11 +925,10 @@ func (t *TrackingDB) TrackAlliance(a ifaces.IAlliance) error {
 
 // AddIntegration adds a tracked integration request to our database
 func (t *TrackingDB) AddIntegration(discordid string, p ifaces.IPlayer) error {
-	db, err := sql.Open("sqlite3", t.dbpath)
+	db, err := t.open()
 	if err != nil {
 		return err
 	}
-	defer db.Close()
 
 	var (
 		fid  int64
@@ -414,11 +952,10 @@ func (t *TrackingDB) AddIntegration(discordid string, p ifaces.IPlayer) error {
 
 // RemoveIntegration removes an existing discord integration from the database
 func (t *TrackingDB) RemoveIntegration(p ifaces.IPlayer) error {
-	db, err := sql.Open("sqlite3", t.dbpath)
+	db, err := t.open()
 	if err != nil {
 		return err
 	}
-	defer db.Close()
 
 	var (
 		fid  int64
@@ -426,7 +963,11 @@ func (t *TrackingDB) RemoveIntegration(p ifaces.IPlayer) error {
 	)
 
 	fid, err = strconv.ParseInt(p.Index(), 10, 64)
-	_, err = db.Exec(delQ, fid, p.DiscordUID())
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(delQ, p.DiscordUID(), fid)
 	if err != nil {
 		return err
 	}
@@ -439,11 +980,10 @@ func (t *TrackingDB) RemoveIntegration(p ifaces.IPlayer) error {
 // SetDiscordToPlayer gets the Discord UID from the faction ID and sets the
 // DiscordUID for the player
 func (t *TrackingDB) SetDiscordToPlayer(p ifaces.IPlayer) error {
-	db, err := sql.Open("sqlite3", t.dbpath)
+	db, err := t.open()
 	if err != nil {
 		return err
 	}
-	defer db.Close()
 
 	var (
 		fid  int64
@@ -466,6 +1006,1196 @@ func (t *TrackingDB) SetDiscordToPlayer(p ifaces.IPlayer) error {
 	return nil
 }
 
+// ServerInfo returns the last recorded value for the given server information
+// key, or an empty string if nothing has been recorded yet
+func (t *TrackingDB) ServerInfo(key string) (string, error) {
+	db, err := t.open()
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		val  string
+		selQ = `SELECT VALUE FROM serverinfo WHERE KEY=? LIMIT 1;`
+	)
+
+	err = db.QueryRow(selQ, key).Scan(&val)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	return val, nil
+}
+
+// SetServerInfo records the value for the given server information key,
+// replacing any previously recorded value
+func (t *TrackingDB) SetServerInfo(key, val string) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var setQ = `INSERT OR REPLACE INTO serverinfo ("KEY","VALUE") VALUES (?,?);`
+
+	if _, err = db.Exec(setQ, key, val); err != nil {
+		logger.LogError(t, fmt.Sprintf("SetServerInfo: %s", err.Error()))
+		return err
+	}
+
+	logger.LogDebug(t, fmt.Sprintf("SetServerInfo: %s = %s", key, val))
+	return nil
+}
+
+// SteamID returns the cached Steam64 ID of a player, or 0 if it hasn't been
+// resolved yet
+func (t *TrackingDB) SteamID(index string) (int64, error) {
+	db, err := t.open()
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		id   int64
+		selQ = `SELECT STEAMID FROM steamids WHERE GAMEID=? LIMIT 1;`
+	)
+
+	err = db.QueryRow(selQ, index).Scan(&id)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// SetSteamID caches the Steam64 ID of a player
+func (t *TrackingDB) SetSteamID(index string, id int64) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var setQ = `INSERT OR REPLACE INTO steamids ("GAMEID","STEAMID") VALUES (?,?);`
+
+	if _, err = db.Exec(setQ, index, id); err != nil {
+		logger.LogError(t, fmt.Sprintf("SetSteamID: %s", err.Error()))
+		return err
+	}
+
+	logger.LogDebug(t, fmt.Sprintf("SetSteamID: %s = %d", index, id))
+	return nil
+}
+
+// LastSeen returns the last time that a player was seen online, or the zero
+// time if they haven't been seen before
+func (t *TrackingDB) LastSeen(index string) (time.Time, error) {
+	db, err := t.open()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var (
+		ts   int64
+		selQ = `SELECT TIME FROM lastseen WHERE GAMEID=? LIMIT 1;`
+	)
+
+	err = db.QueryRow(selQ, index).Scan(&ts)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(ts, 0), nil
+}
+
+// SetLastSeen records the last time that a player was seen online
+func (t *TrackingDB) SetLastSeen(index string, seen time.Time) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var setQ = `INSERT OR REPLACE INTO lastseen ("GAMEID","TIME") VALUES (?,?);`
+
+	if _, err = db.Exec(setQ, index, seen.Unix()); err != nil {
+		logger.LogError(t, fmt.Sprintf("SetLastSeen: %s", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// PlayerCount returns the number of unique players that have been tracked
+func (t *TrackingDB) PlayerCount() (int64, error) {
+	db, err := t.open()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = db.QueryRow(`SELECT COUNT(*) FROM factions WHERE KIND=0;`).Scan(&count)
+	return count, err
+}
+
+// Privacy returns whether or not a player has opted out of the public display
+// of their tracking data
+func (t *TrackingDB) Privacy(index string) (bool, error) {
+	db, err := t.open()
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		hidden int
+		selQ   = `SELECT HIDDEN FROM privacy WHERE GAMEID=? LIMIT 1;`
+	)
+
+	err = db.QueryRow(selQ, index).Scan(&hidden)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	return hidden != 0, nil
+}
+
+// SetPrivacy records a players tracking privacy preference
+func (t *TrackingDB) SetPrivacy(index string, hidden bool) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var (
+		val  = 0
+		setQ = `INSERT OR REPLACE INTO privacy ("GAMEID","HIDDEN") VALUES (?,?);`
+	)
+
+	if hidden {
+		val = 1
+	}
+
+	if _, err = db.Exec(setQ, index, val); err != nil {
+		logger.LogError(t, fmt.Sprintf("SetPrivacy: %s", err.Error()))
+		return err
+	}
+
+	logger.LogDebug(t, fmt.Sprintf("SetPrivacy: %s = %t", index, hidden))
+	return nil
+}
+
+// AddChat stores a bridged chat message and indexes it for searching
+func (t *TrackingDB) AddChat(c ifaces.ChatRecord) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var (
+		addQ = `INSERT INTO chatlog ("TIME","NAME","MSG","SOURCE") VALUES (?,?,?,?);`
+		idxQ = `INSERT INTO chatlog_fts (docid, "MSG") VALUES (?,?);`
+	)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(addQ, c.Time.Unix(), c.Name, c.Msg, c.Source)
+	if err != nil {
+		tx.Rollback()
+		logger.LogError(t, fmt.Sprintf("AddChat: %s", err.Error()))
+		return err
+	}
+
+	id, _ := res.LastInsertId()
+	if _, err = tx.Exec(idxQ, id, c.Msg); err != nil {
+		tx.Rollback()
+		logger.LogError(t, fmt.Sprintf("AddChat: %s", err.Error()))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SearchChat returns the most recent chat messages matching a full-text query,
+// optionally limited to a single author and to messages sent after a given time
+func (t *TrackingDB) SearchChat(query, name string, since time.Time,
+	limit int) ([]ifaces.ChatRecord, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		records = make([]ifaces.ChatRecord, 0)
+		selQ    = `SELECT c."TIME", c."NAME", c."MSG", c."SOURCE" FROM chatlog c
+			JOIN chatlog_fts f ON f.docid = c."ID"
+			WHERE f."MSG" MATCH ? AND (? = '' OR c."NAME" = ? COLLATE NOCASE)
+			AND c."TIME" >= ? ORDER BY c."TIME" DESC LIMIT ?;`
+	)
+
+	rows, err := db.Query(selQ, query, name, name, since.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			ts int64
+			r  ifaces.ChatRecord
+		)
+
+		if err := rows.Scan(&ts, &r.Name, &r.Msg, &r.Source); err != nil {
+			return nil, err
+		}
+
+		r.Time = time.Unix(ts, 0)
+		records = append(records, r)
+	}
+
+	return records, rows.Err()
+}
+
+// AddCommandUse records an invocation of a bot command in a guild
+func (t *TrackingDB) AddCommandUse(guild, name string, failed bool) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var (
+		fail = 0
+		addQ = `INSERT OR IGNORE INTO commandstats ("GUILD","NAME","USES","FAILURES")
+			VALUES (?,?,0,0);`
+		incQ = `UPDATE commandstats SET "USES"="USES"+1, "FAILURES"="FAILURES"+?
+			WHERE "GUILD"=? AND "NAME"=?;`
+	)
+
+	if failed {
+		fail = 1
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(addQ, guild, name); err != nil {
+		tx.Rollback()
+		logger.LogError(t, fmt.Sprintf("AddCommandUse: %s", err.Error()))
+		return err
+	}
+
+	if _, err = tx.Exec(incQ, fail, guild, name); err != nil {
+		tx.Rollback()
+		logger.LogError(t, fmt.Sprintf("AddCommandUse: %s", err.Error()))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddDonation adds a donation of a resource (or credits) by a player to an
+// alliance to the players running total
+func (t *TrackingDB) AddDonation(alliance, player int64, resource string,
+	amount int64) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var (
+		addQ = `INSERT OR IGNORE INTO donations
+			("ALLIANCE","PLAYER","RESOURCE","AMOUNT","COUNT","LAST")
+			VALUES (?,?,?,0,0,0);`
+		incQ = `UPDATE donations SET "AMOUNT"="AMOUNT"+?, "COUNT"="COUNT"+1,
+			"LAST"=? WHERE "ALLIANCE"=? AND "PLAYER"=? AND "RESOURCE"=?;`
+	)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(addQ, alliance, player, resource); err != nil {
+		tx.Rollback()
+		logger.LogError(t, fmt.Sprintf("AddDonation: %s", err.Error()))
+		return err
+	}
+
+	if _, err = tx.Exec(incQ, amount, time.Now().Unix(), alliance, player,
+		resource); err != nil {
+		tx.Rollback()
+		logger.LogError(t, fmt.Sprintf("AddDonation: %s", err.Error()))
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Donations returns the total that each member has donated to an alliance,
+// per resource, ordered by the largest contributions
+func (t *TrackingDB) Donations(alliance int64) ([]ifaces.DonationStat, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		stats = make([]ifaces.DonationStat, 0)
+		selQ  = `SELECT "PLAYER", "RESOURCE", "AMOUNT", "COUNT", "LAST"
+			FROM donations WHERE "ALLIANCE"=?
+			ORDER BY "RESOURCE", "AMOUNT" DESC;`
+	)
+
+	rows, err := db.Query(selQ, alliance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			last   int64
+			player int64
+			st     ifaces.DonationStat
+		)
+
+		if err := rows.Scan(&player, &st.Resource, &st.Amount, &st.Count,
+			&last); err != nil {
+			return nil, err
+		}
+
+		st.Player = strconv.FormatInt(player, 10)
+		st.Last = time.Unix(last, 0)
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
+
+// CommandStats returns the usage of each bot command in a guild, ordered by
+// the number of uses. Passing an empty guild totals the usage of all guilds.
+func (t *TrackingDB) CommandStats(guild string) ([]ifaces.CommandStat, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		stats = make([]ifaces.CommandStat, 0)
+		selQ  = `SELECT "NAME", SUM("USES"), SUM("FAILURES") FROM commandstats
+			WHERE ? = '' OR "GUILD" = ? GROUP BY "NAME"
+			ORDER BY SUM("USES") DESC, "NAME";`
+	)
+
+	rows, err := db.Query(selQ, guild, guild)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var st ifaces.CommandStat
+		if err := rows.Scan(&st.Name, &st.Uses, &st.Failures); err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+
+	return stats, rows.Err()
+}
+
+// AddKill records a ship that was destroyed
+func (t *TrackingDB) AddKill(k ifaces.ShipKill) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var q = `INSERT INTO kills ("TIME","FACTION","KILLER","NAME","CLASS","VOLUME",
+		"KILLERCLASS","X","Y") VALUES (?,?,?,?,?,?,?,?,?);`
+
+	if _, err = db.Exec(q, k.Time.Unix(), k.Faction, k.Killer, k.Name, k.Class,
+		k.Volume, k.KillerClass, k.X, k.Y); err != nil {
+		logger.LogError(t, fmt.Sprintf("AddKill: %s", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// CombatStats returns the ships that have been destroyed, grouped in a number
+// of ways. Each group lists up to limit entries.
+func (t *TrackingDB) CombatStats(limit int) (ifaces.CombatStats, error) {
+	var stats ifaces.CombatStats
+
+	db, err := t.open()
+	if err != nil {
+		return stats, err
+	}
+
+	err = db.QueryRow(`SELECT COUNT(*) FROM kills;`).Scan(&stats.Total)
+	if err != nil {
+		return stats, err
+	}
+
+	// Ships whose class wasn't reported are grouped as unknown
+	for _, group := range []struct {
+		expr string
+		dest *[]ifaces.CombatStat
+	}{
+		{`COALESCE(NULLIF("CLASS",''),'Unknown')`, &stats.ByClass},
+		{`COALESCE(NULLIF("KILLERCLASS",''),'Unknown')`, &stats.ByKillerClass},
+		{`"NAME"`, &stats.ByName},
+		{`"X" || ':' || "Y"`, &stats.BySector}} {
+		rows, err := db.Query(`SELECT `+group.expr+`, COUNT(*) FROM kills
+			GROUP BY 1 ORDER BY 2 DESC, 1 LIMIT ?;`, limit)
+		if err != nil {
+			return stats, err
+		}
+
+		*group.dest = make([]ifaces.CombatStat, 0)
+		for rows.Next() {
+			var st ifaces.CombatStat
+			if err := rows.Scan(&st.Name, &st.Count); err != nil {
+				rows.Close()
+				return stats, err
+			}
+			*group.dest = append(*group.dest, st)
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+// AddBan records a ban, replacing any earlier ban of the same player. Bans
+// that expire have a non-zero expiry time.
+func (t *TrackingDB) AddBan(b ifaces.BanRecord) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var (
+		expires int64
+		setQ    = `INSERT OR REPLACE INTO bans ("GAMEID","NAME","STEAM64ID",
+			"AUTHOR","REASON","TIME","EXPIRES") VALUES (?,?,?,?,?,?,?);`
+	)
+
+	if !b.Expires.IsZero() {
+		expires = b.Expires.Unix()
+	}
+
+	if _, err = db.Exec(setQ, b.Index, b.Name, b.Steam64, b.Author, b.Reason,
+		b.Time.Unix(), expires); err != nil {
+		logger.LogError(t, fmt.Sprintf("AddBan: %s", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// Bans returns the recorded bans, newest first
+func (t *TrackingDB) Bans() ([]ifaces.BanRecord, error) {
+	return t.queryBans(`SELECT "GAMEID", "NAME", "STEAM64ID", "AUTHOR",
+		"REASON", "TIME", "EXPIRES" FROM bans ORDER BY "TIME" DESC;`)
+}
+
+// ExpiredBans returns the temporary bans that expired before the given time
+func (t *TrackingDB) ExpiredBans(now time.Time) ([]ifaces.BanRecord, error) {
+	return t.queryBans(`SELECT "GAMEID", "NAME", "STEAM64ID", "AUTHOR",
+		"REASON", "TIME", "EXPIRES" FROM bans
+		WHERE "EXPIRES" > 0 AND "EXPIRES" <= ?;`, now.Unix())
+}
+
+// queryBans runs a query that selects every column of the bans table
+func (t *TrackingDB) queryBans(selQ string, args ...interface{}) (
+	[]ifaces.BanRecord, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(selQ, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bans := make([]ifaces.BanRecord, 0)
+	for rows.Next() {
+		var (
+			b           ifaces.BanRecord
+			at, expires int64
+		)
+		if err := rows.Scan(&b.Index, &b.Name, &b.Steam64, &b.Author, &b.Reason,
+			&at, &expires); err != nil {
+			return nil, err
+		}
+
+		b.Time = time.Unix(at, 0)
+		if expires > 0 {
+			b.Expires = time.Unix(expires, 0)
+		}
+		bans = append(bans, b)
+	}
+
+	return bans, rows.Err()
+}
+
+// RemoveBan removes the recorded ban of a player once it has been lifted
+func (t *TrackingDB) RemoveBan(index string) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`DELETE FROM bans WHERE "GAMEID"=?;`, index)
+	return err
+}
+
+// AddStaffMessage records a message that a player sent to the staff, and
+// returns the ID that staff use to reply to it
+func (t *TrackingDB) AddStaffMessage(m ifaces.StaffMessage) (int64, error) {
+	db, err := t.open()
+	if err != nil {
+		return 0, err
+	}
+
+	var addQ = `INSERT INTO staffmessages ("GAMEID","NAME","MSG","TIME")
+		VALUES (?,?,?,?);`
+
+	res, err := db.Exec(addQ, m.Index, m.Name, m.Msg, m.Time.Unix())
+	if err != nil {
+		logger.LogError(t, fmt.Sprintf("AddStaffMessage: %s", err.Error()))
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// StaffMessage returns the staff message with the given ID
+func (t *TrackingDB) StaffMessage(id int64) (ifaces.StaffMessage, error) {
+	db, err := t.open()
+	if err != nil {
+		return ifaces.StaffMessage{}, err
+	}
+
+	var (
+		m    = ifaces.StaffMessage{ID: id}
+		sent int64
+		selQ = `SELECT "GAMEID", "NAME", "MSG", "TIME" FROM staffmessages
+			WHERE "ID"=?;`
+	)
+
+	if err := db.QueryRow(selQ, id).Scan(&m.Index, &m.Name, &m.Msg,
+		&sent); err != nil {
+		return m, err
+	}
+
+	m.Time = time.Unix(sent, 0)
+	return m, nil
+}
+
+// AddReview adds a player to the review queue, replacing any review that is
+// already queued for them
+func (t *TrackingDB) AddReview(r ifaces.ReviewEntry) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var setQ = `INSERT OR REPLACE INTO reviews ("GAMEID","NAME","DISCORD","REASON","TIME")
+		VALUES (?,?,?,?,?);`
+
+	if _, err = db.Exec(setQ, r.Index, r.Name, r.DiscordID, r.Reason,
+		r.Time.Unix()); err != nil {
+		logger.LogError(t, fmt.Sprintf("AddReview: %s", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// Reviews returns the players in the review queue, oldest first
+func (t *TrackingDB) Reviews() ([]ifaces.ReviewEntry, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		reviews = make([]ifaces.ReviewEntry, 0)
+		selQ    = `SELECT "GAMEID", "NAME", "DISCORD", "REASON", "TIME"
+			FROM reviews ORDER BY "TIME" ASC;`
+	)
+
+	rows, err := db.Query(selQ)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			r    ifaces.ReviewEntry
+			unix int64
+		)
+
+		if err := rows.Scan(&r.Index, &r.Name, &r.DiscordID, &r.Reason,
+			&unix); err != nil {
+			return nil, err
+		}
+
+		r.Time = time.Unix(unix, 0)
+		reviews = append(reviews, r)
+	}
+
+	return reviews, rows.Err()
+}
+
+// RemoveReview removes a player from the review queue. If the player wasn't
+// queued, the returned error wraps ifaces.ErrPlayerNotFound.
+func (t *TrackingDB) RemoveReview(index string) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`DELETE FROM reviews WHERE "GAMEID"=?;`, index)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: %s is not in the review queue",
+			ifaces.ErrPlayerNotFound, index)
+	}
+
+	return nil
+}
+
+// SetAllianceLeadership records the current leader and members of an alliance,
+// and returns the recorded founder and the previous leader. The game doesn't
+// track who founded an alliance, so the first leader that we see is treated as
+// its founder.
+func (t *TrackingDB) SetAllianceLeadership(alliance, leader int64,
+	members string) (int64, int64, error) {
+	db, err := t.open()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var (
+		founder  = leader
+		previous = int64(-1)
+		selQ     = `SELECT "FOUNDER", "LEADER" FROM allianceleaders WHERE "ALLIANCE"=?;`
+		setQ     = `INSERT OR REPLACE INTO allianceleaders
+			("ALLIANCE","FOUNDER","LEADER","MEMBERS","UPDATED") VALUES (?,?,?,?,?);`
+	)
+
+	err = db.QueryRow(selQ, alliance).Scan(&founder, &previous)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, 0, err
+	}
+
+	if _, err = db.Exec(setQ, alliance, founder, leader, members,
+		time.Now().Unix()); err != nil {
+		logger.LogError(t, fmt.Sprintf("SetAllianceLeadership: %s", err.Error()))
+		return 0, 0, err
+	}
+
+	return founder, previous, nil
+}
+
+// SetShip records the last known location of a ship
+func (t *TrackingDB) SetShip(fid int64, sc ifaces.ShipCoordData) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var setQ = `INSERT OR REPLACE INTO ships ("GAMEID","NAME","X","Y","TIME")
+		VALUES (?,?,?,?,?);`
+
+	if _, err = db.Exec(setQ, fid, sc.Name, sc.X, sc.Y, sc.Time.Unix()); err != nil {
+		logger.LogError(t, fmt.Sprintf("SetShip: %s", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// FindShips returns the ships whose names contain the given string, along
+// with their owners and last known locations, most recently seen first
+func (t *TrackingDB) FindShips(name string, limit int) ([]ifaces.ShipRecord,
+	error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ships = make([]ifaces.ShipRecord, 0)
+		selQ  = `SELECT s."NAME", s."GAMEID", COALESCE((SELECT f."NAME" FROM factions f
+			WHERE f."GAMEID" = s."GAMEID" LIMIT 1), ''), s."X", s."Y", s."TIME"
+			FROM ships s WHERE s."NAME" LIKE '%' || ? || '%'
+			ORDER BY s."TIME" DESC LIMIT ?;`
+	)
+
+	rows, err := db.Query(selQ, name, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			ts int64
+			sr ifaces.ShipRecord
+		)
+
+		if err := rows.Scan(&sr.Name, &sr.OwnerIndex, &sr.Owner, &sr.X, &sr.Y,
+			&ts); err != nil {
+			return nil, err
+		}
+
+		sr.Seen = time.Unix(ts, 0)
+		ships = append(ships, sr)
+	}
+
+	return ships, rows.Err()
+}
+
+// exportQueries are the queries used to export each kind of tracked data.
+// Each takes the player name or index to filter by three times (or an empty
+// string to export everything).
+var exportQueries = map[string]string{
+	"players": `SELECT f."GAMEID" AS game_id, f."NAME" AS name,
+		COALESCE(i."DISCORD", '') AS discord_id,
+		COALESCE(p."HIDDEN", 0) AS private
+		FROM factions f
+		LEFT JOIN integrations i ON i."FACTION" = f."GAMEID"
+		LEFT JOIN privacy p ON p."GAMEID" = f."GAMEID"
+		WHERE f."KIND" = 0 AND (? = '' OR f."NAME" = ? COLLATE NOCASE
+		OR CAST(f."GAMEID" AS TEXT) = ?) ORDER BY f."GAMEID";`,
+
+	"jumps": `SELECT * FROM (SELECT j."TIME" AS time, j."FACTION" AS faction_id,
+		COALESCE((SELECT f."NAME" FROM factions f WHERE f."GAMEID" = j."FACTION"
+		AND f."KIND" = j."KIND" LIMIT 1), '') AS owner,
+		j."SHIP NAME" AS ship, s."X" AS x, s."Y" AS y,
+		COALESCE(t."NAME", '') AS territory
+		FROM jumps j LEFT JOIN sectors s ON s."ID" = j."SECTOR"
+		LEFT JOIN territory t ON t."X" = s."X" AND t."Y" = s."Y")
+		WHERE ? = '' OR owner = ? COLLATE NOCASE OR CAST(faction_id AS TEXT) = ?
+		ORDER BY time;`,
+
+	"chat": `SELECT "TIME" AS time, "NAME" AS name, "MSG" AS message,
+		"SOURCE" AS source FROM chatlog
+		WHERE ? = '' OR "NAME" = ? COLLATE NOCASE OR "NAME" = ?
+		ORDER BY "TIME";`}
+
+// Export returns the column names and rows of a kind of tracked data
+// (players, jumps, or chat), optionally limited to a single player. Times are
+// converted to RFC3339.
+func (t *TrackingDB) Export(kind, player string) ([]string, [][]string, error) {
+	q, ok := exportQueries[kind]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown export type: %s", kind)
+	}
+
+	db, err := t.open()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := db.Query(q, player, player, player)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := make([][]string, 0)
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, nil, err
+		}
+
+		row := make([]string, len(cols))
+		for i, v := range vals {
+			switch val := v.(type) {
+			case nil:
+				row[i] = ""
+			case []byte:
+				row[i] = string(val)
+			case int64:
+				row[i] = strconv.FormatInt(val, 10)
+				if cols[i] == "time" {
+					row[i] = time.Unix(val, 0).UTC().Format(time.RFC3339)
+				}
+			case float64:
+				row[i] = strconv.FormatFloat(val, 'f', -1, 64)
+				if cols[i] == "time" {
+					row[i] = time.Unix(int64(val), 0).UTC().Format(time.RFC3339)
+				}
+			default:
+				row[i] = fmt.Sprint(val)
+			}
+		}
+		data = append(data, row)
+	}
+
+	return cols, data, rows.Err()
+}
+
+// SetTerritory records the NPC faction that currently controls a sector
+func (t *TrackingDB) SetTerritory(sec *ifaces.Sector) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	var setQ = `INSERT OR REPLACE INTO territory ("X","Y","FACTION","NAME","TIME")
+		VALUES (?,?,?,?,?);`
+
+	_, err = db.Exec(setQ, sec.X, sec.Y, sec.Faction, sec.FactionName,
+		time.Now().Unix())
+	if err != nil {
+		logger.LogError(t, fmt.Sprintf("SetTerritory: %s", err.Error()))
+		return err
+	}
+
+	logger.LogDebug(t, fmt.Sprintf("SetTerritory: %d:%d = %s (%d)", sec.X,
+		sec.Y, sec.FactionName, sec.Faction))
+	return nil
+}
+
+// AllianceAlerts returns the alert settings and sector claims of every
+//	alliance that has linked a Discord channel or claimed a sector
+func (t *TrackingDB) AllianceAlerts() ([]ifaces.AllianceAlerts, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		index  = make(map[string]*ifaces.AllianceAlerts)
+		alerts = make([]ifaces.AllianceAlerts, 0)
+		order  = make([]string, 0)
+	)
+
+	get := func(aid string) *ifaces.AllianceAlerts {
+		if _, ok := index[aid]; !ok {
+			index[aid] = &ifaces.AllianceAlerts{Alliance: aid,
+				Claims: make([]ifaces.SectorCoord, 0)}
+			order = append(order, aid)
+		}
+		return index[aid]
+	}
+
+	rows, err := db.Query(`SELECT "ALLIANCE", "CHANNEL", "ROLE"
+		FROM alliancealerts;`)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var aid, channel, role string
+		if err := rows.Scan(&aid, &channel, &role); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		a := get(aid)
+		a.Channel, a.Role = channel, role
+	}
+	rows.Close()
+
+	rows, err = db.Query(`SELECT "ALLIANCE", "X", "Y" FROM allianceclaims
+		ORDER BY "X", "Y";`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			aid  string
+			x, y int
+		)
+		if err := rows.Scan(&aid, &x, &y); err != nil {
+			return nil, err
+		}
+		a := get(aid)
+		a.Claims = append(a.Claims, ifaces.SectorCoord{X: x, Y: y})
+	}
+
+	for _, aid := range order {
+		alerts = append(alerts, *index[aid])
+	}
+
+	return alerts, rows.Err()
+}
+
+// SetAllianceAlerts records the Discord channel and role that an alliance is
+//	alerted in. An empty channel removes the alerts.
+func (t *TrackingDB) SetAllianceAlerts(a ifaces.AllianceAlerts) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	if a.Channel == "" {
+		_, err = db.Exec(`DELETE FROM alliancealerts WHERE "ALLIANCE"=?;`,
+			a.Alliance)
+	} else {
+		_, err = db.Exec(`INSERT OR REPLACE INTO alliancealerts
+			("ALLIANCE","CHANNEL","ROLE") VALUES (?,?,?);`,
+			a.Alliance, a.Channel, a.Role)
+	}
+
+	if err != nil {
+		logger.LogError(t, fmt.Sprintf("SetAllianceAlerts: %s", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// ClaimSector records that an alliance has claimed a sector, replacing any
+//	previous claim on it
+func (t *TrackingDB) ClaimSector(alliance string, x, y int) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`INSERT OR REPLACE INTO allianceclaims ("X","Y","ALLIANCE")
+		VALUES (?,?,?);`, x, y, alliance)
+	if err != nil {
+		logger.LogError(t, fmt.Sprintf("ClaimSector: %s", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// UnclaimSector removes the claim that an alliance has on a sector
+func (t *TrackingDB) UnclaimSector(alliance string, x, y int) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`DELETE FROM allianceclaims
+		WHERE "X"=? AND "Y"=? AND "ALLIANCE"=?;`, x, y, alliance)
+	if err != nil {
+		logger.LogError(t, fmt.Sprintf("UnclaimSector: %s", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// AddGameConfig stores a copy of server.ini, and returns its version number
+func (t *TrackingDB) AddGameConfig(v ifaces.GameConfigVersion) (int64, error) {
+	db, err := t.open()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := db.Exec(`INSERT INTO gameconfigs ("TIME","AUTHOR","REASON",
+		"CONTENT") VALUES (?,?,?,?);`, v.Time.Unix(), v.Author, v.Reason,
+		v.Content)
+	if err != nil {
+		logger.LogError(t, fmt.Sprintf("AddGameConfig: %s", err.Error()))
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// GameConfigs returns up to limit of the most recent stored copies of
+//	server.ini, newest first
+func (t *TrackingDB) GameConfigs(limit int) ([]ifaces.GameConfigVersion, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT "VERSION", "TIME", "AUTHOR", "REASON",
+		"CONTENT" FROM gameconfigs ORDER BY "VERSION" DESC LIMIT ?;`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]ifaces.GameConfigVersion, 0)
+	for rows.Next() {
+		var (
+			v       ifaces.GameConfigVersion
+			created int64
+		)
+		if err := rows.Scan(&v.Version, &created, &v.Author, &v.Reason,
+			&v.Content); err != nil {
+			return nil, err
+		}
+		v.Time = time.Unix(created, 0)
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// GameConfig returns the stored copy of server.ini with the given version
+func (t *TrackingDB) GameConfig(version int64) (ifaces.GameConfigVersion, error) {
+	db, err := t.open()
+	if err != nil {
+		return ifaces.GameConfigVersion{}, err
+	}
+
+	var (
+		v       = ifaces.GameConfigVersion{Version: version}
+		created int64
+	)
+
+	if err := db.QueryRow(`SELECT "TIME", "AUTHOR", "REASON", "CONTENT"
+		FROM gameconfigs WHERE "VERSION"=?;`, version).Scan(&created, &v.Author,
+		&v.Reason, &v.Content); err != nil {
+		return v, err
+	}
+
+	v.Time = time.Unix(created, 0)
+	return v, nil
+}
+
+// AddOneShotAction stores an action that is scheduled to run once, and returns
+//	its ID
+func (t *TrackingDB) AddOneShotAction(a ifaces.OneShotAction) (int64, error) {
+	db, err := t.open()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := db.Exec(`INSERT INTO oneshotactions ("ACTION","TIME","AUTHOR")
+		VALUES (?,?,?);`, a.Action, a.At.Unix(), a.Author)
+	if err != nil {
+		logger.LogError(t, fmt.Sprintf("AddOneShotAction: %s", err.Error()))
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// OneShotActions returns the actions that are scheduled to run once, soonest
+//	first
+func (t *TrackingDB) OneShotActions() ([]ifaces.OneShotAction, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT "ID", "ACTION", "TIME", "AUTHOR"
+		FROM oneshotactions ORDER BY "TIME" ASC;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := make([]ifaces.OneShotAction, 0)
+	for rows.Next() {
+		var (
+			a  ifaces.OneShotAction
+			at int64
+		)
+		if err := rows.Scan(&a.ID, &a.Action, &at, &a.Author); err != nil {
+			return nil, err
+		}
+		a.At = time.Unix(at, 0)
+		actions = append(actions, a)
+	}
+
+	return actions, rows.Err()
+}
+
+// RemoveOneShotAction removes an action that was scheduled to run once
+func (t *TrackingDB) RemoveOneShotAction(id int64) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM oneshotactions WHERE "ID"=?;`,
+		id); err != nil {
+		logger.LogError(t, fmt.Sprintf("RemoveOneShotAction: %s", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// AddServerExit records an exit of the Avorion process
+func (t *TrackingDB) AddServerExit(e ifaces.ServerExit) error {
+	db, err := t.open()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`INSERT INTO serverexits ("TIME","CODE","SIGNAL","CLASS")
+		VALUES (?,?,?,?);`, e.Time.Unix(), e.Code, e.Signal, e.Class); err != nil {
+		logger.LogError(t, fmt.Sprintf("AddServerExit: %s", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// ServerExits returns up to limit of the most recent exits of the Avorion
+//	process, newest first
+func (t *TrackingDB) ServerExits(limit int) ([]ifaces.ServerExit, error) {
+	db, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT "TIME", "CODE", "SIGNAL", "CLASS"
+		FROM serverexits ORDER BY "TIME" DESC LIMIT ?;`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exits := make([]ifaces.ServerExit, 0)
+	for rows.Next() {
+		var (
+			e  ifaces.ServerExit
+			at int64
+		)
+		if err := rows.Scan(&at, &e.Code, &e.Signal, &e.Class); err != nil {
+			return nil, err
+		}
+		e.Time = time.Unix(at, 0)
+		exits = append(exits, e)
+	}
+
+	return exits, rows.Err()
+}
+
 /************************/
 /* IFace logger.ILogger */
 /************************/
@@ -0,0 +1,54 @@
+package gamedb
+
+import (
+	"avorioncontrol/ifaces"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryConcurrentJumps(t *testing.T) {
+	tracking, err := NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tracking.Close()
+
+	if _, err := tracking.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mutex  sync.Mutex
+		failed int
+	)
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				err := tracking.AddJump(int64(i), int64(j), 0, ifaces.JumpInfo{
+					Name: "Sparrow", Time: time.Now()})
+				if err == nil {
+					_, _, err = tracking.FactionJumps(0, 10)
+				}
+				if err != nil {
+					mutex.Lock()
+					failed++
+					mutex.Unlock()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if failed > 0 {
+		t.Errorf("%d of 1600 concurrent jumps failed", failed)
+	}
+
+	if n, err := tracking.JumpCount(); err != nil || n != 1600 {
+		t.Errorf("expected 1600 recorded jumps, got %d (%v)", n, err)
+	}
+}
@@ -15,6 +15,13 @@ CREATE TABLE IF NOT EXISTS "jumps" (
   "SHIPNAME"  TEXT,
   "SECTORID"  INTEGER,
   "FACTIONID" INTEGER);
+CREATE TABLE IF NOT EXISTS "territory" (
+  "X"         INTEGER,
+  "Y"         INTEGER,
+  "FACTION"   INTEGER,
+  "NAME"      TEXT,
+  "TIME"      REAL,
+  PRIMARY KEY ("X", "Y"));
 CREATE TABLE IF NOT EXISTS "integrations" (
   "ID"        INTEGER PRIMARY KEY AUTOINCREMENT,
   "FACTIONID" INTEGER,
@@ -22,4 +29,71 @@ CREATE TABLE IF NOT EXISTS "integrations" (
 CREATE TABLE IF NOT EXISTS "playerlogins" (
   "ID"        INTEGER PRIMARY KEY AUTOINCREMENT,
   "TIME"      REAL,
-  "STEAM64ID" TEXT);
\ No newline at end of file
+  "STEAM64ID" TEXT);
+CREATE TABLE IF NOT EXISTS "commandstats" (
+  "GUILD"     TEXT,
+  "NAME"      TEXT,
+  "USES"      INTEGER,
+  "FAILURES"  INTEGER,
+  PRIMARY KEY ("GUILD", "NAME"));
+CREATE TABLE IF NOT EXISTS "tempbans" (
+  "GAMEID"    INTEGER PRIMARY KEY,
+  "NAME"      TEXT,
+  "STEAM64ID" TEXT,
+  "EXPIRES"   INTEGER);
+CREATE TABLE IF NOT EXISTS "ships" (
+  "GAMEID"    INTEGER,
+  "NAME"      TEXT,
+  "X"         INTEGER,
+  "Y"         INTEGER,
+  "TIME"      INTEGER,
+  PRIMARY KEY ("GAMEID", "NAME"));
+CREATE TABLE IF NOT EXISTS "reviews" (
+  "GAMEID"    INTEGER PRIMARY KEY,
+  "NAME"      TEXT,
+  "DISCORD"   TEXT,
+  "REASON"    TEXT,
+  "TIME"      INTEGER);
+CREATE TABLE IF NOT EXISTS "allianceleaders" (
+  "ALLIANCE"  INTEGER PRIMARY KEY,
+  "FOUNDER"   INTEGER,
+  "LEADER"    INTEGER,
+  "MEMBERS"   TEXT,
+  "UPDATED"   INTEGER);
+CREATE TABLE IF NOT EXISTS "steamids" (
+  "GAMEID"    INTEGER PRIMARY KEY,
+  "STEAMID"   INTEGER);
+CREATE TABLE IF NOT EXISTS "kills" (
+  "ID"          INTEGER PRIMARY KEY AUTOINCREMENT,
+  "TIME"        INTEGER,
+  "FACTION"     INTEGER,
+  "KILLER"      INTEGER,
+  "NAME"        TEXT,
+  "CLASS"       TEXT,
+  "VOLUME"      REAL,
+  "KILLERCLASS" TEXT,
+  "X"           INTEGER,
+  "Y"           INTEGER);
+CREATE TABLE IF NOT EXISTS "lastseen" (
+  "GAMEID"    INTEGER PRIMARY KEY,
+  "TIME"      INTEGER);
+CREATE TABLE IF NOT EXISTS "jumphours" (
+  "SECTOR"    INTEGER,
+  "HOUR"      INTEGER,
+  "JUMPS"     INTEGER,
+  PRIMARY KEY ("SECTOR", "HOUR"));
+CREATE TABLE IF NOT EXISTS "alliancealerts" (
+  "ALLIANCE"  INTEGER PRIMARY KEY,
+  "CHANNEL"   TEXT,
+  "ROLE"      TEXT);
+CREATE TABLE IF NOT EXISTS "allianceclaims" (
+  "X"         INTEGER,
+  "Y"         INTEGER,
+  "ALLIANCE"  INTEGER,
+  PRIMARY KEY ("X", "Y"));
+CREATE TABLE IF NOT EXISTS "gameconfigs" (
+  "VERSION"   INTEGER PRIMARY KEY AUTOINCREMENT,
+  "TIME"      INTEGER,
+  "AUTHOR"    TEXT,
+  "REASON"    TEXT,
+  "CONTENT"   TEXT);
@@ -0,0 +1,219 @@
+package avorion
+
+import (
+	gamedb "avorioncontrol/avorion/database"
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"errors"
+	"os"
+	"time"
+)
+
+const warnDBDegraded = "⚠️ **The tracking database is unavailable** (%s). " +
+	"The bot is running with a temporary in-memory database, so tracked data " +
+	"won't be saved. Run `db rebuild` to recreate it."
+
+// openTracking opens the tracking database. If the database file can't be
+// opened or is corrupt, the bot falls back to a temporary in-memory database
+// rather than refusing to start the server.
+func (s *Server) openTracking() ([]*ifaces.Sector, error) {
+	if err := s.prepareTrackingDir(); err != nil {
+		return nil, s.degradeTracking(err)
+	}
+
+	tracking, sectors, err := openTrackingFile(s.trackingFile())
+	if err == nil {
+		// A galaxy that was reset starts over with a new database
+		archived, aerr := s.archiveSeason(tracking)
+		if aerr != nil {
+			logger.LogError(s, "Failed to archive the tracked data: "+aerr.Error())
+		}
+		if archived {
+			tracking, sectors, err = openTrackingFile(s.trackingFile())
+		}
+	}
+
+	if err != nil {
+		return nil, s.degradeTracking(err)
+	}
+
+	tracking.SetLoglevel(s.loglevel)
+	s.swapTracking(tracking)
+	s.cache.update(func(c *statusSnapshot) { c.dbdegraded = "" })
+	return sectors, nil
+}
+
+// trackingDB returns the tracking database, or nil if it's closed. The
+// database can be replaced at any time, so callers that use it more than once
+// should keep the value that is returned rather than calling this again.
+func (s *Server) trackingDB() *gamedb.TrackingDB {
+	s.trackinglock.RLock()
+	defer s.trackinglock.RUnlock()
+	return s.tracking
+}
+
+// swapTracking replaces the tracking database, and closes the one it replaced
+func (s *Server) swapTracking(tracking *gamedb.TrackingDB) {
+	s.trackinglock.Lock()
+	old := s.tracking
+	s.tracking = tracking
+	s.trackinglock.Unlock()
+
+	if old != nil {
+		if err := old.Close(); err != nil {
+			logger.LogError(s, "Failed to close the tracking database: "+
+				err.Error())
+		}
+	}
+}
+
+// openTrackingFile opens and initializes a tracking database file
+func openTrackingFile(file string) (*gamedb.TrackingDB, []*ifaces.Sector,
+	error) {
+	tracking, err := gamedb.New(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sectors, err := tracking.Init()
+	if err != nil {
+		tracking.Close()
+		return nil, nil, err
+	}
+
+	return tracking, sectors, nil
+}
+
+// degradeTracking replaces the tracking database with an in-memory database,
+// and warns the admins that tracked data isn't being saved
+func (s *Server) degradeTracking(cause error) error {
+	logger.LogError(s, "GameDB: "+cause.Error())
+
+	tracking, err := gamedb.NewMemory()
+	if err != nil {
+		return errors.New("GameDB: " + err.Error())
+	}
+
+	if _, err := tracking.Init(); err != nil {
+		tracking.Close()
+		return errors.New("GameDB: " + err.Error())
+	}
+
+	tracking.SetLoglevel(s.loglevel)
+	s.swapTracking(tracking)
+	s.cache.update(func(c *statusSnapshot) { c.dbdegraded = cause.Error() })
+
+	logger.LogWarning(s, "Running with an in-memory tracking database")
+	s.SendLog(ifaces.ChatData{Msg: sprintf(warnDBDegraded, cause.Error())})
+	return nil
+}
+
+// checkTracking switches to an in-memory database if the tracking database
+// has become corrupt since it was opened
+func (s *Server) checkTracking() {
+	tracking := s.trackingDB()
+	if tracking == nil || s.DBDegraded() != "" {
+		return
+	}
+
+	if err := tracking.Check(); err != nil {
+		s.dblock.Lock()
+		defer s.dblock.Unlock()
+		if err := s.degradeTracking(err); err != nil {
+			logger.LogError(s, err.Error())
+		}
+	}
+}
+
+/********************************/
+/* IFace ifaces.IDatabaseServer */
+/********************************/
+
+// DBDegraded returns the reason that the tracking database is unavailable, or
+// an empty string if it's working normally
+func (s *Server) DBDegraded() string {
+	return s.cache.load().dbdegraded
+}
+
+// RebuildDB moves the tracking database file aside and recreates it, then
+// re-imports the sectors, players, and alliances that the server knows about
+// along with the sectors saved in the galaxy files.
+// If the game is online, the player database is also refreshed from the game.
+func (s *Server) RebuildDB() error {
+	s.dblock.Lock()
+
+	file := s.trackingFile()
+	s.swapTracking(nil)
+
+	// Keep the old database around in case anything can be recovered from it
+	if _, err := os.Stat(file); err == nil {
+		backup := sprintf("%s.broken-%d", file, time.Now().Unix())
+		if err := os.Rename(file, backup); err != nil {
+			s.dblock.Unlock()
+			s.degradeTracking(err)
+			return errors.New("Failed to move the old database: " + err.Error())
+		}
+		logger.LogWarning(s, "Moved the old tracking database to "+backup)
+	}
+
+	for _, ext := range []string{"-wal", "-shm"} {
+		os.Remove(file + ext)
+	}
+
+	tracking, err := gamedb.New(file)
+	if err == nil {
+		if _, err = tracking.Init(); err != nil {
+			tracking.Close()
+		}
+	}
+
+	if err != nil {
+		s.dblock.Unlock()
+		s.degradeTracking(err)
+		return errors.New("GameDB: " + err.Error())
+	}
+
+	tracking.SetLoglevel(s.loglevel)
+	s.swapTracking(tracking)
+	s.cache.update(func(c *statusSnapshot) { c.dbdegraded = "" })
+
+	for _, row := range s.sectors {
+		for _, sec := range row {
+			tracking.TrackSector(sec)
+		}
+	}
+
+	if _, err := s.importSectors(); err != nil {
+		logger.LogError(s, "Failed to import sectors: "+err.Error())
+	}
+
+	for _, p := range s.players {
+		if err := tracking.TrackPlayer(p); err != nil {
+			logger.LogError(s, err.Error())
+		}
+	}
+
+	for _, a := range s.alliances {
+		tracking.TrackAlliance(a)
+	}
+
+	s.dblock.Unlock()
+	logger.LogInfo(s, "Rebuilt the tracking database")
+
+	if s.IsUp() {
+		if _, err := s.refreshPlayerDatabase(false, nil); err != nil {
+			return errors.New("Rebuilt the database, but failed to import the " +
+				"player data: " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+// CloseDB closes the tracking database. Anything that uses the database after
+// this treats it as unavailable.
+func (s *Server) CloseDB() {
+	s.dblock.Lock()
+	defer s.dblock.Unlock()
+	s.swapTracking(nil)
+}
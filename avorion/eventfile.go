@@ -0,0 +1,217 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Major version of the event file format that the bot understands. The mod
+	// writes this with every record, and records from a newer major version
+	// are skipped rather than misread.
+	eventFileVersion = 1
+
+	eventFilePoll = time.Second
+
+	rconSetEventFile = `seteventfile "%s"`
+	rconClrEventFile = `seteventfile`
+
+	noticeShipDestroyed = "**Ship Destroyed**: `%s` (%s) at `%d:%d`"
+)
+
+// eventRecord is a single line of the JSONL event file written by the mod
+type eventRecord struct {
+	Version int    `json:"v"`
+	Type    string `json:"type"`
+	Time    int64  `json:"time"`
+
+	// Faction that owns the ship the event is for
+	Faction int    `json:"faction"`
+	Name    string `json:"name"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+
+	// Faction that destroyed the ship, and the class and size of the ships
+	// involved, for destroyed events
+	Killer      int     `json:"killer"`
+	Class       string  `json:"class"`
+	Volume      float64 `json:"volume"`
+	KillerClass string  `json:"killer_class"`
+
+	// Donating player, resource and amount, for donation events. The faction
+	// is the alliance that received the donation.
+	Player   int    `json:"player"`
+	Resource string `json:"resource"`
+	Amount   int64  `json:"amount"`
+}
+
+// eventFilePath returns the path of the event file for the current galaxy, or
+// an empty string if the event file is disabled
+func (s *Server) eventFilePath() string {
+	name := strings.TrimSpace(s.config.EventFile())
+	if name == "" {
+		return ""
+	}
+	if strings.HasPrefix(name, "/") {
+		return name
+	}
+	return s.datapath + "/" + s.name + "/" + name
+}
+
+// resetEventFile removes the event file left by a previous run, so that its
+// events aren't ingested a second time
+func (s *Server) resetEventFile() {
+	if path := s.eventFilePath(); path != "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			logger.LogWarning(s, "Failed to remove old event file: "+err.Error())
+		}
+	}
+}
+
+// announceEventFile tells the mod where to write events, or to stop writing
+// them and fall back to printing them when the event file is disabled
+func (s *Server) announceEventFile() {
+	cmd := rconClrEventFile
+	if path := s.eventFilePath(); path != "" {
+		cmd = sprintf(rconSetEventFile, path)
+	}
+
+	if _, err := s.RunCommandPriority(cmd, ifaces.CommandPriorityHealth); err != nil {
+		logger.LogWarning(s, "Failed to set the mod event file: "+err.Error())
+	}
+}
+
+// watchEventFile follows the event file that the mod writes, and ingests each
+// complete line as it is appended. Partial lines are kept until the rest of
+// the line is written.
+func (s *Server) watchEventFile(closech chan struct{}) {
+	path := s.eventFilePath()
+	if path == "" {
+		return
+	}
+
+	logger.LogInit(s, "Watching mod event file: "+path)
+
+	var (
+		offset  int64
+		partial string
+	)
+
+	for {
+		select {
+		case <-closech:
+			return
+		case <-s.exit:
+			return
+		case <-s.clock.After(eventFilePoll):
+		}
+
+		info, err := s.fs.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		// The file was replaced or truncated, so start again from the top
+		if info.Size() < offset {
+			logger.LogWarning(s, "Mod event file was truncated, rereading it")
+			offset, partial = 0, ""
+		}
+
+		if info.Size() == offset {
+			continue
+		}
+
+		f, err := s.fs.Open(path)
+		if err != nil {
+			logger.LogError(s, "Failed to open mod event file: "+err.Error())
+			continue
+		}
+
+		if _, err = f.Seek(offset, io.SeekStart); err == nil {
+			reader := bufio.NewReader(f)
+			for {
+				line, err := reader.ReadString('\n')
+				offset += int64(len(line))
+				if err != nil {
+					partial += line
+					break
+				}
+
+				s.ingestEvent(partial + line)
+				partial = ""
+			}
+		}
+		f.Close()
+	}
+}
+
+// ingestEvent parses and applies a single line from the event file
+func (s *Server) ingestEvent(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	var rec eventRecord
+	if err := json.Unmarshal([]byte(line), &rec); err != nil {
+		logger.LogError(s, sprintf(errBadDataString, line))
+		return
+	}
+
+	if rec.Version > eventFileVersion {
+		logger.LogWarning(s, sprintf("Skipped %s event from a newer mod version (v%d)",
+			rec.Type, rec.Version))
+		return
+	}
+
+	owner := strconv.Itoa(rec.Faction)
+	switch rec.Type {
+	case "jump":
+		data := ifaces.ShipCoordData{X: rec.X, Y: rec.Y, Name: rec.Name}
+		if rec.Time > 0 {
+			data.Time = time.Unix(rec.Time, 0)
+		}
+
+		if p := s.Player(owner); p != nil {
+			p.AddJump(data)
+		} else if a := s.Alliance(owner); a != nil {
+			a.AddJump(data)
+		}
+
+	case "destroyed":
+		var name string
+		if p := s.Player(owner); p != nil {
+			name = p.Name()
+		} else if a := s.Alliance(owner); a != nil {
+			name = a.Name()
+		} else {
+			return
+		}
+
+		kill := ifaces.ShipKill{Faction: rec.Faction, Killer: rec.Killer,
+			Name: rec.Name, Class: rec.Class, Volume: rec.Volume,
+			KillerClass: rec.KillerClass, X: rec.X, Y: rec.Y, Time: s.clock.Now()}
+		if rec.Time > 0 {
+			kill.Time = time.Unix(rec.Time, 0)
+		}
+
+		logger.LogInfo(s, sprintf("Ship %s of %s was destroyed at %d:%d by %d",
+			rec.Name, name, rec.X, rec.Y, rec.Killer))
+		s.recordKill(kill)
+		s.SendLog(ifaces.ChatData{Msg: sprintf(noticeShipDestroyed, rec.Name, name,
+			rec.X, rec.Y), Thread: sprintf("Battle at %d:%d", rec.X, rec.Y)})
+
+	case "donation":
+		s.RecordDonation(owner, strconv.Itoa(rec.Player), rec.Resource, rec.Amount)
+
+	default:
+		logger.LogDebug(s, "Ignored unknown mod event: "+rec.Type)
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 var discChatRe = regexp.MustCompile(`^\s*<D> <.*?#[0-9]{4}> (.*)$`)
+var emoteChatRe = regexp.MustCompile(`^/me\s+(.+)$`)
+var voteChatRe = regexp.MustCompile(`^!(votestart|vote)\b\s*(.*?)\s*$`)
 var modURLBase = `https://steamcommunity.com/sharedfiles/filedetails/?id=`
 
 func initB() {
@@ -16,6 +19,14 @@ func initB() {
 		`^\s*shipTrackInitEvent: (-?[0-9]+) (-?[0-9]+):(-?[0-9]+) (.*)$`,
 		handleEventShipTrackInit)
 
+	New("EventAllianceChat",
+		`^\s*\[Alliance\] <(.+?)> (.*)`,
+		handleAllianceChat)
+
+	New("EventPrivateChat",
+		`^\s*\[(?:Whisper|Private)\] <(.+?)> (.*)`,
+		handlePrivateChat)
+
 	New("EventPlayerChat",
 		`^\s*<(.+?)> (.*)`,
 		handlePlayerChat)
@@ -24,6 +35,10 @@ func initB() {
 		`^\s*shipJumpEvent: (-?[0-9]+) (-?[0-9]+):(-?[0-9]+) (.*)$`,
 		handleEventShipJump)
 
+	New("EventSectorControl",
+		`^\s*sectorControlEvent: (-?[0-9]+):(-?[0-9]+) (-?[0-9]+)\s*(.*?)\s*$`,
+		handleEventSectorControl)
+
 	New("EventPlayerJoin",
 		`^\s*playerJoinEvent: ([0-9]+) (.+?)\s*$`,
 		handleEventPlayerJoin)
@@ -44,9 +59,21 @@ func initB() {
 		`^\s*discordIntegrationRequestEvent: ([0-9]+) ([0-9]+)`,
 		handleDiscordIntegrationRequest)
 
+	New("EventAllianceDonation",
+		`^\s*allianceDonationEvent: ([0-9]+) ([0-9]+) ([a-zA-Z]+) ([0-9]+)\s*$`,
+		handleEventAllianceDonation)
+
+	New("EventStaffMessage",
+		`^\s*staffMessageEvent: ([0-9]+) (.+?)\s*$`,
+		handleEventStaffMessage)
+
 	New("EventModUpdate",
 		`^\s*Downloading ([0-9]+) \[[^\s]+ of [^\s]+ \| 100%\]\s*$`,
 		handleModUpdate)
+
+	New("EventScriptError",
+		`^.*?([^\s:'"]*scripts/[^\s:'"]+\.lua):([0-9]+): (.+?)\s*$`,
+		handleScriptError)
 }
 
 func handleEventConnection(srv ifaces.IGameServer, e *Event, in string,
@@ -59,7 +86,7 @@ func handleEventPlayerJoin(srv ifaces.IGameServer, e *Event, in string,
 	oc chan string) {
 	m := e.Capture.FindStringSubmatch(in)
 	if p := srv.Player(m[1]); p == nil {
-		p = srv.NewPlayer(m[1], m)
+		p = srv.NewPlayer(m[1])
 		p.SetOnline(true)
 	} else {
 		p.Update()
@@ -74,8 +101,7 @@ func handleEventPlayerLeft(srv ifaces.IGameServer, e *Event, in string,
 	m := e.Capture.FindStringSubmatch(in)
 
 	if p := srv.Player(m[1]); p != nil {
-		p.SetOnline(false)
-		srv.SubPlayerOnline()
+		srv.RemovePlayer(m[1])
 		return
 	}
 
@@ -84,6 +110,12 @@ func handleEventPlayerLeft(srv ifaces.IGameServer, e *Event, in string,
 
 func handleEventShipTrackInit(srv ifaces.IGameServer, e *Event, in string,
 	oc chan string) {
+	m := e.Capture.FindStringSubmatch(in)
+
+	x, _ := strconv.Atoi(m[2])
+	y, _ := strconv.Atoi(m[3])
+
+	srv.TrackShip(m[1], ifaces.ShipCoordData{X: x, Y: y, Name: m[4]})
 }
 
 func handleEventShipJump(srv ifaces.IGameServer, e *Event, in string,
@@ -104,6 +136,25 @@ func handleEventShipJump(srv ifaces.IGameServer, e *Event, in string,
 	}
 }
 
+func handleEventAllianceDonation(srv ifaces.IGameServer, e *Event, in string,
+	oc chan string) {
+	m := e.Capture.FindStringSubmatch(in)
+
+	amount, _ := strconv.ParseInt(m[4], 10, 64)
+	srv.RecordDonation(m[1], m[2], m[3], amount)
+}
+
+func handleEventSectorControl(srv ifaces.IGameServer, e *Event, in string,
+	oc chan string) {
+	m := e.Capture.FindStringSubmatch(in)
+
+	x, _ := strconv.Atoi(m[1])
+	y, _ := strconv.Atoi(m[2])
+	fid, _ := strconv.Atoi(m[3])
+
+	srv.SetSectorFaction(x, y, fid, m[4])
+}
+
 func handlePlayerChat(srv ifaces.IGameServer, e *Event, in string,
 	oc chan string) {
 	logger.LogChat(srv, in)
@@ -114,21 +165,71 @@ func handlePlayerChat(srv ifaces.IGameServer, e *Event, in string,
 
 	m := e.Capture.FindStringSubmatch(in)
 	if m[1] != "Server" && m[1] != "Discord" {
-		out := m[2]
-		if len(out) >= 2000 {
-			logger.LogInfo(srv, "Truncated player message for sending")
-			out = out[0:1900]
-			out += "...(truncated)"
+		// Votes are commands to the bot, so they aren't relayed
+		if vm := voteChatRe.FindStringSubmatch(m[2]); vm != nil {
+			srv.PlayerVote(m[1], vm[1], vm[2])
+			return
 		}
 
 		output := ifaces.ChatData{
 			Name: m[1],
-			Msg:  out}
+			Msg:  m[2],
+			Kind: ifaces.ChatKindGlobal}
+
+		if em := emoteChatRe.FindStringSubmatch(m[2]); em != nil {
+			output.Msg = em[1]
+			output.Kind = ifaces.ChatKindEmote
+		}
 
-		srv.SendChat(output)
+		sendPlayerChat(srv, output)
 	}
 }
 
+func handleAllianceChat(srv ifaces.IGameServer, e *Event, in string,
+	oc chan string) {
+	logger.LogChat(srv, in)
+
+	// Alliance chat is only relayed when the admins have opted into it
+	if !srv.Config().AllianceChatRelay() {
+		return
+	}
+
+	m := e.Capture.FindStringSubmatch(in)
+	sendPlayerChat(srv, ifaces.ChatData{
+		Name: m[1],
+		Msg:  m[2],
+		Kind: ifaces.ChatKindAlliance})
+}
+
+// Private messages are logged locally, but are never relayed to Discord
+func handlePrivateChat(srv ifaces.IGameServer, e *Event, in string,
+	oc chan string) {
+	logger.LogChat(srv, in)
+}
+
+// sendPlayerChat truncates a chat message to fit in a Discord message and
+// sends it to the bot
+func sendPlayerChat(srv ifaces.IGameServer, cd ifaces.ChatData) {
+	if len(cd.Msg) >= 2000 {
+		logger.LogInfo(srv, "Truncated player message for sending")
+		cd.Msg = cd.Msg[0:1900]
+		cd.Msg += "...(truncated)"
+	}
+
+	// Chatting counts as activity, even if the message is filtered
+	if p := srv.PlayerFromName(cd.Name); p != nil {
+		p.SetActive()
+	}
+
+	// Filtered messages and muted players are not relayed
+	if !srv.ModerateChat(cd) {
+		return
+	}
+
+	srv.RecordChat(cd, ifaces.ChatSourceGame)
+	srv.SendChat(cd)
+}
+
 func handleNilCommand(srv ifaces.IGameServer, e *Event, in string,
 	oc chan string) {
 }
@@ -171,11 +272,9 @@ func handleEventPlayerBan(srv ifaces.IGameServer, e *Event, in string,
 		return
 	}
 
-	p.Ban(m[2])
-
-	srv.SendLog(ifaces.ChatData{
-		Msg: fmt.Sprintf("**Kicked Player:** `%s`\n**Reason:** _%s_",
-			p.Name(), m[2])})
+	if _, err := srv.BanPlayer(p, "In-game staff", m[2], 0); err != nil {
+		logger.LogError(srv, "Failed to record the ban: "+err.Error())
+	}
 }
 
 func handleModUpdate(srv ifaces.IGameServer, e *Event, in string,
@@ -192,7 +291,27 @@ func handleModUpdate(srv ifaces.IGameServer, e *Event, in string,
 	srv.SendChat(output)
 }
 
+// handleScriptError records Lua errors for aggregation. Stack traceback frames
+// share the same format as the error itself, so those are only logged.
+func handleScriptError(srv ifaces.IGameServer, e *Event, in string,
+	oc chan string) {
+	logger.LogOutput(srv, in)
+	m := e.Capture.FindStringSubmatch(in)
+
+	if strings.HasPrefix(m[3], "in ") {
+		return
+	}
+
+	srv.RecordScriptError(m[1]+":"+m[2], m[3])
+}
+
 func defaultEventHandler(srv ifaces.IGameServer, e *Event, in string,
 	oc chan string) {
 	logger.LogOutput(srv, in)
 }
+
+func handleEventStaffMessage(srv ifaces.IGameServer, e *Event, in string,
+	oc chan string) {
+	m := e.Capture.FindStringSubmatch(in)
+	srv.RecordStaffMessage(m[1], m[2])
+}
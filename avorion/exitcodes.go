@@ -0,0 +1,207 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"context"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Classes of exits of the Avorion process
+const (
+	exitClean      = "clean stop"
+	exitError      = "error"
+	exitOOM        = "OOM kill"
+	exitKilled     = "killed"
+	exitTerminated = "terminated"
+	exitSegfault   = "segfault"
+	exitAbort      = "abort"
+	exitFault      = "fault"
+	exitUnknown    = "unknown"
+)
+
+// oomWindow is how long before the exit a kernel OOM kill can be logged and
+// still be blamed for it
+const oomWindow = 5 * time.Minute
+
+// exitExplanations explain each class of exit in terms that an admin can act on
+var exitExplanations = map[string]string{
+	exitClean: "Avorion shut down normally",
+	exitError: "Avorion stopped because of an error, the end of the server log " +
+		"usually says why",
+	exitOOM: "The kernel's out-of-memory killer ended Avorion because the host " +
+		"ran out of memory",
+	exitKilled: "Avorion was killed with SIGKILL, either by an admin, a " +
+		"supervisor, or the kernel",
+	exitTerminated: "Avorion was asked to stop by a signal from outside of the bot",
+	exitSegfault: "Avorion crashed with a segmentation fault, which is usually " +
+		"caused by a mod or a bug in the game",
+	exitAbort: "Avorion aborted itself after an unrecoverable error, such as a " +
+		"failed assertion",
+	exitFault: "Avorion crashed because of an invalid instruction or memory " +
+		"access, which can point to failing hardware",
+	exitUnknown: "Avorion exited for a reason that isn't recognized"}
+
+// exitSignals are the names and classes of the signals that end a process
+var exitSignals = map[syscall.Signal][2]string{
+	syscall.SIGHUP:  {"SIGHUP", exitTerminated},
+	syscall.SIGINT:  {"SIGINT", exitTerminated},
+	syscall.SIGQUIT: {"SIGQUIT", exitTerminated},
+	syscall.SIGTERM: {"SIGTERM", exitTerminated},
+	syscall.SIGKILL: {"SIGKILL", exitKilled},
+	syscall.SIGSEGV: {"SIGSEGV", exitSegfault},
+	syscall.SIGABRT: {"SIGABRT", exitAbort},
+	syscall.SIGBUS:  {"SIGBUS", exitFault},
+	syscall.SIGILL:  {"SIGILL", exitFault},
+	syscall.SIGFPE:  {"SIGFPE", exitFault}}
+
+// OOM kills are logged by the kernel as either of:
+//
+//	[ 1234.567890] Out of memory: Killed process 4321 (AvorionServer) ...
+//	[ 1234.567890] oom-kill:constraint=CONSTRAINT_NONE,...,task=AvorionServer,pid=4321,...
+var reOOMKill = regexp.MustCompile(`^\[\s*([0-9]+\.[0-9]+)\].*(?:` +
+	`Killed process ([0-9]+) \(([^)]+)\)|oom-kill:.*task=([^,]+),pid=([0-9]+))`)
+
+// classifyExit works out why the Avorion process with the given PID exited
+func (s *Server) classifyExit(pid int, pe ProcessExit) ifaces.ServerExit {
+	e := ifaces.ServerExit{Time: s.clock.Now(), Code: pe.Code}
+
+	var (
+		sig      syscall.Signal
+		signaled bool
+	)
+
+	if pe.Signal != 0 {
+		sig, signaled = pe.Signal, true
+	} else if e.Code > 128 && e.Code < 160 {
+		// Shells and most wrappers exit with 128+N when their child is ended
+		// by signal N
+		sig, signaled = syscall.Signal(e.Code-128), true
+	}
+
+	switch {
+	case signaled:
+		e.Class = exitUnknown
+		e.Signal = sig.String()
+		if known, ok := exitSignals[sig]; ok {
+			e.Signal, e.Class = known[0], known[1]
+		}
+
+		if sig == syscall.SIGKILL && s.oomKilled(pid) {
+			e.Class = exitOOM
+		}
+
+	case e.Code == 0:
+		e.Class = exitClean
+
+	default:
+		e.Class = exitError
+	}
+
+	e.Explanation = exitExplanations[e.Class]
+	return e
+}
+
+// oomKilled checks the kernel log for a recent OOM kill of the Avorion process.
+// The kernel log isn't readable on every host, in which case the kill can't be
+// attributed and false is returned.
+func (s *Server) oomKilled(pid int) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	out, err := s.exec.Command(ctx, ProcessOptions{}, "dmesg").Output()
+	if err != nil {
+		logger.LogDebug(s, "Unable to read the kernel log: "+err.Error())
+		return false
+	}
+
+	// dmesg timestamps are seconds since boot
+	uptime := float64(-1)
+	if data, err := ioutil.ReadFile("/proc/uptime"); err == nil {
+		if f := strings.Fields(string(data)); len(f) > 0 {
+			uptime, _ = strconv.ParseFloat(f[0], 64)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		m := reOOMKill.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+
+		at, _ := strconv.ParseFloat(m[1], 64)
+		if uptime >= 0 && uptime-at > oomWindow.Seconds() {
+			return false
+		}
+
+		kpid, task := m[2], m[3]
+		if kpid == "" {
+			kpid, task = m[5], m[4]
+		}
+
+		// When Avorion runs under a wrapper, the process that was killed is
+		// the game rather than the wrapper that we started
+		if kpid == strconv.Itoa(pid) ||
+			strings.HasPrefix(gameExecutable(s.config.WrapperCommand()), task) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recordExit stores an exit of the Avorion process
+func (s *Server) recordExit(e ifaces.ServerExit) {
+	logger.LogWarning(s, sprintf("Avorion exit classified as %s: %s", e.Class,
+		e.Explanation))
+
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return
+	}
+
+	if err := tracking.AddServerExit(e); err != nil {
+		logger.LogError(s, "Failed to record the exit: "+err.Error())
+	}
+}
+
+// exitSummary describes an exit in a single line
+func exitSummary(e ifaces.ServerExit) string {
+	if e.Signal != "" {
+		return sprintf("signal `%s` (**%s**)", e.Signal, e.Class)
+	}
+	return sprintf("status code `%d` (**%s**)", e.Code, e.Class)
+}
+
+/***********************************/
+/* IFace ifaces.IExitHistoryServer */
+/***********************************/
+
+// ExitHistory returns up to limit of the most recent exits of the Avorion
+// process, newest first
+func (s *Server) ExitHistory(limit int) ([]ifaces.ServerExit, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return nil, ifaces.ErrDataUnavailable
+	}
+
+	exits, err := tracking.ServerExits(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range exits {
+		exits[i].Explanation = exitExplanations[exits[i].Class]
+		if exits[i].Explanation == "" {
+			exits[i].Explanation = exitExplanations[exitUnknown]
+		}
+	}
+
+	return exits, nil
+}
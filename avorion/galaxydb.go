@@ -0,0 +1,188 @@
+package avorion
+
+import (
+	gamedb "avorioncontrol/avorion/database"
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const noticeSeasonArchived = "**Server Notice**: The galaxy `%s` has a new " +
+	"seed, so the data tracked for the previous seed (`%s`) was archived as " +
+	"`%s`. Run `db galaxies` to list or purge archived data."
+
+// trackingDirName is the directory in the data path that holds the tracking
+// database of each galaxy
+const trackingDirName = "tracking"
+
+// seasonSep separates the name of a galaxy from the seed of an archived season
+const seasonSep = "@"
+
+// reIniSeed matches the seed in server.ini
+var reIniSeed = regexp.MustCompile(`(?m)^\s*Seed\s*=\s*(\S+)\s*$`)
+
+// trackingRoot returns the directory that holds the tracking databases
+func (s *Server) trackingRoot() string {
+	return strings.TrimSuffix(s.config.DataPath(), "/") + "/" + trackingDirName
+}
+
+// trackingFile returns the path to the tracking database of the configured
+// galaxy
+func (s *Server) trackingFile() string {
+	return sprintf("%s/%s/%s", s.trackingRoot(), s.config.Galaxy(),
+		s.config.DBName())
+}
+
+// prepareTrackingDir creates the directory of the configured galaxy's tracking
+// database. The database from before galaxies were tracked separately is moved
+// into it, since it belongs to the galaxy that was being run.
+func (s *Server) prepareTrackingDir() error {
+	file := s.trackingFile()
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+
+	legacy := sprintf("%s/%s", strings.TrimSuffix(s.config.DataPath(), "/"),
+		s.config.DBName())
+	if _, err := os.Stat(legacy); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(file); err == nil {
+		return nil
+	}
+
+	for _, ext := range []string{"", "-wal", "-shm"} {
+		if err := os.Rename(legacy+ext, file+ext); err != nil &&
+			!os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	logger.LogInfo(s, sprintf("Moved the tracking database to %s", file))
+	return nil
+}
+
+// galaxySeed returns the seed in the configured galaxy's server.ini. False is
+// returned if server.ini couldn't be read; a missing server.ini means that the
+// galaxy is new and returns an empty seed.
+func (s *Server) galaxySeed() (string, bool) {
+	data, err := s.config.GameConfigFile()
+	if os.IsNotExist(err) {
+		return "", true
+	} else if err != nil {
+		return "", false
+	}
+
+	if m := reIniSeed.FindSubmatch(data); m != nil {
+		return string(m[1]), true
+	}
+	return "", false
+}
+
+// archiveSeason moves the tracking database aside if it was recorded for a
+// different seed than the galaxy now has, so that a reset galaxy starts over
+// with a new database. It returns whether or not the database was archived, in
+// which case it has been closed.
+func (s *Server) archiveSeason(tracking *gamedb.TrackingDB) (bool, error) {
+	recorded, err := tracking.ServerInfo(dbInfoSeed)
+	if err != nil || recorded == "" {
+		return false, err
+	}
+
+	seed, ok := s.galaxySeed()
+	if !ok || seed == recorded {
+		return false, nil
+	}
+
+	tracking.Close()
+
+	dir := filepath.Dir(s.trackingFile())
+	archive := filepath.Base(dir) + seasonSep + recorded
+	if err := os.Rename(dir, filepath.Join(s.trackingRoot(), archive)); err != nil {
+		return true, err
+	}
+
+	logger.LogWarning(s, sprintf("Archived the tracking data of seed %s as %s",
+		recorded, archive))
+	s.SendLog(ifaces.ChatData{Msg: sprintf(noticeSeasonArchived,
+		s.config.Galaxy(), recorded, archive)})
+	return true, os.MkdirAll(dir, 0700)
+}
+
+/********************************/
+/* IFace ifaces.IDatabaseServer */
+/********************************/
+
+// TrackedGalaxies returns the galaxies and archived seasons that have tracked
+// data, sorted by name
+func (s *Server) TrackedGalaxies() ([]ifaces.TrackedGalaxy, error) {
+	entries, err := ioutil.ReadDir(s.trackingRoot())
+	if err != nil {
+		return nil, err
+	}
+
+	galaxies := make([]ifaces.TrackedGalaxy, 0)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		g := ifaces.TrackedGalaxy{Name: e.Name(), Galaxy: e.Name(),
+			Modified: e.ModTime()}
+		if i := strings.LastIndex(e.Name(), seasonSep); i > 0 {
+			g.Galaxy, g.Seed = e.Name()[:i], e.Name()[i+len(seasonSep):]
+		} else if e.Name() == s.config.Galaxy() {
+			g.Current, g.Seed = true, s.Seed()
+		}
+
+		filepath.Walk(filepath.Join(s.trackingRoot(), e.Name()),
+			func(path string, fi os.FileInfo, err error) error {
+				if err == nil && !fi.IsDir() {
+					g.Size += fi.Size()
+					if fi.ModTime().After(g.Modified) {
+						g.Modified = fi.ModTime()
+					}
+				}
+				return nil
+			})
+
+		galaxies = append(galaxies, g)
+	}
+
+	sort.Slice(galaxies, func(i, j int) bool {
+		return galaxies[i].Name < galaxies[j].Name
+	})
+
+	return galaxies, nil
+}
+
+// PurgeGalaxy deletes the tracked data of a galaxy or archived season. The
+// data of the galaxy that is currently configured can't be purged.
+func (s *Server) PurgeGalaxy(name string) error {
+	if name == "" || name == "." || name == ".." ||
+		strings.ContainsAny(name, `/\`) {
+		return errors.New("invalid galaxy name")
+	}
+
+	if name == s.config.Galaxy() {
+		return errors.New("the tracked data of the current galaxy can't be purged")
+	}
+
+	dir := filepath.Join(s.trackingRoot(), name)
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return errors.New("there is no tracked data for " + name)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	logger.LogInfo(s, "Purged the tracked data of "+name)
+	return nil
+}
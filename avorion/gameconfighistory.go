@@ -0,0 +1,117 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"errors"
+)
+
+// recordGameConfig stores a copy of server.ini unless it matches the newest
+// stored copy, and returns the version that matches the file. s.inilock must
+// be held.
+func (s *Server) recordGameConfig(author, reason string) (int64, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return 0, ifaces.ErrDataUnavailable
+	}
+
+	data, err := s.config.GameConfigFile()
+	if err != nil {
+		return 0, err
+	}
+
+	latest, err := tracking.GameConfigs(1)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(latest) > 0 && latest[0].Content == string(data) {
+		return latest[0].Version, nil
+	}
+
+	version, err := tracking.AddGameConfig(ifaces.GameConfigVersion{
+		Time: s.clock.Now(), Author: author, Reason: reason,
+		Content: string(data)})
+	if err != nil {
+		return 0, err
+	}
+
+	logger.LogInfo(s, sprintf("Stored server.ini version %d (%s)", version,
+		reason))
+	return version, nil
+}
+
+/*****************************************/
+/* IFace ifaces.IGameConfigHistoryServer */
+/*****************************************/
+
+// RecordGameConfig stores a copy of server.ini after it has been changed, and
+// returns its version. A file that matches the newest copy isn't stored again,
+// so this is also used to keep the settings from before a change.
+func (s *Server) RecordGameConfig(author, reason string) (int64, error) {
+	s.inilock.Lock()
+	defer s.inilock.Unlock()
+	return s.recordGameConfig(author, reason)
+}
+
+// GameConfigHistory returns up to limit of the most recent copies of
+// server.ini, newest first
+func (s *Server) GameConfigHistory(limit int) ([]ifaces.GameConfigVersion,
+	error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return nil, ifaces.ErrDataUnavailable
+	}
+	return tracking.GameConfigs(limit)
+}
+
+// GameConfigVersion returns the stored copy of server.ini with the given
+// version
+func (s *Server) GameConfigVersion(version int64) (ifaces.GameConfigVersion,
+	error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return ifaces.GameConfigVersion{}, ifaces.ErrDataUnavailable
+	}
+
+	v, err := tracking.GameConfig(version)
+	if err != nil {
+		return v, errors.New(sprintf("no stored version %d", version))
+	}
+	return v, nil
+}
+
+// RevertGameConfig replaces server.ini with a stored version, and returns the
+// version that the result was stored as. The current file is stored first so
+// that the revert can be undone as well. Avorion has to be restarted for the
+// changes to take effect.
+func (s *Server) RevertGameConfig(version int64, author string) (int64, error) {
+	if s.trackingDB() == nil {
+		return 0, ifaces.ErrDataUnavailable
+	}
+
+	// Avorion writes server.ini when it stops, which would undo the revert
+	if s.IsUp() {
+		return 0, errors.New("the server has to be stopped first")
+	}
+
+	s.inilock.Lock()
+	defer s.inilock.Unlock()
+
+	v, err := s.GameConfigVersion(version)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := s.recordGameConfig(author, sprintf("before reverting to "+
+		"version %d", version)); err != nil {
+		return 0, err
+	}
+
+	if err := s.config.WriteGameConfig([]byte(v.Content)); err != nil {
+		return 0, err
+	}
+
+	return s.recordGameConfig(author, sprintf("reverted to version %d",
+		version))
+}
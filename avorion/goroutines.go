@@ -2,9 +2,10 @@ package avorion
 
 import (
 	"avorioncontrol/avorion/events"
+	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
 	"bufio"
-	"time"
+	"os"
 )
 
 /**************/
@@ -19,27 +20,58 @@ func updateAvorionStatus(s *Server, closech chan struct{}) {
 	defer func() { logger.LogInfo(s, "Stopping old status supervisor") }()
 	s.wg.Add(1)
 
+	// The tickers are only created once, since the status check usually runs
+	// more often than the database update and would keep resetting its timer
+	var (
+		hang, dbupdate   = s.config.HangTimeDuration(), s.config.DBUpdateTimeDuration()
+		statust, stopst  = s.clock.NewTicker(hang)
+		dbupdatet, stopd = s.clock.NewTicker(dbupdate)
+		now              = s.clock.Now()
+	)
+
+	defer func() {
+		stopst()
+		stopd()
+	}()
+
+	s.cache.update(func(c *statusSnapshot) {
+		c.nextstatuscheck, c.nextdbupdate = now.Add(hang), now.Add(dbupdate)
+	})
+
 	logger.LogInit(s, "Starting status supervisor")
 	for {
 		// Close the routine gracefully
 		select {
 		case <-s.exit:
-			s.Stop(false)
+			s.stopQueued(false)
 			return
 
 		case <-closech:
-			if !state.isstopping && !state.isrestarting && !state.isstarting {
+			if state.current() == lifecycleIdle {
 				logger.LogWarning(s, "Avorion server exited abnormally, restarting")
 				s.Crashed()
-				if err := s.Restart(); err == nil {
-					state.iscrashed = false
-					state.isrestarting = false
+				if err := s.cycle(true, true); err == nil {
+					s.Recovered()
+					s.SendLog(ifaces.ChatData{Thread: "Server crash",
+						Msg: "**Server Notice**: Avorion was restarted after the crash"})
+				} else {
+					s.SendLog(ifaces.ChatData{Thread: "Server crash",
+						Msg: "**Server Error**: Failed to restart Avorion after the " +
+							"crash: " + err.Error()})
 				}
 			}
 			return
 
 		// Check the server status after the configured duration of time has passed
-		case <-time.After(s.config.HangTimeDuration()):
+		case <-statust:
+			// The interval changes when the configuration is reloaded
+			if d := s.config.HangTimeDuration(); d != hang {
+				stopst()
+				hang = d
+				statust, stopst = s.clock.NewTicker(hang)
+			}
+
+			next := s.clock.Now().Add(hang)
 			online := 0
 			for _, p := range s.players {
 				if p.Online() {
@@ -47,27 +79,56 @@ func updateAvorionStatus(s *Server, closech chan struct{}) {
 				}
 			}
 
-			s.onlineplayercount = online
+			s.cache.update(func(c *statusSnapshot) {
+				c.onlineplayercount, c.nextstatuscheck = online, next
+			})
 
-			if state.isrestarting || state.isstopping || state.isstarting {
+			if state.current() != lifecycleIdle {
 				continue
 			}
 
 			// TODO: Make this command configura
-			_, err := s.RunCommand("echo Server status check")
+			_, err := s.RunCommandPriority("echo Server status check",
+				ifaces.CommandPriorityHealth)
 			if err != nil {
 				s.Crashed()
 				logger.LogError(s, err.Error())
-				s.Cmd.Process.Kill()
+				s.Cmd.Signal(os.Kill)
 			}
 
 			if s.IsCrashed() && err == nil {
 				s.Recovered()
 			}
 
+			// RCON answering doesn't mean that players can reach the game
+			if err == nil {
+				s.checkGamePort()
+				s.checkQueryPort()
+				s.reconcileOnline()
+			}
+
+			s.summarizeScriptErrors()
+			s.kickIdlePlayers()
+			s.checkInstalledVersion()
+			s.checkModUpdates()
+			s.checkBackup()
+			s.checkOneShotActions()
+			s.checkRestartSchedule()
+
 		// Update our playerinfo db after the configured duration of time has passed
-		case <-time.After(s.config.DBUpdateTimeDuration()):
+		case <-dbupdatet:
+			if d := s.config.DBUpdateTimeDuration(); d != dbupdate {
+				stopd()
+				dbupdate = d
+				dbupdatet, stopd = s.clock.NewTicker(dbupdate)
+			}
+
+			next := s.clock.Now().Add(dbupdate)
+			s.cache.update(func(c *statusSnapshot) { c.nextdbupdate = next })
+
+			s.checkTracking()
 			s.UpdatePlayerDatabase(true)
+			s.pruneJumps()
 		}
 	}
 }
@@ -85,7 +146,7 @@ func superviseAvorionOut(s *Server, ready chan struct{},
 
 	// TODO: Move the scanner.Scan() loop into a goroutine.
 	for scanner.Scan() {
-		out := scanner.Text()
+		out := sanitizeOutput(s, scanner.Bytes())
 
 		// Exit gracefully
 		select {
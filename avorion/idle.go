@@ -0,0 +1,108 @@
+package avorion
+
+import (
+	"avorioncontrol/logger"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	reasonIdleKick = "Idle while the server is full"
+)
+
+// SetMemberRoles replaces the cached Discord roles of the guild members, keyed
+// by their Discord ID. The roles are used to exempt integrated players from
+// being kicked for being idle.
+func (s *Server) SetMemberRoles(roles map[string][]string) {
+	s.rolelock.Lock()
+	defer s.rolelock.Unlock()
+	s.memberroles = roles
+}
+
+// idleExempt returns true if a player has a name tag or an integrated Discord
+// role that exempts them from being kicked for being idle
+func (s *Server) idleExempt(p *Player) bool {
+	for _, tag := range s.config.IdleKickExemptTags() {
+		if tag != "" && strings.Contains(p.Name(), tag) {
+			return true
+		}
+	}
+
+	return s.hasMemberRole(p, s.config.IdleKickExemptRoles())
+}
+
+// hasMemberRole returns true if a player is integrated with a Discord member
+// that has one of the given roles
+func (s *Server) hasMemberRole(p *Player, roles []string) bool {
+	uid := p.DiscordUID()
+	if uid == "" {
+		return false
+	}
+
+	s.rolelock.Lock()
+	defer s.rolelock.Unlock()
+
+	for _, role := range s.memberroles[uid] {
+		for _, want := range roles {
+			if role == want {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// kickIdlePlayers kicks players that have been idle for longer than the
+// configured duration, but only when the server is close enough to its player
+// cap that fewer than the configured number of slots are free. The players
+// that have been idle the longest are kicked first, and only as many as are
+// needed to free those slots.
+func (s *Server) kickIdlePlayers() {
+	idle := s.config.IdleKickDuration()
+	if idle <= 0 {
+		return
+	}
+
+	ini, ok := s.config.GameConfig()
+	if !ok || ini.MaxPlayers <= 0 {
+		return
+	}
+
+	var (
+		online = 0
+		now    = s.clock.Now()
+		idlers = make([]*Player, 0)
+	)
+
+	for _, p := range s.players {
+		if !p.Online() {
+			continue
+		}
+
+		online++
+		if now.Sub(p.LastActive()) > idle && !s.idleExempt(p) {
+			idlers = append(idlers, p)
+		}
+	}
+
+	need := s.config.IdleKickFreeSlots() - (ini.MaxPlayers - int64(online))
+	if need <= 0 || len(idlers) == 0 {
+		return
+	}
+
+	sort.Slice(idlers, func(i, j int) bool {
+		return idlers[i].LastActive().Before(idlers[j].LastActive())
+	})
+
+	for i, p := range idlers {
+		if int64(i) == need {
+			break
+		}
+
+		logger.LogInfo(s, sprintf("Kicking [%s], idle for %s", p.Name(),
+			now.Sub(p.LastActive()).Round(time.Second)))
+		p.Kick(reasonIdleKick)
+	}
+}
@@ -0,0 +1,85 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+const (
+	noticeStaffMessage = "**Message #%d** from `%s` _(index %s)_\n>>> %s"
+	staffReplyFormat   = "[Staff] %s: %s"
+)
+
+// RecordStaffMessage stores a private message that a player sent to the staff
+// with /staff, and posts it to the staff inbox
+func (s *Server) RecordStaffMessage(index, msg string) {
+	name := index
+	if p := s.Player(index); p != nil {
+		name = p.Name()
+		p.SetActive()
+	}
+
+	m := ifaces.StaffMessage{
+		Index: index,
+		Name:  name,
+		Msg:   msg,
+		Time:  s.clock.Now()}
+
+	tracking := s.trackingDB()
+	if tracking == nil {
+		logger.LogError(s, "RecordStaffMessage: "+ifaces.ErrDataUnavailable.Error())
+		return
+	}
+
+	id, err := tracking.AddStaffMessage(m)
+	if err != nil {
+		logger.LogError(s, "RecordStaffMessage: "+err.Error())
+		return
+	}
+
+	logger.LogInfo(s, sprintf("Staff message #%d from %s", id, name))
+	s.publish(ifaces.EventTopicInbox, ifaces.ChatData{Name: name,
+		Msg: sprintf(noticeStaffMessage, id, name, index, msg)})
+}
+
+// ReplyStaffMessage sends a reply to a staff message back to the player that
+// sent it, and returns the message that was replied to
+func (s *Server) ReplyStaffMessage(id int64, from,
+	text string) (ifaces.StaffMessage, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return ifaces.StaffMessage{}, ifaces.ErrDataUnavailable
+	}
+
+	m, err := tracking.StaffMessage(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return m, errors.New(sprintf("there is no staff message #%d", id))
+	} else if err != nil {
+		return m, err
+	}
+
+	if !s.IsUp() {
+		return m, ifaces.ErrServerOffline
+	}
+
+	p := s.Player(m.Index)
+	if p == nil {
+		return m, ifaces.ErrPlayerNotFound
+	}
+
+	if !p.Online() {
+		return m, errors.New(p.Name() + " is not online to receive the reply")
+	}
+
+	reply := strings.ReplaceAll(sprintf(staffReplyFormat, from, text), `"`, `'`)
+	if _, err := s.RunCommand(sprintf(rconPlayerMessage, m.Index,
+		reply)); err != nil {
+		return m, err
+	}
+
+	logger.LogInfo(s, sprintf("%s replied to staff message #%d", from, id))
+	return m, nil
+}
@@ -0,0 +1,176 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	reasonMemberLeft   = "Left the Discord server"
+	reasonMemberBanned = "Banned from the Discord server"
+
+	noticeMemberLeft = "**Integration Notice**: %s\n" +
+		"**Player:** `%s`\n**Discord:** <@%s>\n**Actions:** _%s_"
+
+	noticePinLocked = "**Integration Notice**: Too many failed integration " +
+		"attempts\n**Discord:** <@%s> (`%s`)\n**Last Player Tried:** `%s`\n" +
+		"_Blocked after %d wrong PINs for %s_"
+)
+
+// DiscordMemberLeft applies the configured member leave actions to the player
+// that is integrated with a Discord user that left or was banned from the
+// guild. Users without an integrated player are ignored.
+func (s *Server) DiscordMemberLeft(uid string, banned bool) {
+	tracking := s.trackingDB()
+
+	p := s.PlayerFromDiscord(uid)
+	if p == nil {
+		return
+	}
+
+	reason := reasonMemberLeft
+	if banned {
+		reason = reasonMemberBanned
+	}
+
+	taken := make([]string, 0)
+	for _, action := range s.config.MemberLeaveActions() {
+		var err error
+
+		switch action {
+		case ifaces.MemberLeaveUnlink:
+			err = s.unlinkPlayer(p)
+
+		case ifaces.MemberLeaveCommand:
+			cmd := s.config.MemberLeaveCommand()
+			if cmd == "" {
+				err = errors.New("no member leave command is configured")
+				break
+			}
+			cmd = strings.ReplaceAll(cmd, "{index}", p.Index())
+			cmd = strings.ReplaceAll(cmd, "{name}", p.Name())
+			_, err = s.RunCommand(cmd)
+
+		case ifaces.MemberLeaveReview:
+			if tracking == nil {
+				err = ifaces.ErrDataUnavailable
+				break
+			}
+			err = tracking.AddReview(ifaces.ReviewEntry{
+				Index:     p.Index(),
+				Name:      p.Name(),
+				DiscordID: uid,
+				Reason:    reason,
+				Time:      s.clock.Now()})
+		}
+
+		if err != nil {
+			logger.LogError(s, sprintf("Member leave action %s for %s: %s", action,
+				p.Name(), err.Error()))
+			continue
+		}
+
+		taken = append(taken, action)
+	}
+
+	if len(taken) == 0 {
+		taken = append(taken, "none")
+	}
+
+	logger.LogInfo(s, sprintf("%s (%s): %s", reason, p.Name(),
+		strings.Join(taken, ", ")))
+	s.SendLog(ifaces.ChatData{Msg: sprintf(noticeMemberLeft, reason, p.Name(),
+		uid, strings.Join(taken, ", "))})
+}
+
+// ReviewQueue returns the players that are waiting to be reviewed by staff
+func (s *Server) ReviewQueue() ([]ifaces.ReviewEntry, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return nil, ifaces.ErrDataUnavailable
+	}
+
+	return tracking.Reviews()
+}
+
+// ResolveReview removes a player from the review queue
+func (s *Server) ResolveReview(index string) error {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return ifaces.ErrDataUnavailable
+	}
+
+	return tracking.RemoveReview(index)
+}
+
+// unlinkPlayer removes the Discord integration from a player, both in our
+// database and in-game
+func (s *Server) unlinkPlayer(p ifaces.IPlayer) error {
+	tracking := s.trackingDB()
+	if tracking != nil {
+		if err := tracking.RemoveIntegration(p); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.RunCommand(sprintf(rconPlayerUnlink, p.Index())); err != nil {
+		return err
+	}
+
+	p.SetDiscordUID("")
+	return nil
+}
+
+// pinAttempts tracks the wrong integration pins entered by a Discord user
+type pinAttempts struct {
+	failures int64
+	last     time.Time
+	blocked  time.Time
+}
+
+// pinLocked returns true if a Discord user is blocked from entering
+// integration pins. Blocked attempts are logged, since they suggest someone is
+// guessing pins. s.pinlock must be held.
+func (s *Server) pinLocked(uid, index string) bool {
+	a, ok := s.pinattempts[uid]
+	if !ok || !s.clock.Now().Before(a.blocked) {
+		return false
+	}
+
+	logger.LogWarning(s, sprintf("Blocked integration attempt by Discord user %s "+
+		"for player %s", uid, index))
+	return true
+}
+
+// pinFailed records a wrong integration pin, and blocks the Discord user and
+// notifies moderators once they've entered too many. s.pinlock must be held.
+func (s *Server) pinFailed(uid, index string) {
+	now := s.clock.Now()
+	lockout := s.config.IntegrationPinLockout()
+
+	a, ok := s.pinattempts[uid]
+	if !ok || now.Sub(a.last) > lockout {
+		a = &pinAttempts{}
+		s.pinattempts[uid] = a
+	}
+
+	a.failures++
+	a.last = now
+	logger.LogWarning(s, sprintf("Failed integration attempt by Discord user %s "+
+		"for player %s (%d/%d)", uid, index, a.failures,
+		s.config.IntegrationPinAttempts()))
+
+	if a.failures < s.config.IntegrationPinAttempts() {
+		return
+	}
+
+	a.failures = 0
+	a.blocked = now.Add(lockout)
+	logger.LogWarning(s, sprintf("Blocked Discord user %s from integrating for %s",
+		uid, lockout))
+	s.SendLog(ifaces.ChatData{Msg: sprintf(noticePinLocked, uid, uid, index,
+		s.config.IntegrationPinAttempts(), lockout)})
+}
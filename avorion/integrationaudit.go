@@ -0,0 +1,140 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"errors"
+	"sort"
+	"strings"
+)
+
+const noticeOrphanedIntegrations = "**Integration Notice**: %d integrated " +
+	"players are linked to Discord accounts that are no longer in the server\n" +
+	"**Newly Found:**\n%s\n_Run `integrations orphans` to review them, and " +
+	"`integrations prune` to unlink them._"
+
+// orphanNoticeLimit is the number of newly orphaned players that are named in
+// the log notice, so that a mass departure doesn't produce a wall of text
+const orphanNoticeLimit = 10
+
+/****************************************/
+/* IFace ifaces.IIntegrationAuditServer */
+/****************************************/
+
+// CheckIntegrations compares the integrated players against the complete list
+// of Discord members, and flags the integrations of users that are gone. This
+// catches members that left or deleted their account while the bot was
+// offline. Newly flagged integrations are reported in the log channel once.
+func (s *Server) CheckIntegrations(members map[string]bool) {
+	s.orphanlock.Lock()
+	defer s.orphanlock.Unlock()
+
+	if s.orphans == nil {
+		s.orphans = make(map[string]ifaces.OrphanedIntegration)
+	}
+
+	now := s.clock.Now()
+	flagged := make([]string, 0)
+	current := make(map[string]bool)
+
+	for _, p := range s.players {
+		uid := p.DiscordUID()
+		if uid == "" || members[uid] {
+			continue
+		}
+
+		current[p.Index()] = true
+		if o, ok := s.orphans[p.Index()]; ok && o.DiscordID == uid {
+			continue
+		}
+
+		s.orphans[p.Index()] = ifaces.OrphanedIntegration{
+			Index: p.Index(), Name: p.Name(), DiscordID: uid, Since: now}
+		flagged = append(flagged, sprintf("`%s` (<@%s>)", p.Name(), uid))
+	}
+
+	// Members that came back, and players that were unlinked some other way,
+	// are no longer orphaned
+	for index := range s.orphans {
+		if !current[index] {
+			delete(s.orphans, index)
+		}
+	}
+
+	if len(flagged) == 0 {
+		return
+	}
+
+	logger.LogWarning(s, sprintf("Found %d integrations for Discord users that "+
+		"are no longer members", len(flagged)))
+
+	more := ""
+	if len(flagged) > orphanNoticeLimit {
+		more = sprintf("\n_...and %d more_", len(flagged)-orphanNoticeLimit)
+		flagged = flagged[:orphanNoticeLimit]
+	}
+
+	s.SendLog(ifaces.ChatData{Msg: sprintf(noticeOrphanedIntegrations,
+		len(current), strings.Join(flagged, "\n")+more)})
+}
+
+// OrphanedIntegrations returns the integrations that are flagged as belonging
+// to Discord users that are no longer members, oldest first
+func (s *Server) OrphanedIntegrations() []ifaces.OrphanedIntegration {
+	s.orphanlock.Lock()
+	defer s.orphanlock.Unlock()
+
+	orphans := make([]ifaces.OrphanedIntegration, 0, len(s.orphans))
+	for _, o := range s.orphans {
+		orphans = append(orphans, o)
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Since.Equal(orphans[j].Since) {
+			return orphans[i].Name < orphans[j].Name
+		}
+		return orphans[i].Since.Before(orphans[j].Since)
+	})
+
+	return orphans
+}
+
+// PruneIntegrations unlinks every player with a flagged integration, and
+// returns the number that were unlinked
+func (s *Server) PruneIntegrations() (int, error) {
+	if !s.IsUp() {
+		return 0, errors.New("the server has to be running to unlink players " +
+			"in-game")
+	}
+
+	s.orphanlock.Lock()
+	defer s.orphanlock.Unlock()
+
+	pruned := 0
+	failed := make([]string, 0)
+
+	for index, o := range s.orphans {
+		p := s.Player(index)
+		if p == nil || p.DiscordUID() != o.DiscordID {
+			delete(s.orphans, index)
+			continue
+		}
+
+		if err := s.unlinkPlayer(p); err != nil {
+			logger.LogError(s, sprintf("Failed to unlink %s: %s", p.Name(),
+				err.Error()))
+			failed = append(failed, p.Name())
+			continue
+		}
+
+		delete(s.orphans, index)
+		pruned++
+	}
+
+	logger.LogInfo(s, sprintf("Pruned %d orphaned integrations", pruned))
+
+	if len(failed) > 0 {
+		return pruned, errors.New("failed to unlink " + strings.Join(failed, ", "))
+	}
+	return pruned, nil
+}
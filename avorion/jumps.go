@@ -0,0 +1,73 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"math"
+	"sort"
+	"time"
+)
+
+// Jumps count for half as much towards a sector's activity score for every day
+// that has passed since they were made
+const jumpHalfLife = 24 * time.Hour
+
+// SectorActivity returns how busy each sector has been since the given time,
+// sorted from the busiest sector to the quietest. The activity is computed
+// from the hourly jump totals, so it remains available after the raw jumps
+// have been pruned.
+func (s *Server) SectorActivity(since time.Time) ([]ifaces.SectorActivity, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return nil, ifaces.ErrDataUnavailable
+	}
+
+	buckets, err := tracking.JumpBuckets(since)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	sectors := make(map[[2]int]*ifaces.SectorActivity)
+	for _, b := range buckets {
+		k := [2]int{b.X, b.Y}
+		if _, ok := sectors[k]; !ok {
+			sectors[k] = &ifaces.SectorActivity{X: b.X, Y: b.Y}
+		}
+
+		age := now.Sub(b.Hour).Hours() / jumpHalfLife.Hours()
+		sectors[k].Jumps += b.Jumps
+		sectors[k].Score += float64(b.Jumps) * math.Pow(0.5, math.Max(age, 0))
+	}
+
+	activity := make([]ifaces.SectorActivity, 0, len(sectors))
+	for _, a := range sectors {
+		activity = append(activity, *a)
+	}
+
+	sort.Slice(activity, func(i, j int) bool {
+		return activity[i].Score > activity[j].Score
+	})
+
+	return activity, nil
+}
+
+// pruneJumps deletes the raw jumps that are older than the configured jump
+// retention. Nothing is deleted if no retention has been set.
+func (s *Server) pruneJumps() {
+	retention := s.config.JumpRetention()
+	tracking := s.trackingDB()
+	if tracking == nil || retention <= 0 {
+		return
+	}
+
+	n, err := tracking.PruneJumps(s.clock.Now().Add(-retention))
+	if err != nil {
+		logger.LogError(s, "PruneJumps: "+err.Error())
+		return
+	}
+
+	if n > 0 {
+		logger.LogInfo(s, sprintf("Pruned %d jumps from the tracking DB", n))
+	}
+}
@@ -0,0 +1,107 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Operations that change whether or not the game is running
+const (
+	lifecycleIdle = iota
+	lifecycleStarting
+	lifecycleStopping
+	lifecycleRestarting
+	lifecycleRestoring
+)
+
+var lifecycleNames = [...]string{"idle", "starting", "stopping", "restarting",
+	"restoring"}
+
+// lifecycle serializes the operations that start and stop the game. Discord
+// commands, the control panel, the HTTP API, signals, and the crash supervisor
+// can all request them, so only one may run at a time. A request made while
+// another operation is running is refused with ErrOperationInProgress, unless
+// it is queued to run once that operation has finished.
+type lifecycle struct {
+	// Holds a token for the duration of an operation
+	queue chan struct{}
+
+	mutex   sync.Mutex
+	op      int
+	crashed bool
+	last    time.Time
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{queue: make(chan struct{}, 1)}
+}
+
+// run performs a lifecycle operation. If wait is set, the operation is queued
+// behind any operation that is already running instead of being refused.
+func (l *lifecycle) run(lg logger.ILogger, op int, wait bool,
+	fn func() error) error {
+	if wait {
+		l.queue <- struct{}{}
+	} else {
+		select {
+		case l.queue <- struct{}{}:
+		default:
+			current := l.current()
+			logger.LogInfo(lg, fmt.Sprintf("Refused %s while %s",
+				lifecycleNames[op], lifecycleNames[current]))
+			return fmt.Errorf("%w (server is %s)", ifaces.ErrOperationInProgress,
+				lifecycleNames[current])
+		}
+	}
+
+	l.set(op)
+	defer func() {
+		l.set(lifecycleIdle)
+		<-l.queue
+	}()
+
+	return fn()
+}
+
+// current returns the operation that is running
+func (l *lifecycle) current() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.op
+}
+
+func (l *lifecycle) set(op int) {
+	l.mutex.Lock()
+	l.op = op
+	l.mutex.Unlock()
+}
+
+// isCrashed returns whether or not the game last exited abnormally
+func (l *lifecycle) isCrashed() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.crashed
+}
+
+func (l *lifecycle) setCrashed(crashed bool) {
+	l.mutex.Lock()
+	l.crashed = crashed
+	l.mutex.Unlock()
+}
+
+// started records that the game finished starting
+func (l *lifecycle) started(t time.Time) {
+	l.mutex.Lock()
+	l.last = t
+	l.mutex.Unlock()
+}
+
+// sinceStart returns how long ago the game last finished starting
+func (l *lifecycle) sinceStart(now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return now.Sub(l.last)
+}
@@ -0,0 +1,101 @@
+package avorion
+
+import (
+	"avorioncontrol/avorion/events"
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"time"
+)
+
+const (
+	loadTestName = "LoadTest"
+
+	noticeLoadTest = "**Load Test Complete**\n" +
+		"**Lines:** _%d in %s (%d matched an event)_\n" +
+		"**Parse Rate:** _%.0f lines/s_\n" +
+		"**Chat Relayed:** _%d (%d dropped)_\n" +
+		"**Chat Send Wait:** _%s avg, %s max_"
+)
+
+// loadTestLines are the templates used to generate synthetic output. They
+// cover chat, the events emitted by our mod, and output that matches nothing.
+var loadTestLines = []string{
+	"<" + loadTestName + "> Synthetic chat message %d",
+	"shipJumpEvent: 0 %d:0 " + loadTestName,
+	"sectorControlEvent: %d:0 0",
+	"Synthetic unmatched server output %d"}
+
+// LoadTest injects synthetic server output into the event pipeline at the
+// given rate (in lines per second) for the given duration, and reports how the
+// pipeline coped. Lines are only matched against the registered events, so
+// they don't touch the database. Synthetic chat is sent to Discord to measure
+// backpressure from the chat channel and Discord's rate limits.
+func (s *Server) LoadTest(rate int, d time.Duration) ifaces.LoadTestResult {
+	var (
+		res      ifaces.LoadTestResult
+		parse    time.Duration
+		sendwait time.Duration
+
+		interval = time.Second / time.Duration(rate)
+		ticker   = time.NewTicker(interval)
+		start    = time.Now()
+	)
+	defer ticker.Stop()
+
+	logger.LogInfo(s, sprintf("Starting load test at %d lines/s for %s", rate, d))
+
+	for time.Since(start) < d {
+		select {
+		case <-s.exit:
+			return res
+		case <-ticker.C:
+		}
+
+		line := sprintf(loadTestLines[res.Lines%len(loadTestLines)], res.Lines)
+		res.Lines++
+
+		before := time.Now()
+		e := events.GetFromString(line)
+		parse += time.Since(before)
+
+		if e == nil || e.Name() == "EventNone" {
+			continue
+		}
+		res.Matched++
+
+		if e.Name() != "EventPlayerChat" ||
+			s.bus.Subscribers(ifaces.EventTopicChat) == 0 {
+			continue
+		}
+
+		// Chat is published straight to the bus rather than through the handler,
+		// so that synthetic messages are neither moderated nor recorded
+		before = time.Now()
+		if s.bus.Publish(ifaces.EventTopicChat, ifaces.ChatData{Name: loadTestName,
+			Msg: line, Kind: ifaces.ChatKindGlobal}, interval) {
+			res.Relayed++
+		} else {
+			res.Dropped++
+		}
+
+		wait := time.Since(before)
+		sendwait += wait
+		if wait > res.MaxSendWait {
+			res.MaxSendWait = wait
+		}
+	}
+
+	res.Elapsed = time.Since(start)
+	if parse > 0 {
+		res.ParseRate = float64(res.Lines) / parse.Seconds()
+	}
+	if sent := res.Relayed + res.Dropped; sent > 0 {
+		res.AvgSendWait = sendwait / time.Duration(sent)
+	}
+
+	s.SendLog(ifaces.ChatData{Msg: sprintf(noticeLoadTest, res.Lines,
+		res.Elapsed.Round(time.Second), res.Matched, res.ParseRate, res.Relayed,
+		res.Dropped, res.AvgSendWait.Round(time.Microsecond),
+		res.MaxSendWait.Round(time.Microsecond))})
+	return res
+}
@@ -0,0 +1,97 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"sync"
+	"time"
+)
+
+const (
+	lookupWindow  = 5 * time.Second
+	lookupSpacing = 250 * time.Millisecond
+)
+
+// lookupCall is a single RCON lookup that other callers can wait on
+type lookupCall struct {
+	done chan struct{}
+	out  string
+	err  error
+	at   time.Time
+}
+
+// lookupCoalescer deduplicates the RCON lookups that are triggered by game
+// events. Identical lookups made while one is in flight (or shortly after it
+// completed) share its result, and new lookups are spaced out so that a burst
+// of events such as a mass login doesn't flood RCON.
+type lookupCoalescer struct {
+	mutex *sync.Mutex
+	calls map[string]*lookupCall
+	next  time.Time
+}
+
+func newLookupCoalescer() *lookupCoalescer {
+	return &lookupCoalescer{
+		mutex: new(sync.Mutex),
+		calls: make(map[string]*lookupCall)}
+}
+
+// lookup runs an RCON lookup at background priority, sharing the result with
+// any identical lookups made within the coalescing window
+func (s *Server) lookup(cmd string) (string, error) {
+	l := s.lookups
+	now := s.clock.Now()
+
+	l.mutex.Lock()
+	if c, ok := l.calls[cmd]; ok {
+		select {
+		case <-c.done:
+			if now.Sub(c.at) < lookupWindow {
+				l.mutex.Unlock()
+				logger.LogDebug(s, sprintf("Reusing recent result for: %s", cmd))
+				return c.out, c.err
+			}
+
+		default:
+			l.mutex.Unlock()
+			logger.LogDebug(s, sprintf("Waiting on in-flight lookup: %s", cmd))
+			<-c.done
+			return c.out, c.err
+		}
+	}
+
+	c := &lookupCall{done: make(chan struct{})}
+	l.calls[cmd] = c
+
+	// Reserve the next available slot so that bursts are spread out
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(lookupSpacing)
+	l.mutex.Unlock()
+
+	if wait > 0 {
+		<-s.clock.After(wait)
+	}
+
+	c.out, c.err = s.RunCommandPriority(cmd, ifaces.CommandPriorityBackground)
+
+	l.mutex.Lock()
+	c.at = s.clock.Now()
+	close(c.done)
+
+	// Drop results that can no longer be reused
+	for k, old := range l.calls {
+		select {
+		case <-old.done:
+			if c.at.Sub(old.at) >= lookupWindow {
+				delete(l.calls, k)
+			}
+		default:
+		}
+	}
+	l.mutex.Unlock()
+
+	return c.out, c.err
+}
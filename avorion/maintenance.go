@@ -0,0 +1,125 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"time"
+)
+
+const (
+	reasonMaintenance = "The server is in maintenance mode"
+
+	noticeMaintenanceOn    = "Maintenance mode is on, only whitelisted players may stay"
+	noticeMaintenanceUntil = "Maintenance mode is on until %s, only whitelisted players may stay"
+	noticeMaintenanceOff   = "Maintenance is over, the server is open to everyone"
+)
+
+// maintenanceWindow is a period in which only whitelisted players may be on the
+// server. A zero end time means that maintenance lasts until it is ended.
+type maintenanceWindow struct {
+	until  time.Time
+	motd   string
+	cancel chan struct{}
+}
+
+// StartMaintenance puts the server into maintenance mode, kicking any online
+// players that aren't whitelisted and setting the maintenance MOTD. If the
+// duration is greater than zero, maintenance ends automatically after it has
+// elapsed. Starting maintenance while it is already on replaces its duration.
+func (s *Server) StartMaintenance(d time.Duration) {
+	motd := s.MOTD()
+	if mw := s.maintenance; mw != nil {
+		motd = mw.motd
+		close(mw.cancel)
+	}
+
+	mw := &maintenanceWindow{motd: motd, cancel: make(chan struct{})}
+	if d > 0 {
+		mw.until = s.clock.Now().Add(d)
+	}
+	s.maintenance = mw
+
+	s.SetMOTD(s.config.MaintenanceMOTD())
+	if mw.until.IsZero() {
+		logger.LogInfo(s, "Maintenance mode started")
+		s.NotifyServer(noticeMaintenanceOn)
+	} else {
+		logger.LogInfo(s, sprintf("Maintenance mode started until %s",
+			mw.until.Format(time.RFC3339)))
+		s.NotifyServer(sprintf(noticeMaintenanceUntil, d.Round(time.Second)))
+	}
+
+	s.kickForMaintenance()
+
+	if mw.until.IsZero() {
+		return
+	}
+
+	go func() {
+		defer shutdown.Recover()
+		select {
+		case <-mw.cancel:
+		case <-s.exit:
+		case <-s.clock.After(d):
+			if s.maintenance == mw {
+				s.EndMaintenance()
+				s.SendLog(ifaces.ChatData{
+					Msg: "**Server Notice**: Scheduled maintenance has ended"})
+			}
+		}
+	}()
+}
+
+// EndMaintenance takes the server out of maintenance mode and restores the
+// previous MOTD. It returns whether or not maintenance was on.
+func (s *Server) EndMaintenance() bool {
+	mw := s.maintenance
+	if mw == nil {
+		return false
+	}
+
+	s.maintenance = nil
+	close(mw.cancel)
+	s.SetMOTD(mw.motd)
+
+	logger.LogInfo(s, "Maintenance mode ended")
+	s.NotifyServer(noticeMaintenanceOff)
+	return true
+}
+
+// Maintenance returns when maintenance mode ends, and whether or not it is on.
+// The end time is zero if maintenance lasts until it is ended manually.
+func (s *Server) Maintenance() (time.Time, bool) {
+	if mw := s.maintenance; mw != nil {
+		return mw.until, true
+	}
+	return time.Time{}, false
+}
+
+// maintenanceAllowed returns true if a player is whitelisted by name or index,
+// or is integrated with a Discord member with one of the allowed roles
+func (s *Server) maintenanceAllowed(p *Player) bool {
+	for _, w := range s.config.MaintenanceWhitelist() {
+		if w == p.Name() || w == p.Index() {
+			return true
+		}
+	}
+
+	return s.hasMemberRole(p, s.config.MaintenanceRoles())
+}
+
+// kickForMaintenance kicks every online player that isn't allowed on the
+// server while it is in maintenance mode
+func (s *Server) kickForMaintenance() {
+	if s.maintenance == nil {
+		return
+	}
+
+	for _, p := range s.players {
+		if p.Online() && !s.maintenanceAllowed(p) {
+			logger.LogInfo(s, sprintf("Kicking [%s] for maintenance", p.Name()))
+			p.Kick(reasonMaintenance)
+		}
+	}
+}
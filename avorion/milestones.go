@@ -0,0 +1,90 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dbInfoMilestone  = "milestone_players"
+	dbInfoPeakOnline = "peak_online"
+
+	// Avoid posting a new record for every player that joins during a rush
+	recordCooldown = time.Hour
+)
+
+// checkMilestones celebrates unique player milestones and new concurrent player
+// records in the chat channel. The last milestone and record are stored in the
+// DB so that they are only announced once, and the first check on an existing
+// galaxy only records the current values.
+func (s *Server) checkMilestones() {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return
+	}
+
+	uniquemsg, recordmsg := s.config.MilestoneMessages()
+
+	if count, err := tracking.PlayerCount(); err != nil {
+		logger.LogError(s, "PlayerCount: "+err.Error())
+	} else if reached := milestoneReached(s.config.PlayerMilestones(),
+		count); reached > 0 {
+		last, seen := s.milestoneInfo(dbInfoMilestone)
+		if reached > last {
+			tracking.SetServerInfo(dbInfoMilestone, strconv.FormatInt(reached, 10))
+			if seen {
+				logger.LogInfo(s, sprintf("Reached %d unique players", reached))
+				s.SendChat(ifaces.ChatData{Name: "Milestone",
+					Msg: fillMilestone(uniquemsg, reached)})
+			}
+		}
+	}
+
+	online := int64(s.cache.online())
+	peak, seen := s.milestoneInfo(dbInfoPeakOnline)
+	if online > peak {
+		tracking.SetServerInfo(dbInfoPeakOnline, strconv.FormatInt(online, 10))
+		if seen && s.clock.Now().Sub(s.lastrecord) > recordCooldown {
+			s.lastrecord = s.clock.Now()
+			logger.LogInfo(s, sprintf("New concurrent player record: %d", online))
+			s.SendChat(ifaces.ChatData{Name: "Milestone",
+				Msg: fillMilestone(recordmsg, online)})
+		}
+	}
+}
+
+// milestoneInfo returns a stored milestone value, and whether or not it had
+// been stored previously
+func (s *Server) milestoneInfo(key string) (int64, bool) {
+	val, err := s.trackingDB().ServerInfo(key)
+	if err != nil {
+		logger.LogError(s, "ServerInfo: "+err.Error())
+		return 0, true
+	}
+
+	if val == "" {
+		return 0, false
+	}
+
+	n, _ := strconv.ParseInt(val, 10, 64)
+	return n, true
+}
+
+// milestoneReached returns the highest milestone that count has reached
+func milestoneReached(milestones []int64, count int64) int64 {
+	reached := int64(0)
+	for _, m := range milestones {
+		if count >= m && m > reached {
+			reached = m
+		}
+	}
+	return reached
+}
+
+// fillMilestone fills in the {count} placeholder of a milestone template
+func fillMilestone(tmpl string, count int64) string {
+	return strings.ReplaceAll(tmpl, "{count}", strconv.FormatInt(count, 10))
+}
@@ -0,0 +1,136 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"time"
+)
+
+const (
+	warnChatViolation = `chat violation #%d by [%s], escalating to: %s`
+
+	noticeModeration = "**Moderation Action**: Chat filter violation\n" +
+		"**Player:** `%s`\n**Offence:** _%d within %s_\n**Action:** _%s_\n" +
+		"**Message:**\n> %s"
+
+	msgModWarn    = "Your message was blocked by the chat filter. Further violations will be escalated."
+	msgModMute    = "Your messages will not be relayed to Discord for %s due to repeated chat filter violations."
+	reasonModKick = "Repeated chat filter violations"
+
+	authorChatFilter = "Chat filter"
+)
+
+// chatOffender tracks the recent chat filter violations of a single player
+type chatOffender struct {
+	violations []time.Time
+	muted      time.Time
+}
+
+/*********************************/
+/* IFace ifaces.IModeratedServer */
+/*********************************/
+
+// ModerateChat checks a players chat message against the chat filter and
+// applies the configured escalation policy to repeat offenders. It returns
+// false if the message should not be relayed to Discord.
+func (s *Server) ModerateChat(cd ifaces.ChatData) bool {
+	var (
+		now = s.clock.Now()
+		p   = s.PlayerFromName(cd.Name)
+		key = cd.Name
+	)
+
+	if p != nil {
+		key = p.Index()
+	}
+
+	o := s.offenders[key]
+	if o != nil && now.Before(o.muted) {
+		logger.LogDebug(s, "Not relaying chat from muted player: "+cd.Name)
+		return false
+	}
+
+	if !s.chatViolation(cd.Msg) {
+		return true
+	}
+
+	if o == nil {
+		o = &chatOffender{violations: make([]time.Time, 0)}
+		s.offenders[key] = o
+	}
+
+	// Only violations within the moderation window count towards escalation
+	window := s.config.ModerationWindow()
+	recent := make([]time.Time, 0)
+	for _, t := range o.violations {
+		if now.Sub(t) < window {
+			recent = append(recent, t)
+		}
+	}
+	o.violations = append(recent, now)
+
+	step := ifaces.ModerationMute
+	if policy := s.config.ModerationPolicy(); len(policy) > 0 {
+		n := len(o.violations)
+		if n > len(policy) {
+			n = len(policy)
+		}
+		step = policy[n-1]
+	}
+
+	// Without a player object, the relay is the only thing we can act on
+	if p == nil && step != ifaces.ModerationMute {
+		logger.LogWarning(s, "Unable to locate chat offender, muting instead: "+
+			cd.Name)
+		step = ifaces.ModerationMute
+	}
+
+	logger.LogWarning(s, sprintf(warnChatViolation, len(o.violations), cd.Name,
+		step))
+	s.escalate(p, o, step)
+	s.SendLog(ifaces.ChatData{Msg: sprintf(noticeModeration, cd.Name,
+		len(o.violations), window, step, cd.Msg)})
+
+	return false
+}
+
+// chatViolation returns true if the message matches the chat filter
+func (s *Server) chatViolation(msg string) bool {
+	for _, re := range s.config.ChatFilter() {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// escalate applies a single step of the moderation policy to a player
+func (s *Server) escalate(p ifaces.IPlayer, o *chatOffender, step string) {
+	now := s.clock.Now()
+
+	switch step {
+	case ifaces.ModerationWarn:
+		p.Message(msgModWarn)
+
+	case ifaces.ModerationMute:
+		o.muted = now.Add(s.config.MuteDuration())
+		if p != nil {
+			p.Message(sprintf(msgModMute, s.config.MuteDuration()))
+		}
+
+	case ifaces.ModerationKick:
+		p.Kick(reasonModKick)
+
+	case ifaces.ModerationTempBan:
+		if _, err := s.BanPlayer(p, authorChatFilter, reasonModKick,
+			s.config.TempBanDuration()); err != nil {
+			logger.LogError(s, "BanPlayer: "+err.Error())
+		}
+
+	case ifaces.ModerationBan:
+		if _, err := s.BanPlayer(p, authorChatFilter, reasonModKick,
+			0); err != nil {
+			logger.LogError(s, "BanPlayer: "+err.Error())
+		}
+	}
+}
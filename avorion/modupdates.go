@@ -0,0 +1,184 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	noticeModsUpdated = "**Server Notice**: These server mods were updated on " +
+		"the Workshop:\n%s"
+	noticeModsRestart = "\nThe server will restart <t:%d:R> to load them. " +
+		"Players are being warned in-game."
+	noticeModsPending = "\nThey will be loaded the next time the server restarts."
+
+	workshopAppID = "445220"
+	modURLBase    = "https://steamcommunity.com/sharedfiles/filedetails/?id="
+
+	// Where Workshop items are kept, relative to the installation that the
+	// server reads them from
+	workshopContentDir = "/steamapps/workshop/content/" + workshopAppID
+
+	// How long SteamCMD is given to download every server mod
+	steamcmdTimeout = 15 * time.Minute
+)
+
+// workshopUpdates tracks the versions of the server mods that were current
+// when Avorion was last started, so that updates made since can be detected
+type workshopUpdates struct {
+	lock     sync.Mutex
+	seen     map[int64]time.Time
+	next     time.Time
+	checking bool
+}
+
+// downloadMods downloads the server mods with SteamCMD into the Avorion
+// installation, so that Avorion doesn't stall on the downloads while starting.
+// Nothing is done if SteamCMD isn't configured.
+func (s *Server) downloadMods() {
+	steamcmd := s.config.SteamCMD()
+	ids := s.config.ListServerMods()
+	if steamcmd == "" || len(ids) == 0 {
+		return
+	}
+
+	// SteamCMD keeps items in its own directory unless it's told otherwise,
+	// where Avorion would never find them
+	args := []string{"+force_install_dir", s.serverpath, "+login", "anonymous"}
+	for _, id := range ids {
+		args = append(args, "+workshop_download_item", workshopAppID,
+			strconv.FormatInt(id, 10))
+	}
+	args = append(args, "+quit")
+
+	ctx, cancel := context.WithTimeout(context.Background(), steamcmdTimeout)
+	defer cancel()
+
+	logger.LogInit(s, sprintf("Downloading %d server mods with SteamCMD",
+		len(ids)))
+	out, err := s.exec.Command(ctx, ProcessOptions{}, steamcmd,
+		args...).CombinedOutput()
+	if err != nil {
+		logger.LogWarning(s, "SteamCMD failed to download the server mods: "+
+			err.Error())
+		logger.LogDebug(s, "SteamCMD: "+string(out))
+		return
+	}
+
+	// SteamCMD exits cleanly even when an item fails to download
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.Contains(line, "ERROR!") {
+			logger.LogWarning(s, "SteamCMD: "+strings.TrimSpace(line))
+		}
+	}
+
+	dir := s.serverpath + workshopContentDir
+	for _, id := range ids {
+		path := sprintf("%s/%d", dir, id)
+		if info, err := s.fs.Stat(path); err != nil || !info.IsDir() {
+			logger.LogWarning(s, sprintf("SteamCMD did not download mod %d to %s",
+				id, path))
+		}
+	}
+}
+
+// refreshModVersions records the versions of the server mods that Avorion is
+// about to load
+func (s *Server) refreshModVersions() {
+	interval, _ := s.config.ModUpdateCheck()
+	if interval == 0 {
+		return
+	}
+
+	times, err := s.config.WorkshopUpdateTimes(s.config.ListServerMods())
+	if err != nil {
+		logger.LogWarning(s, "Failed to look up the server mods: "+err.Error())
+	}
+
+	s.modupdates.lock.Lock()
+	defer s.modupdates.lock.Unlock()
+
+	s.modupdates.next = s.clock.Now().Add(interval)
+	if err == nil {
+		s.modupdates.seen = times
+	}
+}
+
+// checkModUpdates checks the Workshop for updates to the server mods once the
+// configured interval has passed. Staff are notified of any updates, and a
+// restart is scheduled to load them if that's configured.
+func (s *Server) checkModUpdates() {
+	interval, restart := s.config.ModUpdateCheck()
+	if interval == 0 {
+		return
+	}
+
+	s.modupdates.lock.Lock()
+	now := s.clock.Now()
+	if s.modupdates.checking || now.Before(s.modupdates.next) {
+		s.modupdates.lock.Unlock()
+		return
+	}
+	s.modupdates.checking = true
+	s.modupdates.next = now.Add(interval)
+	s.modupdates.lock.Unlock()
+
+	go func() {
+		defer shutdown.Recover()
+		defer func() {
+			s.modupdates.lock.Lock()
+			s.modupdates.checking = false
+			s.modupdates.lock.Unlock()
+		}()
+
+		times, err := s.config.WorkshopUpdateTimes(s.config.ListServerMods())
+		if err != nil {
+			logger.LogDebug(s, "Failed to check for mod updates: "+err.Error())
+			return
+		}
+
+		s.modupdates.lock.Lock()
+		if s.modupdates.seen == nil {
+			s.modupdates.seen = make(map[int64]time.Time)
+		}
+
+		updated := make([]string, 0)
+		for id, t := range times {
+			// Mods added since Avorion started aren't loaded yet either way
+			last, ok := s.modupdates.seen[id]
+			s.modupdates.seen[id] = t
+			if ok && t.After(last) {
+				updated = append(updated, sprintf("<%s%d> (<t:%d:R>)", modURLBase,
+					id, t.Unix()))
+			}
+		}
+		s.modupdates.lock.Unlock()
+
+		if len(updated) == 0 {
+			return
+		}
+
+		logger.LogInfo(s, sprintf("%d server mods were updated on the Workshop",
+			len(updated)))
+		msg := sprintf(noticeModsUpdated, strings.Join(updated, "\n"))
+
+		switch pending, ok := s.PendingRestart(); {
+		case ok:
+			msg += sprintf(noticeModsRestart, pending.Unix())
+		case restart && s.IsUp():
+			s.ScheduleRestart(restartWarnings[0])
+			msg += sprintf(noticeModsRestart,
+				s.clock.Now().Add(restartWarnings[0]).Unix())
+		default:
+			msg += noticeModsPending
+		}
+
+		s.SendLog(ifaces.ChatData{Msg: msg})
+	}()
+}
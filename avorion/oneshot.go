@@ -0,0 +1,204 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const noticeOneShotMissed = "**Server Notice**: These scheduled actions were " +
+	"missed while the server was offline, and have been dropped:\n%s"
+
+// oneShotGrace is how late an action can be when the server comes back up and
+// still be run, rather than dropped
+const oneShotGrace = 10 * time.Minute
+
+// oneShotNames are the names that one-off actions are listed under
+var oneShotNames = map[string]string{
+	ifaces.ActionRestart: "Server restart",
+	ifaces.ActionBackup:  "Galaxy backup"}
+
+// oneShotActions are actions that were scheduled to run once at a set time.
+// They are stored in the tracking database so that they survive a restart of
+// the bot.
+type oneShotActions struct {
+	lock    sync.Mutex
+	actions []ifaces.OneShotAction
+}
+
+// loadOneShotActions loads the one-off actions from the tracking DB, and drops
+// the ones that were missed while the server was offline
+func (s *Server) loadOneShotActions() {
+	s.oneshot.lock.Lock()
+	defer s.oneshot.lock.Unlock()
+
+	s.oneshot.actions = make([]ifaces.OneShotAction, 0)
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return
+	}
+
+	actions, err := tracking.OneShotActions()
+	if err != nil {
+		logger.LogError(s, "OneShotActions: "+err.Error())
+		return
+	}
+
+	now := s.clock.Now()
+	missed := make([]string, 0)
+
+	for _, a := range actions {
+		if now.Sub(a.At) <= oneShotGrace {
+			s.oneshot.actions = append(s.oneshot.actions, a)
+			continue
+		}
+
+		if err := tracking.RemoveOneShotAction(a.ID); err != nil {
+			continue
+		}
+
+		logger.LogWarning(s, sprintf("Dropped the %s scheduled for %s",
+			a.Action, a.At.Format(time.RFC3339)))
+		missed = append(missed, sprintf("%s at <t:%d:f>", oneShotNames[a.Action],
+			a.At.Unix()))
+	}
+
+	if len(missed) > 0 {
+		s.SendLog(ifaces.ChatData{Msg: sprintf(noticeOneShotMissed,
+			strings.Join(missed, "\n"))})
+	}
+}
+
+// checkOneShotActions runs the one-off actions that are due. Restarts are
+// handed to the restart countdown once they are close enough for the first
+// in-game warning, so that players are warned the same way as for any other
+// scheduled restart.
+func (s *Server) checkOneShotActions() {
+	tracking := s.trackingDB()
+
+	s.oneshot.lock.Lock()
+	defer s.oneshot.lock.Unlock()
+
+	now := s.clock.Now()
+	pending := make([]ifaces.OneShotAction, 0, len(s.oneshot.actions))
+
+	for _, a := range s.oneshot.actions {
+		left := a.At.Sub(now)
+
+		switch {
+		case a.Action == ifaces.ActionRestart && left <= restartWarnings[0]:
+			if left < 0 {
+				left = 0
+			}
+			s.ScheduleRestart(left)
+
+		case a.Action == ifaces.ActionBackup && left <= 0:
+			go func() {
+				defer shutdown.Recover()
+				if _, err := s.Backup(ifaces.BackupScheduled); err != nil {
+					logger.LogError(s, "Scheduled backup: "+err.Error())
+					s.SendLog(ifaces.ChatData{Msg: sprintf(noticeBackupFailed,
+						err.Error())})
+				}
+			}()
+
+		default:
+			pending = append(pending, a)
+			continue
+		}
+
+		logger.LogInfo(s, sprintf("Running the %s scheduled by %s", a.Action,
+			a.Author))
+		if tracking != nil {
+			tracking.RemoveOneShotAction(a.ID)
+		}
+	}
+
+	s.oneshot.actions = pending
+}
+
+/*********************************/
+/* IFace ifaces.IScheduledServer */
+/*********************************/
+
+// ScheduleAction schedules an action to run once at the given time, and
+// returns it. The action is only run while the server is up.
+func (s *Server) ScheduleAction(action string, at time.Time,
+	author string) (ifaces.OneShotAction, error) {
+	if _, ok := oneShotNames[action]; !ok {
+		return ifaces.OneShotAction{}, errors.New("unknown action " + action)
+	}
+
+	if !at.After(s.clock.Now()) {
+		return ifaces.OneShotAction{}, errors.New("the time has already passed")
+	}
+
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return ifaces.OneShotAction{}, ifaces.ErrDataUnavailable
+	}
+
+	s.oneshot.lock.Lock()
+	defer s.oneshot.lock.Unlock()
+
+	a := ifaces.OneShotAction{Action: action, At: at, Author: author}
+	id, err := tracking.AddOneShotAction(a)
+	if err != nil {
+		return ifaces.OneShotAction{}, err
+	}
+	a.ID = id
+
+	s.oneshot.actions = append(s.oneshot.actions, a)
+	logger.LogInfo(s, sprintf("%s scheduled a %s for %s", author, action,
+		at.Format(time.RFC3339)))
+	return a, nil
+}
+
+// OneShotActions returns the actions that are scheduled to run once, soonest
+// first
+func (s *Server) OneShotActions() []ifaces.OneShotAction {
+	s.oneshot.lock.Lock()
+	defer s.oneshot.lock.Unlock()
+
+	actions := make([]ifaces.OneShotAction, len(s.oneshot.actions))
+	copy(actions, s.oneshot.actions)
+
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].At.Before(actions[j].At)
+	})
+
+	return actions
+}
+
+// CancelAction cancels an action that was scheduled to run once
+func (s *Server) CancelAction(id int64) error {
+	tracking := s.trackingDB()
+
+	s.oneshot.lock.Lock()
+	defer s.oneshot.lock.Unlock()
+
+	for i, a := range s.oneshot.actions {
+		if a.ID != id {
+			continue
+		}
+
+		if tracking != nil {
+			if err := tracking.RemoveOneShotAction(id); err != nil {
+				return err
+			}
+		}
+
+		s.oneshot.actions = append(s.oneshot.actions[:i],
+			s.oneshot.actions[i+1:]...)
+		logger.LogInfo(s, sprintf("Cancelled the %s scheduled for %s", a.Action,
+			a.At.Format(time.RFC3339)))
+		return nil
+	}
+
+	return errors.New(sprintf("there is no scheduled action #%d", id))
+}
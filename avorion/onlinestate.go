@@ -0,0 +1,77 @@
+package avorion
+
+import (
+	"avorioncontrol/avorion/rcon"
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"time"
+)
+
+const rconStatus = "status"
+
+// How often the online state of the players is compared with the players that
+// the game reports as online. Logins and logoffs are tracked from the log, so
+// this only catches the ones that were missed, such as during a bot restart.
+const onlineReconcileInterval = 5 * time.Minute
+
+// reconcileOnline corrects the online state of the players using the output of
+// status. When status only reports a count of players, the state can only be
+// corrected when nobody is online; otherwise any drift is logged.
+func (s *Server) reconcileOnline() {
+	now := s.clock.Now()
+	if now.Before(s.nextreconcile) {
+		return
+	}
+	s.nextreconcile = now.Add(onlineReconcileInterval)
+
+	out, err := s.RunCommandPriority(rconStatus, ifaces.CommandPriorityBackground)
+	if err != nil {
+		logger.LogDebug(s, "Failed to get the status: "+err.Error())
+		return
+	}
+
+	st := rcon.ParseStatus(out)
+	if st.Online < 0 {
+		logger.LogDebug(s, "status did not report the players online")
+		return
+	}
+
+	listed := make(map[string]bool, len(st.OnlinePlayers))
+	for _, name := range st.OnlinePlayers {
+		listed[name] = true
+	}
+	complete := len(st.OnlinePlayers) == st.Online
+
+	var tracked, corrected int
+	for _, p := range s.players {
+		online := p.Online()
+		switch {
+		case st.Online == 0:
+			online = false
+		case complete:
+			online = listed[p.Name()]
+		}
+
+		if online != p.Online() {
+			logger.LogInfo(p, sprintf("Corrected online state to %t", online))
+			p.SetOnline(online)
+			corrected++
+		}
+
+		if online {
+			tracked++
+		}
+	}
+
+	if corrected > 0 {
+		s.cache.update(func(c *statusSnapshot) { c.onlineplayercount = tracked })
+		s.updateOnlineString()
+		logger.LogWarning(s, sprintf("Corrected the online state of %d players",
+			corrected))
+	}
+
+	if tracked != st.Online {
+		logger.LogDebug(s, sprintf("Tracking %d players online, but the game "+
+			"reports %d", tracked, st.Online))
+	}
+}
@@ -0,0 +1,179 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	pidFileName = "avorioncontrol.pid"
+	orphanWait  = 10 * time.Minute
+
+	warnOrphanFound   = `found an orphaned Avorion process (PID %d) for galaxy %s`
+	noticeOrphanFound = "**Server Error**: An Avorion process (PID `%d`) is " +
+		"already running the galaxy `%s`, likely left behind by a previous crash. " +
+		"Run `server reap` to terminate it, or stop it manually. Startup will " +
+		"continue once it has exited."
+	errOrphanTimeout = `orphaned Avorion process (PID %d) is still running`
+	errNoOrphan      = `no orphaned Avorion process was found`
+)
+
+// pidFile returns the path to the pidfile for the configured galaxy
+func (s *Server) pidFile() string {
+	return strings.TrimSuffix(s.config.DataPath(), "/") + "/" +
+		s.config.Galaxy() + "/" + pidFileName
+}
+
+// writePidFile records the PID of the running Avorion process
+func (s *Server) writePidFile() {
+	if s.Cmd == nil || s.Cmd.Pid() == 0 {
+		return
+	}
+
+	pid := strconv.Itoa(s.Cmd.Pid())
+	if err := ioutil.WriteFile(s.pidFile(), []byte(pid), 0600); err != nil {
+		logger.LogWarning(s, "Failed to write pidfile: "+err.Error())
+	}
+}
+
+// removePidFile removes the pidfile once Avorion has exited
+func (s *Server) removePidFile() {
+	if err := os.Remove(s.pidFile()); err != nil && !os.IsNotExist(err) {
+		logger.LogWarning(s, "Failed to remove pidfile: "+err.Error())
+	}
+}
+
+// isGalaxyProcess returns true if the given PID is alive and is an Avorion
+// server running the configured galaxy. If its command line can't be read, the
+// PID is only trusted if it came from a source that identifies it as Avorion,
+// such as the pidfile; a process found while scanning may have just exited.
+func (s *Server) isGalaxyProcess(pid int, trusted bool) bool {
+	if pid <= 0 || syscall.Kill(pid, 0) != nil {
+		return false
+	}
+
+	// Without procfs, we have to trust that the PID belongs to Avorion
+	cmdline, err := ioutil.ReadFile(sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return trusted
+	}
+
+	// Wrapped servers are run by wine or box64, which may keep the Windows path
+	// to the binary as the first argument, or pass it as a later one
+	args := strings.Split(string(cmdline), "\x00")
+	found := false
+	for _, arg := range args {
+		if filepath.Base(strings.ReplaceAll(arg, `\`, "/")) == s.executable {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return false
+	}
+
+	for i, arg := range args[:len(args)-1] {
+		if arg == "--galaxy-name" && args[i+1] == s.config.Galaxy() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findOrphan looks for an Avorion process that is already running the
+// configured galaxy. The pidfile is checked first, followed by a scan of the
+// running processes.
+func (s *Server) findOrphan() (int, bool) {
+	if data, err := ioutil.ReadFile(s.pidFile()); err == nil {
+		pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		if s.isGalaxyProcess(pid, true) {
+			return pid, true
+		}
+	}
+
+	procs, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return 0, false
+	}
+
+	for _, p := range procs {
+		pid, err := strconv.Atoi(p.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+
+		if s.isGalaxyProcess(pid, false) {
+			return pid, true
+		}
+	}
+
+	return 0, false
+}
+
+// awaitOrphan alerts the log channel about an orphaned Avorion process and
+// waits for it to exit (either by way of `server reap` or manual intervention)
+func (s *Server) awaitOrphan() error {
+	pid, ok := s.findOrphan()
+	if !ok {
+		return nil
+	}
+
+	logger.LogWarning(s, sprintf(warnOrphanFound, pid, s.config.Galaxy()))
+	s.SendLog(ifaces.ChatData{Msg: sprintf(noticeOrphanFound, pid,
+		s.config.Galaxy())})
+
+	timeout := s.clock.After(orphanWait)
+	for s.isGalaxyProcess(pid, true) {
+		select {
+		case <-timeout:
+			return errors.New(sprintf(errOrphanTimeout, pid))
+		case <-s.exit:
+			return errors.New(sprintf(errOrphanTimeout, pid))
+		case <-s.clock.After(time.Second * 5):
+		}
+	}
+
+	logger.LogInfo(s, sprintf("Orphaned Avorion process (PID %d) has exited", pid))
+	s.removePidFile()
+	return nil
+}
+
+/********************************/
+/* IFace ifaces.IReapableServer */
+/********************************/
+
+// ReapOrphan terminates an orphaned Avorion process that is running the
+// configured galaxy, and returns its PID
+func (s *Server) ReapOrphan() (int, error) {
+	pid, ok := s.findOrphan()
+	if !ok || (s.Cmd != nil && s.Cmd.Pid() == pid) {
+		return 0, errors.New(errNoOrphan)
+	}
+
+	logger.LogWarning(s, sprintf("Terminating orphaned Avorion process (PID %d)", pid))
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return pid, err
+	}
+
+	timeout := s.clock.After(time.Minute)
+	for s.isGalaxyProcess(pid, true) {
+		select {
+		case <-timeout:
+			logger.LogError(s, sprintf("Sending kill to orphaned process (PID %d)", pid))
+			return pid, syscall.Kill(pid, syscall.SIGKILL)
+		case <-s.clock.After(time.Second):
+		}
+	}
+
+	return pid, nil
+}
@@ -1,24 +1,20 @@
 package avorion
 
 import (
+	"avorioncontrol/avorion/rcon"
 	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
 	"fmt"
 	"net"
-	"regexp"
 	"strconv"
+	"strings"
 
 	"time"
 )
 
 var resourceMap map[string]int
 
-const (
-	steamUIDRegex   = `^\s*([0-9]+) .*$`
-	steamUIDCommand = `playerinfo %s -s -o`
-)
-
-var steamUIDRegexp = regexp.MustCompile(steamUIDRegex)
+const steamUIDCommand = `playerinfo %s -s -o`
 
 // Player is a player that has connected to the server at some point, and has
 // data present in the game db
@@ -31,11 +27,13 @@ type Player struct {
 	discordid string
 
 	// ifaces.Player
-	ip       net.IP
-	name     string
-	online   bool
-	server   *Server
-	loglevel int
+	ip         net.IP
+	name       string
+	online     bool
+	private    bool
+	server     *Server
+	loglevel   int
+	lastactive time.Time
 
 	// playerdata
 	resources   map[string]int64
@@ -47,9 +45,15 @@ func (p *Player) Update() error {
 	return nil
 }
 
-// UpdateFromData updates the players information using the data from
-//	a successful rePlayerData match
-func (p *Player) UpdateFromData(d [15]string) error {
+// UpdateFromData updates the players information using the data reported by
+//	getplayerdata
+func (p *Player) UpdateFromData(d ifaces.PlayerData) error {
+	if d.Index != p.index {
+		return fmt.Errorf("player data is for %s, not %s", d.Index, p.index)
+	}
+
+	p.name = d.Name
+	p.resources = d.Resources
 	return nil
 }
 
@@ -69,9 +73,13 @@ func (p *Player) Steam64() int64 {
 
 // AddJump registers a jump that a player took into a system
 func (p *Player) AddJump(sc ifaces.ShipCoordData) {
-	sc.Time = time.Now()
+	// Jumps from the mod event file carry the time that the jump happened
+	if sc.Time.IsZero() {
+		sc.Time = time.Now()
+	}
+	p.lastactive = sc.Time
 	p.jumphistory = append(p.jumphistory, sc)
-	if len(p.jumphistory) > 1000 {
+	if len(p.jumphistory) > jumpHistoryLimit {
 		p.jumphistory = p.jumphistory[1:]
 	}
 
@@ -92,8 +100,12 @@ func (p *Player) AddJump(sc ifaces.ShipCoordData) {
 	sector.Jumphistory = append(sector.Jumphistory, jump)
 
 	id, _ := strconv.Atoi(p.Index())
-	p.server.tracking.AddJump(sector.Index, int64(id), 0, *jump)
+	p.server.trackingDB().AddJump(sector.Index, int64(id), 0, *jump)
+	p.server.TrackShip(p.index, sc)
 	logger.LogDebug(p, "Updated jumphistory")
+
+	p.server.checkJumpAnomaly(p, p.Name(), p.jumphistory, sc)
+	p.server.checkAllianceAlerts(p.index, p.Name(), sc)
 }
 
 /************************/
@@ -139,7 +151,25 @@ func (p *Player) Online() bool {
 
 // SetOnline updates the player status to the boolean passed
 func (p *Player) SetOnline(o bool) {
+	if o != p.online {
+		p.server.playerSeen(p, o)
+	}
+
 	p.online = o
+	if o {
+		p.SetActive()
+	}
+}
+
+// LastActive returns the last time that the player logged in, chatted, or
+// jumped
+func (p *Player) LastActive() time.Time {
+	return p.lastactive
+}
+
+// SetActive records that the player is active
+func (p *Player) SetActive() {
+	p.lastactive = time.Now()
 }
 
 // SetDiscordUID sets a players Discord ID
@@ -155,41 +185,70 @@ func (p *Player) DiscordUID() string {
 	return p.discordid
 }
 
-// Message messages a player
-func (p *Player) Message(string) {
+// Private returns whether the player has opted out of the public display of
+// their tracking data
+func (p *Player) Private() bool {
+	return p.private
+}
+
+// SetPrivate sets and stores the players tracking privacy preference
+func (p *Player) SetPrivate(hidden bool) error {
+	if err := p.server.trackingDB().SetPrivacy(p.index, hidden); err != nil {
+		return err
+	}
+
+	p.private = hidden
+	logger.LogInfo(p, sprintf("Set tracking privacy to %t", hidden))
+	return nil
+}
+
+// Message sends a warning message to the player in game
+func (p *Player) Message(msg string) {
+	msg = strings.ReplaceAll(msg, `"`, `'`)
+	if _, err := p.server.RunCommand(sprintf(rconPlayerMessage, p.index,
+		msg)); err != nil {
+		logger.LogError(p, "Message: "+err.Error())
+	}
 }
 
 /*****************************/
 /* IFace ifaces.ISteamPlayer */
 /*****************************/
 
-// SteamUID returns the steamUID or 0 of the player
-// TODO: Modify this function, as well as the GameDB to store this data
-// in the sqlite database
+// SteamUID returns the steamUID or 0 of the player. IDs are normally resolved
+// in the background, so this only queries the server for players that haven't
+// been resolved yet.
 func (p *Player) SteamUID() int64 {
 	if p.steam64 != 0 {
 		return p.steam64
 	}
 
-	cmd := sprintf(steamUIDCommand, p.Index())
-	out, err := p.server.RunCommand(cmd)
+	id, err := p.resolveSteamUID()
 	if err != nil {
-		return 0
+		logger.LogDebug(p, "Failed to resolve steam ID: "+err.Error())
 	}
+	return id
+}
 
-	m := steamUIDRegexp.FindStringSubmatch(out)
-	if len(m) < 2 {
-		return 0
+// resolveSteamUID queries the server for the players steam64 ID, and caches it
+// in the player and the tracking DB
+func (p *Player) resolveSteamUID() (int64, error) {
+	out, err := p.server.lookup(sprintf(steamUIDCommand, p.Index()))
+	if err != nil {
+		return 0, err
 	}
 
-	sid, err := strconv.ParseInt(m[1], 10, 64)
+	info, err := rcon.ParsePlayerInfo(out)
 	if err != nil {
-		return 0
+		return 0, err
 	}
 
-	logger.LogDebug(p, "Setting player steamcmd to: "+m[1])
-	p.steam64 = sid
-	return sid
+	logger.LogDebug(p, sprintf("Setting player steamcmd to: %d", info.SteamID))
+	p.steam64 = info.SteamID
+	if err := p.server.trackingDB().SetSteamID(p.index, info.SteamID); err != nil {
+		logger.LogError(p, "GameDB: "+err.Error())
+	}
+	return info.SteamID, nil
 }
 
 /************************/
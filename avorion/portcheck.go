@@ -0,0 +1,88 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	portCheckTimeout = 3 * time.Second
+
+	warnGamePort = `game port %s is unreachable (%s)`
+
+	noticeGamePortDown = "**Server Warning**: The game port is unreachable " +
+		"while RCON is still answering\n**Address:** `%s`\n**Error:** _%s_\n" +
+		"_Please check the firewall and the server's network listener._"
+	noticeGamePortUp = "**Server Notice**: The game port is reachable again\n" +
+		"**Address:** `%s`"
+)
+
+// probeTCP checks that a TCP connection can be made to the given address
+func probeTCP(addr string) error {
+	conn, err := net.DialTimeout("tcp", addr, portCheckTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// probeUDP sends a datagram to the given address and waits briefly for a
+// reply. UDP has no handshake, so silence is treated as reachable; only an
+// explicit refusal (an ICMP port unreachable) means that nothing is listening.
+func probeUDP(addr string) error {
+	conn, err := net.DialTimeout("udp", addr, portCheckTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte{0}); err != nil {
+		return err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(portCheckTimeout))
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		var nerr net.Error
+		if errors.As(err, &nerr) && nerr.Timeout() {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// checkGamePort checks that the game port answers on both TCP and UDP. It is
+// run after RCON has answered the status check, so that a firewalled port or
+// a dead game listener is noticed even though the server looks healthy. The
+// admins are only notified when the reachability changes.
+func (s *Server) checkGamePort() {
+	addr := net.JoinHostPort(s.config.PublicAddress(),
+		strconv.Itoa(s.config.GamePort()))
+
+	err := probeTCP(addr)
+	if err == nil {
+		err = probeUDP(addr)
+	}
+
+	failure := s.cache.load().portfailure
+	if err != nil {
+		if failure == "" {
+			logger.LogWarning(s, sprintf(warnGamePort, addr, err.Error()))
+			s.SendLog(ifaces.ChatData{
+				Msg: sprintf(noticeGamePortDown, addr, err.Error())})
+		}
+		s.cache.update(func(c *statusSnapshot) { c.portfailure = err.Error() })
+		return
+	}
+
+	if failure != "" {
+		logger.LogInfo(s, sprintf("Game port %s is reachable again", addr))
+		s.SendLog(ifaces.ChatData{Msg: sprintf(noticeGamePortUp, addr)})
+		s.cache.update(func(c *statusSnapshot) { c.portfailure = "" })
+	}
+}
@@ -0,0 +1,49 @@
+package avorion
+
+import (
+	"avorioncontrol/logger"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	preflightTimeout   = 30 * time.Second
+	errPreflightFailed = `preflight command failed: %s (%s)`
+	errPreflightHung   = `preflight command timed out after %s: %s`
+)
+
+// preflight runs a command that is required to succeed before the server can
+// be initialized. Commands are run under a context so that a hung filesystem
+// or wrapper cannot block startup forever, and any output that the command
+// produced is logged on failure to aid in debugging.
+func preflight(l logger.ILogger, e Executor, timeout time.Duration, name string,
+	args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmdline := strings.TrimSpace(name + " " + strings.Join(args, " "))
+	logger.LogDebug(l, "Running preflight: "+cmdline)
+
+	ret, err := e.Command(ctx, ProcessOptions{}, name, args...).CombinedOutput()
+	out := string(ret)
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = errors.New(sprintf(errPreflightHung, timeout, cmdline))
+	} else if err != nil {
+		err = errors.New(sprintf(errPreflightFailed, cmdline, err.Error()))
+	}
+
+	if err != nil {
+		logger.LogError(l, err.Error())
+		for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+			if line != "" {
+				logger.LogError(l, "preflight: "+line)
+			}
+		}
+		return out, err
+	}
+
+	return out, nil
+}
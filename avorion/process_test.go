@@ -0,0 +1,209 @@
+package avorion
+
+import (
+	"avorioncontrol/configuration"
+	"avorioncontrol/eventbus"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeExecutor stands in for the host, running a fake game that starts up
+// straight away and exits when it's told to stop over RCON
+type fakeExecutor struct {
+	mutex sync.Mutex
+	game  string
+	rcon  string
+	pid   int
+
+	running  *fakeProcess
+	commands []string
+}
+
+func (e *fakeExecutor) Command(ctx context.Context, opts ProcessOptions,
+	name string, args ...string) Process {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.pid++
+	p := &fakeProcess{pid: e.pid, opts: opts, done: make(chan struct{})}
+
+	switch {
+	case name == e.rcon:
+		cmd := args[len(args)-1]
+		e.commands = append(e.commands, cmd)
+		if cmd == "stop" && e.running != nil {
+			e.running.exit(ProcessExit{})
+		}
+
+	case name == e.game && len(args) > 0 && args[0] == "--version":
+		p.output = "2.0.0 r12345\n"
+
+	case name == e.game:
+		p.game = true
+		e.running = p
+	}
+
+	return p
+}
+
+// ran reports whether the given RCON command has been run
+func (e *fakeExecutor) ran(cmd string) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for _, c := range e.commands {
+		if c == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeProcess struct {
+	mutex   sync.Mutex
+	pid     int
+	opts    ProcessOptions
+	game    bool
+	output  string
+	started bool
+	exited  *ProcessExit
+	done    chan struct{}
+}
+
+func (p *fakeProcess) Start() error {
+	p.mutex.Lock()
+	p.started = true
+	p.mutex.Unlock()
+
+	if p.game {
+		go io.WriteString(p.opts.Output, "Server startup complete.\n")
+	} else {
+		p.exit(ProcessExit{})
+	}
+	return nil
+}
+
+func (p *fakeProcess) Wait() error {
+	<-p.done
+	return nil
+}
+
+func (p *fakeProcess) Output() ([]byte, error) {
+	p.Start()
+	return []byte(p.output), nil
+}
+
+func (p *fakeProcess) CombinedOutput() ([]byte, error) {
+	return p.Output()
+}
+
+func (p *fakeProcess) StdinPipe() (io.WriteCloser, error) {
+	_, w := io.Pipe()
+	return w, nil
+}
+
+func (p *fakeProcess) Pid() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.started {
+		return 0
+	}
+	return p.pid
+}
+
+func (p *fakeProcess) Signal(sig os.Signal) error {
+	if !p.game {
+		return errors.New("process has already exited")
+	}
+	p.exit(ProcessExit{Code: -1, Signal: sig.(syscall.Signal)})
+	return nil
+}
+
+func (p *fakeProcess) Exit() (ProcessExit, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.exited == nil {
+		return ProcessExit{}, false
+	}
+	return *p.exited, true
+}
+
+func (p *fakeProcess) exit(pe ProcessExit) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.exited == nil {
+		p.exited = &pe
+		close(p.done)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "avorioncontrol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	install := filepath.Join(dir, "install")
+	if err := os.MkdirAll(filepath.Join(install, "bin"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	game := filepath.Join(install, "bin", gameExecutable(""))
+	if err := ioutil.WriteFile(game, nil, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	c := configuration.New()
+	c.ConfigFile = filepath.Join(dir, "config.yaml")
+	conf := sprintf("Game:\n  galaxy_name: fakegalaxy\n  install_dir: %s\n"+
+		"  data_dir: %s\n", install, dir)
+	if err := ioutil.WriteFile(c.ConfigFile, []byte(conf), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadConfiguration(); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		exit = make(chan struct{})
+		e    = &fakeExecutor{game: game, rcon: c.RCONBin()}
+		s    = newServer(c, eventbus.New(), &wg, exit, e)
+	)
+	defer close(exit)
+
+	if err := s.Start(false); err != nil {
+		t.Fatalf("failed to start: %s", err)
+	}
+	if !s.IsUp() {
+		t.Fatal("server is not up after starting")
+	}
+
+	if err := s.Stop(false); err != nil {
+		t.Fatalf("failed to stop: %s", err)
+	}
+	if s.IsUp() {
+		t.Error("server is still up after stopping")
+	}
+	if !e.ran("save") || !e.ran("stop") {
+		t.Errorf("expected the galaxy to be saved and stopped, ran: %s",
+			strings.Join(e.commands, ", "))
+	}
+	if s.IsCrashed() {
+		t.Error("a clean stop was recorded as a crash")
+	}
+
+	select {
+	case <-s.close:
+	case <-time.After(time.Second):
+		t.Error("the server's goroutines were not told to close")
+	}
+}
@@ -0,0 +1,144 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"bytes"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	a2sInfoReply      = 0x49
+	a2sChallengeReply = 0x41
+
+	// Tracking has to disagree with the query port for this many status checks
+	// in a row before the admins are warned, since players joining or leaving
+	// between the two counts would otherwise look like drift
+	queryDriftChecks = 3
+
+	noticeQueryDrift = "**Server Warning**: The player count reported by the " +
+		"Steam query port doesn't match tracking\n**Query Port:** `%d/%d`\n" +
+		"**Tracked:** `%d`\n_Tracked player data may be out of date. Running " +
+		"`playerdb refresh` may correct it._"
+	noticeQueryInSync = "**Server Notice**: The tracked player count matches " +
+		"the Steam query port again"
+)
+
+var (
+	a2sInfoRequest = append([]byte{0xFF, 0xFF, 0xFF, 0xFF, 'T'},
+		[]byte("Source Engine Query\x00")...)
+
+	errA2SReply = errors.New("unexpected reply from the query port")
+)
+
+// queryA2SInfo asks a Steam query port for its server info, and returns the
+// number of players online and the number of player slots
+func queryA2SInfo(addr string) (int, int, error) {
+	conn, err := net.DialTimeout("udp", addr, portCheckTimeout)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Close()
+
+	req := a2sInfoRequest
+	buf := make([]byte, 1400)
+
+	// Newer servers answer the first request with a challenge, which has to be
+	// sent back with the request
+	for attempt := 0; attempt < 2; attempt++ {
+		conn.SetDeadline(time.Now().Add(portCheckTimeout))
+		if _, err := conn.Write(req); err != nil {
+			return 0, 0, err
+		}
+
+		n, err := conn.Read(buf)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if n < 5 || !bytes.Equal(buf[:4], []byte{0xFF, 0xFF, 0xFF, 0xFF}) {
+			return 0, 0, errA2SReply
+		}
+
+		switch buf[4] {
+		case a2sChallengeReply:
+			if n < 9 {
+				return 0, 0, errA2SReply
+			}
+			req = append(append([]byte{}, a2sInfoRequest...), buf[5:9]...)
+		case a2sInfoReply:
+			return parseA2SInfo(buf[5:n])
+		default:
+			return 0, 0, errA2SReply
+		}
+	}
+
+	return 0, 0, errA2SReply
+}
+
+// parseA2SInfo reads the player count and slots out of an A2S_INFO reply: a
+// protocol byte, the name, map, folder, and game strings, the app ID, and then
+// the player and slot counts
+func parseA2SInfo(b []byte) (int, int, error) {
+	if len(b) == 0 {
+		return 0, 0, errA2SReply
+	}
+
+	i := 1
+	for s := 0; s < 4; s++ {
+		end := bytes.IndexByte(b[i:], 0)
+		if end < 0 {
+			return 0, 0, errA2SReply
+		}
+		i += end + 1
+	}
+
+	// Skip the two byte app ID
+	if len(b) < i+4 {
+		return 0, 0, errA2SReply
+	}
+
+	return int(b[i+2]), int(b[i+3]), nil
+}
+
+// checkQueryPort queries the Steam query port for the public player count,
+// and warns the admins if it keeps disagreeing with the tracked count, which
+// means that tracking has drifted from the game
+func (s *Server) checkQueryPort() {
+	addr := net.JoinHostPort(s.config.PublicAddress(),
+		strconv.Itoa(s.config.QueryPort()))
+
+	players, slots, err := queryA2SInfo(addr)
+	if err != nil {
+		logger.LogDebug(s, sprintf("Query port %s: %s", addr, err.Error()))
+		s.cache.update(func(c *statusSnapshot) { c.queryok = false })
+		return
+	}
+
+	s.cache.update(func(c *statusSnapshot) {
+		c.queryplayers, c.queryslots, c.queryok = players, slots, true
+	})
+
+	online := s.cache.online()
+	if players == online {
+		s.querydrift = 0
+		if s.queryalert {
+			s.queryalert = false
+			logger.LogInfo(s, "Tracked player count matches the query port again")
+			s.SendLog(ifaces.ChatData{Msg: noticeQueryInSync})
+		}
+		return
+	}
+
+	s.querydrift++
+	if s.querydrift >= queryDriftChecks && !s.queryalert {
+		s.queryalert = true
+		logger.LogWarning(s, sprintf("Query port reports %d players, but %d are "+
+			"tracked as online", players, online))
+		s.SendLog(ifaces.ChatData{Msg: sprintf(noticeQueryDrift, players, slots,
+			online)})
+	}
+}
@@ -0,0 +1,261 @@
+// Package rcon converts the output of the RCON commands that the bot relies on
+// into typed results, so that the formats are defined in one place.
+package rcon
+
+import (
+	"avorioncontrol/ifaces"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Resources in the order that getplayerdata reports them
+var resourceNames = []string{"credits", "iron", "titanium", "naonite",
+	"trinium", "xanian", "ogonite", "avorion"}
+
+const resourceData = `credits:(-?[0-9]+) iron:(-?[0-9]+) titanium:(-?[0-9]+) ` +
+	`naonite:(-?[0-9]+) trinium:(-?[0-9]+) xanian:(-?[0-9]+) ` +
+	`ogonite:(-?[0-9]+) avorion:(-?[0-9]+)`
+
+/**
+ * Substring Match Indexes:
+ * 0     Entire string
+ * 1     Player index
+ * 2     Current coordinates (X)
+ * 3     Current coordinates (Y)
+ * 4     Ship Count
+ * 5     Station Count
+ * 6-13  Resources
+ * 14    Player Name
+**/
+var rePlayerData = regexp.MustCompile(
+	`^\s*player: ([0-9]+) (-?[0-9]{1,3}):(-?[0-9]{1,3}) ([0-9]+) ([0-9]+) ` +
+		resourceData + ` (.*)$`)
+
+/**
+ * Substring Match Indexes:
+ * 0     Entire string
+ * 1     Alliance index
+ * 2     Ship Count
+ * 3     Station Count
+ * 4-11  Resources
+ * 12    Alliance Name
+**/
+var reAllianceData = regexp.MustCompile(`^\s*alliance: ([0-9]+) ([0-9]+) ([0-9]+) ` +
+	resourceData + ` (.*)$`)
+
+/**
+ * Substring Match Indexes:
+ * 0  Entire string
+ * 1  Faction index
+ * 2  X Coordinate
+ * 3  Y Coordinate
+ * 4  Ship Name
+**/
+var reShipData = regexp.MustCompile(`^\s*ship: ([0-9]+) (-?[0-9]+):(-?[0-9]+) (.+?)\s*$`)
+
+/**
+ * Substring Match Indexes:
+ * 0  Entire string
+ * 1  Alliance index
+ * 2  Leader index
+ * 3  Members (comma separated index[:rank] pairs)
+**/
+var reAllianceLead = regexp.MustCompile(`^\s*alliancelead: ([0-9]+) ([0-9]+) ([0-9:,]*)\s*$`)
+
+/**
+ * Substring Match Indexes:
+ * 0  Entire string
+ * 1  Steam ID
+**/
+var rePlayerInfo = regexp.MustCompile(`^\s*([0-9]+) .*$`)
+
+/**
+ * Substring Match Indexes:
+ * 0  Entire string
+ * 1  Number of players online
+**/
+var reOnlineCount = regexp.MustCompile(`^([0-9]+)\s*(?:/\s*[0-9]+)?$`)
+
+// Keys of the status line that reports the number of players online
+var onlineKeys = map[string]bool{"players": true, "players online": true,
+	"online players": true, "online": true}
+
+// resources converts the resource substrings of a match into a map
+func resources(m []string) map[string]int64 {
+	res := make(map[string]int64, len(resourceNames))
+	for i, name := range resourceNames {
+		res[name], _ = strconv.ParseInt(m[i], 10, 64)
+	}
+	return res
+}
+
+// ParsePlayerData parses a player line from getplayerdata
+func ParsePlayerData(line string) (ifaces.PlayerData, error) {
+	m := rePlayerData.FindStringSubmatch(line)
+	if m == nil {
+		return ifaces.PlayerData{}, &ErrBadData{Kind: "player", Line: line}
+	}
+
+	d := ifaces.PlayerData{
+		Index:     m[1],
+		Name:      m[14],
+		Resources: resources(m[6:14])}
+	d.X, _ = strconv.Atoi(m[2])
+	d.Y, _ = strconv.Atoi(m[3])
+	d.Ships, _ = strconv.Atoi(m[4])
+	d.Stations, _ = strconv.Atoi(m[5])
+	return d, nil
+}
+
+// ParseAllianceData parses an alliance line from getplayerdata
+func ParseAllianceData(line string) (ifaces.AllianceData, error) {
+	m := reAllianceData.FindStringSubmatch(line)
+	if m == nil {
+		return ifaces.AllianceData{}, &ErrBadData{Kind: "alliance", Line: line}
+	}
+
+	d := ifaces.AllianceData{
+		Index:     m[1],
+		Name:      m[12],
+		Resources: resources(m[4:12])}
+	d.Ships, _ = strconv.Atoi(m[2])
+	d.Stations, _ = strconv.Atoi(m[3])
+	return d, nil
+}
+
+// ParsePlayerDataOutput parses the complete output of getplayerdata, which
+// contains a line for each player and alliance
+func ParsePlayerDataOutput(out string) PlayerDataOutput {
+	res := PlayerDataOutput{
+		Players:   make([]ifaces.PlayerData, 0),
+		Alliances: make([]ifaces.AllianceData, 0),
+		Errors:    make([]error, 0)}
+
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+			continue
+
+		case strings.HasPrefix(strings.TrimSpace(line), "player: "):
+			if d, err := ParsePlayerData(line); err != nil {
+				res.Errors = append(res.Errors, err)
+			} else {
+				res.Players = append(res.Players, d)
+			}
+
+		case strings.HasPrefix(strings.TrimSpace(line), "alliance: "):
+			if d, err := ParseAllianceData(line); err != nil {
+				res.Errors = append(res.Errors, err)
+			} else {
+				res.Alliances = append(res.Alliances, d)
+			}
+
+		default:
+			res.Errors = append(res.Errors, &ErrBadData{Kind: "playerdata",
+				Line: line})
+		}
+	}
+
+	return res
+}
+
+// ParseShipData parses a line from getshipdata
+func ParseShipData(line string) (ShipData, error) {
+	m := reShipData.FindStringSubmatch(line)
+	if m == nil {
+		return ShipData{}, &ErrBadData{Kind: "ship", Line: line}
+	}
+
+	d := ShipData{Faction: m[1], Name: m[4]}
+	d.X, _ = strconv.Atoi(m[2])
+	d.Y, _ = strconv.Atoi(m[3])
+	return d, nil
+}
+
+// ParseAllianceLead parses a line from getalliancelead
+func ParseAllianceLead(line string) (AllianceLead, error) {
+	m := reAllianceLead.FindStringSubmatch(line)
+	if m == nil {
+		return AllianceLead{}, &ErrBadData{Kind: "alliancelead", Line: line}
+	}
+
+	d := AllianceLead{
+		Alliance: m[1],
+		Leader:   m[2],
+		Members:  make([]string, 0),
+		Ranks:    make(map[string]int),
+		Raw:      m[3]}
+
+	for _, member := range strings.Split(m[3], ",") {
+		if member == "" {
+			continue
+		}
+
+		parts := strings.SplitN(member, ":", 2)
+		d.Members = append(d.Members, parts[0])
+		if len(parts) == 2 {
+			if rank, err := strconv.Atoi(parts[1]); err == nil {
+				d.Ranks[parts[0]] = rank
+			}
+		}
+	}
+
+	return d, nil
+}
+
+// ParsePlayerInfo parses the output of `playerinfo <index> -s -o`
+func ParsePlayerInfo(out string) (PlayerInfo, error) {
+	m := rePlayerInfo.FindStringSubmatch(out)
+	if m == nil {
+		return PlayerInfo{}, &ErrBadData{Kind: "playerinfo", Line: out}
+	}
+
+	sid, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return PlayerInfo{}, &ErrBadData{Kind: "playerinfo", Line: out}
+	}
+	return PlayerInfo{SteamID: sid}, nil
+}
+
+// ParseStatus parses the output of status, dropping blank lines
+func ParseStatus(out string) Status {
+	st := Status{
+		Lines:  make([]string, 0),
+		Fields: make(map[string]string),
+		Online: -1}
+
+	// Indented lines that follow the player count name the online players
+	listing := false
+
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, "\r ")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		st.Lines = append(st.Lines, line)
+		parts := strings.SplitN(line, ":", 2)
+
+		if listing && len(parts) == 1 && strings.TrimLeft(line, " \t") != line {
+			name := strings.TrimSpace(strings.TrimPrefix(
+				strings.TrimSpace(line), "- "))
+			st.OnlinePlayers = append(st.OnlinePlayers, name)
+			continue
+		}
+		listing = false
+
+		if len(parts) == 2 {
+			key := strings.ToLower(strings.TrimSpace(parts[0]))
+			st.Fields[key] = strings.TrimSpace(parts[1])
+
+			m := reOnlineCount.FindStringSubmatch(st.Fields[key])
+			if onlineKeys[key] && m != nil && st.Online < 0 {
+				st.Online, _ = strconv.Atoi(m[1])
+				listing = true
+			}
+		}
+	}
+
+	return st
+}
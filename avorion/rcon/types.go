@@ -0,0 +1,62 @@
+package rcon
+
+import (
+	"avorioncontrol/ifaces"
+	"fmt"
+)
+
+// ErrBadData is returned when a line of RCON output doesn't match the format
+// that its command is expected to produce
+type ErrBadData struct {
+	Kind string
+	Line string
+}
+
+func (e *ErrBadData) Error() string {
+	return fmt.Sprintf("%s: failed to parse data string (%s)", e.Kind, e.Line)
+}
+
+// PlayerDataOutput is the parsed output of getplayerdata. Lines that couldn't
+// be parsed are returned as errors, so that the rest of the output is usable.
+type PlayerDataOutput struct {
+	Players   []ifaces.PlayerData
+	Alliances []ifaces.AllianceData
+	Errors    []error
+}
+
+// ShipData describes a ship reported by getshipdata
+type ShipData struct {
+	Faction string
+	X       int
+	Y       int
+	Name    string
+}
+
+// AllianceLead describes the leader and members reported by getalliancelead.
+// Members is the raw member list, as it is stored in the tracking database.
+type AllianceLead struct {
+	Alliance string
+	Leader   string
+	Members  []string
+	Ranks    map[string]int
+	Raw      string
+}
+
+// PlayerInfo describes the output of playerinfo
+type PlayerInfo struct {
+	SteamID int64
+}
+
+// Status describes the output of status. Lines in the form "Key: value" are
+// also available by their lowercased key.
+//
+// Online is the number of players that status reported as online, or -1 if it
+// didn't report them. The names of the online players are only listed in
+// OnlinePlayers when status lists them beneath the count.
+type Status struct {
+	Lines  []string
+	Fields map[string]string
+
+	Online        int
+	OnlinePlayers []string
+}
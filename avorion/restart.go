@@ -0,0 +1,229 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"sync"
+	"time"
+)
+
+const (
+	noticeRestartPending   = "The server will restart in %s"
+	noticeRestartCancelled = "The scheduled server restart has been cancelled"
+	voiceRestartPending    = "Heads up, the Avorion server restarts in %s"
+
+	noticeRestartScheduled = "**Server Notice**: The server will restart " +
+		"<t:%d:R> (`%s`). Players are being warned in-game."
+	noticeRestartSchedule = "**Server Warning**: The restart schedule `%s` is " +
+		"invalid and will be ignored: %s"
+)
+
+// restartWarnings are the points in a restart countdown at which players are
+// warned in-game
+var restartWarnings = []time.Duration{
+	30 * time.Minute,
+	15 * time.Minute,
+	10 * time.Minute,
+	5 * time.Minute,
+	time.Minute,
+	30 * time.Second}
+
+// pendingRestart is a restart that will happen once its countdown elapses. The
+// pending restart is replaced and its cancel channel closed while holding
+// s.restartlock.
+type pendingRestart struct {
+	at     time.Time
+	cancel chan struct{}
+}
+
+// restartSchedule is the next restart due from the configured restart schedule
+type restartSchedule struct {
+	lock sync.Mutex
+	next time.Time
+	spec string
+}
+
+// scheduleRestarts works out when the next restart from the configured restart
+// schedule is due, and reports schedules that can't be parsed
+func (s *Server) scheduleRestarts() {
+	s.restarts.lock.Lock()
+	defer s.restarts.lock.Unlock()
+
+	s.restarts.next = time.Time{}
+	s.restarts.spec = ""
+
+	for _, spec := range s.config.RestartSchedule() {
+		cron, err := parseCron(spec)
+		if err != nil {
+			logger.LogError(s, sprintf("Restart schedule %q: %s", spec, err.Error()))
+			s.SendLog(ifaces.ChatData{Msg: sprintf(noticeRestartSchedule, spec,
+				err.Error())})
+			continue
+		}
+
+		s.advanceRestarts(cron, s.clock.Now())
+	}
+}
+
+// advanceRestarts moves the next scheduled restart to the first time after t
+// that a schedule runs, if that is sooner. s.restarts.lock must be held.
+func (s *Server) advanceRestarts(cron *cronSchedule, t time.Time) {
+	next := cron.next(t.In(s.config.Location()))
+	if next.IsZero() {
+		return
+	}
+
+	if s.restarts.next.IsZero() || next.Before(s.restarts.next) {
+		s.restarts.next = next
+		s.restarts.spec = cron.spec
+	}
+}
+
+// nextScheduledRestart returns when the next restart from the restart schedule
+// is due
+func (s *Server) nextScheduledRestart() (time.Time, bool) {
+	s.restarts.lock.Lock()
+	defer s.restarts.lock.Unlock()
+	return s.restarts.next, !s.restarts.next.IsZero()
+}
+
+// checkRestartSchedule starts the countdown of a scheduled restart once it is
+// close enough for the first in-game warning. A restart that is already
+// pending and happens sooner takes the place of the scheduled one.
+func (s *Server) checkRestartSchedule() {
+	s.restarts.lock.Lock()
+	defer s.restarts.lock.Unlock()
+
+	if s.restarts.next.IsZero() {
+		return
+	}
+
+	at, spec := s.restarts.next, s.restarts.spec
+	left := at.Sub(s.clock.Now())
+	if left > restartWarnings[0] {
+		return
+	}
+
+	if pending, ok := s.PendingRestart(); !ok || pending.After(at) {
+		if left < 0 {
+			left = 0
+		}
+
+		logger.LogInfo(s, sprintf("Starting the countdown for the restart "+
+			"scheduled by %q", spec))
+		s.ScheduleRestart(left)
+		s.SendLog(ifaces.ChatData{Msg: sprintf(noticeRestartScheduled, at.Unix(),
+			spec)})
+	}
+
+	// The schedule resumes after the restart, but is also advanced here in
+	// case the restart doesn't happen
+	s.restarts.next = time.Time{}
+	for _, sched := range s.config.RestartSchedule() {
+		if cron, err := parseCron(sched); err == nil {
+			s.advanceRestarts(cron, at)
+		}
+	}
+}
+
+// ScheduleRestart restarts the server after the given delay, warning players
+// in-game as the restart approaches. Scheduling a restart replaces any restart
+// that is already pending.
+func (s *Server) ScheduleRestart(d time.Duration) {
+	pr := &pendingRestart{
+		at:     s.clock.Now().Add(d),
+		cancel: make(chan struct{})}
+
+	s.restartlock.Lock()
+	old := s.restart
+	s.restart = pr
+	if old != nil {
+		close(old.cancel)
+	}
+	s.restartlock.Unlock()
+
+	if old != nil {
+		logger.LogInfo(s, "Scheduled restart cancelled")
+		s.NotifyServer(noticeRestartCancelled)
+	}
+
+	logger.LogInfo(s, sprintf("Restart scheduled for %s",
+		pr.at.Format(time.RFC3339)))
+	s.NotifyServer(sprintf(noticeRestartPending, d.Round(time.Second)))
+	if d <= voiceRestartWarning {
+		s.announceVoice(ifaces.VoiceRestart, sprintf(voiceRestartPending,
+			d.Round(time.Second)))
+	}
+
+	go func() {
+		defer shutdown.Recover()
+		for _, w := range restartWarnings {
+			left := pr.at.Sub(s.clock.Now())
+			if w >= left {
+				continue
+			}
+
+			select {
+			case <-pr.cancel:
+				return
+			case <-s.clock.After(left - w):
+				s.NotifyServer(sprintf(noticeRestartPending, w))
+				if w == voiceRestartWarning {
+					s.announceVoice(ifaces.VoiceRestart, sprintf(
+						voiceRestartPending, w))
+				}
+			}
+		}
+
+		select {
+		case <-pr.cancel:
+			return
+		case <-s.clock.After(pr.at.Sub(s.clock.Now())):
+		}
+
+		// A cancel can land after the countdown has finished, so the restart
+		// only goes ahead if it is still the pending one
+		s.restartlock.Lock()
+		if s.restart != pr {
+			s.restartlock.Unlock()
+			return
+		}
+		s.restart = nil
+		s.restartlock.Unlock()
+
+		if err := s.Restart(); err != nil {
+			logger.LogError(s, "Scheduled restart: "+err.Error())
+		}
+	}()
+}
+
+// CancelRestart cancels a pending restart, and returns whether or not there was
+// one to cancel
+func (s *Server) CancelRestart() bool {
+	s.restartlock.Lock()
+	pr := s.restart
+	if pr == nil {
+		s.restartlock.Unlock()
+		return false
+	}
+
+	s.restart = nil
+	close(pr.cancel)
+	s.restartlock.Unlock()
+
+	logger.LogInfo(s, "Scheduled restart cancelled")
+	s.NotifyServer(noticeRestartCancelled)
+	return true
+}
+
+// PendingRestart returns the time at which a scheduled restart will happen, and
+// whether or not one is pending
+func (s *Server) PendingRestart() (time.Time, bool) {
+	s.restartlock.Lock()
+	defer s.restartlock.Unlock()
+	if pr := s.restart; pr != nil {
+		return pr.at, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,42 @@
+package avorion
+
+import (
+	"avorioncontrol/configuration"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentRestartCancel(t *testing.T) {
+	s := &Server{config: configuration.New(), clock: hostClock{}}
+
+	for i := 0; i < 20; i++ {
+		s.ScheduleRestart(time.Hour)
+
+		var (
+			wg        sync.WaitGroup
+			mutex     sync.Mutex
+			cancelled int
+		)
+
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if s.CancelRestart() {
+					mutex.Lock()
+					cancelled++
+					mutex.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if cancelled != 1 {
+			t.Fatalf("expected one cancel to succeed, %d did", cancelled)
+		}
+		if _, ok := s.PendingRestart(); ok {
+			t.Fatal("a restart is still pending after being cancelled")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Players are greeted once they've had time to finish loading into the game
+const greetingDelay = 30 * time.Second
+
+// playerSeen records that a player was seen online. When a player logs in after
+// being away for longer than the configured absence, they are greeted in-game,
+// and their return is announced in the chat channel if a message is configured.
+func (s *Server) playerSeen(p *Player, login bool) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return
+	}
+
+	now := s.clock.Now()
+	last, err := tracking.LastSeen(p.index)
+	if err != nil {
+		logger.LogError(p, "LastSeen: "+err.Error())
+	}
+
+	tracking.SetLastSeen(p.index, now)
+
+	absence := s.config.ReturningPlayerAbsence()
+	if !login || err != nil || last.IsZero() || absence <= 0 ||
+		now.Sub(last) < absence {
+		return
+	}
+
+	days := int64(now.Sub(last) / (24 * time.Hour))
+	greeting, welcome := s.config.ReturningPlayerMessages()
+	logger.LogInfo(p, sprintf("Returned after %d days away", days))
+
+	if welcome != "" {
+		s.SendChat(ifaces.ChatData{Name: "Welcome",
+			Msg: fillReturning(welcome, p.Name(), days)})
+	}
+
+	if greeting != "" {
+		go func() {
+			defer shutdown.Recover()
+			<-s.clock.After(greetingDelay)
+			if p.Online() {
+				p.Message(fillReturning(greeting, p.Name(), days))
+			}
+		}()
+	}
+}
+
+// fillReturning fills in the placeholders of a returning player message
+func fillReturning(tmpl, name string, days int64) string {
+	return strings.NewReplacer(
+		"{name}", name,
+		"{days}", strconv.FormatInt(days, 10)).Replace(tmpl)
+}
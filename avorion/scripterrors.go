@@ -0,0 +1,121 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	scriptErrorTop    = 5
+	scriptErrorMaxLen = 150
+
+	warnScriptError    = `script error at %s: %s`
+	noticeScriptErrors = "**Script Errors**: %d errors (%d unique) in the last %s\n%s"
+)
+
+// scriptErrorValues matches the parts of an error message that vary between
+// occurrences of the same error, such as addresses, indexes, and ids
+var scriptErrorValues = regexp.MustCompile(`0x[0-9a-fA-F]+|[0-9]+`)
+
+// scriptError is a single deduplicated script error
+type scriptError struct {
+	location string
+	message  string
+	count    int
+}
+
+// scriptErrorLog aggregates the script errors reported by the server between
+// summaries. It is written to by the output supervisor and read by the status
+// supervisor, so access is guarded by a mutex.
+type scriptErrorLog struct {
+	sync.Mutex
+	errors map[string]*scriptError
+	since  time.Time
+}
+
+func newScriptErrorLog() *scriptErrorLog {
+	return &scriptErrorLog{
+		errors: make(map[string]*scriptError),
+		since:  time.Now()}
+}
+
+// RecordScriptError records a Lua error raised at the given script location.
+// Errors are deduplicated by their location and message, ignoring any numeric
+// values in the message.
+func (s *Server) RecordScriptError(location, message string) {
+	sig := location + ": " + scriptErrorValues.ReplaceAllString(message, "#")
+
+	s.scripterrors.Lock()
+	defer s.scripterrors.Unlock()
+
+	if e, ok := s.scripterrors.errors[sig]; ok {
+		e.count++
+		return
+	}
+
+	// Only the first occurrence of an error is logged, repeats are counted
+	logger.LogWarning(s, sprintf(warnScriptError, location, message))
+	s.scripterrors.errors[sig] = &scriptError{
+		location: location,
+		message:  message,
+		count:    1}
+}
+
+// summarizeScriptErrors posts the most frequent script errors since the last
+// summary to the log channel once the configured interval has passed, and then
+// resets the counts.
+func (s *Server) summarizeScriptErrors() {
+	interval := s.config.ErrorSummaryDuration()
+	if interval < time.Second {
+		return
+	}
+
+	s.scripterrors.Lock()
+	defer s.scripterrors.Unlock()
+
+	elapsed := time.Since(s.scripterrors.since)
+	if elapsed < interval {
+		return
+	}
+
+	errs := make([]*scriptError, 0, len(s.scripterrors.errors))
+	total := 0
+	for _, e := range s.scripterrors.errors {
+		errs = append(errs, e)
+		total += e.count
+	}
+
+	s.scripterrors.errors = make(map[string]*scriptError)
+	s.scripterrors.since = time.Now()
+
+	if len(errs) == 0 {
+		return
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].count > errs[j].count
+	})
+
+	lines := make([]string, 0)
+	for i, e := range errs {
+		if i == scriptErrorTop {
+			break
+		}
+
+		msg := e.message
+		if len(msg) > scriptErrorMaxLen {
+			msg = msg[:scriptErrorMaxLen] + "..."
+		}
+
+		lines = append(lines, sprintf("> **%dx** `%s`\n> _%s_", e.count,
+			e.location, msg))
+	}
+
+	s.SendLog(ifaces.ChatData{Msg: sprintf(noticeScriptErrors, total, len(errs),
+		elapsed.Round(time.Minute), strings.Join(lines, "\n"))})
+}
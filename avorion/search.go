@@ -0,0 +1,239 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// searchLimit is the most results that are returned for each kind of match
+const searchLimit = 10
+
+// fuzzyScore rates how well a name matches a search term, from 0 for no match
+// to 100 for an exact match. Close misspellings still match, with a low score.
+func fuzzyScore(term, name string) int {
+	term, name = strings.ToLower(term), strings.ToLower(name)
+
+	switch {
+	case term == "" || name == "":
+		return 0
+	case name == term:
+		return 100
+	case strings.HasPrefix(name, term):
+		return 80
+	}
+
+	for _, word := range strings.Fields(name) {
+		if strings.HasPrefix(word, term) {
+			return 70
+		}
+	}
+
+	if strings.Contains(name, term) {
+		return 60
+	}
+
+	// Every letter of the term appears in order, such as "drgn" for "Dragon"
+	if utf8.RuneCountInString(term) >= 3 && subsequence(term, name) {
+		return 40
+	}
+
+	// Allow roughly one typo for every four letters
+	allowed := utf8.RuneCountInString(term) / 4
+	if allowed < 1 {
+		allowed = 1
+	}
+
+	if d := levenshtein(term, name); d <= allowed {
+		return 30 - d
+	}
+
+	return 0
+}
+
+// subsequence returns whether or not every rune of term appears in s in order
+func subsequence(term, s string) bool {
+	rs := []rune(s)
+	i := 0
+	for _, r := range term {
+		for i < len(rs) && rs[i] != r {
+			i++
+		}
+		if i == len(rs) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// levenshtein returns the number of single rune edits between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = prev[j] + 1
+			if v := cur[j-1] + 1; v < cur[j] {
+				cur[j] = v
+			}
+			if v := prev[j-1] + cost; v < cur[j] {
+				cur[j] = v
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// bestResults sorts results by score and name, and keeps the best of them
+func bestResults(results []ifaces.SearchResult) []ifaces.SearchResult {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) > searchLimit {
+		results = results[:searchLimit]
+	}
+	return results
+}
+
+/**********************************/
+/* IFace ifaces.ISearchableServer */
+/**********************************/
+
+// Search looks for players, alliances, ships, and sectors with names that
+// match a term. Sectors are matched by the region or faction that they belong
+// to. The results are grouped by kind, best match first. Ships owned by
+// players that visible rejects are left out, since they give away where those
+// players have been.
+func (s *Server) Search(term string,
+	visible func(ifaces.IPlayer) bool) ([]ifaces.SearchResult, error) {
+	var (
+		players   = make([]ifaces.SearchResult, 0)
+		alliances = make([]ifaces.SearchResult, 0)
+		ships     = make([]ifaces.SearchResult, 0)
+		sectors   = make([]ifaces.SearchResult, 0)
+	)
+
+	for _, p := range s.players {
+		if score := fuzzyScore(term, p.Name()); score > 0 {
+			players = append(players, ifaces.SearchResult{
+				Kind: ifaces.SearchPlayer, Name: p.Name(), Ref: p.Index(),
+				Score: score})
+		}
+	}
+
+	for _, a := range s.alliances {
+		if score := fuzzyScore(term, a.Name()); score > 0 {
+			alliances = append(alliances, ifaces.SearchResult{
+				Kind: ifaces.SearchAlliance, Name: a.Name(), Ref: a.Index(),
+				Score: score})
+		}
+	}
+
+	tracking := s.trackingDB()
+	// Ships are only kept in the tracking database, which can only match
+	// part of a name
+	if tracking != nil {
+		records, err := tracking.FindShips(term, 100)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range records {
+			if p := s.Player(r.OwnerIndex); p != nil && visible != nil &&
+				!visible(p) {
+				continue
+			}
+
+			ships = append(ships, ifaces.SearchResult{
+				Kind: ifaces.SearchShip, Name: r.Name, Ref: r.OwnerIndex,
+				Detail: sprintf("%s, last seen in %d:%d", r.Owner, r.X, r.Y),
+				Score:  fuzzyScore(term, r.Name)})
+		}
+	}
+
+	for _, r := range s.config.ContestedRegions() {
+		if score := fuzzyScore(term, r.Name); score > 0 {
+			cx, cy := (r.MinX+r.MaxX)/2, (r.MinY+r.MaxY)/2
+			sectors = append(sectors, ifaces.SearchResult{
+				Kind: ifaces.SearchSector, Name: r.Name,
+				Ref: sprintf("%d:%d", cx, cy),
+				Detail: sprintf("contested region from %d:%d to %d:%d", r.MinX,
+					r.MinY, r.MaxX, r.MaxY),
+				Score: score})
+		}
+	}
+
+	// Group the sectors of each faction, and point at the one with the most
+	// recorded jumps
+	var (
+		scores   = make(map[string]int)
+		factions = make(map[string]*ifaces.SearchResult)
+		counts   = make(map[string]int)
+		busiest  = make(map[string]int)
+	)
+
+	for _, col := range s.sectors {
+		for _, sec := range col {
+			name := sec.FactionName
+			if name == "" {
+				continue
+			}
+
+			score, ok := scores[name]
+			if !ok {
+				score = fuzzyScore(term, name)
+				scores[name] = score
+			}
+			if score == 0 {
+				continue
+			}
+
+			f, ok := factions[name]
+			if !ok {
+				f = &ifaces.SearchResult{Kind: ifaces.SearchSector, Name: name,
+					Score: score}
+				factions[name] = f
+				busiest[name] = -1
+			}
+
+			counts[name]++
+			if len(sec.Jumphistory) > busiest[name] {
+				busiest[name] = len(sec.Jumphistory)
+				f.Ref = sprintf("%d:%d", sec.X, sec.Y)
+			}
+		}
+	}
+
+	for name, f := range factions {
+		f.Detail = sprintf("faction controlling %d sectors", counts[name])
+		sectors = append(sectors, *f)
+	}
+
+	results := make([]ifaces.SearchResult, 0)
+	for _, group := range [][]ifaces.SearchResult{players, alliances, ships,
+		sectors} {
+		results = append(results, bestResults(group)...)
+	}
+
+	return results, nil
+}
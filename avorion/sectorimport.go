@@ -0,0 +1,83 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// Avorion names the files of a sector after its coordinates, such as
+// "-12_34", with a suffix for the different kinds of sector data
+var reSectorFile = regexp.MustCompile(`^(-?\d+)_(-?\d+)`)
+
+// importSectors tracks every sector that the galaxy has saved to disk, so
+// that sector counts and activity cover the whole galaxy from the start
+// instead of only the sectors that have been seen in jumps. It returns the
+// number of sectors that weren't being tracked yet.
+func (s *Server) importSectors() (int, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return 0, nil
+	}
+
+	dir := s.datapath + "/" + s.name + "/sectors"
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if s.sectors == nil {
+		s.sectors = make(map[int]map[int]*ifaces.Sector, 0)
+	}
+
+	found := make([]*ifaces.Sector, 0)
+	for _, f := range files {
+		m := reSectorFile.FindStringSubmatch(f.Name())
+		if f.IsDir() || m == nil {
+			continue
+		}
+
+		x, errx := strconv.Atoi(m[1])
+		y, erry := strconv.Atoi(m[2])
+		if errx != nil || erry != nil {
+			continue
+		}
+
+		if _, ok := s.sectors[x]; !ok {
+			s.sectors[x] = make(map[int]*ifaces.Sector, 0)
+		}
+
+		// Each sector has several files, so only the first one counts
+		if _, ok := s.sectors[x][y]; ok {
+			continue
+		}
+
+		sec := &ifaces.Sector{
+			X: x, Y: y, Jumphistory: make([]*ifaces.JumpInfo, 0)}
+		s.sectors[x][y] = sec
+		found = append(found, sec)
+	}
+
+	if len(found) == 0 {
+		return 0, nil
+	}
+
+	if err := tracking.TrackSectors(found); err != nil {
+		// Leave the sectors to be tracked when they're seen in a jump instead
+		for _, sec := range found {
+			delete(s.sectors[sec.X], sec.Y)
+		}
+		return 0, err
+	}
+
+	s.cache.update(func(c *statusSnapshot) { c.sectorcount += len(found) })
+	logger.LogInfo(s, sprintf("Imported %d sectors from the galaxy files",
+		len(found)))
+	return len(found), nil
+}
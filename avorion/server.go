@@ -3,16 +3,16 @@ package avorion
 import (
 	gamedb "avorioncontrol/avorion/database"
 	"avorioncontrol/avorion/events"
+	"avorioncontrol/avorion/rcon"
 	"avorioncontrol/discord"
 	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
-	"os/exec"
 	"regexp"
 	"runtime"
 	"sort"
@@ -34,45 +34,58 @@ const (
 	errFailedRCON      = `failed to run RCON command (%s)`
 	errFailToGetData   = `failed to acquire data for %s (%s)`
 
-	warnChatDiscarded = `discarded chat message (time: >5 seconds)`
-	warnGameLagging   = `Avorion is lagging, performing restart`
+	warnChatDiscarded   = `discarded chat message (time: >5 seconds)`
+	warnGameLagging     = `Avorion is lagging, performing restart`
+	warnIdentityChanged = `galaxy %s changed from [%s] to [%s]`
+
+	noticeIdentityChanged = "**Server Warning**: The galaxy %s has changed " +
+		"since the last start!\n**Previous:** `%s`\n**Current:** `%s`\n" +
+		"_Please confirm that the datapath and galaxy name are correct._"
+	noticeLeaderChanged = "**Alliance Notice**: Leadership of `%s` changed\n" +
+		"**Previous:** `%s`\n**Current:** `%s`"
+
+	dbInfoSeed    = `seed`
+	dbInfoVersion = `version`
 
 	noticeDBUpate       = `Updating player data DB. Potential lag incoming.`
 	regexIntegration    = `^([0-9]+):([0-9]{10})$`
 	rconPlayerDiscord   = `linkdiscordacct %s %s`
+	rconPlayerUnlink    = `unlinkdiscordacct %s`
 	rconGetPlayerData   = `getplayerdata -p %s`
 	rconGetAllianceData = `getplayerdata -a %s`
 	rconGetAllData      = `getplayerdata`
+	rconBotStatus       = `botstatus %s`
+	rconPlayerMessage   = `messageplayer %s "%s"`
+	rconGetShipData     = `getshipdata`
+	rconGetAllianceLead = `getalliancelead %s`
+
+	botStatusOnline     = "online"
+	botStatusStopping   = "stopping"
+	botStatusRestarting = "restarting"
+
+	// Restarts are refused this soon after the server finished starting
+	restartGrace = 10 * time.Second
+
+	// Number of records between player database refresh progress reports
+	playerDBProgressStep = 25
+
+	// Number of jumps loaded from the database at a time on startup, and the
+	// number of jumps kept in memory for each player and alliance
+	sectorLoadBatch  = 5000
+	jumpHistoryLimit = 1000
 )
 
 var (
 	sprintf          = fmt.Sprintf
 	regexpDiscordPin = regexp.MustCompile(regexIntegration)
 
-	state    RunState
-	cmdmutex *sync.Mutex
+	state    *lifecycle
+	cmdqueue *commandQueue
 )
 
 func init() {
-	state = RunState{
-		mutex: new(sync.Mutex),
-		last:  time.Now()}
-	cmdmutex = new(sync.Mutex)
-}
-
-// RunState describes the current state of commands being run
-type RunState struct {
-	mutex *sync.Mutex
-	state bool
-
-	//TODO: Migrate all of these into a singular int at
-	isrestarting bool
-	isstopping   bool
-	isstarting   bool
-	iscrashed    bool
-
-	// Track the last time the server was started
-	last time.Time
+	state = newLifecycle()
+	cmdqueue = newCommandQueue()
 }
 
 // Server - Avorion server definition
@@ -80,7 +93,7 @@ type Server struct {
 	ifaces.IGameServer
 
 	// Execution variables
-	Cmd        *exec.Cmd
+	Cmd        Process
 	executable string
 	name       string
 	admin      string
@@ -107,14 +120,41 @@ type Server struct {
 	alliances []*Alliance
 	sectors   map[int]map[int]*ifaces.Sector
 	tracking  *gamedb.TrackingDB
+	anomalies map[string]time.Time
+	offenders map[string]*chatOffender
+	lookups   *lookupCoalescer
+	bus       ifaces.IEventBus
+
+	scripterrors *scriptErrorLog
+	querydrift   int
+	queryalert   bool
+
+	// Discord roles of guild members, used for idle kick exemptions
+	memberroles map[string][]string
+	rolelock    sync.Mutex
+
+	// Held while the player database is being refreshed
+	dblock sync.Mutex
+
+	// Held while the tracking database is replaced, see trackingDB
+	trackinglock sync.RWMutex
+
+	lastrecord time.Time
+
+	// Scheduled actions
+	nextreconcile   time.Time
+	restart         *pendingRestart
+	restartlock     sync.Mutex
+	restarts        restartSchedule
+	maintenance     *maintenanceWindow
+
+	// Restart vote called by players in-game
+	vote     *restartVote
+	lastvote time.Time
+	votelock sync.Mutex
 
 	// Cached values so we don't run loops constantly
-	onlineplayers     string
-	statusoutput      string
-	onlineplayercount int
-	playercount       int
-	alliancecount     int
-	sectorcount       int
+	cache statusCache
 
 	// Config
 	configfile string
@@ -122,15 +162,45 @@ type Server struct {
 
 	// Game information
 	password string
-	version  string
 	seed     string
 	motd     string
 	time     string
 
+	// When the binary on disk is next checked for a different version
+	nextversioncheck time.Time
+
+	// Versions of the Workshop mods that were loaded
+	modupdates workshopUpdates
+
 	// Discord
 	bot      *discord.Bot
 	requests map[string]string
 
+	// Alliance alert channels and sector claims
+	alliancealerts allianceAlerts
+
+	// Serializes the changes that are stored in the server.ini history
+	inilock sync.Mutex
+
+	// Galaxy backups
+	backup backupManager
+
+	// Actions scheduled to run once
+	oneshot oneShotActions
+
+	// Integrations of Discord users that are no longer members, by player
+	orphans    map[string]ifaces.OrphanedIntegration
+	orphanlock sync.Mutex
+
+	// Failed integration pins by Discord user
+	pinattempts map[string]*pinAttempts
+	pinlock     sync.Mutex
+
+	// Host system access
+	exec  Executor
+	fs    Filesystem
+	clock Clock
+
 	// Close goroutines
 	close chan struct{}
 	exit  chan struct{}
@@ -142,44 +212,69 @@ type Server struct {
 /********/
 
 // New returns a new object of type Server
-func New(c ifaces.IConfigurator, wg *sync.WaitGroup, exit chan struct{},
-	args ...string) ifaces.IGameServer {
-
-	path := c.InstallPath()
-	cmnd := "AvorionServer.exe"
-	if runtime.GOOS != "windows" {
-		cmnd = "AvorionServer"
-	}
+func New(c ifaces.IConfigurator, bus ifaces.IEventBus, wg *sync.WaitGroup,
+	exit chan struct{}, args ...string) ifaces.IGameServer {
+	return newServer(c, bus, wg, exit, hostExecutor{})
+}
 
-	version, err := exec.Command(path+"/bin/"+cmnd,
-		"--version").Output()
-	if err != nil {
-		log.Fatal(sprintf(errExecFailed, path, cmnd))
-	}
+// newServer returns a Server that runs its commands with the given Executor
+func newServer(c ifaces.IConfigurator, bus ifaces.IEventBus, wg *sync.WaitGroup,
+	exit chan struct{}, e Executor) *Server {
 
-	_, err = exec.Command(c.RCONBin(), "-h").Output()
-	if err != nil {
-		log.Fatal(sprintf(`Failed to run %s`, c.RCONBin()))
-	}
+	path := c.InstallPath()
+	cmnd := gameExecutable(c.WrapperCommand())
 
 	s := &Server{
 		wg:         wg,
 		exit:       exit,
 		uuid:       logUUID,
 		config:     c,
+		bus:        bus,
 		serverpath: strings.TrimSuffix(path, "/"),
 		executable: cmnd,
 
-		version:  string(version),
-		rconpass: c.RCONPass(),
-		rconaddr: c.RCONAddr(),
-		rconport: c.RCONPort(),
-		requests: make(map[string]string)}
+		rconpass:  c.RCONPass(),
+		rconaddr:  c.RCONAddr(),
+		rconport:  c.RCONPort(),
+		requests:  make(map[string]string),
+		anomalies: make(map[string]time.Time),
+		offenders: make(map[string]*chatOffender),
+		lookups:   newLookupCoalescer(),
+
+		scripterrors: newScriptErrorLog(),
+		pinattempts:  make(map[string]*pinAttempts),
+
+		exec:  e,
+		fs:    hostFilesystem{},
+		clock: hostClock{}}
 
 	s.SetLoglevel(s.config.Loglevel())
+
+	version, err := preflight(s, s.exec, preflightTimeout, path+"/bin/"+cmnd,
+		"--version")
+	if err != nil {
+		shutdown.Fatal(sprintf(errExecFailed, path, cmnd))
+	}
+
+	if _, err = preflight(s, s.exec, preflightTimeout, c.RCONBin(), "-h"); err != nil {
+		shutdown.Fatal(sprintf(`Failed to run %s`, c.RCONBin()))
+	}
+
+	s.cache.update(func(c *statusSnapshot) { c.version = version })
 	return s
 }
 
+// announceStatus notifies server-side mods of a bot lifecycle transition so that
+// they can prepare for (or recover from) a controlled shutdown
+func (s *Server) announceStatus(status string) {
+	_, err := s.RunCommandPriority(sprintf(rconBotStatus, status),
+		ifaces.CommandPriorityHealth)
+	if err != nil {
+		logger.LogWarning(s, sprintf("Failed to announce bot status %s: %s",
+			status, err.Error()))
+	}
+}
+
 // NotifyServer sends an ingame notification
 func (s *Server) NotifyServer(in string) error {
 	cmd := sprintf("say [NOTIFICATION] %s", in)
@@ -194,16 +289,14 @@ func (s *Server) NotifyServer(in string) error {
 // Start starts the Avorion server process
 func (s *Server) Start(sendchat bool) error {
 	logger.LogDebug(s, "Start() was called")
-	state.mutex.Lock()
-	state.isstarting = true
-	logger.LogDebug(s, "Start() is locking Avorion command state")
-
-	defer func() {
-		state.isstarting = false
-		state.mutex.Unlock()
-		logger.LogDebug(s, "Unlocked Avorion state from Start()")
-	}()
+	return state.run(s, lifecycleStarting, false, func() error {
+		return s.start(sendchat)
+	})
+}
 
+// start launches the Avorion process and waits for it to come online. It must
+// only be run as part of a lifecycle operation.
+func (s *Server) start(sendchat bool) error {
 	var (
 		sectors []*ifaces.Sector
 		err     error
@@ -225,93 +318,117 @@ func (s *Server) Start(sendchat bool) error {
 	// Make sure we are on a fresh server
 	s.players = make([]*Player, 0)
 	s.sectors = make(map[int]map[int]*ifaces.Sector, 0)
-	s.onlineplayercount = 0
-	s.statusoutput = ""
+	s.cache.update(func(c *statusSnapshot) {
+		c.onlineplayercount = 0
+		c.statusoutput = ""
+		c.portfailure = ""
+	})
 
 	s.InitializeEvents()
 
 	logger.LogInit(s, "Beginning Avorion startup sequence")
 
 	s.name = s.config.Galaxy()
+	s.cache.update(func(c *statusSnapshot) { c.name = s.name })
 	s.datapath = strings.TrimSuffix(s.config.DataPath(), "/")
 	galaxydir := s.datapath + "/" + s.name
 
-	if _, err := os.Stat(galaxydir); os.IsNotExist(err) {
-		err := os.Mkdir(galaxydir, 0700)
+	if _, err := s.fs.Stat(galaxydir); os.IsNotExist(err) {
+		err := s.fs.Mkdir(galaxydir, 0700)
 		if err != nil {
 			logger.LogError(s, "os.Mkdir: "+err.Error())
 		}
 	}
 
+	// Avorion won't be able to bind its ports if a previous instance is still
+	// running the galaxy, so give the admins a chance to deal with it first
+	if err := s.awaitOrphan(); err != nil {
+		return err
+	}
+
+	s.resetEventFile()
+	s.refreshRunningVersion()
+	s.downloadMods()
+	s.refreshModVersions()
+
 	if err := s.config.BuildModConfig(); err != nil {
 		return errors.New("Failed to generate modconfig.lua file")
 	}
 
-	s.tracking, err = gamedb.New(sprintf("%s/%s",
-		s.config.DataPath(),
-		s.config.DBName()))
-	if err != nil {
-		return err
-	}
+	// The tracking database keeps its handle open, so release the handle from
+	// any previous run before opening a new one
+	s.swapTracking(nil)
 
-	sectors, err = s.tracking.Init()
+	sectors, err = s.openTracking()
 	if err != nil {
-		return errors.New("GameDB: " + err.Error())
+		return err
 	}
 
-	s.sectorcount = 0
 	for _, sec := range sectors {
 		if _, ok := s.sectors[sec.X]; !ok {
 			s.sectors[sec.X] = make(map[int]*ifaces.Sector, 0)
 		}
 		s.sectors[sec.X][sec.Y] = sec
-		s.sectorcount++
+	}
+	s.cache.update(func(c *statusSnapshot) { c.sectorcount = len(sectors) })
+
+	if _, err := s.importSectors(); err != nil {
+		logger.LogError(s, "Failed to import sectors: "+err.Error())
 	}
 
-	s.tracking.SetLoglevel(s.loglevel)
+	s.loadAllianceAlerts()
+	s.loadOneShotActions()
 
-	s.Cmd = exec.Command(
-		s.serverpath+"/bin/"+s.executable,
+	// The wrapper command may have changed since the last start
+	s.executable = gameExecutable(s.config.WrapperCommand())
+	bin, args := s.gameCommand(s.serverpath+"/bin/"+s.executable,
 		"--galaxy-name", s.name,
 		"--datapath", s.datapath,
 		"--admin", s.admin,
 		"--rcon-ip", s.config.RCONAddr(),
 		"--rcon-password", s.config.RCONPass(),
-		"--rcon-port", fmt.Sprint(s.config.RCONPort()))
+		"--rcon-port", fmt.Sprint(s.config.RCONPort()),
+		"--port", fmt.Sprint(s.config.GamePort()))
 
-	s.Cmd.Dir = s.serverpath
-	s.Cmd.Env = append(os.Environ(),
-		"LD_LIBRARY_PATH="+s.serverpath+"/linux64")
+	// Set the STDOUT pipe, so that we can reuse that as needed later
+	outr, outw := io.Pipe()
+	s.stdout = outr
 
+	opts := ProcessOptions{Dir: s.serverpath, Env: s.gameEnv(), Output: outw}
 	if runtime.GOOS != "windows" {
 		// This prevents ctrl+c from killing the child process as well as the parent
 		// on *Nix systems (not an issue on Windows). Unneeded when running as a unit.
 		// https://rosettacode.org/wiki/Check_output_device_is_a_terminal#Go
-		if terminal.IsTerminal(int(os.Stdout.Fd())) {
-			s.Cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true, Pgid: 0}
-		}
+		opts.Group = terminal.IsTerminal(int(os.Stdout.Fd()))
 	}
 
+	s.Cmd = s.exec.Command(context.Background(), opts, bin, args...)
+
 	// Doing this prevents errors, but is a stub
 	logger.LogDebug(s, "Getting Stdin Pipe")
 	if s.stdin, err = s.Cmd.StdinPipe(); err != nil {
 		return err
 	}
 
-	// Set the STDOUT pipe, so that we can reuse that as needed later
-	outr, outw := io.Pipe()
-	s.Cmd.Stderr = outw
-	s.Cmd.Stdout = outw
-	s.stdout = outr
-
 	// Make our intercom channels
 	ready := make(chan struct{})  // Avorion is fully up
 	s.close = make(chan struct{}) // Close all goroutines
 
-	go superviseAvorionOut(s, ready, s.close)
-	go updateAvorionStatus(s, s.close)
+	// Both supervisors are restarted if they panic, since the game blocks when
+	// nothing is reading its output
+	closech := s.close
+	go s.supervise("output supervisor", closech, func() {
+		superviseAvorionOut(s, ready, closech)
+	})
+	go s.supervise("status supervisor", closech, func() {
+		updateAvorionStatus(s, closech)
+	})
+
+	// The galaxy can't be changed by a config reload while the game is using it
+	s.config.LockGalaxy(true)
 
 	go func() {
+		defer shutdown.Recover()
 		defer func() {
 			downstring := strings.TrimSpace(s.config.PostDownCommand())
 
@@ -328,8 +445,8 @@ func (s *Server) Start(sendchat bool) error {
 				defer downcancel()
 
 				// Set the environment
-				postdown := exec.CommandContext(ctx, c[0], c[1:]...)
-				postdown.Env = append(os.Environ(), "SAVEPATH="+s.datapath+"/"+s.name)
+				postdown := s.exec.Command(ctx, ProcessOptions{Env: append(os.Environ(),
+					"SAVEPATH="+s.datapath+"/"+s.name)}, c[0], c[1:]...)
 
 				// Get the output of the PostDown command
 				ret, err := postdown.CombinedOutput()
@@ -350,41 +467,64 @@ func (s *Server) Start(sendchat bool) error {
 		if err := s.Cmd.Start(); err != nil {
 			logger.LogError(s, err.Error())
 		}
+		s.writePidFile()
 
 		logger.LogInit(s, "Started Server and waiting till ready")
 		s.Cmd.Wait()
+		pe, _ := s.Cmd.Exit()
 		logger.LogWarning(s, sprintf("Avorion exited with status code (%d)",
-			s.Cmd.ProcessState.ExitCode()))
-		code := s.Cmd.ProcessState.ExitCode()
-		if code != 0 {
+			pe.Code))
+		s.removePidFile()
+		s.config.LockGalaxy(false)
+		exit := s.classifyExit(s.Cmd.Pid(), pe)
+		s.recordExit(exit)
+		if exit.Code != 0 {
 			s.Crashed()
-			s.SendLog(ifaces.ChatData{Msg: sprintf(
-				"**Server Error**: Avorion has exited with non-zero status code: `%d`",
-				code)})
+			s.SendLog(ifaces.ChatData{Thread: "Server crash", Msg: sprintf(
+				"**Server Error**: Avorion has exited with %s\n_%s_",
+				exitSummary(exit), exit.Explanation)})
 		}
 		close(s.close)
 	}()
 
 	select {
 	case <-ready:
-		state.iscrashed = false
+		state.setCrashed(false)
 		logger.LogInit(s, "Server is online")
 		s.config.LoadGameConfig()
+		s.checkGalaxyIdentity()
+		s.checkConfigDrift()
+		s.announceStatus(botStatusOnline)
+		s.announceEventFile()
+		go s.supervise("steam ID resolver", closech, func() {
+			s.resolveSteamIDs(closech)
+		})
+		go s.supervise("ban supervisor", closech, func() {
+			s.liftBans(closech)
+		})
 
 		// Temporary hack to address a case wherein the playerdata loading occurs too
 		// quickly in the games initial startup.
 		go func() {
-			<-time.After(time.Second * 90)
+			defer shutdown.Recover()
+			<-s.clock.After(time.Second * 90)
 			s.UpdatePlayerDatabase(false)
 		}()
 
 		s.loadSectors()
 
+		// The watcher adds jumps to the histories that loadSectors fills, so it
+		// only starts once they have been loaded
+		go s.supervise("event file watcher", closech, func() {
+			s.watchEventFile(closech)
+		})
+
 		// If we have a Post-Up command configured, start that script in a goroutine.
 		// We start it there, so that in the event that the script is intende to
 		// stay online, it won't block the bot from continuing.
 		if upstring := strings.TrimSpace(s.config.PostUpCommand()); upstring != "" {
 			go func() {
+				defer shutdown.Recover()
 				ctx, cancel := context.WithCancel(context.Background())
 				defer cancel()
 
@@ -395,17 +535,16 @@ func (s *Server) Start(sendchat bool) error {
 					c = append(c, m[0])
 				}
 
-				postup := exec.CommandContext(ctx, c[0], c[1:]...)
-				postup.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-				postup.Env = append(os.Environ(),
-					"SAVEPATH="+s.datapath+"/"+s.name,
-					"RCONADDR="+s.rconaddr,
-					"RCONPASS="+s.rconpass,
-					sprintf("RCONPORT=%d", s.rconport))
-
 				// Merge output with AvorionServer. This allows the bot to filter this
 				// output along with Avorions without any extra code
-				postup.Stdout = outw
+				postup := s.exec.Command(ctx, ProcessOptions{
+					Env: append(os.Environ(),
+						"SAVEPATH="+s.datapath+"/"+s.name,
+						"RCONADDR="+s.rconaddr,
+						"RCONPASS="+s.rconpass,
+						sprintf("RCONPORT=%d", s.rconport)),
+					Output: outw,
+					Group:  true}, c[0], c[1:]...)
 
 				logger.LogInit(s, "Starting PostUp: "+upstring)
 				if err := postup.Start(); err != nil {
@@ -417,15 +556,16 @@ func (s *Server) Start(sendchat bool) error {
 				}
 
 				defer func() {
-					if postup.ProcessState == nil && postup.Process != nil {
+					if _, exited := postup.Exit(); !exited && postup.Pid() != 0 {
 						s.wg.Add(1)
 						defer s.wg.Done()
-						syscall.Kill(-postup.Process.Pid, syscall.SIGTERM)
+						syscall.Kill(-postup.Pid(), syscall.SIGTERM)
 
 						fin := make(chan struct{})
 						logger.LogInfo(s, "Waiting for PostUp to stop")
 
 						go func() {
+							defer shutdown.Recover()
 							postup.Wait()
 							close(fin)
 						}()
@@ -434,9 +574,9 @@ func (s *Server) Start(sendchat bool) error {
 						case <-fin:
 							logger.LogInfo(s, "PostUp command stopped")
 							return
-						case <-time.After(time.Minute):
+						case <-s.clock.After(time.Minute):
 							logger.LogError(s, "Sending kill to PostUp")
-							syscall.Kill(-postup.Process.Pid, syscall.SIGKILL)
+							syscall.Kill(-postup.Pid(), syscall.SIGKILL)
 							return
 						}
 					}
@@ -452,17 +592,18 @@ func (s *Server) Start(sendchat bool) error {
 			}()
 		}
 
-		state.last = time.Now()
+		s.scheduleBackup()
+		s.scheduleRestarts()
+		state.started(s.clock.Now())
 		return nil
 
 	case <-s.close:
 		close(ready)
-		state.isstarting = false
 		return errors.New("avorion initialization failed")
 
-	case <-time.After(5 * time.Minute):
+	case <-s.clock.After(5 * time.Minute):
 		close(ready)
-		s.Cmd.Process.Kill()
+		s.Cmd.Signal(os.Kill)
 		return errors.New("avorion took over 5 minutes to start")
 	}
 }
@@ -470,22 +611,35 @@ func (s *Server) Start(sendchat bool) error {
 // Stop gracefully stops the Avorion process
 func (s *Server) Stop(sendchat bool) error {
 	logger.LogDebug(s, "Stop() was called")
+	return state.run(s, lifecycleStopping, false, func() error {
+		return s.stop(sendchat)
+	})
+}
 
-	// Lock until any previous state operations are completed
-	state.mutex.Lock()
-	defer func() {
-		state.isstopping = false
-		state.mutex.Unlock()
-	}()
-	state.isstopping = true
+// stopQueued stops the server once any lifecycle operation that is running has
+// finished. It is used when the bot itself needs the server stopped, and can't
+// be refused.
+func (s *Server) stopQueued(sendchat bool) error {
+	return state.run(s, lifecycleStopping, true, func() error {
+		return s.stop(sendchat)
+	})
+}
 
+// stop saves and stops the Avorion process. It must only be run as part of a
+// lifecycle operation.
+func (s *Server) stop(sendchat bool) error {
 	if s.IsUp() != true {
 		logger.LogOutput(s, "Server is already offline")
 		return nil
 	}
 
 	logger.LogInfo(s, "Stopping Avorion server and waiting for it to exit")
+	if state.current() != lifecycleRestarting {
+		s.announceStatus(botStatusStopping)
+	}
+
 	go func() {
+		defer shutdown.Recover()
 		_, err := s.RunCommand("save")
 		if err == nil {
 			s.RunCommand("stop")
@@ -494,16 +648,16 @@ func (s *Server) Stop(sendchat bool) error {
 		logger.LogError(s, err.Error())
 	}()
 
-	s.onlineplayercount = 0
-	stopt := time.After(5 * time.Minute)
+	s.cache.update(func(c *statusSnapshot) { c.onlineplayercount = 0 })
+	stopt := s.clock.After(5 * time.Minute)
 
 	// If the process still exists after 5 minutes have passed kill the server
 	// We've SIGKILL'ed the game so it *will* close, so we block until its dead
 	// and writes have completed
 	select {
 	case <-stopt:
-		state.iscrashed = true
-		s.Cmd.Process.Kill()
+		state.setCrashed(true)
+		s.Cmd.Signal(os.Kill)
 		<-s.close
 		return errors.New("Avorion took too long to exit and had to be killed")
 
@@ -511,6 +665,15 @@ func (s *Server) Stop(sendchat bool) error {
 	// use that to safely detect when this function has completed
 	case <-s.close:
 		logger.LogInfo(s, "Avorion server has been stopped")
+
+		// Restarts come straight back up, so only a full stop is backed up
+		if state.current() == lifecycleStopping && s.config.BackupOnShutdown() {
+			if _, err := s.Backup(ifaces.BackupShutdown); err != nil {
+				logger.LogError(s, "Shutdown backup: "+err.Error())
+				s.SendLog(ifaces.ChatData{Msg: sprintf(noticeBackupFailed,
+					err.Error())})
+			}
+		}
 		return nil
 	}
 }
@@ -518,31 +681,38 @@ func (s *Server) Stop(sendchat bool) error {
 // Restart restarts the Avorion server
 func (s *Server) Restart() error {
 	logger.LogDebug(s, "Restart() was called")
+	return s.cycle(false, false)
+}
 
-	// We don't want to restart if the server was started in the last 10 seconds
-	if time.Now().Sub(state.last) > 10 {
-		if state.isrestarting || state.isstarting {
-			return nil
+// cycle stops and starts the server as a single lifecycle operation. If
+// wait is set, the restart is queued behind any operation that is running.
+// Recovering from a crash skips the grace period after a start, since a server
+// that crashes right after starting would otherwise be left down.
+func (s *Server) cycle(wait, crashed bool) error {
+	return state.run(s, lifecycleRestarting, wait, func() error {
+		// We don't want to restart if the server was started in the last 10
+		// seconds
+		if !crashed && state.sinceStart(s.clock.Now()) < restartGrace {
+			logger.LogInfo(s, "Server was just started, skipping reboot attempt")
+			return errors.New("Server was just restarted")
 		}
 
-		if err := s.Stop(false); err != nil {
-			logger.LogError(s, err.Error())
+		if s.IsUp() {
+			s.announceStatus(botStatusRestarting)
 		}
 
-		defer func() { state.isrestarting = false }()
-		state.isrestarting = true
+		if err := s.stop(false); err != nil {
+			logger.LogError(s, err.Error())
+		}
 
-		if err := s.Start(false); err != nil {
+		if err := s.start(false); err != nil {
 			logger.LogError(s, err.Error())
 			return err
 		}
 
 		logger.LogInfo(s, "Restarted Avorion")
 		return nil
-	}
-
-	logger.LogInfo(s, "Server was just started, skipping reboot attempt")
-	return errors.New("Server was just restarted")
+	})
 }
 
 // IsUp checks whether or not the game process is running
@@ -552,15 +722,11 @@ func (s *Server) IsUp() bool {
 		return false
 	}
 
-	if s.Cmd.ProcessState != nil {
+	if _, exited := s.Cmd.Exit(); exited {
 		return false
 	}
 
-	if s.Cmd.Process != nil {
-		return true
-	}
-
-	return false
+	return s.Cmd.Pid() != 0
 }
 
 // Config returns the server configuration struct
@@ -569,97 +735,145 @@ func (s *Server) Config() ifaces.IConfigurator {
 }
 
 // UpdatePlayerDatabase updates the Avorion player database with all
-// of the players that are known to the game
-//
-// FIXME: Fix this absolute mess of a method
+//	of the players that are known to the game
 func (s *Server) UpdatePlayerDatabase(notify bool) error {
 	logger.LogDebug(s, "UpdatePlayerDatabase() was called")
-	var (
-		out string
-		err error
-		m   []string
+	_, err := s.refreshPlayerDatabase(notify, nil)
+	return err
+}
 
-		allianceCount = 0
-		playerCount   = 0
+// RefreshPlayerDatabase updates the player database on demand, calling
+//	progress periodically as players and alliances are processed. It returns
+//	the players and alliances that were added, and any players that changed
+//	their name.
+func (s *Server) RefreshPlayerDatabase(
+	progress func(ifaces.PlayerDBProgress)) (ifaces.PlayerDBProgress, error) {
+	logger.LogDebug(s, "RefreshPlayerDatabase() was called")
+	if !s.IsUp() {
+		return ifaces.PlayerDBProgress{}, ifaces.ErrServerOffline
+	}
+	return s.refreshPlayerDatabase(true, progress)
+}
+
+func (s *Server) refreshPlayerDatabase(notify bool,
+	progress func(ifaces.PlayerDBProgress)) (ifaces.PlayerDBProgress, error) {
+	var (
+		out  string
+		err  error
+		prog ifaces.PlayerDBProgress
 	)
 
+	s.dblock.Lock()
+	defer s.dblock.Unlock()
+
+	report := func(force bool) {
+		count := prog.Players + prog.Alliances + prog.Processed
+		if progress != nil && (force || count%playerDBProgressStep == 0) {
+			progress(prog)
+		}
+	}
+
 	if notify {
 		s.NotifyServer(noticeDBUpate)
 	}
 
-	if out, err = s.RunCommand(rconGetAllData); err != nil {
+	if out, err = s.RunCommandPriority(rconGetAllData,
+		ifaces.CommandPriorityBackground); err != nil {
 		logger.LogError(s, err.Error())
-		return err
+		return prog, err
 	}
 
-	for _, info := range strings.Split(out, "\n") {
-		switch {
-		case strings.HasPrefix(info, "player: "):
-			playerCount++
-			if m = rePlayerData.FindStringSubmatch(info); m != nil {
-				if p := s.Player(m[1]); p == nil {
-					s.NewPlayer(m[1], m)
-				}
-			} else {
-				logger.LogError(s, "player: "+sprintf(errBadDataString, info))
-				continue
-			}
+	data := rcon.ParsePlayerDataOutput(out)
+	for _, err := range data.Errors {
+		logger.LogError(s, err.Error())
+	}
 
-		case strings.HasPrefix(info, "alliance: "):
-			allianceCount++
-			if m = reAllianceData.FindStringSubmatch(info); m != nil {
-				if a := s.Alliance(m[1]); a == nil {
-					s.NewAlliance(m[1], m)
-				}
-			} else {
-				logger.LogError(s, sprintf(errBadDataString, info))
-				continue
-			}
+	if len(data.Players)+len(data.Alliances) == 0 {
+		logger.LogWarning(s, "playerdb: "+errEmptyDataString)
+	}
 
-		case info == "":
-			logger.LogWarning(s, "playerdb: "+errEmptyDataString)
+	for _, d := range data.Players {
+		prog.Players++
+		if p := s.Player(d.Index); p == nil {
+			s.addPlayer(d)
+			prog.NewPlayers = append(prog.NewPlayers, d.Name)
+		} else {
+			if p.Name() != d.Name {
+				prog.Renamed = append(prog.Renamed,
+					sprintf("%s -> %s", p.Name(), d.Name))
+				logger.LogInfo(s, sprintf("Player %s renamed to %s", p.Name(), d.Name))
+			}
+			p.UpdateFromData(d)
+		}
+		report(false)
+	}
 
-		default:
-			logger.LogError(s, sprintf(errBadDataString, info))
+	for _, d := range data.Alliances {
+		prog.Alliances++
+		if a := s.Alliance(d.Index); a == nil {
+			s.addAlliance(d)
+			prog.NewAlliances = append(prog.NewAlliances, d.Name)
+		} else {
+			a.UpdateFromData(d)
 		}
+		report(false)
 	}
 
-	s.playercount = playerCount
-	s.alliancecount = allianceCount
+	s.cache.update(func(c *statusSnapshot) {
+		c.playercount = prog.Players
+		c.alliancecount = prog.Alliances
+	})
 
 	for _, p := range s.players {
-		s.tracking.SetDiscordToPlayer(p)
-		p.SteamUID()
+		s.trackingDB().SetDiscordToPlayer(p)
 		logger.LogDebug(s, "Processed player: "+p.Name())
+		prog.Processed++
+		report(false)
 	}
 
 	for _, a := range s.alliances {
 		logger.LogDebug(s, "Processed alliance: "+a.Name())
 	}
 
-	return nil
+	s.updateShipRegistry()
+	s.updateAllianceLeadership()
+
+	prog.Done = true
+	report(true)
+	return prog, nil
 }
 
 // Status returns a struct containing the current status of the server
 func (s *Server) Status() ifaces.ServerStatus {
 	logger.LogDebug(s, "Status() was called")
 
-	name := s.name
+	cache := s.cache.load()
+	name := cache.name
 	if name == "" {
 		name = s.config.Galaxy()
 	}
 
 	config, _ := s.config.GameConfig()
+	restart, _ := s.PendingRestart()
+	mainttill, maint := s.Maintenance()
 
 	return ifaces.ServerStatus{
 		Name:          name,
 		Status:        s.statusInt(),
-		Players:       s.onlineplayers,
-		TotalPlayers:  s.playercount,
-		PlayersOnline: s.onlineplayercount,
-		Alliances:     s.alliancecount,
-		Output:        s.statusoutput,
-		Sectors:       s.sectorcount,
+		Players:       cache.onlineplayers,
+		TotalPlayers:  cache.playercount,
+		PlayersOnline: cache.onlineplayercount,
+		Alliances:     cache.alliancecount,
+		Output:        cache.statusoutput,
+		Sectors:       cache.sectorcount,
+		RestartAt:     restart,
+		Maintenance:   maint,
+		MaintUntil:    mainttill,
+		Version:       strings.TrimSpace(cache.version),
+		Installed:     cache.installedversion,
+		QueryPlayers:  cache.queryplayers,
+		QuerySlots:    cache.queryslots,
+		QueryOK:       cache.queryok,
 		INI:           config}
 }
 
@@ -674,7 +888,14 @@ func (s *Server) CompareStatus(a, b ifaces.ServerStatus) bool {
 		a.PlayersOnline == b.PlayersOnline &&
 		a.Alliances == b.Alliances &&
 		a.Output == b.Output &&
-		a.Sectors == b.Sectors {
+		a.Sectors == b.Sectors &&
+		a.RestartAt.Equal(b.RestartAt) &&
+		a.Maintenance == b.Maintenance &&
+		a.MaintUntil.Equal(b.MaintUntil) &&
+		a.Installed == b.Installed &&
+		a.QueryPlayers == b.QueryPlayers &&
+		a.QuerySlots == b.QuerySlots &&
+		a.QueryOK == b.QueryOK {
 		return true
 	}
 	return false
@@ -683,19 +904,19 @@ func (s *Server) CompareStatus(a, b ifaces.ServerStatus) bool {
 // IsCrashed returns the current crash status of the server
 func (s *Server) IsCrashed() bool {
 	logger.LogDebug(s, "IsCrashed() was called")
-	return state.iscrashed
+	return state.isCrashed()
 }
 
 // Crashed sets the server status to crashed
 func (s *Server) Crashed() {
 	logger.LogDebug(s, "Crashed() was called")
-	state.iscrashed = true
+	state.setCrashed(true)
 }
 
 // Recovered sets the server status to be normal (from crashed)
 func (s *Server) Recovered() {
 	logger.LogDebug(s, "Recovered() was called")
-	state.iscrashed = false
+	state.setCrashed(false)
 }
 
 /************************/
@@ -721,17 +942,33 @@ func (s *Server) SetLoglevel(l int) {
 /* IFace ifaces.ICommandableServer */
 /***********************************/
 
-// RunCommand runs a command via rcon and returns the output
+// RunCommand runs a command via rcon and returns the output. Commands run
+// this way are queued with the same priority as user commands.
+func (s *Server) RunCommand(c string) (string, error) {
+	return s.RunCommandPriority(c, ifaces.CommandPriorityUser)
+}
+
+// RunCommandPriority runs a command via rcon with the given queue priority and
+// returns the output
 //	TODO 1: Modify this to use the games rcon websocket interface or an rcon lib
 //	TODO 2: Modify this function to make use of permitted command levels
-func (s *Server) RunCommand(c string) (string, error) {
+func (s *Server) RunCommandPriority(c string, p int) (string, error) {
 	logger.LogDebug(s, sprintf(`RunCommand("%s") was called`, c))
 
-	cmdmutex.Lock()
+	if n := cmdqueue.total(); n >= queueDepthWarning {
+		logger.LogWarning(s, sprintf("RCON queue is backed up (%d waiting): %v",
+			n, cmdqueue.Depth()))
+	}
+
+	if err := cmdqueue.acquire(p); err != nil {
+		logger.LogError(s, sprintf("RunCommand(%s): %s", c, err.Error()))
+		return "", err
+	}
+
 	logger.LogDebug(s, sprintf("RunCommand(%s) locking", c))
 
 	defer func() {
-		cmdmutex.Unlock()
+		cmdqueue.release()
 		logger.LogDebug(s, sprintf("Unlocking RunCommand(%s)", c))
 	}()
 
@@ -740,7 +977,7 @@ func (s *Server) RunCommand(c string) (string, error) {
 		defer cancel()
 
 		// TODO: Make this use an rcon lib
-		ret, err := exec.CommandContext(ctx, s.config.RCONBin(), "-H",
+		ret, err := s.exec.Command(ctx, ProcessOptions{}, s.config.RCONBin(), "-H",
 			s.rconaddr, "-p", sprintf("%d", s.rconport),
 			"-P", s.rconpass, c).CombinedOutput()
 		out := string(ret)
@@ -748,6 +985,9 @@ func (s *Server) RunCommand(c string) (string, error) {
 		if err != nil {
 			logger.LogError(s, "rcon: "+err.Error())
 			logger.LogError(s, "rcon: "+out)
+			if ctx.Err() == context.DeadlineExceeded {
+				return "", fmt.Errorf("%w running: %s", ifaces.ErrRCONTimeout, c)
+			}
 			return "", errors.New("Failed to run the following command: " + c)
 		}
 
@@ -758,7 +998,13 @@ func (s *Server) RunCommand(c string) (string, error) {
 		return strings.TrimSuffix(out, "\n"), nil
 	}
 
-	return "", errors.New("Server is not online")
+	return "", ifaces.ErrServerOffline
+}
+
+// CommandQueueDepth returns the number of callers waiting on RCON for each
+// command priority
+func (s *Server) CommandQueueDepth() []int {
+	return cmdqueue.Depth()
 }
 
 /*********************************/
@@ -767,12 +1013,172 @@ func (s *Server) RunCommand(c string) (string, error) {
 
 // SetVersion - Sets the current version of the Avorion server
 func (s *Server) SetVersion(v string) {
-	s.version = v
+	s.cache.update(func(c *statusSnapshot) { c.version = v })
 }
 
 // Version - Return the version of the Avorion server
 func (s *Server) Version() string {
-	return s.version
+	return s.cache.load().version
+}
+
+/*********************************/
+/* IFace ifaces.IScheduledServer */
+/*********************************/
+
+// Schedule returns the automated actions that the server will run next, ordered
+// by the time that they will run. Actions are only scheduled while the status
+// supervisor is running.
+func (s *Server) Schedule() []ifaces.ScheduledAction {
+	actions := make([]ifaces.ScheduledAction, 0)
+
+	if !s.IsUp() {
+		return actions
+	}
+
+	snap := s.cache.load()
+	if !snap.nextstatuscheck.IsZero() {
+		actions = append(actions, ifaces.ScheduledAction{
+			Name: "Server status check", Next: snap.nextstatuscheck})
+	}
+
+	if !snap.nextdbupdate.IsZero() {
+		actions = append(actions, ifaces.ScheduledAction{
+			Name: "Player database refresh", Next: snap.nextdbupdate})
+	}
+
+	if at, ok := s.PendingRestart(); ok {
+		actions = append(actions, ifaces.ScheduledAction{
+			Name: "Server restart", Next: at})
+	}
+
+	if at, ok := s.nextScheduledRestart(); ok {
+		actions = append(actions, ifaces.ScheduledAction{
+			Name: "Scheduled server restart", Next: at})
+	}
+
+	if at, ok := s.nextBackup(); ok {
+		actions = append(actions, ifaces.ScheduledAction{
+			Name: "Galaxy backup", Next: at})
+	}
+
+	for _, a := range s.OneShotActions() {
+		actions = append(actions, ifaces.ScheduledAction{
+			Name: sprintf("%s (one-off #%d)", oneShotNames[a.Action], a.ID),
+			Next: a.At})
+	}
+
+	if until, ok := s.Maintenance(); ok && !until.IsZero() {
+		actions = append(actions, ifaces.ScheduledAction{
+			Name: "End of maintenance", Next: until})
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].Next.Before(actions[j].Next)
+	})
+
+	return actions
+}
+
+/********************************/
+/* IFace ifaces.IHealthReporter */
+/********************************/
+
+// Health returns the health of the Avorion server. A server that was stopped
+// on purpose is still considered healthy, a crashed server is not.
+func (s *Server) Health() ifaces.SubsystemHealth {
+	cache := s.cache.load()
+	status, _ := ifaces.State(s.statusInt())
+	if cache.portfailure != "" {
+		status += ", game port unreachable: " + cache.portfailure
+	}
+
+	if cache.dbdegraded != "" {
+		status += ", tracking database unavailable: " + cache.dbdegraded
+	}
+
+	healthy := !state.isCrashed() && cache.portfailure == "" &&
+		cache.dbdegraded == ""
+
+	return ifaces.SubsystemHealth{
+		Name:    "Avorion",
+		Healthy: healthy,
+		Detail:  status}
+}
+
+/**********************************/
+/* IFace ifaces.IMigratableServer */
+/**********************************/
+
+// InstallVersion validates the Avorion installation at the given path and
+// returns the version of the game that it contains
+func (s *Server) InstallVersion(path string) (string, error) {
+	path = strings.TrimSuffix(path, "/")
+	bin := path + "/bin/" + s.executable
+
+	if info, err := s.fs.Stat(bin); err != nil || info.IsDir() {
+		return "", errors.New(sprintf(errExecFailed, path, s.executable))
+	}
+
+	name, args := s.gameCommand(bin, "--version")
+	version, err := preflight(s, s.exec, preflightTimeout, name, args...)
+	if err != nil {
+		return "", errors.New(sprintf(errExecFailed, path, s.executable))
+	}
+
+	return strings.TrimSpace(version), nil
+}
+
+// MigrateInstall switches the server to the Avorion installation at the given
+// path. If the server is online, it is restarted so that the new installation
+// takes effect.
+func (s *Server) MigrateInstall(path string) error {
+	version, err := s.InstallVersion(path)
+	if err != nil {
+		return err
+	}
+
+	logger.LogInfo(s, sprintf("Migrating from %s to %s", s.serverpath, path))
+	s.serverpath = strings.TrimSuffix(path, "/")
+	s.SetVersion(version)
+
+	if s.IsUp() {
+		return s.Restart()
+	}
+
+	return nil
+}
+
+// SwitchGalaxy switches the server to the galaxy name that was loaded from the
+// config while the server was running. If the server is up, it is stopped
+// before the switch and started again afterwards.
+func (s *Server) SwitchGalaxy() (string, error) {
+	var name string
+	err := state.run(s, lifecycleRestarting, false, func() error {
+		pending, ok := s.config.PendingGalaxy()
+		if !ok {
+			return errors.New("the galaxy name in the config hasn't changed")
+		}
+
+		up := s.IsUp()
+		if up {
+			logger.LogInfo(s, "Stopping Avorion to switch to the galaxy "+pending)
+			s.announceStatus(botStatusRestarting)
+			if err := s.stop(false); err != nil {
+				return err
+			}
+		}
+
+		name, _ = s.config.SwitchGalaxy()
+		s.SendLog(ifaces.ChatData{Msg: sprintf(
+			"**Server Notice**: Switched to the galaxy `%s`", name)})
+
+		if !up {
+			return nil
+		}
+		return s.start(false)
+	})
+
+	return name, err
 }
 
 /******************************/
@@ -848,9 +1254,16 @@ func (s *Server) PlayerFromName(name string) ifaces.IPlayer {
 
 // PlayerFromDiscord return a player object that has been assigned the given
 //	Discord user
-//
-// TODO: Complete this stub
 func (s *Server) PlayerFromDiscord(name string) ifaces.IPlayer {
+	if name == "" {
+		return nil
+	}
+
+	for _, p := range s.players {
+		if p.DiscordUID() == name {
+			return p
+		}
+	}
 	return nil
 }
 
@@ -894,92 +1307,117 @@ func (s *Server) Alliances() []ifaces.IAlliance {
 	return v
 }
 
-// NewPlayer adds a new player to the list of players if it isn't already present
-func (s *Server) NewPlayer(index string, d []string) ifaces.IPlayer {
+// NewPlayer looks up a player that the game knows about, and adds them to the
+//	list of players
+func (s *Server) NewPlayer(index string) ifaces.IPlayer {
 	if _, err := strconv.Atoi(index); err != nil {
 		logger.LogError(s, "player: "+sprintf(errBadIndex, index))
-		s.Stop(true)
-		os.Exit(1)
+		s.stopQueued(true)
+		shutdown.Exit(1)
 	}
 
-	cmd := sprintf(rconGetPlayerData, index)
+	out, err := s.lookup(sprintf(rconGetPlayerData, index))
+	if err != nil {
+		logger.LogError(s, sprintf(errFailedRCON, err.Error()))
+	}
 
-	if len(d) < 15 {
-		if data, err := s.RunCommand(cmd); err != nil {
-			logger.LogError(s, sprintf(errFailedRCON, err.Error()))
-		} else {
-			if d = rePlayerData.FindStringSubmatch(data); d == nil {
-				logger.LogError(s, sprintf(errBadDataString, data))
-				s.Stop(true)
-				<-s.close
-				panic("Failed to parse data string")
-			}
-		}
+	d, err := rcon.ParsePlayerData(out)
+	if err != nil {
+		logger.LogError(s, err.Error())
+		s.stopQueued(true)
+		<-s.close
+		panic("Failed to parse data string")
 	}
 
+	return s.addPlayer(d)
+}
+
+// addPlayer adds a player to the list of players using the data reported by
+//	getplayerdata
+func (s *Server) addPlayer(d ifaces.PlayerData) ifaces.IPlayer {
 	p := &Player{
-		index:       index,
-		name:        d[14],
+		index:       d.Index,
+		name:        d.Name,
 		server:      s,
 		jumphistory: make([]ifaces.ShipCoordData, 0),
 		loglevel:    s.Loglevel()}
 
-	// Convert our string into an array for safety
-	var darr [15]string
-	copy(darr[:], d)
-	p.UpdateFromData(darr)
+	p.UpdateFromData(d)
 	s.players = append(s.players, p)
-	if err := s.tracking.TrackPlayer(p); err != nil {
+	tracking := s.trackingDB()
+	if err := tracking.TrackPlayer(p); err != nil {
 		logger.LogError(s, err.Error())
 	}
+	if hidden, err := tracking.Privacy(d.Index); err != nil {
+		logger.LogError(s, err.Error())
+	} else {
+		p.private = hidden
+	}
+	if id, err := tracking.SteamID(d.Index); err != nil {
+		logger.LogError(s, err.Error())
+	} else {
+		p.steam64 = id
+	}
 	logger.LogInfo(p, "Registered player")
-	s.playercount++
+	s.cache.update(func(c *statusSnapshot) { c.playercount++ })
 	return p
 }
 
-// RemovePlayer removes a player from the list of online players
-// TODO: This function is currently a stub and needs to be made functional once
-// more.
-func (s *Server) RemovePlayer(n string) {
-	return
+// RemovePlayer removes a player from the list of online players. Players that
+// are already offline are ignored, so that a repeated logoff can't throw off
+// the online player count.
+func (s *Server) RemovePlayer(index string) {
+	p := s.Player(index)
+	if p == nil || !p.Online() {
+		return
+	}
+
+	p.SetOnline(false)
+	s.SubPlayerOnline()
 }
 
-// NewAlliance adds a new alliance to the list of alliances if it isn't already
-//	present
-func (s *Server) NewAlliance(index string, d []string) ifaces.IAlliance {
+// NewAlliance looks up an alliance that the game knows about, and adds it to
+//	the list of alliances if it isn't already present
+func (s *Server) NewAlliance(index string) ifaces.IAlliance {
 	if _, err := strconv.Atoi(index); err != nil {
 		logger.LogError(s, "alliance: "+sprintf(errBadIndex, index))
-		s.Stop(true)
-		os.Exit(1)
+		s.stopQueued(true)
+		shutdown.Exit(1)
 	}
 
-	if len(d) < 13 {
-		if data, err := s.RunCommand("getplayerdata -a " + index); err != nil {
-			logger.LogError(s, sprintf("Failed to get alliance data: (%s)", err.Error()))
-		} else {
-			if d = rePlayerData.FindStringSubmatch(data); d != nil {
-				logger.LogError(s,
-					sprintf("alliance: "+errBadDataString, data))
-				s.Stop(true)
-				<-s.close
-				panic("Bad data string given in *Server.NewAlliance")
-			}
-		}
+	if a := s.Alliance(index); a != nil {
+		a.Update()
+		return a
 	}
 
-	if p := s.Alliance(index); p != nil {
-		p.Update()
-		return p
+	out, err := s.lookup(sprintf(rconGetAllianceData, index))
+	if err != nil {
+		logger.LogError(s, sprintf("Failed to get alliance data: (%s)", err.Error()))
 	}
 
+	d, err := rcon.ParseAllianceData(out)
+	if err != nil {
+		logger.LogError(s, err.Error())
+		s.stopQueued(true)
+		<-s.close
+		panic("Bad data string given in *Server.NewAlliance")
+	}
+
+	return s.addAlliance(d)
+}
+
+// addAlliance adds an alliance to the list of alliances using the data
+//	reported by getplayerdata
+func (s *Server) addAlliance(d ifaces.AllianceData) ifaces.IAlliance {
 	a := &Alliance{
-		index:       index,
-		name:        d[12],
+		index:       d.Index,
+		name:        d.Name,
 		server:      s,
 		jumphistory: make([]ifaces.ShipCoordData, 0),
 		loglevel:    s.Loglevel()}
 
-	s.tracking.TrackAlliance(a)
+	a.UpdateFromData(d)
+	s.trackingDB().TrackAlliance(a)
 	s.alliances = append(s.alliances, a)
 	logger.LogInfo(a, "Registered alliance")
 	return a
@@ -987,13 +1425,16 @@ func (s *Server) NewAlliance(index string, d []string) ifaces.IAlliance {
 
 // AddPlayerOnline increments the count of online players
 func (s *Server) AddPlayerOnline() {
-	s.onlineplayercount++
+	s.cache.update(func(c *statusSnapshot) { c.onlineplayercount++ })
 	s.updateOnlineString()
+	s.checkMilestones()
+	s.kickForMaintenance()
+	s.kickIdlePlayers()
 }
 
 // SubPlayerOnline decrements the count of online players
 func (s *Server) SubPlayerOnline() {
-	s.onlineplayercount--
+	s.cache.update(func(c *statusSnapshot) { c.onlineplayercount-- })
 	s.updateOnlineString()
 }
 
@@ -1004,8 +1445,8 @@ func (s *Server) updateOnlineString() {
 			online = sprintf("%s\n%s", online, p.Name())
 		}
 	}
-	s.onlineplayers = online
-	logger.LogDebug(s, "Updated online string: "+s.onlineplayers)
+	s.cache.update(func(c *statusSnapshot) { c.onlineplayers = online })
+	logger.LogDebug(s, "Updated online string: "+online)
 }
 
 /*****************************************/
@@ -1019,24 +1460,34 @@ func (s *Server) AddIntegrationRequest(index, pin string) {
 }
 
 // ValidateIntegrationPin confirms that a given pin was indeed a valid request
-//	and registers the integration
-func (s *Server) ValidateIntegrationPin(in, discordID string) bool {
+//	and registers the integration. Discord users that enter too many wrong pins
+//	are blocked from trying again for a while.
+func (s *Server) ValidateIntegrationPin(in, discordID string) error {
+	s.pinlock.Lock()
+	defer s.pinlock.Unlock()
+
 	m := regexpDiscordPin.FindStringSubmatch(in)
 	if len(m) < 2 {
 		logger.LogError(s, sprintf("Invalid integration request provided: [%s]/[%s]",
 			in, discordID))
-		return false
+		return ifaces.ErrInvalidPin
+	}
+
+	if s.pinLocked(discordID, m[1]) {
+		return ifaces.ErrPinLocked
 	}
 
 	if val, ok := s.requests[m[1]]; ok {
 		if val == m[2] {
-			s.tracking.AddIntegration(discordID, s.Player(m[1]))
+			delete(s.pinattempts, discordID)
+			s.trackingDB().AddIntegration(discordID, s.Player(m[1]))
 			s.addIntegration(m[1], discordID)
-			return true
+			return nil
 		}
 	}
 
-	return false
+	s.pinFailed(discordID, m[1])
+	return ifaces.ErrInvalidPin
 }
 
 /******************************/
@@ -1057,51 +1508,326 @@ func (s *Server) Sector(x, y int) *ifaces.Sector {
 
 		// TODO: This performs unnecessarily expensive DB calls here. Granted,
 		// that ONLY affects initilization, but it should still be optimized
-		s.tracking.TrackSector(s.sectors[x][y])
-		s.sectorcount++
+		s.trackingDB().TrackSector(s.sectors[x][y])
+		s.cache.update(func(c *statusSnapshot) { c.sectorcount++ })
 	}
 
 	return s.sectors[x][y]
 }
 
-// SendChat sends an ifaces.ChatData object to the discord bot if chatting is
-//	currently enabled in the configuration
+// SetSectorFaction records the NPC faction that controls a sector. Changes in
+// ownership are logged and persisted to the tracking database.
+func (s *Server) SetSectorFaction(x, y, fid int, name string) {
+	sec := s.Sector(x, y)
+	if sec.Faction == fid && sec.FactionName == name {
+		return
+	}
+
+	if sec.Faction != 0 && sec.Faction != fid {
+		logger.LogInfo(s, sprintf("Sector %d:%d changed hands from %s to %s",
+			x, y, sec.FactionName, name))
+	}
+
+	sec.Faction = fid
+	sec.FactionName = name
+
+	if err := s.trackingDB().SetTerritory(sec); err != nil {
+		logger.LogError(s, "SetTerritory: "+err.Error())
+	}
+}
+
+// SendChat publishes an ifaces.ChatData object to the chat topic of the event
+//	bus, which the discord bot relays to the chat channel
 func (s *Server) SendChat(input ifaces.ChatData) {
-	if s.config.ChatPipe() != nil {
-		if len(input.Msg) >= 2000 {
-			logger.LogInfo(s, "Truncated player message for sending")
-			input.Msg = input.Msg[0:1900]
-			input.Msg += "...(truncated)"
+	s.publish(ifaces.EventTopicChat, input)
+}
+
+// SendLog publishes an ifaces.ChatData object to the log topic of the event
+//	bus, which the discord bot relays to the log channel
+func (s *Server) SendLog(input ifaces.ChatData) {
+	s.publish(ifaces.EventTopicLog, input)
+}
+
+// publish truncates a message to fit in Discord and sends it to the event bus
+func (s *Server) publish(topic string, input ifaces.ChatData) {
+	if s.bus.Subscribers(topic) == 0 {
+		return
+	}
+
+	if len(input.Msg) >= 2000 {
+		logger.LogInfo(s, "Truncated "+topic+" message for sending")
+		input.Msg = input.Msg[0:1900]
+		input.Msg += "...(truncated)"
+	}
+
+	if s.bus.Publish(topic, input, time.Second*5) {
+		logger.LogDebug(s, "Published "+topic+" data to the event bus")
+	} else {
+		logger.LogWarning(s, warnChatDiscarded)
+	}
+}
+
+// RecordChat stores a bridged chat message so that it can be searched later
+func (s *Server) RecordChat(cd ifaces.ChatData, source string) {
+	tracking := s.trackingDB()
+	if tracking == nil || cd.Msg == "" {
+		return
+	}
+
+	err := tracking.AddChat(ifaces.ChatRecord{
+		Time:   s.clock.Now(),
+		Name:   cd.Name,
+		Msg:    cd.Msg,
+		Source: source})
+	if err != nil {
+		logger.LogError(s, "RecordChat: "+err.Error())
+	}
+}
+
+// SearchChat searches the history of bridged chat messages
+func (s *Server) SearchChat(query, name string, since time.Time,
+	limit int) ([]ifaces.ChatRecord, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return nil, ifaces.ErrDataUnavailable
+	}
+
+	return tracking.SearchChat(query, name, since, limit)
+}
+
+// RecordCommand records the use of a bot command in a guild, and whether or
+// not it failed
+func (s *Server) RecordCommand(guild, name string, failed bool) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return
+	}
+
+	if err := tracking.AddCommandUse(guild, name, failed); err != nil {
+		logger.LogError(s, "RecordCommand: "+err.Error())
+	}
+}
+
+// CommandStats returns the usage statistics for bot commands in a guild, or
+// for all guilds if guild is empty
+func (s *Server) CommandStats(guild string) ([]ifaces.CommandStat, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return nil, ifaces.ErrDataUnavailable
+	}
+
+	return tracking.CommandStats(guild)
+}
+
+// recordKill records a ship that was destroyed for the combat statistics
+func (s *Server) recordKill(k ifaces.ShipKill) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return
+	}
+
+	if k.Time.Unix() <= 0 {
+		k.Time = s.clock.Now()
+	}
+
+	if err := tracking.AddKill(k); err != nil {
+		logger.LogError(s, "AddKill: "+err.Error())
+	}
+}
+
+// CombatStats returns statistics on the ships that have been destroyed, with up
+// to limit entries in each group
+func (s *Server) CombatStats(limit int) (ifaces.CombatStats, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return ifaces.CombatStats{}, ifaces.ErrDataUnavailable
+	}
+
+	return tracking.CombatStats(limit)
+}
+
+// RecordDonation records a donation of a resource (or credits) by a player to
+// an alliance
+func (s *Server) RecordDonation(alliance, player, resource string, amount int64) {
+	tracking := s.trackingDB()
+	if tracking == nil || amount <= 0 {
+		return
+	}
+
+	aid, aerr := strconv.ParseInt(alliance, 10, 64)
+	pid, perr := strconv.ParseInt(player, 10, 64)
+	if aerr != nil || perr != nil {
+		logger.LogError(s, sprintf("RecordDonation: invalid index (%s, %s)",
+			alliance, player))
+		return
+	}
+
+	resource = strings.ToLower(resource)
+	if err := tracking.AddDonation(aid, pid, resource, amount); err != nil {
+		logger.LogError(s, "RecordDonation: "+err.Error())
+		return
+	}
+
+	logger.LogDebug(s, sprintf("Player %s donated %d %s to alliance %s", player,
+		amount, resource, alliance))
+}
+
+// AllianceDonations returns the donations that each member has made to an
+// alliance
+func (s *Server) AllianceDonations(alliance string) ([]ifaces.DonationStat, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return nil, ifaces.ErrDataUnavailable
+	}
+
+	aid, err := strconv.ParseInt(alliance, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return tracking.Donations(aid)
+}
+
+// TrackShip records the location of a ship owned by the given faction index
+// in the ship registry
+func (s *Server) TrackShip(index string, sc ifaces.ShipCoordData) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return
+	}
+
+	fid, err := strconv.ParseInt(index, 10, 64)
+	if err != nil {
+		logger.LogError(s, "TrackShip: invalid faction index "+index)
+		return
+	}
+
+	if sc.Time.IsZero() {
+		sc.Time = s.clock.Now()
+	}
+
+	if err := tracking.SetShip(fid, sc); err != nil {
+		logger.LogError(s, "TrackShip: "+err.Error())
+	}
+}
+
+// FindShips searches the ship registry for ships with a matching name
+func (s *Server) FindShips(name string) ([]ifaces.ShipRecord, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return nil, ifaces.ErrDataUnavailable
+	}
+
+	return tracking.FindShips(name, 25)
+}
+
+// updateShipRegistry refreshes the ship registry with the ships owned by the
+// online players and their alliances
+func (s *Server) updateShipRegistry() {
+	out, err := s.RunCommandPriority(rconGetShipData,
+		ifaces.CommandPriorityBackground)
+	if err != nil {
+		logger.LogError(s, "getshipdata: "+err.Error())
+		return
+	}
+
+	for _, info := range strings.Split(out, "\n") {
+		if info == "" {
+			continue
 		}
 
-		select {
-		case s.Config().ChatPipe() <- input:
-			logger.LogDebug(s, "Sent chat data to bot")
-		case <-time.After(time.Second * 5):
-			logger.LogWarning(s, warnChatDiscarded)
+		ship, err := rcon.ParseShipData(info)
+		if err != nil {
+			logger.LogError(s, err.Error())
+			continue
 		}
+
+		s.TrackShip(ship.Faction, ifaces.ShipCoordData{X: ship.X, Y: ship.Y,
+			Name: ship.Name})
 	}
 }
 
-// SendLog sends an ifaces.ChatData object to the discord bot if logging is
-//	currently enabled in the configuration
-func (s *Server) SendLog(input ifaces.ChatData) {
-	if s.config.LogPipe() != nil {
-		if len(input.Msg) >= 2000 {
-			logger.LogInfo(s, "Truncated log for sending")
-			input.Msg = input.Msg[0:1900]
-			input.Msg += "...(truncated)"
+// updateAllianceLeadership refreshes the leader and members of each tracked
+// alliance, and records leadership changes in the log channel
+func (s *Server) updateAllianceLeadership() {
+	tracking := s.trackingDB()
+	if len(s.alliances) == 0 || tracking == nil {
+		return
+	}
+
+	indexes := make([]string, 0)
+	for _, a := range s.alliances {
+		indexes = append(indexes, a.Index())
+	}
+
+	out, err := s.RunCommandPriority(sprintf(rconGetAllianceLead,
+		strings.Join(indexes, " ")), ifaces.CommandPriorityBackground)
+	if err != nil {
+		logger.LogError(s, "getalliancelead: "+err.Error())
+		return
+	}
+
+	for _, info := range strings.Split(out, "\n") {
+		if info == "" {
+			continue
+		}
+
+		data, err := rcon.ParseAllianceLead(info)
+		if err != nil {
+			logger.LogError(s, err.Error())
+			continue
 		}
 
-		select {
-		case s.Config().LogPipe() <- input:
-			logger.LogDebug(s, "Sent event log to bot")
-		case <-time.After(time.Second * 5):
-			logger.LogWarning(s, warnChatDiscarded)
+		a := s.Alliance(data.Alliance)
+		if a == nil {
+			continue
+		}
+
+		lead := ifaces.AllianceLeadership{
+			Leader:  data.Leader,
+			Members: data.Members,
+			Ranks:   data.Ranks,
+			Updated: s.clock.Now()}
+
+		aid, _ := strconv.ParseInt(data.Alliance, 10, 64)
+		lid, _ := strconv.ParseInt(data.Leader, 10, 64)
+		founder, previous, err := tracking.SetAllianceLeadership(aid, lid,
+			data.Raw)
+		if err != nil {
+			continue
+		}
+
+		lead.Founder = strconv.FormatInt(founder, 10)
+		a.SetLeadership(lead)
+
+		if previous >= 0 && previous != lid {
+			s.SendLog(ifaces.ChatData{Msg: sprintf(noticeLeaderChanged, a.Name(),
+				s.playerName(strconv.FormatInt(previous, 10)),
+				s.playerName(data.Leader))})
 		}
 	}
 }
 
+// playerName returns the name of the player with the given index, or the
+// index itself if that player isn't tracked
+func (s *Server) playerName(index string) string {
+	if p := s.Player(index); p != nil {
+		return p.Name()
+	}
+	return "#" + index
+}
+
+// ExportData returns the column names and rows of a kind of tracked data
+// (players, jumps, or chat), optionally limited to a single player
+func (s *Server) ExportData(kind, player string) ([]string, [][]string, error) {
+	tracking := s.trackingDB()
+	if tracking == nil {
+		return nil, nil, ifaces.ErrDataUnavailable
+	}
+
+	return tracking.Export(kind, player)
+}
+
 // addIntegration is a helper function that registers an integration
 func (s *Server) addIntegration(index, discordID string) {
 	s.RunCommand(sprintf(rconPlayerDiscord, index, discordID))
@@ -1118,6 +1844,7 @@ func (s *Server) InitializeEvents() {
 	)
 
 	for _, ed := range s.config.GetEvents() {
+		name := ed.Name
 		ge := &events.Event{
 			FString: ed.FString,
 			Capture: ed.Regex,
@@ -1151,8 +1878,9 @@ func (s *Server) InitializeEvents() {
 					strings = append(strings, v)
 				}
 
-				srv.SendLog(ifaces.ChatData{
-					Msg: sprintf(e.FString, strings[1:]...)})
+				msg := sprintf(e.FString, strings[1:]...)
+				srv.SendLog(ifaces.ChatData{Msg: msg})
+				s.announceVoice(name, msg)
 			}}
 
 		ge.SetLoglevel(s.Loglevel())
@@ -1173,34 +1901,117 @@ func (s *Server) InitializeEvents() {
 	logger.LogInit(s, "Completed event registration")
 }
 
-// TODO: Make this less godawful
-func (s *Server) loadSectors() {
-	for _, x := range s.sectors {
-		for _, sec := range x {
-			for _, j := range sec.Jumphistory {
-				for _, p := range s.players {
-					if p.Index() == strconv.FormatInt(int64(j.FID), 10) {
-						p.jumphistory = append(p.jumphistory, ifaces.ShipCoordData{
-							X: j.X, Y: j.Y, Name: j.Name, Time: j.Time})
-					}
-				}
+// checkGalaxyIdentity compares the seed and version of the galaxy that was just
+// started against the values that were recorded the last time that it was run.
+// A change in either of these usually means that the datapath is wrong, a new
+// galaxy was generated by accident, or the game was updated, so we alert loudly.
+func (s *Server) checkGalaxyIdentity() {
+	gcfg, ok := s.config.GameConfig()
+	if !ok {
+		logger.LogWarning(s, "Cannot check galaxy identity without server.ini")
+		return
+	}
 
-				for _, a := range s.alliances {
-					if a.Index() == strconv.FormatInt(int64(j.FID), 10) {
-						a.jumphistory = append(a.jumphistory, ifaces.ShipCoordData{
-							X: j.X, Y: j.Y, Name: j.Name, Time: j.Time})
-					}
-				}
-			}
+	s.SetSeed(gcfg.Seed)
+	current := map[string]string{
+		dbInfoSeed:    gcfg.Seed,
+		dbInfoVersion: strings.TrimSpace(s.Version())}
+
+	tracking := s.trackingDB()
+	for _, key := range []string{dbInfoSeed, dbInfoVersion} {
+		last, err := tracking.ServerInfo(key)
+		if err != nil {
+			logger.LogError(s, "GameDB: "+err.Error())
+			continue
+		}
+
+		if last != "" && last != current[key] {
+			logger.LogWarning(s, sprintf(warnIdentityChanged, key, last, current[key]))
+			s.SendLog(ifaces.ChatData{Msg: sprintf(noticeIdentityChanged,
+				key, last, current[key])})
 		}
+
+		if err := tracking.SetServerInfo(key, current[key]); err != nil {
+			logger.LogError(s, "GameDB: "+err.Error())
+		}
+	}
+}
+
+// loadSectors loads the recorded jump history of each tracked player and
+// alliance, in batches so that progress can be reported on large galaxies
+func (s *Server) loadSectors() {
+	tracking := s.trackingDB()
+	total, err := tracking.JumpCount()
+	if err != nil {
+		logger.LogError(s, "GameDB: "+err.Error())
+		return
 	}
 
+	histories := make(map[int]*[]ifaces.ShipCoordData,
+		len(s.players)+len(s.alliances))
 	for _, p := range s.players {
-		sort.Sort(jumpsByTime(p.jumphistory))
+		if fid, err := strconv.Atoi(p.Index()); err == nil {
+			histories[fid] = &p.jumphistory
+		}
 	}
 
 	for _, a := range s.alliances {
-		sort.Sort(jumpsByTime(a.jumphistory))
+		if fid, err := strconv.Atoi(a.Index()); err == nil {
+			histories[fid] = &a.jumphistory
+		}
+	}
+
+	var (
+		after  int64
+		loaded int
+		start  = time.Now()
+	)
+
+	logger.LogInit(s, sprintf("Loading jump history (%d jumps recorded)", total))
+	for {
+		jumps, last, err := tracking.FactionJumps(after, sectorLoadBatch)
+		if err != nil {
+			logger.LogError(s, "GameDB: "+err.Error())
+			break
+		}
+
+		touched := make(map[int]bool)
+		for _, j := range jumps {
+			if h, ok := histories[j.FID]; ok {
+				*h = append(*h, ifaces.ShipCoordData{
+					X: j.X, Y: j.Y, Name: j.Name, Time: j.Time})
+				touched[j.FID] = true
+			}
+		}
+
+		// Only the most recent jumps are kept in memory, matching AddJump.
+		// Trimming as we go keeps a busy faction's full history from being
+		// held in memory at once.
+		for fid := range touched {
+			trimJumps(histories[fid])
+		}
+
+		loaded += len(jumps)
+		after = last
+		if len(jumps) < sectorLoadBatch {
+			break
+		}
+
+		logger.LogInit(s, sprintf("Loaded %d/%d jumps", loaded, total))
+	}
+
+	logger.LogInit(s, sprintf("Loaded %d jumps in %s", loaded,
+		time.Since(start).Round(time.Millisecond)))
+}
+
+// trimJumps sorts a jump history and drops all but the most recent
+// jumpHistoryLimit jumps. The kept jumps are copied so that the dropped ones
+// can be freed.
+func trimJumps(h *[]ifaces.ShipCoordData) {
+	sort.Sort(jumpsByTime(*h))
+	if len(*h) > jumpHistoryLimit {
+		kept := (*h)[len(*h)-jumpHistoryLimit:]
+		*h = append([]ifaces.ShipCoordData(nil), kept...)
 	}
 }
 
@@ -1208,17 +2019,17 @@ func (s *Server) statusInt() int {
 	var sint = ifaces.ServerOffline
 
 	switch {
-	case state.isrestarting:
+	case state.current() == lifecycleRestarting:
 		sint = ifaces.ServerRestarting
-	case state.isstopping:
+	case state.current() == lifecycleStopping:
 		sint = ifaces.ServerStopping
-	case state.isstarting:
+	case state.current() == lifecycleStarting:
 		sint = ifaces.ServerStarting
 	case s.IsUp():
 		sint = ifaces.ServerOnline
 	}
 
-	if state.iscrashed {
+	if state.isCrashed() {
 		sint = ifaces.ServerCrashedOffline + sint
 	}
 
@@ -0,0 +1,69 @@
+package avorion
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// statusSnapshot holds the cached values that make up the server status
+type statusSnapshot struct {
+	name              string
+	version           string
+	onlineplayers     string
+	statusoutput      string
+	onlineplayercount int
+	playercount       int
+	alliancecount     int
+	sectorcount       int
+
+	// Last answer from the Steam query port
+	queryplayers int
+	queryslots   int
+	queryok      bool
+
+	// Version of the binary on disk, when it differs from the running version
+	installedversion string
+
+	// Why the game port is unreachable, or why the tracking database is
+	// running in memory
+	portfailure string
+	dbdegraded  string
+
+	// When the status supervisor will next check the server and refresh the
+	// player database
+	nextstatuscheck time.Time
+	nextdbupdate    time.Time
+}
+
+// statusCache holds the cached status values, which are written by the
+// supervisors and read by the Discord goroutines. Readers get an immutable
+// snapshot without locking, and writers are serialized so that updates based
+// on the current values aren't lost.
+type statusCache struct {
+	value atomic.Value
+	lock  sync.Mutex
+}
+
+// load returns the current snapshot of the cached status
+func (c *statusCache) load() statusSnapshot {
+	if snap, ok := c.value.Load().(statusSnapshot); ok {
+		return snap
+	}
+	return statusSnapshot{}
+}
+
+// update changes the cached status, and stores the result as a new snapshot
+func (c *statusCache) update(f func(*statusSnapshot)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	snap := c.load()
+	f(&snap)
+	c.value.Store(snap)
+}
+
+// online returns the number of players that are online
+func (c *statusCache) online() int {
+	return c.load().onlineplayercount
+}
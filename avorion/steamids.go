@@ -0,0 +1,79 @@
+package avorion
+
+import (
+	"avorioncontrol/logger"
+	"time"
+)
+
+const (
+	// How often the resolver looks for players without a steam ID
+	steamResolveInterval = 2 * time.Minute
+
+	// Players that can't be resolved are retried with a backoff, since the
+	// lookup fails for as long as the game has no record of their account
+	steamRetryMin = 5 * time.Minute
+	steamRetryMax = 6 * time.Hour
+)
+
+type steamRetry struct {
+	next  time.Time
+	delay time.Duration
+}
+
+// resolveSteamIDs resolves the steam IDs of players that don't have one
+// cached. Lookups are run one at a time at background priority, so that the
+// resolver never competes with commands or the player database refresh.
+func (s *Server) resolveSteamIDs(closech chan struct{}) {
+	retries := make(map[string]*steamRetry)
+
+	for {
+		select {
+		case <-closech:
+			return
+		case <-s.exit:
+			return
+		case <-s.clock.After(steamResolveInterval):
+		}
+
+		// Copy the list, since the player database refresh may append to it
+		players := make([]*Player, len(s.players))
+		copy(players, s.players)
+
+		for _, p := range players {
+			if p.steam64 != 0 {
+				delete(retries, p.index)
+				continue
+			}
+
+			r, ok := retries[p.index]
+			if ok && s.clock.Now().Before(r.next) {
+				continue
+			}
+
+			select {
+			case <-closech:
+				return
+			case <-s.exit:
+				return
+			default:
+			}
+
+			_, err := p.resolveSteamUID()
+			if err == nil {
+				delete(retries, p.index)
+				continue
+			}
+
+			if !ok {
+				r = &steamRetry{delay: steamRetryMin}
+				retries[p.index] = r
+			} else if r.delay *= 2; r.delay > steamRetryMax {
+				r.delay = steamRetryMax
+			}
+
+			r.next = s.clock.Now().Add(r.delay)
+			logger.LogDebug(p, sprintf("Steam ID lookup failed (%s), retrying in %s",
+				err.Error(), r.delay))
+		}
+	}
+}
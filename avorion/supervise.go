@@ -0,0 +1,85 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"runtime/debug"
+	"time"
+)
+
+const (
+	supervisorBackoffMin = time.Second
+	supervisorBackoffMax = time.Minute
+	supervisorStackLen   = 1500
+
+	noticeSupervisorPanic = "**Server Warning**: The %s panicked and will be " +
+		"restarted in %s\n**Panic:** `%v`"
+	noticeSupervisorStack = "**Stack trace:**\n```%s```"
+)
+
+// supervise runs one of the servers supervisor goroutines, recovering from
+// any panic that it raises and running it again after a backoff. The backoff
+// doubles with each consecutive panic, and is reset once the goroutine has run
+// for longer than the maximum backoff. Supervision ends when the goroutine
+// returns normally, or when closech or the exit channel are closed.
+func (s *Server) supervise(name string, closech chan struct{}, fn func()) {
+	defer shutdown.Recover()
+
+	backoff := supervisorBackoffMin
+
+	for {
+		started := s.clock.Now()
+		if !s.runSupervised(name, backoff, fn) {
+			return
+		}
+
+		if s.clock.Now().Sub(started) > supervisorBackoffMax {
+			backoff = supervisorBackoffMin
+		}
+
+		select {
+		case <-closech:
+			return
+		case <-s.exit:
+			return
+		case <-s.clock.After(backoff):
+		}
+
+		logger.LogWarning(s, "Restarting the "+name)
+		if backoff *= 2; backoff > supervisorBackoffMax {
+			backoff = supervisorBackoffMax
+		}
+	}
+}
+
+// runSupervised runs a supervisor goroutine and returns whether or not it
+// panicked. Panics are logged with their stack trace, and reported to the log
+// channel.
+func (s *Server) runSupervised(name string, backoff time.Duration,
+	fn func()) (panicked bool) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		panicked = true
+		stack := string(debug.Stack())
+		logger.LogError(s, sprintf("%s panicked: %v\n%s", name, r, stack))
+
+		if len(stack) > supervisorStackLen {
+			stack = stack[:supervisorStackLen] + "..."
+		}
+		// The stack trace goes in a thread with the warning, so that repeated
+		// panics don't flood the log channel
+		thread := "Panic in the " + name
+		s.SendLog(ifaces.ChatData{Thread: thread,
+			Msg: sprintf(noticeSupervisorPanic, name, backoff, r)})
+		s.SendLog(ifaces.ChatData{Thread: thread,
+			Msg: sprintf(noticeSupervisorStack, stack)})
+	}()
+
+	fn()
+	return false
+}
@@ -0,0 +1,177 @@
+package avorion
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// Executor describes an object that builds the commands that the server runs
+// on the host. Replacing it allows process management to be exercised without
+// launching the real Avorion or RCON binaries. The process is killed if the
+// context is done before it exits.
+type Executor interface {
+	Command(context.Context, ProcessOptions, string, ...string) Process
+}
+
+// ProcessOptions describes the environment that a command is run in
+type ProcessOptions struct {
+	Dir string
+	Env []string
+
+	// Output receives both stdout and stderr. It can't be set for commands
+	// that are run with Output or CombinedOutput.
+	Output io.Writer
+
+	// Group runs the command in a new process group, so that it can be
+	// signalled as a whole and doesn't receive the terminal's signals
+	Group bool
+}
+
+// Process describes a command that the server runs
+type Process interface {
+	Start() error
+	Wait() error
+	Output() ([]byte, error)
+	CombinedOutput() ([]byte, error)
+	StdinPipe() (io.WriteCloser, error)
+
+	// Pid returns the process ID, or 0 if the process hasn't been started
+	Pid() int
+	Signal(os.Signal) error
+
+	// Exit returns how the process exited, and false if it hasn't yet
+	Exit() (ProcessExit, bool)
+}
+
+// ProcessExit describes how a process exited
+type ProcessExit struct {
+	Code int
+
+	// Signal that ended the process, or 0 if it exited on its own
+	Signal syscall.Signal
+}
+
+// Filesystem describes the filesystem operations that the server performs
+type Filesystem interface {
+	Stat(string) (os.FileInfo, error)
+	Mkdir(string, os.FileMode) error
+	Open(string) (File, error)
+}
+
+// File describes a file that has been opened for reading
+type File interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// Clock describes a source of time for the server and its supervisors.
+// NewTicker returns a channel that receives the time at every interval, along
+// with a function that stops it.
+type Clock interface {
+	Now() time.Time
+	After(time.Duration) <-chan time.Time
+	NewTicker(time.Duration) (<-chan time.Time, func())
+}
+
+// hostExecutor runs commands directly on the host
+type hostExecutor struct{}
+
+func (hostExecutor) Command(ctx context.Context, opts ProcessOptions,
+	name string, args ...string) Process {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = opts.Dir
+	cmd.Env = opts.Env
+
+	if opts.Output != nil {
+		cmd.Stdout, cmd.Stderr = opts.Output, opts.Output
+	}
+
+	if opts.Group {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+
+	return hostProcess{cmd}
+}
+
+// hostProcess is a process running on the host
+type hostProcess struct {
+	*exec.Cmd
+}
+
+func (p hostProcess) Pid() int {
+	if p.Process == nil {
+		return 0
+	}
+	return p.Process.Pid
+}
+
+func (p hostProcess) Signal(sig os.Signal) error {
+	if p.Process == nil {
+		return errors.New("process has not been started")
+	}
+	return p.Process.Signal(sig)
+}
+
+func (p hostProcess) Exit() (ProcessExit, bool) {
+	ps := p.ProcessState
+	if ps == nil {
+		return ProcessExit{}, false
+	}
+
+	e := ProcessExit{Code: ps.ExitCode()}
+	if ws, ok := ps.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		e.Signal = ws.Signal()
+	}
+	return e, true
+}
+
+// hostFilesystem accesses the host filesystem
+type hostFilesystem struct{}
+
+func (hostFilesystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (hostFilesystem) Mkdir(name string, perm os.FileMode) error {
+	return os.Mkdir(name, perm)
+}
+
+func (hostFilesystem) Open(name string) (File, error) {
+	return os.Open(name)
+}
+
+// hostClock uses the system time
+type hostClock struct{}
+
+func (hostClock) Now() time.Time {
+	return time.Now()
+}
+
+func (hostClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (hostClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+// SetExecutor sets the Executor that the server uses to run commands
+func (s *Server) SetExecutor(e Executor) {
+	s.exec = e
+}
+
+// SetFilesystem sets the Filesystem that the server uses
+func (s *Server) SetFilesystem(f Filesystem) {
+	s.fs = f
+}
+
+// SetClock sets the Clock that the server and its supervisors use
+func (s *Server) SetClock(c Clock) {
+	s.clock = c
+}
@@ -0,0 +1,61 @@
+package avorion
+
+import (
+	"avorioncontrol/logger"
+	"strings"
+	"time"
+)
+
+// How often the binary on disk is checked for a different version than the
+// one that is running. The check runs the binary, so it isn't done on every
+// status check.
+const versionCheckInterval = 30 * time.Minute
+
+// refreshRunningVersion records the version of the binary that is about to be
+// started, so that updates that were installed while the server was offline
+// aren't reported as pending
+func (s *Server) refreshRunningVersion() {
+	s.cache.update(func(c *statusSnapshot) { c.installedversion = "" })
+	s.nextversioncheck = s.clock.Now()
+
+	version, err := s.InstallVersion(s.serverpath)
+	if err != nil {
+		logger.LogWarning(s, "Failed to check the installed version: "+err.Error())
+		return
+	}
+
+	if running := strings.TrimSpace(s.Version()); version != running {
+		logger.LogInfo(s, sprintf("Starting Avorion %s (was %s)", version,
+			running))
+	}
+	s.SetVersion(version)
+}
+
+// checkInstalledVersion compares the version of the binary on disk with the
+// version that is running, so that staff can be told when a SteamCMD update or
+// a manual binary swap needs a restart to take effect
+func (s *Server) checkInstalledVersion() {
+	now := s.clock.Now()
+	if now.Before(s.nextversioncheck) {
+		return
+	}
+	s.nextversioncheck = now.Add(versionCheckInterval)
+
+	version, err := s.InstallVersion(s.serverpath)
+	if err != nil {
+		logger.LogDebug(s, "Failed to check the installed version: "+err.Error())
+		return
+	}
+
+	cache := s.cache.load()
+	if version == strings.TrimSpace(cache.version) {
+		s.cache.update(func(c *statusSnapshot) { c.installedversion = "" })
+		return
+	}
+
+	if version != cache.installedversion {
+		logger.LogWarning(s, sprintf("Installed version %s differs from the "+
+			"running version %s", version, strings.TrimSpace(cache.version)))
+	}
+	s.cache.update(func(c *statusSnapshot) { c.installedversion = version })
+}
@@ -0,0 +1,23 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"time"
+)
+
+// voiceRestartWarning is how long before a restart it is announced in the
+// voice channel
+const voiceRestartWarning = 5 * time.Minute
+
+// announceVoice publishes a short announcement for the voice channel, if the
+// event is configured to be announced there
+func (s *Server) announceVoice(event, msg string) {
+	if !s.config.VoiceAnnounces(event) {
+		return
+	}
+
+	logger.LogDebug(s, sprintf("Voice announcement for %s: %s", event, msg))
+	s.bus.Publish(ifaces.EventTopicVoice, ifaces.ChatData{
+		Channel: s.config.VoiceChannel(), Msg: msg}, 0)
+}
@@ -0,0 +1,185 @@
+package avorion
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"strings"
+	"time"
+)
+
+const (
+	// Players are given a short countdown once a restart vote passes
+	voteRestartDelay = time.Minute
+
+	noticeVoteCalled = "%s called a vote to restart the server. Type !vote yes " +
+		"or !vote no within %s, %d votes are needed"
+	noticeVotePassed = "The restart vote passed with %d of %d votes"
+	noticeVoteFailed = "The restart vote failed with %d of %d votes"
+	noticeVoteLogged = "**Restart Vote**: A vote called by `%s` passed with " +
+		"%d of %d votes, restarting in %s"
+
+	msgVoteDisabled  = "Restart votes are disabled on this server"
+	msgVoteUsage     = "Usage: !votestart restart, or !vote yes/no"
+	msgVoteActive    = "There is already a restart vote in progress"
+	msgVoteNone      = "There is no vote in progress, call one with !votestart restart"
+	msgVoteScheduled = "A restart is already scheduled"
+	msgVoteMaint     = "Restart votes can't be called during maintenance"
+	msgVoteCooldown  = "Another restart vote can be called in %s"
+	msgVoteTooFew    = "At least %d players must be online to call a restart vote"
+	msgVoteRecorded  = "Your vote was recorded"
+)
+
+// restartVote is a vote that players have called to restart the server.
+// Votes are keyed by player index, so that a player can change their vote.
+type restartVote struct {
+	caller string
+	votes  map[string]bool
+	done   chan struct{}
+}
+
+// count returns the number of votes for and against the restart
+func (v *restartVote) count() (yes, no int) {
+	for _, y := range v.votes {
+		if y {
+			yes++
+		} else {
+			no++
+		}
+	}
+	return yes, no
+}
+
+// PlayerVote handles the !votestart and !vote chat commands, which let players
+// restart the server when there is nobody around to do it for them
+func (s *Server) PlayerVote(name, cmd, arg string) {
+	var p *Player
+	for _, pl := range s.players {
+		if pl.name == name {
+			p = pl
+			break
+		}
+	}
+
+	if p == nil {
+		logger.LogWarning(s, "Vote from unknown player: "+name)
+		return
+	}
+
+	if s.config.RestartVoteQuorum() == 0 {
+		p.Message(msgVoteDisabled)
+		return
+	}
+
+	s.votelock.Lock()
+	defer s.votelock.Unlock()
+
+	arg = strings.ToLower(arg)
+	switch {
+	case cmd == "votestart" && arg == "restart":
+		s.startVote(p)
+	case cmd == "vote" && (arg == "yes" || arg == "no"):
+		if s.vote == nil {
+			p.Message(msgVoteNone)
+			return
+		}
+
+		s.vote.votes[p.index] = arg == "yes"
+		p.Message(msgVoteRecorded)
+		s.tallyVote(false)
+	default:
+		p.Message(msgVoteUsage)
+	}
+}
+
+// startVote calls a restart vote on behalf of a player. It must be called with
+// the vote lock held.
+func (s *Server) startVote(p *Player) {
+	now := s.clock.Now()
+	cooldown := s.lastvote.Add(s.config.RestartVoteCooldown())
+	_, restarting := s.PendingRestart()
+
+	switch {
+	case s.vote != nil:
+		p.Message(msgVoteActive)
+		return
+	case restarting:
+		p.Message(msgVoteScheduled)
+		return
+	case s.maintenance != nil:
+		p.Message(msgVoteMaint)
+		return
+	case now.Before(cooldown):
+		p.Message(sprintf(msgVoteCooldown, cooldown.Sub(now).Round(time.Second)))
+		return
+	case int64(s.cache.online()) < s.config.RestartVoteMinPlayers():
+		p.Message(sprintf(msgVoteTooFew, s.config.RestartVoteMinPlayers()))
+		return
+	}
+
+	v := &restartVote{
+		caller: p.name,
+		votes:  map[string]bool{p.index: true},
+		done:   make(chan struct{})}
+	s.vote = v
+	s.lastvote = now
+
+	d := s.config.RestartVoteDuration()
+	logger.LogInfo(s, p.name+" called a restart vote")
+	s.NotifyServer(sprintf(noticeVoteCalled, p.name, d.Round(time.Second),
+		s.votesNeeded()))
+
+	go func() {
+		defer shutdown.Recover()
+		select {
+		case <-v.done:
+		case <-s.clock.After(d):
+			s.votelock.Lock()
+			if s.vote == v {
+				s.tallyVote(true)
+			}
+			s.votelock.Unlock()
+		}
+	}()
+
+	s.tallyVote(false)
+}
+
+// votesNeeded returns the number of votes that a restart needs to pass, based
+// on the number of players online
+func (s *Server) votesNeeded() int {
+	online := int64(s.cache.online())
+	needed := (online*s.config.RestartVoteQuorum() + 99) / 100
+	if needed < 1 {
+		needed = 1
+	}
+	return int(needed)
+}
+
+// tallyVote ends the current vote if it has passed, or if it can no longer
+// pass. Once the vote has closed, it fails unless it has passed. It must be
+// called with the vote lock held.
+func (s *Server) tallyVote(closed bool) {
+	v := s.vote
+	yes, no := v.count()
+	needed := s.votesNeeded()
+
+	switch {
+	case yes >= needed:
+		s.vote = nil
+		close(v.done)
+
+		logger.LogInfo(s, sprintf("Restart vote passed (%d/%d)", yes, needed))
+		s.NotifyServer(sprintf(noticeVotePassed, yes, needed))
+		s.SendLog(ifaces.ChatData{Msg: sprintf(noticeVoteLogged, v.caller, yes,
+			needed, voteRestartDelay)})
+		s.ScheduleRestart(voteRestartDelay)
+
+	case closed || s.cache.online()-no < needed:
+		s.vote = nil
+		close(v.done)
+
+		logger.LogInfo(s, sprintf("Restart vote failed (%d/%d)", yes, needed))
+		s.NotifyServer(sprintf(noticeVoteFailed, yes, needed))
+	}
+}
@@ -0,0 +1,60 @@
+package avorion
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// gameExecutable returns the name of the Avorion server binary to run. The
+// Windows binary is used on other platforms when the wrapper is wine or
+// proton, while other wrappers (box64, for instance) run the native binary.
+func gameExecutable(wrapper string) string {
+	if runtime.GOOS == "windows" || windowsWrapper(wrapper) {
+		return "AvorionServer.exe"
+	}
+	return "AvorionServer"
+}
+
+// windowsWrapper reports whether the given wrapper command runs Windows
+// binaries
+func windowsWrapper(wrapper string) bool {
+	fields := strings.Fields(wrapper)
+	if len(fields) == 0 {
+		return false
+	}
+
+	name := strings.ToLower(filepath.Base(fields[0]))
+	return strings.Contains(name, "wine") || strings.Contains(name, "proton")
+}
+
+// gameCommand returns the name and arguments used to run the given Avorion
+// binary, prefixed with the configured wrapper command
+func (s *Server) gameCommand(bin string, args ...string) (string, []string) {
+	wrapper := strings.Fields(s.config.WrapperCommand())
+	if len(wrapper) == 0 {
+		return bin, args
+	}
+
+	return wrapper[0], append(append(wrapper[1:], bin), args...)
+}
+
+// gameEnv returns the environment that Avorion is run with. The bundled
+// libraries are added to the library path whenever the native Linux binary is
+// run, wrapped or not.
+func (s *Server) gameEnv() []string {
+	env := os.Environ()
+	if gameExecutable(s.config.WrapperCommand()) == "AvorionServer" {
+		env = append(env, "LD_LIBRARY_PATH="+s.serverpath+"/linux64")
+	}
+
+	extra := make([]string, 0, len(s.config.GameEnvironment()))
+	for k, v := range s.config.GameEnvironment() {
+		extra = append(extra, k+"="+v)
+	}
+	sort.Strings(extra)
+
+	return append(env, extra...)
+}
@@ -0,0 +1,26 @@
+package avorion
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGameExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the Windows binary is always used on Windows")
+	}
+
+	for wrapper, expected := range map[string]string{
+		"":                             "AvorionServer",
+		"box64":                        "AvorionServer",
+		"/usr/local/bin/box64 -v":      "AvorionServer",
+		"wine":                         "AvorionServer.exe",
+		"/usr/bin/wine64":              "AvorionServer.exe",
+		"/opt/proton/Proton run":       "AvorionServer.exe",
+		"/opt/GE-Proton9-1/proton run": "AvorionServer.exe",
+	} {
+		if exe := gameExecutable(wrapper); exe != expected {
+			t.Errorf("wrapper %q: expected %s, got %s", wrapper, expected, exe)
+		}
+	}
+}
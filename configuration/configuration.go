@@ -2,14 +2,19 @@ package configuration
 
 import (
 	"avorioncontrol/ifaces"
+	"avorioncontrol/locale"
 	"avorioncontrol/logger"
+	"avorioncontrol/mods"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"math/rand"
+	"net"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -28,6 +33,7 @@ const (
 	defaultLoglevel    = 1
 	defaultBotsAllowed = false
 	defaultDiscordLink = ""
+	defaultUpdateURL   = "https://api.github.com/repos/SleepyFugu/avorioncontrol/releases/latest"
 
 	// Avorion
 	defaultGamePort           = 27000
@@ -40,17 +46,66 @@ const (
 	defaultServerInstallation = "/srv/avorion/server_files/"
 	defaultTimeDatabaseUpdate = int64(3600)
 	defaultTimeHangCheck      = int64(300)
+	defaultJumpAnomalyRate    = int64(120)
+	defaultTimeErrorSummary   = int64(3600)
 	defaultCommandPrefix      = "mention"
 	defaultStatusClear        = false
 	defaultEnforceMods        = false
 	defaultSentReact          = false
+	defaultAllianceChat       = false
+	defaultChatMentions       = false
+	defaultUniqueMessage      = "🎉 We just welcomed our **{count}th** unique player!"
+	defaultRecordMessage      = "🚀 New record: **{count}** players online at once!"
+	defaultPresence           = "Avorion"
+	defaultReturningGreeting  = "Welcome back, {name}! It has been {days} days " +
+		"since we last saw you."
+
+	defaultModWindow    = int64(60)
+	defaultMuteMinutes  = int64(30)
+	defaultTempBanHours = int64(24)
+
+	defaultIdleFreeSlots = int64(1)
+
+	defaultVoteMinPlayers = int64(2)
+	defaultVoteSeconds    = int64(120)
+	defaultVoteCooldown   = int64(60)
+
+	defaultMaintenanceMOTD = "The server is down for maintenance"
+
+	defaultPinAttempts       = int64(5)
+	defaultPinLockoutMinutes = int64(30)
+
+	defaultBackupKeep = int64(5)
 
 	defaultTimeZone = "America/New_York"
 	defaultDBName   = "data.db"
+
+	// API credentials copied from an old example configuration start with this
+	apiPlaceholder = "changeme"
 )
 
 var sprintf = fmt.Sprintf
 
+var (
+	defaultStatusLayout = []ifaces.EmbedField{
+		{Field: "state"},
+		{Field: "server_config", Inline: true},
+		{Field: "player_config", Inline: true},
+		{Field: "galaxy"},
+		{Field: "query"},
+		{Field: "restart"},
+		{Field: "maintenance"}}
+
+	// The public layout omits the seed and configuration limits, so that it
+	// can be shown to the community
+	defaultPublicStatusLayout = []ifaces.EmbedField{
+		{Field: "state"},
+		{Field: "server_info"},
+		{Field: "galaxy"},
+		{Field: "restart"},
+		{Field: "maintenance"}}
+)
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
@@ -64,16 +119,60 @@ type Conf struct {
 	logtime  bool
 	loglevel int
 	timezone string
+	location *time.Location
+	locale   *locale.Locale
 	dbname   string
 
+	// HTTP API
+	healthaddr      string
+	tlscert         string
+	tlskey          string
+	autocertdomains []string
+	autocertcache   string
+	trustedproxies  []*net.IPNet
+	apitokens       map[string][]string
+	apiusers        map[string]ifaces.APIUser
+	apioauth        ifaces.APIOAuth
+	pprof           bool
+
+	// Data exports
+	exportdir string
+
+	// Self updates
+	updateurl  string
+	updatekey  string
+	autoupdate bool
+
+	// Sentry-compatible error reporting
+	reportdsn string
+	reportenv string
+
 	// Avorion
 	galaxyname          string
+	pendinggalaxy       string
+	galaxylocked        bool
 	installdir          string
 	datadir             string
 	logfile             string
 	gameconfig          *ifaces.ServerGameConfig
+	gamesettings        map[string]string
 	hangtimeseconds     int64
 	dbupdatetimeseconds int64
+	jumpanomalyrate     int64
+	errorsummaryseconds int64
+	jumpretentiondays   int64
+	contested           []ifaces.Region
+
+	// Galaxy backups
+	backupdir      string
+	backupinterval int64
+	backupshutdown bool
+	backupkeep     int64
+	backupkeepdays int64
+	backupcompress bool
+
+	// Restarts on a cron-like schedule
+	restartschedule []string
 
 	rconbin  string
 	rconpass string
@@ -82,37 +181,108 @@ type Conf struct {
 	gameport int
 	pingport int
 
+	// Address used to check that the game port is reachable
+	publicaddr string
+
+	// JSONL file that the mod writes events to
+	eventfile string
+
 	// Custom Up/Down handling
 	postUpCmd   string
 	postDownCmd string
 
+	// Wrapper (wine, proton, box64) and environment used to run Avorion
+	wrapperCmd string
+	gameenv    map[string]string
+
 	// Discord
-	token              string
-	prefix             string
-	statuschannel      string
-	chatchannel        string
-	logchannel         string
-	discordLink        string
-	botsallowed        bool
-	statuschannelclear bool
+	token               string
+	prefix              string
+	statuschannel       string
+	publicstatuschannel string
+	controlpanelchannel string
+	controlpanelmessage string
+	inboxchannel        string
+	chatchannel         string
+	logchannel          string
+	discordLink         string
+	botsallowed         bool
+	statuschannelclear  bool
 
 	roleAuthLevels   map[string]int
 	cmndAuthLevels   map[string]int
+	userCmndAuth     map[string]map[string]bool
 	aliasedCommands  map[string][]string
+	aliasTemplates   map[string]map[string]string
 	disabledCommands []string
 
 	steamID         string
 	enforceMods     bool
 	sentreact       bool
+	alliancechat    bool
+	chatmentions    bool
+	chatlinks       []string
+	startupqueue    bool
+	presence        string
+	milestones      []int64
+	uniquemessage   string
+	recordmessage   string
+	returningdays   int64
+	greeting        string
+	welcomeback     string
+	memberleave     []string
+	memberleavecmd  string
+	voicechannel    string
+	voiceevents     []string
+	statuslayout    []ifaces.EmbedField
+	publiclayout    []ifaces.EmbedField
 	enabledMods     []int64
 	allowedMods     []int64
 	enabledModPaths []string
 
+	// Workshop mod validation
+	modvalidate   string
+	modclienttags []string
+	modservertags []string
+	steamapikey   string
+
+	// Workshop mod updates
+	steamcmd         string
+	modupdatecheck   time.Duration
+	modupdaterestart bool
+
 	loggedevents []*ifaces.LoggedServerEvent
 
-	// Chat
-	chatpipe chan ifaces.ChatData
-	logpipe  chan ifaces.ChatData
+	// Named bundles of server.ini values, mods, and schedules
+	presets map[string]ifaces.Preset
+
+	// Moderation
+	chatfilter   []*regexp.Regexp
+	escalation   []string
+	modwindow    int64
+	muteminutes  int64
+	tempbanhours int64
+
+	// Idle kick
+	idleminutes     int64
+	idlefreeslots   int64
+	idleexemptroles []string
+	idleexempttags  []string
+
+	// Restart votes
+	votequorum     int64
+	voteminplayers int64
+	voteseconds    int64
+	votecooldown   int64
+
+	// Maintenance mode
+	maintwhitelist []string
+	maintroles     []string
+	maintmotd      string
+
+	// Failed integration PINs
+	pinattempts int64
+	pinlockout  int64
 }
 
 // New returns a new object representing our program configuration
@@ -127,10 +297,14 @@ func New() *Conf {
 		datadir:             defaultDataDirectory,
 		dbupdatetimeseconds: defaultTimeDatabaseUpdate,
 		hangtimeseconds:     defaultTimeHangCheck,
+		jumpanomalyrate:     defaultJumpAnomalyRate,
+		errorsummaryseconds: defaultTimeErrorSummary,
 
 		rconbin:     defaultRconBin,
 		rconpass:    makePass(),
 		rconaddr:    defaultRconAddress,
+		publicaddr:  defaultRconAddress,
+		updateurl:   defaultUpdateURL,
 		discordLink: defaultDiscordLink,
 
 		rconport: defaultRconPort,
@@ -142,15 +316,57 @@ func New() *Conf {
 		steamID:         defaultModID,
 		enforceMods:     defaultEnforceMods,
 		sentreact:       defaultSentReact,
+		alliancechat:    defaultAllianceChat,
+		chatmentions:    defaultChatMentions,
+		milestones:      []int64{100, 250, 500, 1000, 2500, 5000, 10000},
+		uniquemessage:   defaultUniqueMessage,
+		presence:        defaultPresence,
+		recordmessage:   defaultRecordMessage,
+		greeting:        defaultReturningGreeting,
+		statuslayout:    defaultStatusLayout,
+		presets:         defaultPresets,
+		publiclayout:    defaultPublicStatusLayout,
 		enabledMods:     make([]int64, 0),
 		allowedMods:     make([]int64, 0),
+		modvalidate:     defaultModValidate,
+		modclienttags:   defaultModClientTags,
+		modservertags:   defaultModServerTags,
 		enabledModPaths: make([]string, 0),
 
 		timezone:        defaultTimeZone,
+		location:        defaultLocation(),
+		locale:          locale.Get(locale.DefaultTag),
 		roleAuthLevels:  make(map[string]int),
 		cmndAuthLevels:  make(map[string]int),
+		userCmndAuth:    make(map[string]map[string]bool),
 		aliasedCommands: make(map[string][]string),
-		loggedevents:    make([]*ifaces.LoggedServerEvent, 0)}
+		aliasTemplates:  make(map[string]map[string]string),
+		loggedevents:    make([]*ifaces.LoggedServerEvent, 0),
+
+		chatfilter:   make([]*regexp.Regexp, 0),
+		modwindow:    defaultModWindow,
+		muteminutes:  defaultMuteMinutes,
+		tempbanhours: defaultTempBanHours,
+
+		idlefreeslots:   defaultIdleFreeSlots,
+		idleexemptroles: make([]string, 0),
+		idleexempttags:  make([]string, 0),
+
+		voteminplayers: defaultVoteMinPlayers,
+		voteseconds:    defaultVoteSeconds,
+		votecooldown:   defaultVoteCooldown,
+
+		maintwhitelist: make([]string, 0),
+		maintroles:     make([]string, 0),
+		maintmotd:      defaultMaintenanceMOTD,
+
+		pinattempts: defaultPinAttempts,
+		pinlockout:  defaultPinLockoutMinutes,
+
+		backupkeep: defaultBackupKeep,
+
+		escalation: []string{ifaces.ModerationWarn, ifaces.ModerationMute,
+			ifaces.ModerationKick, ifaces.ModerationTempBan}}
 
 	return c
 }
@@ -183,9 +399,45 @@ func (c *Conf) TimeZone() string {
 	return c.timezone
 }
 
-// SetTimeZone -
+// SetTimeZone - Set the timezone used for output. The previous timezone is kept
+// if the one provided cannot be loaded.
 func (c *Conf) SetTimeZone(tz string) error {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return err
+	}
+
 	c.timezone = tz
+	c.location = loc
+	return nil
+}
+
+// Location - Return the effective location for the configured timezone
+func (c *Conf) Location() *time.Location {
+	if c.location == nil {
+		return time.UTC
+	}
+	return c.location
+}
+
+// Locale - Return the locale used to format numbers, dates, and times
+func (c *Conf) Locale() *locale.Locale {
+	if c.locale == nil {
+		return locale.Get(locale.DefaultTag)
+	}
+	return c.locale
+}
+
+// SetLocale - Set the locale used for output. The previous locale is kept if
+// the one provided isn't known.
+func (c *Conf) SetLocale(tag string) error {
+	l, ok := locale.Lookup(tag)
+	if !ok {
+		return fmt.Errorf("unknown locale %s (known locales: %s)", tag,
+			strings.Join(locale.Tags(), ", "))
+	}
+
+	c.locale = l
 	return nil
 }
 
@@ -283,6 +535,38 @@ func (c *Conf) SetAliasCommand(r string, a string) error {
 	return nil
 }
 
+// SetAliasTemplate - Configure a guild specific alias that expands into a full
+// command invocation. Setting an existing template alias replaces it.
+//  @g string    Guild the alias belongs to
+//  @a string    Alias to be configured
+//  @t string    Command template the alias expands to
+func (c *Conf) SetAliasTemplate(g, a, t string) error {
+	if ok, cmdname := c.GetAliasedCommand(a); ok {
+		return errors.New("Alias `" + a + "` is aleady aliased to `" +
+			cmdname + "`")
+	}
+
+	if c.aliasTemplates[g] == nil {
+		c.aliasTemplates[g] = make(map[string]string)
+	}
+
+	c.aliasTemplates[g][a] = t
+	logger.LogInfo(c, sprintf("Added command alias template for %s: %s -> %s",
+		g, a, t))
+	return nil
+}
+
+// AliasTemplate - Return the command template configured for an alias in the
+// given guild.
+//  @g string    Guild the alias belongs to
+//  @a string    Alias to be checked
+func (c *Conf) AliasTemplate(g, a string) (bool, string) {
+	if t, ok := c.aliasTemplates[g][a]; ok {
+		return true, t
+	}
+	return false, ""
+}
+
 // LoadConfiguration loads the given configuration file
 func (c *Conf) LoadConfiguration() error {
 	if _, err := os.Stat(c.ConfigFile); err != nil {
@@ -306,6 +590,15 @@ func (c *Conf) LoadConfiguration() error {
 		return err
 	}
 
+	// This is checked before anything is applied, so that a reload that fails
+	// leaves the running configuration as it was
+	if strings.Contains(out.Core.DBName, "/") {
+		fmt.Printf("Invalid DBName %s (must be a string not a path)\n",
+			out.Core.DBName)
+		return fmt.Errorf("invalid db_filename %s (must be a file name, not a "+
+			"path)", out.Core.DBName)
+	}
+
 	//TODO: Make this not a bunch of if statements
 	//TODO: Add configuration validation
 
@@ -317,6 +610,45 @@ func (c *Conf) LoadConfiguration() error {
 		c.hangtimeseconds = out.Game.SecondsTillHangCheck
 	}
 
+	// A negative rate disables jump anomaly detection entirely
+	if out.Game.JumpAnomalyRate != 0 {
+		c.jumpanomalyrate = out.Game.JumpAnomalyRate
+	}
+
+	// A negative interval disables script error summaries
+	if out.Game.SecondsTillErrorSum != 0 {
+		c.errorsummaryseconds = out.Game.SecondsTillErrorSum
+	}
+
+	// Raw jumps are kept forever unless a retention is configured
+	c.jumpretentiondays = out.Game.JumpRetentionDays
+
+	c.contested = make([]ifaces.Region, 0, len(out.Game.ContestedRegions))
+	for _, r := range out.Game.ContestedRegions {
+		// Corners can be given in either order
+		if r.MinX > r.MaxX {
+			r.MinX, r.MaxX = r.MaxX, r.MinX
+		}
+		if r.MinY > r.MaxY {
+			r.MinY, r.MaxY = r.MaxY, r.MinY
+		}
+		c.contested = append(c.contested, ifaces.Region(r))
+	}
+
+	// Backups are only taken on a schedule when an interval is configured, and
+	//	are kept regardless of their age unless keep_days is set
+	c.backupdir = out.Game.Backups.Directory
+	c.backupinterval = out.Game.Backups.IntervalMinutes
+	c.backupshutdown = out.Game.Backups.OnShutdown
+	c.backupkeepdays = out.Game.Backups.KeepDays
+	c.backupcompress = out.Game.Backups.Compress
+
+	if out.Game.Backups.Keep > 0 {
+		c.backupkeep = out.Game.Backups.Keep
+	}
+
+	c.restartschedule = out.Game.RestartSchedule
+
 	if !out.Core.LogTime {
 		c.logtime = false
 		log.SetFlags(0)
@@ -339,25 +671,97 @@ func (c *Conf) LoadConfiguration() error {
 	}
 
 	if out.Core.TimeZone != "" {
-		c.SetTimeZone(out.Core.TimeZone)
+		if err := c.SetTimeZone(out.Core.TimeZone); err != nil {
+			logger.LogWarning(c, sprintf(
+				"Invalid timezone %s configured (%s), falling back to %s",
+				out.Core.TimeZone, err.Error(), c.timezone))
+		}
 	}
 
-	if out.Core.DBName != "" {
-		if strings.Contains(out.Core.DBName, "/") {
-			fmt.Printf("Invalid DBName %s (must be a string not a path)\n",
-				out.Core.DBName)
-			os.Exit(1)
+	if out.Core.Locale != "" {
+		if err := c.SetLocale(out.Core.Locale); err != nil {
+			logger.LogWarning(c, sprintf("%s, falling back to %s", err.Error(),
+				c.Locale().Tag()))
 		}
+	}
+
+	if out.Core.DBName != "" {
 		c.dbname = out.Core.DBName
 	}
 
+	c.healthaddr = out.Core.HealthAddr
+	c.tlscert = out.API.TLSCert
+	c.tlskey = out.API.TLSKey
+	c.autocertdomains = out.API.AutocertDomains
+	c.autocertcache = out.API.AutocertCache
+	c.apitokens = out.API.Tokens
+	c.pprof = out.API.PProf
+
+	c.apiusers = make(map[string]ifaces.APIUser)
+	for name, u := range out.API.Users {
+		if !validAPIRole(u.Role) {
+			logger.LogWarning(c, sprintf("Ignoring API user %s with invalid role %s",
+				name, u.Role))
+			continue
+		}
+		c.apiusers[name] = ifaces.APIUser{Password: u.Password, Role: u.Role}
+	}
+
+	c.apioauth = ifaces.APIOAuth{
+		ClientID:     out.API.OAuth.ClientID,
+		ClientSecret: out.API.OAuth.ClientSecret,
+		RedirectURL:  out.API.OAuth.RedirectURL,
+		Levels:       make(map[string]int)}
+	for role, lvl := range out.API.OAuth.Levels {
+		if !validAPIRole(role) {
+			logger.LogWarning(c, "Ignoring OAuth level for invalid API role "+role)
+			continue
+		}
+		c.apioauth.Levels[role] = lvl
+	}
+
+	c.trustedproxies = make([]*net.IPNet, 0)
+	for _, p := range out.API.TrustedProxies {
+		if n, err := parseNetwork(p); err == nil {
+			c.trustedproxies = append(c.trustedproxies, n)
+		} else {
+			logger.LogError(c, sprintf("Invalid trusted proxy %s (%s)", p, err.Error()))
+		}
+	}
+	logger.SetTrustedProxies(c.trustedproxies)
+	c.exportdir = out.Core.ExportDir
+
+	if out.Core.Update.URL != "" {
+		c.updateurl = out.Core.Update.URL
+	}
+	c.updatekey = out.Core.Update.PublicKey
+	c.autoupdate = out.Core.Update.Auto
+
+	c.reportdsn = out.Core.Reporting.DSN
+	c.reportenv = out.Core.Reporting.Environment
+	if err := logger.SetErrorReporting(c.reportdsn, c.reportenv); err != nil {
+		logger.LogWarning(c, err.Error())
+	}
+
 	if out.Game.DataDir != "" {
 		c.datadir = out.Game.DataDir
 	}
 
-	if out.Game.GalaxyName != "" {
-		c.galaxyname = out.Game.GalaxyName
+	// Changing the galaxy while it is in use needs to be confirmed, so the new
+	// name is held until then
+	switch name := out.Game.GalaxyName; {
+	case name == "" || name == c.galaxyname:
+		c.pendinggalaxy = ""
+	case c.galaxylocked:
+		c.pendinggalaxy = name
+		logger.LogWarning(c, sprintf("Galaxy changed from %s to %s while the "+
+			"server is running, use server switch-galaxy to switch to it",
+			c.galaxyname, name))
+	default:
+		c.galaxyname = name
+		c.pendinggalaxy = ""
 	}
+	logger.SetReportTag("galaxy", c.galaxyname)
 
 	if out.Game.GamePort != 0 {
 		c.gameport = out.Game.GamePort
@@ -371,6 +775,12 @@ func (c *Conf) LoadConfiguration() error {
 		c.pingport = out.Game.PingPort
 	}
 
+	if out.Game.PublicAddress != "" {
+		c.publicaddr = out.Game.PublicAddress
+	}
+
+	c.eventfile = out.Game.EventFile
+
 	if out.RCON.Address != "" {
 		c.rconaddr = out.RCON.Address
 	}
@@ -395,12 +805,29 @@ func (c *Conf) LoadConfiguration() error {
 		c.SetStatusChannel(out.Discord.StatusChannel)
 	}
 
+	if out.Discord.PublicStatusChannel != "" {
+		c.SetPublicStatusChannel(out.Discord.PublicStatusChannel)
+	}
+
+	if out.Discord.ControlPanelChannel != "" {
+		c.SetControlPanelChannel(out.Discord.ControlPanelChannel)
+	}
+	c.controlpanelmessage = out.Discord.ControlPanelMessage
+
+	if out.Discord.InboxChannel != "" {
+		c.SetInboxChannel(out.Discord.InboxChannel)
+	}
+
 	if out.Discord.AliasedCommands != nil {
 		if len(out.Discord.AliasedCommands) > 0 {
 			c.aliasedCommands = out.Discord.AliasedCommands
 		}
 	}
 
+	if out.Discord.AliasTemplates != nil {
+		c.aliasTemplates = out.Discord.AliasTemplates
+	}
+
 	if out.Discord.DisabledCommands != nil {
 		if len(out.Discord.DisabledCommands) > 0 {
 			c.disabledCommands = out.Discord.DisabledCommands
@@ -415,6 +842,10 @@ func (c *Conf) LoadConfiguration() error {
 		c.roleAuthLevels = out.Discord.RoleAuthLevels
 	}
 
+	if out.Discord.UserCommands != nil {
+		c.userCmndAuth = out.Discord.UserCommands
+	}
+
 	if out.Discord.DiscordLink != "" {
 		c.discordLink = out.Discord.DiscordLink
 	}
@@ -440,6 +871,28 @@ func (c *Conf) LoadConfiguration() error {
 		c.enabledMods = out.Mods.Enabled
 	}
 
+	switch out.Mods.Validate {
+	case modValidateOff, modValidateWarn, modValidateEnforce:
+		c.modvalidate = out.Mods.Validate
+	case "":
+	default:
+		logger.LogWarning(c, sprintf("Invalid mod validation mode: %s",
+			out.Mods.Validate))
+	}
+
+	if len(out.Mods.ClientTags) > 0 {
+		c.modclienttags = out.Mods.ClientTags
+	}
+
+	if len(out.Mods.ServerTags) > 0 {
+		c.modservertags = out.Mods.ServerTags
+	}
+
+	c.steamapikey = out.Mods.SteamAPIKey
+	c.steamcmd = out.Mods.SteamCMD
+	c.modupdatecheck = time.Duration(out.Mods.UpdateCheckMinutes) * time.Minute
+	c.modupdaterestart = out.Mods.UpdateRestart
+
 	if out.Mods.ModPaths != nil {
 		c.enabledModPaths = out.Mods.ModPaths
 	}
@@ -465,15 +918,170 @@ func (c *Conf) LoadConfiguration() error {
 		}
 	}
 
+	if out.Presets != nil {
+		c.presets = c.loadPresets(out.Presets)
+	}
+
+	c.chatfilter = make([]*regexp.Regexp, 0)
+	for _, f := range out.Moderation.Filter {
+		re, err := regexp.Compile("(?i)" + f)
+		if err != nil {
+			logger.LogError(c, sprintf(`Failed to compile chat filter: [%s] (%s)`,
+				f, err.Error()))
+			continue
+		}
+		c.chatfilter = append(c.chatfilter, re)
+	}
+
+	if out.Moderation.Escalation != nil {
+		c.escalation = make([]string, 0)
+		for _, step := range out.Moderation.Escalation {
+			switch step {
+			case ifaces.ModerationWarn, ifaces.ModerationMute, ifaces.ModerationKick,
+				ifaces.ModerationTempBan, ifaces.ModerationBan:
+				c.escalation = append(c.escalation, step)
+			default:
+				logger.LogWarning(c, "Ignoring invalid escalation step: "+step)
+			}
+		}
+	}
+
+	if out.Moderation.Window > 0 {
+		c.modwindow = out.Moderation.Window
+	}
+
+	if out.Moderation.MuteMinutes > 0 {
+		c.muteminutes = out.Moderation.MuteMinutes
+	}
+
+	if out.Moderation.TempBanHours > 0 {
+		c.tempbanhours = out.Moderation.TempBanHours
+	}
+
+	// Idle kicking is disabled unless a duration is configured
+	c.idleminutes = out.Moderation.IdleKick.IdleMinutes
+	if out.Moderation.IdleKick.FreeSlots > 0 {
+		c.idlefreeslots = out.Moderation.IdleKick.FreeSlots
+	}
+
+	if out.Moderation.IdleKick.ExemptRoles != nil {
+		c.idleexemptroles = out.Moderation.IdleKick.ExemptRoles
+	}
+
+	if out.Moderation.IdleKick.ExemptTags != nil {
+		c.idleexempttags = out.Moderation.IdleKick.ExemptTags
+	}
+
+	// Restart votes are disabled unless a quorum is configured
+	c.votequorum = out.Moderation.RestartVote.Quorum
+	if c.votequorum > 100 {
+		c.votequorum = 100
+	}
+
+	if out.Moderation.RestartVote.MinPlayers > 0 {
+		c.voteminplayers = out.Moderation.RestartVote.MinPlayers
+	}
+
+	if out.Moderation.RestartVote.VoteSeconds > 0 {
+		c.voteseconds = out.Moderation.RestartVote.VoteSeconds
+	}
+
+	if out.Moderation.RestartVote.CooldownMinutes > 0 {
+		c.votecooldown = out.Moderation.RestartVote.CooldownMinutes
+	}
+
+	if out.Moderation.Maintenance.Whitelist != nil {
+		c.maintwhitelist = out.Moderation.Maintenance.Whitelist
+	}
+
+	if out.Moderation.Maintenance.AllowedRoles != nil {
+		c.maintroles = out.Moderation.Maintenance.AllowedRoles
+	}
+
+	if out.Moderation.Maintenance.MOTD != "" {
+		c.maintmotd = out.Moderation.Maintenance.MOTD
+	}
+
+	if out.Moderation.IntegrationPins.MaxAttempts > 0 {
+		c.pinattempts = out.Moderation.IntegrationPins.MaxAttempts
+	}
+
+	if out.Moderation.IntegrationPins.LockoutMinutes > 0 {
+		c.pinlockout = out.Moderation.IntegrationPins.LockoutMinutes
+	}
+
 	if out.Mods.SteamID != "" {
 		c.steamID = out.Mods.SteamID
 	}
 
 	c.enforceMods = out.Mods.Enforce
 	c.sentreact = out.Discord.SentReact
+	c.alliancechat = out.Discord.AllianceChat
+	c.chatmentions = out.Discord.ChatMentions
+	c.chatlinks = make([]string, 0)
+	for _, host := range out.Discord.ChatLinks {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			c.chatlinks = append(c.chatlinks, host)
+		}
+	}
+	c.startupqueue = out.Discord.StartupQueue
+
+	if out.Discord.Presence != "" {
+		c.presence = out.Discord.Presence
+	}
+
+	c.voicechannel = out.Discord.Voice.Channel
+	c.voiceevents = out.Discord.Voice.Events
+
+	if out.Discord.Milestones.UniquePlayers != nil {
+		c.milestones = out.Discord.Milestones.UniquePlayers
+		sort.Slice(c.milestones, func(i, j int) bool {
+			return c.milestones[i] < c.milestones[j]
+		})
+	}
+
+	if out.Discord.Milestones.UniqueMessage != "" {
+		c.uniquemessage = out.Discord.Milestones.UniqueMessage
+	}
+
+	if out.Discord.Milestones.RecordMessage != "" {
+		c.recordmessage = out.Discord.Milestones.RecordMessage
+	}
+
+	// Returning players are only greeted once a number of days is configured
+	c.returningdays = out.Discord.Returning.Days
+	c.welcomeback = out.Discord.Returning.Discord
+	if out.Discord.Returning.Greeting != "" {
+		c.greeting = out.Discord.Returning.Greeting
+	}
+
+	if out.Discord.StatusLayout != nil {
+		c.statuslayout = c.loadEmbedLayout("status_layout",
+			out.Discord.StatusLayout)
+	}
+
+	if out.Discord.PublicStatusLayout != nil {
+		c.publiclayout = c.loadEmbedLayout("public_status_layout",
+			out.Discord.PublicStatusLayout)
+	}
+
+	c.memberleave = make([]string, 0)
+	for _, action := range out.Discord.MemberLeave.Actions {
+		switch action {
+		case ifaces.MemberLeaveUnlink, ifaces.MemberLeaveCommand,
+			ifaces.MemberLeaveReview:
+			c.memberleave = append(c.memberleave, action)
+		default:
+			logger.LogWarning(c, "Ignoring invalid member leave action: "+action)
+		}
+	}
+	c.memberleavecmd = out.Discord.MemberLeave.Command
 	c.postUpCmd = out.Game.PostUpCommand
 	c.postDownCmd = out.Game.PostDownCommand
 
+	c.wrapperCmd = strings.TrimSpace(out.Game.WrapperCommand)
+	c.gameenv = out.Game.Environment
+
 	rconhost := fmt.Sprintf("[%s]\nhostname = %s\nport = %d\npassword = %s\n",
 		c.Galaxy(), c.RCONAddr(), c.RCONPort(), c.RCONPass())
 	ioutil.WriteFile(fmt.Sprintf("%s/rconhost.conf", c.DataPath()),
@@ -495,24 +1103,86 @@ func (c *Conf) SaveConfiguration() error {
 		}
 	}
 
+	// A galaxy change that hasn't been switched to yet is kept in the file
+	galaxy := c.galaxyname
+	if c.pendinggalaxy != "" {
+		galaxy = c.pendinggalaxy
+	}
+
+	proxies := make([]string, 0)
+	for _, n := range c.trustedproxies {
+		proxies = append(proxies, n.String())
+	}
+
+	users := make(map[string]yamlDataAPIUser)
+	for name, u := range c.apiusers {
+		users[name] = yamlDataAPIUser{Password: u.Password, Role: u.Role}
+	}
+
+	filter := make([]string, 0)
+	for _, re := range c.chatfilter {
+		filter = append(filter, strings.TrimPrefix(re.String(), "(?i)"))
+	}
+
 	y := &yamlData{
 		Core: yamlDataCore{
-			LogTime:  c.logtime,
-			TimeZone: c.timezone,
-			LogLevel: c.loglevel,
-			LogFile:  c.logfile,
-			DBName:   c.dbname},
+			LogTime:    c.logtime,
+			TimeZone:   c.timezone,
+			Locale:     c.Locale().Tag(),
+			LogLevel:   c.loglevel,
+			LogFile:    c.logfile,
+			DBName:     c.dbname,
+			HealthAddr: c.healthaddr,
+			ExportDir:  c.exportdir,
+			Update: yamlDataUpdate{
+				URL:       c.updateurl,
+				PublicKey: c.updatekey,
+				Auto:      c.autoupdate},
+			Reporting: yamlDataReporting{
+				DSN:         c.reportdsn,
+				Environment: c.reportenv}},
+
+		API: yamlDataAPI{
+			TLSCert:         c.tlscert,
+			TLSKey:          c.tlskey,
+			AutocertDomains: c.autocertdomains,
+			AutocertCache:   c.autocertcache,
+			TrustedProxies:  proxies,
+			Tokens:          c.apitokens,
+			PProf:           c.pprof,
+			Users:           users,
+			OAuth: yamlDataAPIOAuth{
+				ClientID:     c.apioauth.ClientID,
+				ClientSecret: c.apioauth.ClientSecret,
+				RedirectURL:  c.apioauth.RedirectURL,
+				Levels:       c.apioauth.Levels}},
 
 		Game: yamlDataGame{
-			GalaxyName:           c.galaxyname,
+			GalaxyName:           galaxy,
 			InstallDir:           c.installdir,
 			DataDir:              c.datadir,
 			GamePort:             c.gameport,
 			PingPort:             c.pingport,
+			PublicAddress:        c.publicaddr,
+			EventFile:            c.eventfile,
+			WrapperCommand:       c.wrapperCmd,
+			Environment:          c.gameenv,
 			PostUpCommand:        c.postUpCmd,
 			PostDownCommand:      c.postDownCmd,
 			SecondsTillDBUpdate:  c.dbupdatetimeseconds,
-			SecondsTillHangCheck: c.hangtimeseconds},
+			SecondsTillHangCheck: c.hangtimeseconds,
+			JumpAnomalyRate:      c.jumpanomalyrate,
+			SecondsTillErrorSum:  c.errorsummaryseconds,
+			JumpRetentionDays:    c.jumpretentiondays,
+			ContestedRegions:     saveRegions(c.contested),
+			Backups: yamlDataBackups{
+				Directory:       c.backupdir,
+				IntervalMinutes: c.backupinterval,
+				OnShutdown:      c.backupshutdown,
+				Keep:            c.backupkeep,
+				KeepDays:        c.backupkeepdays,
+				Compress:        c.backupcompress},
+			RestartSchedule: c.restartschedule},
 
 		RCON: yamlDataRCON{
 			Address: c.rconaddr,
@@ -520,28 +1190,89 @@ func (c *Conf) SaveConfiguration() error {
 			Port:    c.rconport},
 
 		Discord: yamlDataDiscord{
-			ClearStatusChannel: c.statuschannelclear,
-			SentReact:          c.sentreact,
-			LogChannel:         c.logchannel,
-			ChatChannel:        c.chatchannel,
-			StatusChannel:      c.statuschannel,
-			BotsAllowed:        c.botsallowed,
-			DiscordLink:        c.discordLink,
-			Prefix:             c.prefix,
-			Token:              c.token,
-			CommandAuthLevels:  c.cmndAuthLevels,
-			RoleAuthLevels:     c.roleAuthLevels,
-			AliasedCommands:    c.aliasedCommands,
-			DisabledCommands:   c.disabledCommands},
+			ClearStatusChannel:  c.statuschannelclear,
+			StartupQueue:        c.startupqueue,
+			Presence:            c.presence,
+			SentReact:           c.sentreact,
+			AllianceChat:        c.alliancechat,
+			ChatMentions:        c.chatmentions,
+			ChatLinks:           c.chatlinks,
+			LogChannel:          c.logchannel,
+			ChatChannel:         c.chatchannel,
+			StatusChannel:       c.statuschannel,
+			PublicStatusChannel: c.publicstatuschannel,
+			ControlPanelChannel: c.controlpanelchannel,
+			ControlPanelMessage: c.controlpanelmessage,
+			InboxChannel:        c.inboxchannel,
+			BotsAllowed:         c.botsallowed,
+			DiscordLink:         c.discordLink,
+			Prefix:              c.prefix,
+			Token:               c.token,
+			CommandAuthLevels:   c.cmndAuthLevels,
+			RoleAuthLevels:      c.roleAuthLevels,
+			UserCommands:        c.userCmndAuth,
+			AliasedCommands:     c.aliasedCommands,
+			AliasTemplates:      c.aliasTemplates,
+			DisabledCommands:    c.disabledCommands,
+			Milestones: yamlDataMilestones{
+				UniquePlayers: c.milestones,
+				UniqueMessage: c.uniquemessage,
+				RecordMessage: c.recordmessage},
+			Returning: yamlDataReturning{
+				Days:     c.returningdays,
+				Greeting: c.greeting,
+				Discord:  c.welcomeback},
+			MemberLeave: yamlDataMemberLeave{
+				Actions: c.memberleave,
+				Command: c.memberleavecmd},
+			Voice: yamlDataVoice{
+				Channel: c.voicechannel,
+				Events:  c.voiceevents},
+			StatusLayout:       saveEmbedLayout(c.statuslayout),
+			PublicStatusLayout: saveEmbedLayout(c.publiclayout)},
 
 		Mods: yamlDataMods{
 			SteamID:  c.steamID,
 			Enforce:  c.enforceMods,
 			Enabled:  c.enabledMods,
-			Allowed:  c.allowedMods,
-			ModPaths: c.enabledModPaths},
-
-		Events: events}
+			Allowed:    c.allowedMods,
+			ModPaths:   c.enabledModPaths,
+			Validate:   c.modvalidate,
+			ClientTags: c.modclienttags,
+			ServerTags: c.modservertags,
+
+			SteamAPIKey: c.steamapikey,
+
+			SteamCMD:           c.steamcmd,
+			UpdateCheckMinutes: int64(c.modupdatecheck / time.Minute),
+			UpdateRestart:      c.modupdaterestart},
+
+		Moderation: yamlDataModeration{
+			Filter:       filter,
+			Escalation:   c.escalation,
+			Window:       c.modwindow,
+			MuteMinutes:  c.muteminutes,
+			TempBanHours: c.tempbanhours,
+			IdleKick: yamlDataIdleKick{
+				IdleMinutes: c.idleminutes,
+				FreeSlots:   c.idlefreeslots,
+				ExemptRoles: c.idleexemptroles,
+				ExemptTags:  c.idleexempttags},
+			RestartVote: yamlDataRestartVote{
+				Quorum:          c.votequorum,
+				MinPlayers:      c.voteminplayers,
+				VoteSeconds:     c.voteseconds,
+				CooldownMinutes: c.votecooldown},
+			Maintenance: yamlDataMaintenance{
+				Whitelist:    c.maintwhitelist,
+				AllowedRoles: c.maintroles,
+				MOTD:         c.maintmotd},
+			IntegrationPins: yamlDataIntegrationPins{
+				MaxAttempts:    c.pinattempts,
+				LockoutMinutes: c.pinlockout}},
+
+		Events:  events,
+		Presets: savePresets(c.presets)}
 
 	if strings.HasPrefix(y.Discord.Prefix, "<@!") {
 		y.Discord.Prefix = "mention"
@@ -623,12 +1354,104 @@ func (c *Conf) StatusChannel() (string, bool) {
 	return "", false
 }
 
+// SetPublicStatusChannel sets the channel that the public status embed is
+//	posted to
+func (c *Conf) SetPublicStatusChannel(id string) {
+	logger.LogInfo(c, sprintf("Setting public status channel to: %s", id))
+	c.publicstatuschannel = id
+}
+
+// PublicStatusChannel returns the current public status channel
+func (c *Conf) PublicStatusChannel() (string, bool) {
+	if c.publicstatuschannel != "" {
+		return c.publicstatuschannel, true
+	}
+	return "", false
+}
+
+// StatusLayout returns the fields of the status embed, in the order that they
+//	are shown
+func (c *Conf) StatusLayout() []ifaces.EmbedField {
+	return c.statuslayout
+}
+
+// PublicStatusLayout returns the fields of the public status embed, in the
+//	order that they are shown
+func (c *Conf) PublicStatusLayout() []ifaces.EmbedField {
+	return c.publiclayout
+}
+
+// SetControlPanelChannel sets the channel that the server control panel is
+//	posted to
+func (c *Conf) SetControlPanelChannel(id string) {
+	logger.LogInfo(c, sprintf("Setting control panel channel to: %s", id))
+	c.controlpanelchannel = id
+}
+
+// ControlPanelChannel returns the current control panel channel
+func (c *Conf) ControlPanelChannel() (string, bool) {
+	if c.controlpanelchannel != "" {
+		return c.controlpanelchannel, true
+	}
+	return "", false
+}
+
+// SetControlPanelMessage sets the ID of the control panel message, so that
+//	the same message is reused when the bot starts again
+func (c *Conf) SetControlPanelMessage(id string) {
+	c.controlpanelmessage = id
+}
+
+// ControlPanelMessage returns the ID of the last control panel message
+func (c *Conf) ControlPanelMessage() string {
+	return c.controlpanelmessage
+}
+
+// SetInboxChannel sets the channel that private messages from players to the
+//	staff are posted to
+func (c *Conf) SetInboxChannel(id string) {
+	logger.LogInfo(c, sprintf("Setting staff inbox channel to: %s", id))
+	c.inboxchannel = id
+}
+
+// InboxChannel returns the channel that private messages from players to the
+//	staff are posted to, falling back to the log channel
+func (c *Conf) InboxChannel() string {
+	if c.inboxchannel != "" {
+		return c.inboxchannel
+	}
+	return c.logchannel
+}
+
 // StatusChannelClear returns whether or not the bot should clear
 //	our server status channel before posting
 func (c *Conf) StatusChannelClear() bool {
 	return c.statuschannelclear
 }
 
+// StartupQueue returns whether or not server control commands that are run
+// while the server is starting should be queued until it is ready
+func (c *Conf) StartupQueue() bool {
+	return c.startupqueue
+}
+
+// PresenceTemplate returns the template used for the bots Discord presence
+func (c *Conf) PresenceTemplate() string {
+	return c.presence
+}
+
+// MemberLeaveActions returns the actions that are taken when an integrated
+// Discord user leaves or is banned from the guild
+func (c *Conf) MemberLeaveActions() []string {
+	return c.memberleave
+}
+
+// MemberLeaveCommand returns the RCON command template that is run for the
+// player of an integrated Discord user that left the guild
+func (c *Conf) MemberLeaveCommand() string {
+	return c.memberleavecmd
+}
+
 /**********************************/
 /* IFace ifaces.IGameConfigurator */
 /**********************************/
@@ -638,6 +1461,12 @@ func (c *Conf) InstallPath() string {
 	return c.installdir
 }
 
+// SetInstallPath sets the installation path for Avorion
+func (c *Conf) SetInstallPath(path string) {
+	logger.LogInfo(c, sprintf("Setting install path to: %s", path))
+	c.installdir = path
+}
+
 // DataPath returns the current datapath for Avorion
 func (c *Conf) DataPath() string {
 	return c.datadir
@@ -651,6 +1480,34 @@ func (c *Conf) Galaxy() string {
 // SetGalaxy returns the current Galaxyname for Avorion
 func (c *Conf) SetGalaxy(name string) {
 	c.galaxyname = name
+	logger.SetReportTag("galaxy", name)
+}
+
+// LockGalaxy sets whether or not the galaxy is in use by a running server.
+// While it is, a different galaxy name loaded from the config file is held
+// as pending instead of being used.
+func (c *Conf) LockGalaxy(locked bool) {
+	c.galaxylocked = locked
+}
+
+// PendingGalaxy returns the galaxy name that was loaded while the galaxy was
+// in use, if there is one
+func (c *Conf) PendingGalaxy() (string, bool) {
+	return c.pendinggalaxy, c.pendinggalaxy != ""
+}
+
+// SwitchGalaxy makes the pending galaxy name the current one, and returns it
+func (c *Conf) SwitchGalaxy() (string, bool) {
+	if c.pendinggalaxy == "" {
+		return c.galaxyname, false
+	}
+
+	logger.LogInfo(c, sprintf("Switching galaxy from %s to %s", c.galaxyname,
+		c.pendinggalaxy))
+	c.galaxyname = c.pendinggalaxy
+	c.pendinggalaxy = ""
+	logger.SetReportTag("galaxy", c.galaxyname)
+	return c.galaxyname, true
 }
 
 // RCONBin returns the current RCON binary in use
@@ -674,6 +1531,42 @@ func (c *Conf) RCONPass() string {
 	return c.rconpass
 }
 
+// GamePort returns the port that players connect to the game on
+func (c *Conf) GamePort() int {
+	return c.gameport
+}
+
+// QueryPort returns the Steam query port, which answers server browser queries
+// with the public player count
+func (c *Conf) QueryPort() int {
+	return c.pingport
+}
+
+// PublicAddress returns the address that is used to check that the game port
+// is reachable
+func (c *Conf) PublicAddress() string {
+	return c.publicaddr
+}
+
+// EventFile returns the name of the JSONL file that the mod writes events to,
+// relative to the galaxy directory. Events are read from the game output when
+// this is empty.
+func (c *Conf) EventFile() string {
+	return c.eventfile
+}
+
+// WrapperCommand returns the command that Avorion is run through, such as wine
+// or box64. Avorion is run directly when this is empty
+func (c *Conf) WrapperCommand() string {
+	return c.wrapperCmd
+}
+
+// GameEnvironment returns the environment variables that are set for Avorion,
+// in addition to the environment of the bot
+func (c *Conf) GameEnvironment() map[string]string {
+	return c.gameenv
+}
+
 // PostUpCommand returns the command configured to be run when starting the
 // server
 func (c *Conf) PostUpCommand() string {
@@ -692,6 +1585,30 @@ func (c *Conf) HangTimeDuration() time.Duration {
 	return time.Duration(c.hangtimeseconds) * time.Second
 }
 
+// JumpAnomalyRate returns the maximum number of sectors that a single ship can
+// plausibly travel within a minute. Values below one disable anomaly detection
+func (c *Conf) JumpAnomalyRate() int64 {
+	return c.jumpanomalyrate
+}
+
+// ErrorSummaryDuration returns the time between summaries of the script errors
+// reported by the server. Durations below one second disable the summaries
+func (c *Conf) ErrorSummaryDuration() time.Duration {
+	return time.Duration(c.errorsummaryseconds) * time.Second
+}
+
+// JumpRetention returns how long individual jumps are kept in the tracking DB.
+// Hourly jump counts are kept regardless. A zero duration keeps every jump
+func (c *Conf) JumpRetention() time.Duration {
+	return time.Duration(c.jumpretentiondays) * 24 * time.Hour
+}
+
+// ContestedRegions returns the regions of the galaxy that alliances are alerted
+// about when their ships jump into them
+func (c *Conf) ContestedRegions() []ifaces.Region {
+	return c.contested
+}
+
 // DBUpdateTimeDuration returns a time.Duration based on the configured seconds until
 // between dbupdates
 func (c *Conf) DBUpdateTimeDuration() time.Duration {
@@ -704,26 +1621,9 @@ func (c *Conf) DBUpdateTimeDuration() time.Duration {
 
 // SetChatChannel sets the channel that ifaces chat is output to
 //	@id string		Channel ID to set
-func (c *Conf) SetChatChannel(id string) chan ifaces.ChatData {
+func (c *Conf) SetChatChannel(id string) {
 	c.chatchannel = id
-
-	// Close the channel if its still listening.
-	if c.chatpipe != nil {
-		select {
-		case _, ok := <-c.chatpipe:
-			if ok {
-				close(c.chatpipe)
-			}
-		case <-time.After(100 * time.Nanosecond):
-			logger.LogDebug(c, "Closing old chatpipe")
-			close(c.chatpipe)
-		}
-	}
-
 	logger.LogInfo(c, sprintf("Setting chat channel to: %s", id))
-
-	c.chatpipe = make(chan ifaces.ChatData, 100)
-	return c.chatpipe
 }
 
 // ChatChannel returns the current chat channel ID string
@@ -731,17 +1631,76 @@ func (c *Conf) ChatChannel() string {
 	return c.chatchannel
 }
 
-// ChatPipe returns a go channel for chat piping
-func (c *Conf) ChatPipe() chan ifaces.ChatData {
-	return c.chatpipe
-}
-
 // ReactConfirm returns a bool that determines whether or not to react to a
 // chat message that was transferred.
 func (c *Conf) ReactConfirm() bool {
 	return c.sentreact
 }
 
+// PlayerMilestones returns the unique player counts that should be celebrated,
+// in ascending order
+func (c *Conf) PlayerMilestones() []int64 {
+	return c.milestones
+}
+
+// MilestoneMessages returns the message templates used for unique player
+// milestones and concurrent player records
+func (c *Conf) MilestoneMessages() (string, string) {
+	return c.uniquemessage, c.recordmessage
+}
+
+// VoiceChannel returns the voice channel that events are announced in
+func (c *Conf) VoiceChannel() string {
+	return c.voicechannel
+}
+
+// VoiceAnnounces returns whether or not an event is announced in the voice
+// channel
+func (c *Conf) VoiceAnnounces(event string) bool {
+	if c.voicechannel == "" {
+		return false
+	}
+
+	for _, e := range c.voiceevents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ReturningPlayerAbsence returns how long a player has to have been away to be
+// greeted when they return. A zero duration disables the greeting
+func (c *Conf) ReturningPlayerAbsence() time.Duration {
+	return time.Duration(c.returningdays) * 24 * time.Hour
+}
+
+// ReturningPlayerMessages returns the in-game greeting for returning players,
+// and the message posted to Discord when they return (empty if disabled)
+func (c *Conf) ReturningPlayerMessages() (string, string) {
+	return c.greeting, c.welcomeback
+}
+
+// ChatMentions returns a bool that determines whether or not the names of
+// integrated players are converted to Discord mentions in relayed chat, and
+// Discord mentions are converted to names in-game
+func (c *Conf) ChatMentions() bool {
+	return c.chatmentions
+}
+
+// ChatLinkAllowlist returns the domains that links relayed from Discord to the
+// game may point to. Subdomains of a listed domain are also allowed. If the
+// list is empty, all links are relayed.
+func (c *Conf) ChatLinkAllowlist() []string {
+	return c.chatlinks
+}
+
+// AllianceChatRelay returns a bool that determines whether or not alliance
+// chat is relayed to Discord alongside the global chat.
+func (c *Conf) AllianceChatRelay() bool {
+	return c.alliancechat
+}
+
 /**************************************/
 /* IFace ifaces.ICommandAuthenticator */
 /**************************************/
@@ -799,6 +1758,47 @@ func (c *Conf) RemoveCmndAuth(cmnd string) error {
 	return nil
 }
 
+// SetUserCmndAuth overrides the authorization of a Discord user for a command,
+// either granting or revoking it regardless of their roles
+func (c *Conf) SetUserCmndAuth(uid, cmnd string, allowed bool) {
+	if _, ok := c.userCmndAuth[uid]; !ok {
+		c.userCmndAuth[uid] = make(map[string]bool)
+	}
+	c.userCmndAuth[uid][cmnd] = allowed
+	c.SaveConfiguration()
+}
+
+// RemoveUserCmndAuth removes the override for a Discord user and command, so
+// that their roles decide whether or not they can run it
+func (c *Conf) RemoveUserCmndAuth(uid, cmnd string) error {
+	if _, ok := c.userCmndAuth[uid][cmnd]; !ok {
+		return errors.New("no override is set for that user and command")
+	}
+
+	delete(c.userCmndAuth[uid], cmnd)
+	if len(c.userCmndAuth[uid]) == 0 {
+		delete(c.userCmndAuth, uid)
+	}
+	c.SaveConfiguration()
+	return nil
+}
+
+// UserCmndAuth returns the commands that have been granted (true) or revoked
+// (false) for each Discord user
+func (c *Conf) UserCmndAuth() map[string]map[string]bool {
+	return c.userCmndAuth
+}
+
+// CommandAllowed returns whether or not a Discord user may run a command. An
+// override for the user takes precedence, otherwise the authorization level
+// granted by their roles must meet the commands requirement.
+func (c *Conf) CommandAllowed(uid string, authlvl int, cmnd string) bool {
+	if allowed, ok := c.userCmndAuth[uid][cmnd]; ok {
+		return allowed
+	}
+	return authlvl >= c.GetCmndAuth(cmnd)
+}
+
 /**************************************/
 /* IFace ifaces.IDatabaseConfigurator */
 /**************************************/
@@ -809,10 +1809,331 @@ func (c *Conf) DBName() string {
 	return c.dbname
 }
 
+/**********************************/
+/* IFace ifaces.ICoreConfigurator */
+/**********************************/
+
+// HealthAddress returns the address that the HTTP health endpoint listens on,
+// or an empty string if it is disabled
+func (c *Conf) HealthAddress() string {
+	return c.healthaddr
+}
+
+// TLSFiles returns the certificate and key that the HTTP API uses for TLS
+func (c *Conf) TLSFiles() (string, string) {
+	return c.tlscert, c.tlskey
+}
+
+// AutocertDomains returns the domains that the HTTP API requests certificates
+// for using ACME, and the directory that they are cached in
+func (c *Conf) AutocertDomains() ([]string, string) {
+	cache := c.autocertcache
+	if cache == "" {
+		cache = strings.TrimSuffix(c.DataPath(), "/") + "/autocert"
+	}
+	return c.autocertdomains, cache
+}
+
+// TrustedProxies returns the networks of the reverse proxies whose
+// X-Forwarded-For headers are trusted
+func (c *Conf) TrustedProxies() []*net.IPNet {
+	return c.trustedproxies
+}
+
+// APIAuthEnabled returns whether or not any way of authenticating to the HTTP
+// API has been configured. If none has, the API is open.
+func (c *Conf) APIAuthEnabled() bool {
+	return len(c.apitokens) > 0 || len(c.apiusers) > 0 ||
+		c.apioauth.ClientID != ""
+}
+
+// APITokenScopes returns the scopes granted to an HTTP API token, and whether
+// or not the token is valid
+func (c *Conf) APITokenScopes(token string) ([]string, bool) {
+	for t, scopes := range c.apitokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return scopes, true
+		}
+	}
+
+	return nil, false
+}
+
+// APIUser returns the HTTP API account with the given name, if there is one
+func (c *Conf) APIUser(name string) (ifaces.APIUser, bool) {
+	u, ok := c.apiusers[name]
+	return u, ok
+}
+
+// APIOAuth returns the Discord OAuth2 application used to log in to the HTTP
+// API, and whether or not one has been configured
+func (c *Conf) APIOAuth() (ifaces.APIOAuth, bool) {
+	return c.apioauth, c.apioauth.ClientID != ""
+}
+
+// APIPlaceholders returns the HTTP API tokens and users that still use the
+// placeholder credentials from the example configuration
+func (c *Conf) APIPlaceholders() []string {
+	found := make([]string, 0)
+	for t := range c.apitokens {
+		if strings.HasPrefix(t, apiPlaceholder) {
+			found = append(found, "token "+t)
+		}
+	}
+
+	for name, u := range c.apiusers {
+		if strings.Contains(u.Password, apiPlaceholder) {
+			found = append(found, "user "+name)
+		}
+	}
+
+	sort.Strings(found)
+	return found
+}
+
+// PProfEnabled returns whether or not the HTTP API serves the pprof profiling
+// handlers to admins
+func (c *Conf) PProfEnabled() bool {
+	return c.pprof
+}
+
+// ExportPath returns the directory that data exports are written to when they
+// are too large to attach to a Discord message
+func (c *Conf) ExportPath() string {
+	if c.exportdir != "" {
+		return strings.TrimSuffix(c.exportdir, "/")
+	}
+	return strings.TrimSuffix(c.DataPath(), "/") + "/exports"
+}
+
+// UpdateSource returns the URL of the release API that self updates are
+// fetched from, and the base64 ed25519 key that release checksums must be
+// signed with (or an empty string if signatures aren't checked)
+func (c *Conf) UpdateSource() (string, string) {
+	return c.updateurl, c.updatekey
+}
+
+// AutoUpdate returns whether or not the bot installs updates when it starts.
+// Updates are only installed automatically when a public key is configured.
+func (c *Conf) AutoUpdate() bool {
+	return c.autoupdate
+}
+
+// LogFile returns the file that the bot logs to, if one has been configured
+func (c *Conf) LogFile() string {
+	return c.logfile
+}
+
+/****************************************/
+/* IFace ifaces.IModerationConfigurator */
+/****************************************/
+
+// ChatFilter returns the patterns that flag a chat message as a violation
+func (c *Conf) ChatFilter() []*regexp.Regexp {
+	return c.chatfilter
+}
+
+// ModerationPolicy returns the escalation steps that are taken against repeat
+// chat offenders, in order. Offences past the end of the policy repeat the last
+// step.
+func (c *Conf) ModerationPolicy() []string {
+	return c.escalation
+}
+
+// ModerationWindow returns the duration that a chat violation counts towards
+// the escalation policy
+func (c *Conf) ModerationWindow() time.Duration {
+	return time.Duration(c.modwindow) * time.Minute
+}
+
+// MuteDuration returns the duration that a muted player's chat is not relayed
+func (c *Conf) MuteDuration() time.Duration {
+	return time.Duration(c.muteminutes) * time.Minute
+}
+
+// TempBanDuration returns the duration of a temporary ban
+func (c *Conf) TempBanDuration() time.Duration {
+	return time.Duration(c.tempbanhours) * time.Hour
+}
+
+// IdleKickDuration returns the duration that a player can be inactive before
+// they can be kicked to free a slot. A zero duration disables idle kicking
+func (c *Conf) IdleKickDuration() time.Duration {
+	return time.Duration(c.idleminutes) * time.Minute
+}
+
+// IdleKickFreeSlots returns the number of free player slots below which idle
+// players are kicked
+func (c *Conf) IdleKickFreeSlots() int64 {
+	return c.idlefreeslots
+}
+
+// IdleKickExemptRoles returns the Discord role IDs whose linked players are
+// never kicked for being idle
+func (c *Conf) IdleKickExemptRoles() []string {
+	return c.idleexemptroles
+}
+
+// IdleKickExemptTags returns the name tags that exempt a player from being
+// kicked for being idle
+func (c *Conf) IdleKickExemptTags() []string {
+	return c.idleexempttags
+}
+
+// RestartVoteQuorum returns the percentage of online players that must vote
+// for a restart for it to pass. A quorum of zero disables restart votes
+func (c *Conf) RestartVoteQuorum() int64 {
+	return c.votequorum
+}
+
+// RestartVoteMinPlayers returns the number of players that must be online for
+// a restart vote to be called
+func (c *Conf) RestartVoteMinPlayers() int64 {
+	return c.voteminplayers
+}
+
+// RestartVoteDuration returns how long a restart vote stays open
+func (c *Conf) RestartVoteDuration() time.Duration {
+	return time.Duration(c.voteseconds) * time.Second
+}
+
+// RestartVoteCooldown returns how long players must wait after a restart vote
+// before calling another one
+func (c *Conf) RestartVoteCooldown() time.Duration {
+	return time.Duration(c.votecooldown) * time.Minute
+}
+
+// MaintenanceWhitelist returns the names or indexes of the players that can
+// stay on the server while it is in maintenance mode
+func (c *Conf) MaintenanceWhitelist() []string {
+	return c.maintwhitelist
+}
+
+// MaintenanceRoles returns the Discord role IDs whose linked players can stay
+// on the server while it is in maintenance mode
+func (c *Conf) MaintenanceRoles() []string {
+	return c.maintroles
+}
+
+// MaintenanceMOTD returns the MOTD that is set while the server is in
+// maintenance mode
+func (c *Conf) MaintenanceMOTD() string {
+	return c.maintmotd
+}
+
+// IntegrationPinAttempts returns how many integration PINs a Discord user can
+// get wrong before further attempts are blocked
+func (c *Conf) IntegrationPinAttempts() int64 {
+	return c.pinattempts
+}
+
+// IntegrationPinLockout returns how long a Discord user is blocked from trying
+// integration PINs after too many failed attempts
+func (c *Conf) IntegrationPinLockout() time.Duration {
+	return time.Duration(c.pinlockout) * time.Minute
+}
+
+/************************************/
+/* IFace ifaces.IBackupConfigurator */
+/************************************/
+
+// BackupPath returns the directory that galaxy backups are written to
+func (c *Conf) BackupPath() string {
+	if c.backupdir != "" {
+		return strings.TrimSuffix(c.backupdir, "/")
+	}
+	return strings.TrimSuffix(c.DataPath(), "/") + "/backups"
+}
+
+// BackupInterval returns the time between scheduled galaxy backups. A zero
+// duration disables scheduled backups
+func (c *Conf) BackupInterval() time.Duration {
+	if c.backupinterval <= 0 {
+		return 0
+	}
+	return time.Duration(c.backupinterval) * time.Minute
+}
+
+// BackupOnShutdown returns whether or not the galaxy is backed up whenever the
+// server is stopped cleanly
+func (c *Conf) BackupOnShutdown() bool {
+	return c.backupshutdown
+}
+
+// BackupRetention returns how many galaxy backups are kept, and how old they
+// can get before they're removed. A zero duration keeps them regardless of age
+func (c *Conf) BackupRetention() (int, time.Duration) {
+	keep := time.Duration(c.backupkeepdays) * 24 * time.Hour
+	if keep < 0 {
+		keep = 0
+	}
+	return int(c.backupkeep), keep
+}
+
+// BackupCompress returns whether or not galaxy backups are gzip compressed
+func (c *Conf) BackupCompress() bool {
+	return c.backupcompress
+}
+
+/*************************************/
+/* IFace ifaces.IRestartConfigurator */
+/*************************************/
+
+// RestartSchedule returns the cron-like schedules that the server is restarted
+// on, in the configured timezone
+func (c *Conf) RestartSchedule() []string {
+	return c.restartschedule
+}
+
 /*********************************/
 /* IFace ifaces.IModConfigurator */
 /*********************************/
 
+// modPrefix returns the directory that mods given by path are loaded from
+func (c *Conf) modPrefix() string {
+	return strings.TrimSuffix(c.datadir, "/") + "/mods/"
+}
+
+// SteamCMD returns the path to the SteamCMD binary that Workshop mods are
+// downloaded with, or an empty string if mods aren't downloaded ahead of time
+func (c *Conf) SteamCMD() string {
+	return c.steamcmd
+}
+
+// ModUpdateCheck returns how often the Workshop is checked for updates to the
+// server mods, and whether or not a restart is scheduled when one is found.
+// The check is disabled when the interval is zero.
+func (c *Conf) ModUpdateCheck() (time.Duration, bool) {
+	return c.modupdatecheck, c.modupdaterestart
+}
+
+// CompanionModPath returns the directory that the companion mod is deployed to
+func (c *Conf) CompanionModPath() string {
+	return c.modPrefix() + mods.CompanionName
+}
+
+// DeployCompanionMod installs or updates the companion mod from the copy
+// embedded in the bot, and regenerates modconfig.lua to load it
+func (c *Conf) DeployCompanionMod() (ifaces.ModDeployment, error) {
+	path := c.CompanionModPath()
+	logger.LogInfo(c, "Deploying the companion mod to "+path)
+
+	d, err := mods.Deploy(path)
+	if err != nil {
+		return ifaces.ModDeployment{}, err
+	}
+
+	logger.LogInfo(c, sprintf("Companion mod deployed (%d written, %d unchanged, "+
+		"%d removed)", d.Written, d.Unchanged, d.Removed))
+
+	if err := c.BuildModConfig(); err != nil {
+		return ifaces.ModDeployment{}, err
+	}
+
+	return ifaces.ModDeployment{Path: path, Written: d.Written,
+		Unchanged: d.Unchanged, Removed: d.Removed}, nil
+}
+
 // BuildModConfig generates a valid modconfig.lua file for Avorion
 func (c *Conf) BuildModConfig() error {
 	file := sprintf("%s/%s/modconfig.lua", c.datadir, c.galaxyname)
@@ -825,15 +2146,23 @@ func (c *Conf) BuildModConfig() error {
 		"modLocation   = \"\"\n"+
 		"forceEnabling = %t\n"+
 		"local prefix  = \"%s\"\n"+
-		"\nmods = {\n", c.enforceMods, c.datadir+"mods/")
+		"\nmods = {\n", c.enforceMods, c.modPrefix())
 
-	modconfig += sprintf("  {workshopid = \"%s\"},\n", c.steamID)
+	// A deployed copy of the companion mod takes the place of the Workshop one
+	if mods.Deployed(c.CompanionModPath()) {
+		modconfig += sprintf("  {path = prefix .. \"%s\"},\n", mods.CompanionName)
+	} else {
+		modconfig += sprintf("  {workshopid = \"%s\"},\n", c.steamID)
+	}
 
 	for _, modid := range c.enabledMods {
 		modconfig += sprintf("  {workshopid = \"%d\"},\n", modid)
 	}
 
 	for _, modpath := range c.enabledModPaths {
+		if modpath == mods.CompanionName {
+			continue
+		}
 		modconfig += sprintf("  {path = prefix .. \"%s\"},\n", modpath)
 	}
 
@@ -848,15 +2177,23 @@ func (c *Conf) BuildModConfig() error {
 	return ioutil.WriteFile(file, []byte(modconfig), 0644)
 }
 
-// AddServerMod adds a server mod to the config file and saves said config
-func (c *Conf) AddServerMod(id int64) error {
+// AddServerMod adds a server mod to the config file and saves said config. If
+// the Workshop lists the mod as client-only, a warning is returned, or the mod
+// is refused if validation is enforced.
+func (c *Conf) AddServerMod(id int64) (string, error) {
 	for _, found := range c.enabledMods {
 		if id == found {
-			return errors.New("Mod is already present in the configuration")
+			return "", errors.New("Mod is already present in the configuration")
 		}
 	}
+
+	warning, err := c.validateMod(id, true)
+	if err != nil {
+		return "", err
+	}
+
 	c.enabledMods = append(c.enabledMods, id)
-	return nil
+	return warning, nil
 }
 
 // RemoveServerMod removes a server mod to the config file and saves said config
@@ -874,15 +2211,23 @@ func (c *Conf) RemoveServerMod(id int64) error {
 	return errors.New("Mod is not present in the configuration")
 }
 
-// AddClientMod adds an allowed mod to the config file and saves said config
-func (c *Conf) AddClientMod(id int64) error {
+// AddClientMod adds an allowed mod to the config file and saves said config. If
+// the Workshop lists the mod as server-only, a warning is returned, or the mod
+// is refused if validation is enforced.
+func (c *Conf) AddClientMod(id int64) (string, error) {
 	for _, found := range c.allowedMods {
 		if id == found {
-			return errors.New("Mod is already present in the configuration")
+			return "", errors.New("Mod is already present in the configuration")
 		}
 	}
+
+	warning, err := c.validateMod(id, false)
+	if err != nil {
+		return "", err
+	}
+
 	c.allowedMods = append(c.allowedMods, id)
-	return nil
+	return warning, nil
 }
 
 // RemoveClientMod removes an allowed mod to the config file and saves said config
@@ -941,6 +2286,7 @@ func (c *Conf) LoadGameConfig() error {
 	gcfg.Difficulty = section.Key("Difficulty").MustInt()
 	gcfg.BlockLimit = section.Key("MaximumBlocksPerCraft").MustInt64()
 	gcfg.VolumeLimit = section.Key("MaximumVolumePerShip").MustInt64()
+	gcfg.MaxPlayers = cfg.Section("Administration").Key("maxPlayers").MustInt64()
 	gcfg.MaxPlayerShips = section.Key("MaximumPlayerShips").MustInt64()
 	gcfg.MaxPlayerSlots = section.Key("PlayerInventorySlots").MustInt64()
 	gcfg.MaxPlayerStations = section.Key("MaximumPlayerStations").MustInt64()
@@ -948,6 +2294,13 @@ func (c *Conf) LoadGameConfig() error {
 	gcfg.MaxAllianceShips = section.Key("MaximumAllianceShips").MustInt64()
 	gcfg.MaxAllianceStations = section.Key("MaximumAllianceStations").MustInt64()
 	c.gameconfig = gcfg
+
+	c.gamesettings = make(map[string]string)
+	for _, sec := range cfg.Sections() {
+		for _, key := range sec.Keys() {
+			c.gamesettings[sec.Name()+"."+key.Name()] = key.String()
+		}
+	}
 	logger.LogInit(c, "Loaded server.ini")
 	return nil
 }
@@ -960,32 +2313,77 @@ func (c *Conf) GameConfig() (*ifaces.ServerGameConfig, bool) {
 	return nil, false
 }
 
+// GameSettings returns every setting in the loaded server.ini, keyed by
+// "Section.Key"
+func (c *Conf) GameSettings() map[string]string {
+	return c.gamesettings
+}
+
+// GameConfigFile returns the contents of the server.ini file
+func (c *Conf) GameConfigFile() ([]byte, error) {
+	return ioutil.ReadFile(c.datadir + "/" + c.galaxyname + "/server.ini")
+}
+
+// WriteGameConfig replaces the server.ini file and reloads it. Avorion has to
+// be restarted for the changes to take effect.
+func (c *Conf) WriteGameConfig(data []byte) error {
+	if _, err := ini.Load(data); err != nil {
+		return err
+	}
+
+	file := c.datadir + "/" + c.galaxyname + "/server.ini"
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		logger.LogError(c, "Failed to save game ini: "+err.Error())
+		return err
+	}
+
+	return c.LoadGameConfig()
+}
+
+// GameConfigChanges returns the settings that replacing server.ini with the
+// given contents would change, sorted by setting name
+func (c *Conf) GameConfigChanges(data []byte) ([]ifaces.SettingChange, error) {
+	cfg, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]ifaces.SettingChange, 0)
+	seen := make(map[string]bool)
+	for _, sec := range cfg.Sections() {
+		for _, key := range sec.Keys() {
+			k := sec.Name() + "." + key.Name()
+			seen[k] = true
+			if old := c.gamesettings[k]; old != key.String() {
+				changes = append(changes, ifaces.SettingChange{
+					Setting: "server.ini " + k, Old: old, New: key.String()})
+			}
+		}
+	}
+
+	for k, old := range c.gamesettings {
+		if !seen[k] {
+			changes = append(changes, ifaces.SettingChange{
+				Setting: "server.ini " + k, Old: old})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Setting < changes[j].Setting
+	})
+
+	return changes, nil
+}
+
 /***********************************/
 /* IFace ifaces.IEventConfigurator */
 /***********************************/
 
 // SetLogChannel sets the channel that ifaces chat is output to
 //	@id string		Channel ID to set
-func (c *Conf) SetLogChannel(id string) chan ifaces.ChatData {
+func (c *Conf) SetLogChannel(id string) {
 	c.logchannel = id
-
-	// Close the channel if its still listening.
-	if c.logpipe != nil {
-		select {
-		case _, ok := <-c.logpipe:
-			if ok {
-				close(c.logpipe)
-			}
-		case <-time.After(100 * time.Nanosecond):
-			logger.LogDebug(c, "Closing old logpipe")
-			close(c.logpipe)
-		}
-	}
-
 	logger.LogInfo(c, sprintf("Setting log channel to: %s", id))
-
-	c.logpipe = make(chan ifaces.ChatData, 100)
-	return c.logpipe
 }
 
 // GetEvents returns the current list of LoggedServerEvents
@@ -993,11 +2391,6 @@ func (c *Conf) GetEvents() []*ifaces.LoggedServerEvent {
 	return c.loggedevents
 }
 
-// LogPipe returns a go channel for chat piping
-func (c *Conf) LogPipe() chan ifaces.ChatData {
-	return c.logpipe
-}
-
 // LogChannel returns the current chat channel ID string
 func (c *Conf) LogChannel() string {
 	return c.logchannel
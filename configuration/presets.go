@@ -0,0 +1,224 @@
+package configuration
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ini/ini"
+)
+
+// Presets that are available when none have been configured
+var defaultPresets = map[string]ifaces.Preset{
+	"casual": {
+		Name: "casual",
+		Settings: map[string]string{
+			"Game.Difficulty":           "-1",
+			"Game.PlayerToPlayerDamage": "false",
+			"Game.CollisionDamage":      "0"}},
+
+	"hardcore": {
+		Name: "hardcore",
+		Settings: map[string]string{
+			"Game.Difficulty":           "1",
+			"Game.PlayerToPlayerDamage": "true",
+			"Game.CollisionDamage":      "1"}},
+
+	"pvp-season": {
+		Name: "pvp-season",
+		Settings: map[string]string{
+			"Game.Difficulty":           "0",
+			"Game.PlayerToPlayerDamage": "true",
+			"Game.CollisionDamage":      "1"},
+		DBUpdate: 5 * time.Minute}}
+
+// loadPresets converts the configured presets, skipping any server.ini
+// settings that aren't named as "Section.Key". Preset names are lowercased,
+// since they are looked up case insensitively.
+func (c *Conf) loadPresets(in map[string]yamlDataPreset) map[string]ifaces.Preset {
+	presets := make(map[string]ifaces.Preset, len(in))
+	for name, p := range in {
+		name = strings.ToLower(name)
+		if _, ok := presets[name]; ok {
+			logger.LogWarning(c, sprintf("Preset %s is configured more than "+
+				"once, ignoring the duplicate", name))
+			continue
+		}
+
+		settings := make(map[string]string, len(p.Settings))
+		for k, v := range p.Settings {
+			if sec, key := splitSetting(k); sec == "" || key == "" {
+				logger.LogWarning(c, sprintf("Preset %s: invalid setting %q "+
+					"(expected Section.Key)", name, k))
+				continue
+			}
+			settings[k] = v
+		}
+
+		presets[name] = ifaces.Preset{
+			Name:       name,
+			Settings:   settings,
+			ServerMods: p.ServerMods,
+			ClientMods: p.ClientMods,
+			DBUpdate:   time.Duration(p.SecondsTillDBUpdate) * time.Second,
+			HangCheck:  time.Duration(p.SecondsTillHangCheck) * time.Second}
+	}
+
+	return presets
+}
+
+// savePresets converts presets back into their configuration format
+func savePresets(in map[string]ifaces.Preset) map[string]yamlDataPreset {
+	presets := make(map[string]yamlDataPreset, len(in))
+	for name, p := range in {
+		presets[name] = yamlDataPreset{
+			Settings:             p.Settings,
+			ServerMods:           p.ServerMods,
+			ClientMods:           p.ClientMods,
+			SecondsTillDBUpdate:  int64(p.DBUpdate / time.Second),
+			SecondsTillHangCheck: int64(p.HangCheck / time.Second)}
+	}
+
+	return presets
+}
+
+// splitSetting splits a "Section.Key" server.ini setting name
+func splitSetting(setting string) (string, string) {
+	i := strings.Index(setting, ".")
+	if i < 0 {
+		return "", ""
+	}
+	return setting[:i], setting[i+1:]
+}
+
+// joinMods formats a list of mods for comparison and display
+func joinMods(mods []int64) string {
+	ids := make([]string, len(mods))
+	for i, id := range mods {
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(ids, ", ")
+}
+
+/************************************/
+/* IFace ifaces.IPresetConfigurator */
+/************************************/
+
+// Presets returns the names of the configured presets, in alphabetical order
+func (c *Conf) Presets() []string {
+	names := make([]string, 0, len(c.presets))
+	for name := range c.presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PresetChanges returns the settings that applying the named preset would
+// change, sorted by setting name
+func (c *Conf) PresetChanges(name string) ([]ifaces.SettingChange, error) {
+	p, ok := c.presets[name]
+	if !ok {
+		return nil, errors.New("no preset named " + name)
+	}
+
+	changes := make([]ifaces.SettingChange, 0)
+	add := func(setting, old, new string) {
+		if old != new {
+			changes = append(changes, ifaces.SettingChange{
+				Setting: setting, Old: old, New: new})
+		}
+	}
+
+	for k, v := range p.Settings {
+		add("server.ini "+k, c.gamesettings[k], v)
+	}
+
+	if p.ServerMods != nil {
+		add("Mods.enabled", joinMods(c.enabledMods), joinMods(p.ServerMods))
+	}
+
+	if p.ClientMods != nil {
+		add("Mods.allowed", joinMods(c.allowedMods), joinMods(p.ClientMods))
+	}
+
+	if p.DBUpdate > 0 {
+		add("Game.seconds_until_dbupdate",
+			strconv.FormatInt(c.dbupdatetimeseconds, 10),
+			strconv.FormatInt(int64(p.DBUpdate/time.Second), 10))
+	}
+
+	if p.HangCheck > 0 {
+		add("Game.seconds_until_hangcheck",
+			strconv.FormatInt(c.hangtimeseconds, 10),
+			strconv.FormatInt(int64(p.HangCheck/time.Second), 10))
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Setting < changes[j].Setting
+	})
+
+	return changes, nil
+}
+
+// ApplyPreset writes the server.ini settings of the named preset, replaces the
+// configured mods and schedule intervals that it sets, and saves the
+// configuration. Avorion has to be restarted for the changes to take effect.
+func (c *Conf) ApplyPreset(name string) error {
+	p, ok := c.presets[name]
+	if !ok {
+		return errors.New("no preset named " + name)
+	}
+
+	if len(p.Settings) > 0 {
+		file := c.datadir + "/" + c.galaxyname + "/server.ini"
+		cfg, err := ini.Load(file)
+		if err != nil {
+			logger.LogError(c, "Failed to load game ini: "+err.Error())
+			return err
+		}
+
+		for k, v := range p.Settings {
+			sec, key := splitSetting(k)
+			cfg.Section(sec).Key(key).SetValue(v)
+		}
+
+		if err := cfg.SaveTo(file); err != nil {
+			logger.LogError(c, "Failed to save game ini: "+err.Error())
+			return err
+		}
+
+		if err := c.LoadGameConfig(); err != nil {
+			return err
+		}
+	}
+
+	if p.ServerMods != nil {
+		c.enabledMods = append([]int64{}, p.ServerMods...)
+	}
+
+	if p.ClientMods != nil {
+		c.allowedMods = append([]int64{}, p.ClientMods...)
+	}
+
+	if p.ServerMods != nil || p.ClientMods != nil {
+		if err := c.BuildModConfig(); err != nil {
+			return err
+		}
+	}
+
+	if p.DBUpdate > 0 {
+		c.dbupdatetimeseconds = int64(p.DBUpdate / time.Second)
+	}
+
+	if p.HangCheck > 0 {
+		c.hangtimeseconds = int64(p.HangCheck / time.Second)
+	}
+
+	logger.LogInfo(c, "Applied preset "+name)
+	return c.SaveConfiguration()
+}
@@ -1,9 +1,13 @@
 package configuration
 
 import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
 	"math/rand"
 	"net"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func isPortAvailable(p int) bool {
@@ -26,3 +30,97 @@ func makePass() string {
 	}
 	return string(b)
 }
+
+// defaultLocation loads the default timezone, falling back to UTC on hosts that
+// are missing timezone data
+func defaultLocation() *time.Location {
+	loc, err := time.LoadLocation(defaultTimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseNetwork parses a CIDR network, or a single IP address as a network
+// containing only that address
+func parseNetwork(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		if ip := net.ParseIP(s); ip != nil {
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+		}
+	}
+
+	_, n, err := net.ParseCIDR(s)
+	return n, err
+}
+
+// loadEmbedLayout converts an embed layout from the config file, skipping
+// fields that name an unknown built-in field, or that have nothing to show
+func (c *Conf) loadEmbedLayout(key string,
+	fields []yamlDataEmbedField) []ifaces.EmbedField {
+	layout := make([]ifaces.EmbedField, 0, len(fields))
+	for i, f := range fields {
+		f.Field = strings.ToLower(strings.TrimSpace(f.Field))
+		switch {
+		case f.Field != "" && !validEmbedField(f.Field):
+			logger.LogWarning(c, sprintf("Ignoring unknown field %s in %s",
+				f.Field, key))
+			continue
+		case f.Field == "" && (f.Name == "" || f.Value == ""):
+			logger.LogWarning(c, sprintf(
+				"Ignoring field %d in %s, custom fields need a name and value",
+				i+1, key))
+			continue
+		}
+
+		layout = append(layout, ifaces.EmbedField{
+			Field:  f.Field,
+			Name:   f.Name,
+			Value:  f.Value,
+			Inline: f.Inline,
+			Hidden: f.Hidden})
+	}
+	return layout
+}
+
+// saveEmbedLayout converts an embed layout back into its config file form
+func saveEmbedLayout(layout []ifaces.EmbedField) []yamlDataEmbedField {
+	fields := make([]yamlDataEmbedField, 0, len(layout))
+	for _, f := range layout {
+		fields = append(fields, yamlDataEmbedField(f))
+	}
+	return fields
+}
+
+// saveRegions converts regions back into their config file form
+func saveRegions(regions []ifaces.Region) []yamlDataRegion {
+	out := make([]yamlDataRegion, 0, len(regions))
+	for _, r := range regions {
+		out = append(out, yamlDataRegion(r))
+	}
+	return out
+}
+
+// validEmbedField returns true if the field is a built-in status embed field
+func validEmbedField(field string) bool {
+	for _, f := range ifaces.StatusEmbedFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// validAPIRole returns true if the role is one that the HTTP API knows
+func validAPIRole(role string) bool {
+	for _, r := range ifaces.APIRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
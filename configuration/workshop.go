@@ -0,0 +1,266 @@
+package configuration
+
+import (
+	"avorioncontrol/ifaces"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	workshopDetailsURL = "https://api.steampowered.com/ISteamRemoteStorage/" +
+		"GetPublishedFileDetails/v1/"
+	workshopQueryURL = "https://api.steampowered.com/IPublishedFileService/" +
+		"QueryFiles/v1/"
+	workshopTimeout = 10 * time.Second
+	avorionAppID    = 445220
+
+	modValidateOff     = "off"
+	modValidateWarn    = "warn"
+	modValidateEnforce = "enforce"
+
+	defaultModValidate = modValidateWarn
+
+	// Workshop query type that ranks results by how well they match the text
+	workshopRankedByText = 12
+)
+
+var (
+	defaultModClientTags = []string{"client"}
+	defaultModServerTags = []string{"server"}
+)
+
+// Sides of the game that a Workshop mod runs on, as determined from its tags
+const (
+	modSideUnknown = iota
+	modSideClient
+	modSideServer
+	modSideBoth
+)
+
+// workshopItem is the subset of the Workshop file details that validation uses
+type workshopItem struct {
+	Result int    `json:"result"`
+	Title  string `json:"title"`
+	AppID  int    `json:"consumer_app_id"`
+	Tags   []struct {
+		Tag string `json:"tag"`
+	} `json:"tags"`
+}
+
+// workshopCache holds looked up Workshop items, since their tags rarely change
+var workshopCache = struct {
+	sync.Mutex
+	items map[int64]*workshopItem
+}{items: make(map[int64]*workshopItem)}
+
+// fetchWorkshopItem looks up the details of a Workshop item
+func fetchWorkshopItem(id int64) (*workshopItem, error) {
+	workshopCache.Lock()
+	item, ok := workshopCache.items[id]
+	workshopCache.Unlock()
+	if ok {
+		return item, nil
+	}
+
+	client := &http.Client{Timeout: workshopTimeout}
+	resp, err := client.PostForm(workshopDetailsURL, url.Values{
+		"itemcount":           {"1"},
+		"publishedfileids[0]": {strconv.FormatInt(id, 10)}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Workshop lookup failed (%s)", resp.Status)
+	}
+
+	var data struct {
+		Response struct {
+			Details []*workshopItem `json:"publishedfiledetails"`
+		} `json:"response"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	if len(data.Response.Details) == 0 || data.Response.Details[0].Result != 1 {
+		return nil, errors.New("Workshop item does not exist")
+	}
+
+	item = data.Response.Details[0]
+	workshopCache.Lock()
+	workshopCache.items[id] = item
+	workshopCache.Unlock()
+	return item, nil
+}
+
+// modSide returns the side of the game that a mod runs on, using the tags that
+// have been configured for client and server mods
+func (c *Conf) modSide(item *workshopItem) int {
+	var client, server bool
+	for _, t := range item.Tags {
+		tag := strings.ToLower(t.Tag)
+		for _, ct := range c.modclienttags {
+			client = client || strings.Contains(tag, strings.ToLower(ct))
+		}
+		for _, st := range c.modservertags {
+			server = server || strings.Contains(tag, strings.ToLower(st))
+		}
+	}
+
+	switch {
+	case client && server:
+		return modSideBoth
+	case client:
+		return modSideClient
+	case server:
+		return modSideServer
+	}
+	return modSideUnknown
+}
+
+// validateMod checks a Workshop mod against the list that it is being added
+// to, and returns a warning if it doesn't belong there. When validation is
+// enforced, the warning is returned as an error instead.
+func (c *Conf) validateMod(id int64, serverlist bool) (string, error) {
+	if c.modvalidate == modValidateOff {
+		return "", nil
+	}
+
+	item, err := fetchWorkshopItem(id)
+	if err != nil {
+		// Steam being unreachable shouldn't stop admins from managing mods
+		return "Could not validate the mod: " + err.Error(), nil
+	}
+
+	var warning string
+	side := c.modSide(item)
+	switch {
+	case item.AppID != avorionAppID:
+		warning = sprintf("%s is not an Avorion mod", item.Title)
+	case serverlist && side == modSideClient:
+		warning = sprintf("%s is a client-only mod, and should be added to the "+
+			"allowed client mods instead", item.Title)
+	case !serverlist && side == modSideServer:
+		warning = sprintf("%s is a server-only mod, and has no effect as a "+
+			"client mod", item.Title)
+	}
+
+	if warning != "" && c.modvalidate == modValidateEnforce {
+		return "", errors.New(warning)
+	}
+	return warning, nil
+}
+
+// SearchWorkshop searches the Steam Workshop for Avorion mods, ranked by how
+// well they match the query. It returns a page of results, and the total
+// number of mods that matched. Searching requires a Steam Web API key.
+func (c *Conf) SearchWorkshop(query string, page int) ([]ifaces.WorkshopItem,
+	int, error) {
+	if c.steamapikey == "" {
+		return nil, 0, errors.New("no Steam Web API key is configured")
+	}
+
+	client := &http.Client{Timeout: workshopTimeout}
+	resp, err := client.Get(workshopQueryURL + "?" + url.Values{
+		"key":            {c.steamapikey},
+		"appid":          {strconv.Itoa(avorionAppID)},
+		"search_text":    {query},
+		"query_type":     {strconv.Itoa(workshopRankedByText)},
+		"page":           {strconv.Itoa(page)},
+		"numperpage":     {strconv.Itoa(ifaces.WorkshopPageSize)},
+		"return_details": {"true"}}.Encode())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("Workshop search failed (%s)", resp.Status)
+	}
+
+	var data struct {
+		Response struct {
+			Total   int `json:"total"`
+			Details []struct {
+				ID            string `json:"publishedfileid"`
+				Title         string `json:"title"`
+				Subscriptions int64  `json:"subscriptions"`
+			} `json:"publishedfiledetails"`
+		} `json:"response"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]ifaces.WorkshopItem, 0, len(data.Response.Details))
+	for _, d := range data.Response.Details {
+		id, err := strconv.ParseInt(d.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		items = append(items, ifaces.WorkshopItem{ID: id, Title: d.Title,
+			Subscriptions: d.Subscriptions})
+	}
+
+	return items, data.Response.Total, nil
+}
+
+// WorkshopUpdateTimes looks up when each of a set of Workshop mods was last
+// updated. Unlike the details used for validation, these aren't cached.
+func (c *Conf) WorkshopUpdateTimes(ids []int64) (map[int64]time.Time, error) {
+	times := make(map[int64]time.Time)
+	if len(ids) == 0 {
+		return times, nil
+	}
+
+	form := url.Values{"itemcount": {strconv.Itoa(len(ids))}}
+	for i, id := range ids {
+		form.Set(sprintf("publishedfileids[%d]", i), strconv.FormatInt(id, 10))
+	}
+
+	client := &http.Client{Timeout: workshopTimeout}
+	resp, err := client.PostForm(workshopDetailsURL, form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Workshop lookup failed (%s)", resp.Status)
+	}
+
+	var data struct {
+		Response struct {
+			Details []struct {
+				Result  int    `json:"result"`
+				ID      string `json:"publishedfileid"`
+				Updated int64  `json:"time_updated"`
+			} `json:"publishedfiledetails"`
+		} `json:"response"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	for _, d := range data.Response.Details {
+		id, err := strconv.ParseInt(d.ID, 10, 64)
+		if err != nil || d.Result != 1 {
+			continue
+		}
+		times[id] = time.Unix(d.Updated, 0)
+	}
+
+	return times, nil
+}
@@ -1,11 +1,28 @@
 package configuration
 
 type yamlDataCore struct {
-	LogLevel int    `yaml:"log_level"`
-	TimeZone string `yaml:"time_zone"`
-	LogTime  bool   `yaml:"log_timestamps"`
-	LogFile  string `yaml:"log_file"`
-	DBName   string `yaml:"db_filename"`
+	LogLevel   int    `yaml:"log_level"`
+	TimeZone   string `yaml:"time_zone"`
+	Locale     string `yaml:"locale"`
+	LogTime    bool   `yaml:"log_timestamps"`
+	LogFile    string `yaml:"log_file"`
+	DBName     string `yaml:"db_filename"`
+	HealthAddr string `yaml:"health_address"`
+	ExportDir  string `yaml:"export_directory"`
+
+	Update    yamlDataUpdate    `yaml:"update"`
+	Reporting yamlDataReporting `yaml:"error_reporting"`
+}
+
+type yamlDataReporting struct {
+	DSN         string `yaml:"dsn"`
+	Environment string `yaml:"environment"`
+}
+
+type yamlDataUpdate struct {
+	URL       string `yaml:"url"`
+	PublicKey string `yaml:"public_key"`
+	Auto      bool   `yaml:"auto_update"`
 }
 
 type yamlDataGame struct {
@@ -15,29 +32,111 @@ type yamlDataGame struct {
 	PingPort   int    `yaml:"ping_port"`
 	GamePort   int    `yaml:"port"`
 
+	PublicAddress string `yaml:"public_address"`
+	EventFile     string `yaml:"event_file"`
+
+	WrapperCommand string            `yaml:"wrapper_command"`
+	Environment    map[string]string `yaml:"environment"`
+
 	PostUpCommand        string `yaml:"post_up_command"`
 	PostDownCommand      string `yaml:"post_down_command"`
 	SecondsTillDBUpdate  int64  `yaml:"seconds_until_dbupdate"`
 	SecondsTillHangCheck int64  `yaml:"seconds_until_hangcheck"`
+	JumpAnomalyRate      int64  `yaml:"jump_anomaly_sectors_per_minute"`
+	SecondsTillErrorSum  int64  `yaml:"seconds_until_error_summary"`
+	JumpRetentionDays    int64  `yaml:"jump_retention_days"`
+
+	ContestedRegions []yamlDataRegion `yaml:"contested_regions"`
+
+	Backups yamlDataBackups `yaml:"backups"`
+
+	RestartSchedule []string `yaml:"restart_schedule"`
+}
+
+type yamlDataBackups struct {
+	Directory       string `yaml:"directory"`
+	IntervalMinutes int64  `yaml:"interval_minutes"`
+	OnShutdown      bool   `yaml:"on_shutdown"`
+	Keep            int64  `yaml:"keep"`
+	KeepDays        int64  `yaml:"keep_days"`
+	Compress        bool   `yaml:"compress"`
+}
+
+type yamlDataRegion struct {
+	Name string `yaml:"name"`
+	MinX int    `yaml:"min_x"`
+	MinY int    `yaml:"min_y"`
+	MaxX int    `yaml:"max_x"`
+	MaxY int    `yaml:"max_y"`
 }
 
 type yamlDataDiscord struct {
-	BotsAllowed   bool   `yaml:"bots_allowed"`
-	SentReact     bool   `yaml:"confirm_chat_sent"`
-	LogChannel    string `yaml:"log_channel"`
-	ChatChannel   string `yaml:"chat_channel"`
-	StatusChannel string `yaml:"status_channel"`
-	DiscordLink   string `yaml:"invite"`
-	Prefix        string `yaml:"prefix"`
-	Token         string `yaml:"token"`
+	BotsAllowed         bool   `yaml:"bots_allowed"`
+	SentReact           bool   `yaml:"confirm_chat_sent"`
+	AllianceChat        bool   `yaml:"relay_alliance_chat"`
+	ChatMentions        bool   `yaml:"chat_mentions"`
+	LogChannel          string `yaml:"log_channel"`
+	ChatChannel         string `yaml:"chat_channel"`
+	StatusChannel       string `yaml:"status_channel"`
+	PublicStatusChannel string `yaml:"public_status_channel"`
+	ControlPanelChannel string `yaml:"control_panel_channel"`
+	ControlPanelMessage string `yaml:"control_panel_message"`
+	InboxChannel        string `yaml:"inbox_channel"`
+	DiscordLink         string `yaml:"invite"`
+	Prefix              string `yaml:"prefix"`
+	Token               string `yaml:"token"`
 
 	DisabledCommands []string `yaml:"disabled_commands,flow"`
+	ChatLinks        []string `yaml:"chat_link_allowlist,flow"`
 
-	AliasedCommands   map[string][]string `yaml:"aliased_commands"`
-	RoleAuthLevels    map[string]int      `yaml:"role_auth_levels"`
-	CommandAuthLevels map[string]int      `yaml:"command_auth_levels"`
+	AliasedCommands   map[string][]string          `yaml:"aliased_commands"`
+	AliasTemplates    map[string]map[string]string `yaml:"alias_templates"`
+	RoleAuthLevels    map[string]int               `yaml:"role_auth_levels"`
+	CommandAuthLevels map[string]int               `yaml:"command_auth_levels"`
+	UserCommands      map[string]map[string]bool   `yaml:"user_command_overrides"`
 
 	ClearStatusChannel bool `yaml:"status_channel_clear"`
+	StartupQueue       bool `yaml:"queue_during_startup"`
+
+	Presence string `yaml:"presence"`
+
+	Milestones  yamlDataMilestones  `yaml:"milestones"`
+	MemberLeave yamlDataMemberLeave `yaml:"member_leave"`
+	Returning   yamlDataReturning   `yaml:"returning_players"`
+	Voice       yamlDataVoice       `yaml:"voice_announcements"`
+
+	StatusLayout       []yamlDataEmbedField `yaml:"status_layout"`
+	PublicStatusLayout []yamlDataEmbedField `yaml:"public_status_layout"`
+}
+
+type yamlDataEmbedField struct {
+	Field  string `yaml:"field,omitempty"`
+	Name   string `yaml:"name,omitempty"`
+	Value  string `yaml:"value,omitempty"`
+	Inline bool   `yaml:"inline,omitempty"`
+	Hidden bool   `yaml:"hidden,omitempty"`
+}
+
+type yamlDataMilestones struct {
+	UniquePlayers []int64 `yaml:"unique_players,flow"`
+	UniqueMessage string  `yaml:"unique_message"`
+	RecordMessage string  `yaml:"record_message"`
+}
+
+type yamlDataVoice struct {
+	Channel string   `yaml:"channel"`
+	Events  []string `yaml:"events,flow"`
+}
+
+type yamlDataReturning struct {
+	Days     int64  `yaml:"days_away"`
+	Greeting string `yaml:"greeting"`
+	Discord  string `yaml:"discord_message"`
+}
+
+type yamlDataMemberLeave struct {
+	Actions []string `yaml:"actions,flow"`
+	Command string   `yaml:"command"`
 }
 
 type yamlDataRCON struct {
@@ -52,13 +151,99 @@ type yamlDataMods struct {
 	Allowed  []int64  `yaml:"allowed"`
 	Enabled  []int64  `yaml:"enabled"`
 	ModPaths []string `yaml:"modpaths"`
+
+	Validate   string   `yaml:"validate"`
+	ClientTags []string `yaml:"client_tags,flow"`
+	ServerTags []string `yaml:"server_tags,flow"`
+
+	SteamAPIKey string `yaml:"steam_api_key"`
+
+	SteamCMD           string `yaml:"steamcmd"`
+	UpdateCheckMinutes int64  `yaml:"update_check_minutes"`
+	UpdateRestart      bool   `yaml:"update_restart"`
+}
+
+type yamlDataPreset struct {
+	Settings   map[string]string `yaml:"settings,omitempty"`
+	ServerMods []int64           `yaml:"server_mods,flow,omitempty"`
+	ClientMods []int64           `yaml:"client_mods,flow,omitempty"`
+
+	SecondsTillDBUpdate  int64 `yaml:"seconds_until_dbupdate,omitempty"`
+	SecondsTillHangCheck int64 `yaml:"seconds_until_hangcheck,omitempty"`
+}
+
+type yamlDataModeration struct {
+	Filter       []string `yaml:"chat_filter"`
+	Escalation   []string `yaml:"escalation,flow"`
+	Window       int64    `yaml:"window_minutes"`
+	MuteMinutes  int64    `yaml:"mute_minutes"`
+	TempBanHours int64    `yaml:"tempban_hours"`
+
+	IdleKick        yamlDataIdleKick        `yaml:"idle_kick"`
+	Maintenance     yamlDataMaintenance     `yaml:"maintenance"`
+	RestartVote     yamlDataRestartVote     `yaml:"restart_vote"`
+	IntegrationPins yamlDataIntegrationPins `yaml:"integration_pins"`
+}
+
+type yamlDataIdleKick struct {
+	IdleMinutes int64    `yaml:"idle_minutes"`
+	FreeSlots   int64    `yaml:"free_slots"`
+	ExemptRoles []string `yaml:"exempt_roles,flow"`
+	ExemptTags  []string `yaml:"exempt_tags,flow"`
+}
+
+type yamlDataRestartVote struct {
+	Quorum          int64 `yaml:"quorum_percent"`
+	MinPlayers      int64 `yaml:"min_players"`
+	VoteSeconds     int64 `yaml:"vote_seconds"`
+	CooldownMinutes int64 `yaml:"cooldown_minutes"`
+}
+
+type yamlDataIntegrationPins struct {
+	MaxAttempts    int64 `yaml:"max_attempts"`
+	LockoutMinutes int64 `yaml:"lockout_minutes"`
+}
+
+type yamlDataMaintenance struct {
+	Whitelist    []string `yaml:"whitelist,flow"`
+	AllowedRoles []string `yaml:"allowed_roles,flow"`
+	MOTD         string   `yaml:"motd"`
+}
+
+type yamlDataAPI struct {
+	TLSCert         string              `yaml:"tls_cert"`
+	TLSKey          string              `yaml:"tls_key"`
+	AutocertDomains []string            `yaml:"autocert_domains,flow"`
+	AutocertCache   string              `yaml:"autocert_cache"`
+	TrustedProxies  []string            `yaml:"trusted_proxies,flow"`
+	Tokens          map[string][]string `yaml:"tokens"`
+
+	Users map[string]yamlDataAPIUser `yaml:"users"`
+	OAuth yamlDataAPIOAuth           `yaml:"oauth"`
+	PProf bool                       `yaml:"pprof"`
+}
+
+type yamlDataAPIUser struct {
+	Password string `yaml:"password"`
+	Role     string `yaml:"role"`
+}
+
+type yamlDataAPIOAuth struct {
+	ClientID     string         `yaml:"client_id"`
+	ClientSecret string         `yaml:"client_secret"`
+	RedirectURL  string         `yaml:"redirect_url"`
+	Levels       map[string]int `yaml:"levels"`
 }
 
 type yamlData struct {
-	Core    yamlDataCore         `yaml:"Core"`
-	Game    yamlDataGame         `yaml:"Game"`
-	RCON    yamlDataRCON         `yaml:"RCON"`
-	Discord yamlDataDiscord      `yaml:"Discord"`
-	Mods    yamlDataMods         `yaml:"Mods"`
-	Events  map[string][2]string `yaml:"Events"`
+	Core       yamlDataCore         `yaml:"Core"`
+	API        yamlDataAPI          `yaml:"API"`
+	Game       yamlDataGame         `yaml:"Game"`
+	RCON       yamlDataRCON         `yaml:"RCON"`
+	Discord    yamlDataDiscord      `yaml:"Discord"`
+	Mods       yamlDataMods         `yaml:"Mods"`
+	Moderation yamlDataModeration   `yaml:"Moderation"`
+	Events     map[string][2]string `yaml:"Events"`
+
+	Presets map[string]yamlDataPreset `yaml:"Presets"`
 }
@@ -1,25 +1,98 @@
 package main
 
-// Core only exists for logging purposes, and contains no other state
+import (
+	"avorioncontrol/ifaces"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Core coordinates the game server and the Discord bot, and reports on the
+// health of the bot as a whole
 type Core struct {
 	loglevel int
+	started  time.Time
+
+	server ifaces.IGameServer
+	bot    ifaces.IDiscordBot
+
+	// Closed when the bot should restart into a newly installed binary
+	restart     chan struct{}
+	restartOnce sync.Once
+}
+
+// NewCore returns a Core that coordinates the given server and bot
+func NewCore(gs ifaces.IGameServer, bot ifaces.IDiscordBot) *Core {
+	return &Core{
+		loglevel: config.Loglevel(),
+		started:  time.Now(),
+		server:   gs,
+		bot:      bot,
+		restart:  make(chan struct{})}
+}
+
+/**********************/
+/* IFace ifaces.ICore */
+/**********************/
+
+// Version returns the version of the bot
+func (c *Core) Version() string {
+	return version
+}
+
+// Uptime returns the amount of time the bot has been running
+func (c *Core) Uptime() time.Duration {
+	return time.Since(c.started)
+}
+
+// Stats returns the runtime statistics of the bot process
+func (c *Core) Stats() ifaces.CoreStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := ifaces.CoreStats{
+		Goroutines:  runtime.NumGoroutine(),
+		HeapAlloc:   mem.HeapAlloc,
+		Sys:         mem.Sys,
+		NumGC:       mem.NumGC,
+		HeapInuse:   mem.HeapInuse,
+		HeapObjects: mem.HeapObjects,
+		StackInuse:  mem.StackInuse,
+		TotalAlloc:  mem.TotalAlloc,
+		NextGC:      mem.NextGC,
+		PauseTotal:  time.Duration(mem.PauseTotalNs),
+		GCCPU:       mem.GCCPUFraction}
+
+	if mem.NumGC > 0 {
+		stats.LastGC = time.Unix(0, int64(mem.LastGC))
+		stats.LastPause = time.Duration(mem.PauseNs[(mem.NumGC+255)%256])
+	}
+
+	return stats
+}
+
+// Health returns the health of each subsystem
+func (c *Core) Health() []ifaces.SubsystemHealth {
+	return []ifaces.SubsystemHealth{
+		c.server.Health(),
+		c.bot.Health()}
 }
 
 /************************/
 /* IFace logger.ILogger */
 /************************/
 
-// UUID returns the UUID of an alliance
+// UUID returns the UUID of the core
 func (c *Core) UUID() string {
 	return "Core"
 }
 
-// Loglevel returns the loglevel of an alliance
+// Loglevel returns the loglevel of the core
 func (c *Core) Loglevel() int {
 	return config.Loglevel()
 }
 
-// SetLoglevel sets the loglevel of an alliance
+// SetLoglevel sets the loglevel of the core
 func (c *Core) SetLoglevel(l int) {
 	config.SetLoglevel(l)
 }
@@ -9,9 +9,10 @@ package discord
 
 import (
 	"avorioncontrol/ifaces"
+	"errors"
 	"fmt"
-	"log"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,17 +21,25 @@ import (
 
 	"avorioncontrol/discord/commands"
 	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
 )
 
 var reCatchMention = regexp.MustCompile(`(<@!?\d+>)`)
 
+// Markdown that text to speech would read out
+var reVoiceMarkup = regexp.MustCompile("[*~`|]")
+
 // Bot is an object representing a Discord bot
 type Bot struct {
 	processDirectMsg func(*discordgo.Session, *discordgo.MessageCreate)
 
 	config   ifaces.IConfigurator
+	core     ifaces.ICore
+	bus      ifaces.IEventBus
+	panel    *controlPanel
+	advisory *versionAdvisory
 	session  *discordgo.Session
-	chatpipe chan ifaces.ChatData
+	presence string
 	loglevel int
 
 	// Close goroutines
@@ -58,26 +67,16 @@ func (b *Bot) UUID() string {
 	return "Bot"
 }
 
-/****************************/
-/* IFace ifaces.IBotChatter */
-/****************************/
-
-// SetChatPipe sets the current channel to pipe chats into
-func (b *Bot) SetChatPipe(cd chan ifaces.ChatData) {
-	b.chatpipe = cd
-}
-
-// ChatPipe returns the current channel to pipe chats into
-func (b *Bot) ChatPipe() chan ifaces.ChatData {
-	return b.chatpipe
-}
-
 // New returns a new instance of discord.Bot
-func New(c ifaces.IConfigurator, wg *sync.WaitGroup, exit chan struct{}) *Bot {
+func New(c ifaces.IConfigurator, bus ifaces.IEventBus, wg *sync.WaitGroup,
+	exit chan struct{}) *Bot {
 	b := &Bot{
-		config: c,
-		wg:     wg,
-		exit:   exit}
+		config:   c,
+		bus:      bus,
+		wg:       wg,
+		panel:    newControlPanel(),
+		advisory: &versionAdvisory{},
+		exit:     exit}
 	b.SetLoglevel(c.Loglevel())
 	return b
 }
@@ -86,18 +85,24 @@ func New(c ifaces.IConfigurator, wg *sync.WaitGroup, exit chan struct{}) *Bot {
 /* IFace ifaces.IBotStarter */
 /****************************/
 
+// SetCore sets the coordinator that the bot reports on
+func (b *Bot) SetCore(c ifaces.ICore) {
+	b.core = c
+}
+
 // Start initializes the discordgo backend
 func (b *Bot) Start(gs ifaces.IGameServer) {
 	logger.LogInit(b, "Initialized Discord bot")
 	dg, err := discordgo.New("Bot " + b.config.Token())
 	if err != nil {
-		log.Fatal("error creating Discord session,", err)
+		shutdown.Fatal("error creating Discord session, ", err)
 		return
 	}
 
+	b.session = dg
 	err = dg.Open()
 	if err != nil {
-		log.Fatal("error opening connection,", err)
+		shutdown.Fatal("error opening connection, ", err)
 	}
 
 	// Default to a user mention as the prefix
@@ -132,9 +137,13 @@ func (b *Bot) Start(gs ifaces.IGameServer) {
 		onGuildJoin(g.ID, dg, b, gs, cache)
 	}
 
+	b.wg.Add(1)
+	go b.superviseChat(dg, gs)
+
 	cache.UpdateCache(dg, gs)
 
 	go func() {
+		defer shutdown.Recover()
 		for {
 			select {
 			case <-time.After(5 * time.Minute):
@@ -150,18 +159,78 @@ func (b *Bot) Start(gs ifaces.IGameServer) {
 		v := regexp.MustCompile("^[0-9]+:[0-9]{10}$")
 		in := strings.TrimSpace(m.Content)
 		if v.MatchString(in) {
-			if gs.ValidateIntegrationPin(in, m.Author.ID) {
+			switch err := gs.ValidateIntegrationPin(in, m.Author.ID); {
+			case err == nil:
 				s.MessageReactionAdd(m.ChannelID, m.ID, "✅")
-				s.ChannelMessageSend(m.ID, "Thanks for validating!")
+				s.ChannelMessageSend(m.ChannelID, "Thanks for validating!")
+			case errors.Is(err, ifaces.ErrPinLocked):
+				s.MessageReactionAdd(m.ChannelID, m.ID, "🚫")
+				s.ChannelMessageSend(m.ChannelID, ifaces.ErrorMessage(err))
 			}
 		}
 	}
 
+	// Keep integrations in sync when a member leaves or is banned from the guild
+	dg.AddHandler(func(s *discordgo.Session, m *discordgo.GuildMemberRemove) {
+		defer shutdown.Recover()
+		if m.Member != nil && m.User != nil {
+			logger.LogInfo(b, "Member left the guild: "+m.User.String())
+			gs.DiscordMemberLeft(m.User.ID, false)
+		}
+	})
+	dg.AddHandler(func(s *discordgo.Session, m *discordgo.GuildBanAdd) {
+		defer shutdown.Recover()
+		if m.User != nil {
+			logger.LogInfo(b, "Member was banned from the guild: "+m.User.String())
+			gs.DiscordMemberLeft(m.User.ID, true)
+		}
+	})
+
+	// Post a setup guide when the bot is invited to a new guild
+	dg.AddHandler(func(s *discordgo.Session, g *discordgo.GuildCreate) {
+		defer shutdown.Recover()
+		b.onGuildCreate(s, g, gs, cache)
+	})
+
+	// Staff can manage the server by reacting to the control panel
+	dg.AddHandler(func(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+		defer shutdown.Recover()
+		b.onControlPanelReact(s, r, gs)
+		b.onVersionAdvisoryReact(s, r, gs)
+	})
+	go b.superviseControlPanel(dg, gs)
+
+	// Staff are told when an installed update needs a restart to take effect
+	b.wg.Add(1)
+	go b.superviseVersionAdvisory(dg, gs)
+
+	// Editing a command shortly after running it runs the command again
+	dg.AddHandler(func(s *discordgo.Session, m *discordgo.MessageUpdate) {
+		defer shutdown.Recover()
+		if m.Message == nil || m.GuildID == "" || m.Author == nil ||
+			m.Author.ID == s.State.User.ID {
+			return
+		}
+
+		if strings.HasPrefix(m.Author.Token, "Bot ") && !b.config.BotsAllowed() {
+			return
+		}
+
+		reg, err := commands.Registrar(m.GuildID)
+		if err != nil || !strings.HasPrefix(m.Content, b.config.Prefix()) {
+			return
+		}
+
+		if ran, _, cmderr := reg.ReprocessCommand(s, m, b.config, b.exit); ran {
+			b.handleCommandResult(s, reg, m.Message, cmderr)
+		}
+	})
+
 	// Setup our message handler for processing commands
 	dg.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		defer shutdown.Recover()
 		var (
 			reg    *commands.CommandRegistrar
-			cmdhlp *commands.CommandOutput
 			cmderr commands.ICommandError
 			err    error
 		)
@@ -175,7 +244,7 @@ func (b *Bot) Start(gs ifaces.IGameServer) {
 		if reg, err = commands.Registrar(m.GuildID); err != nil {
 			onGuildJoin(m.GuildID, dg, b, gs, cache)
 			if reg, err = commands.Registrar(m.GuildID); err != nil {
-				log.Fatal(err)
+				shutdown.Fatal(err)
 			}
 		}
 
@@ -191,56 +260,8 @@ func (b *Bot) Start(gs ifaces.IGameServer) {
 
 		// Process a command if the prefix is used
 		if strings.HasPrefix(m.Content, b.config.Prefix()) {
-			if _, cmderr = reg.ProcessCommand(s, m, b.config, b.exit); cmderr != nil {
-				s.MessageReactionAdd(m.ChannelID, m.ID, "🚫")
-				cmderr.Emit(s, m.ChannelID)
-				switch cmderr.(type) {
-				case *commands.ErrInvalidArgument:
-					if cmderr.Command() != nil {
-						cmdhlp = cmderr.Command().Help()
-					}
-					if cmdhlp != nil {
-						embed, _, _ := commands.GenerateOutputEmbed(cmdhlp, cmdhlp.ThisPage())
-						s.ChannelMessageSendEmbed(m.ChannelID, embed)
-					}
-
-				case *commands.ErrUnauthorizedUsage:
-					if cmderr.Command() != nil {
-						logger.LogWarning(b, fmt.Sprintf(
-							"%s attempted to run [%s], but wasn't authorized do so",
-							m.Author.String(), cmderr.Command().Name()))
-
-					}
-
-				case *commands.ErrInvalidTimezone:
-					logger.LogError(reg, cmderr.Error())
-
-				case *commands.ErrInvalidCommand:
-
-				case *commands.ErrCommandDisabled:
-
-				case *commands.ErrInvalidAlias:
-
-				case *commands.ErrCommandError:
-
-				case *commands.ErrInvalidSubcommand:
-					if cmderr.Subcommand() != nil {
-						cmdhlp = cmderr.Subcommand().Help()
-					} else if cmderr.Command() != nil {
-						cmdhlp = cmderr.Command().Help()
-					}
-					if cmdhlp != nil {
-						embed, _, _ := commands.GenerateOutputEmbed(cmdhlp, cmdhlp.ThisPage())
-						s.ChannelMessageSendEmbed(m.ChannelID, embed)
-					}
-
-				default:
-					logger.LogError(reg, cmderr.Error())
-				}
-			} else {
-				s.MessageReactionAdd(m.ChannelID, m.ID, "✅")
-			}
-
+			_, cmderr = reg.ProcessCommand(s, m, b.config, b.exit)
+			b.handleCommandResult(s, reg, m.Message, cmderr)
 			return
 		}
 
@@ -258,7 +279,22 @@ func (b *Bot) Start(gs ifaces.IGameServer) {
 
 			// Make sure that doublequotes don't break Avorion command processing
 			author = strings.ReplaceAll(author, `"`, `“`)
-			content := strings.ReplaceAll(m.Content, `"`, `“`)
+			content := m.Content
+			if b.config.ChatMentions() {
+				content = plainMentions(s, m, gs, cache)
+			}
+
+			content = bridgeContent(content, m.Message, b.config.ChatLinkAllowlist())
+			if content == "" {
+				return
+			}
+
+			record := m.Content
+			if record == "" {
+				record = content
+			}
+
+			content = strings.ReplaceAll(content, `"`, `“`)
 			logger.LogDebug(reg, "Processing: "+content)
 
 			_, err = gs.RunCommand(fmt.Sprintf(`discordsay "%s" "%s" "%s"`,
@@ -266,6 +302,8 @@ func (b *Bot) Start(gs ifaces.IGameServer) {
 			if err != nil {
 				s.MessageReactionAdd(m.ChannelID, m.ID, "🚫")
 			} else {
+				gs.RecordChat(ifaces.ChatData{Name: author, UID: m.Author.ID,
+					Msg: record}, ifaces.ChatSourceDiscord)
 				if b.config.ReactConfirm() {
 					s.MessageReactionAdd(m.ChannelID, m.ID, "✅")
 				}
@@ -275,6 +313,7 @@ func (b *Bot) Start(gs ifaces.IGameServer) {
 	})
 
 	go func() {
+		defer shutdown.Recover()
 		for {
 			time.Sleep(30 * time.Minute)
 			gs.RunCommand(fmt.Sprintf("setdiscorddata \"%s\" \"%s\"",
@@ -283,10 +322,25 @@ func (b *Bot) Start(gs ifaces.IGameServer) {
 	}()
 
 	dg.UpdateStatus(0, "Avorion")
+	b.presence = "Avorion"
 	logger.LogInit(b, "DISCORD USER:   "+dg.State.User.String())
 	logger.LogInit(b, "DISCORD PREFIX: "+b.config.Prefix())
 }
 
+/********************************/
+/* IFace ifaces.IHealthReporter */
+/********************************/
+
+// Health returns the health of the Discord connection
+func (b *Bot) Health() ifaces.SubsystemHealth {
+	h := ifaces.SubsystemHealth{Name: "Discord", Detail: "Disconnected"}
+	if b.session != nil && b.session.DataReady {
+		h.Healthy = true
+		h.Detail = "Connected"
+	}
+	return h
+}
+
 /******************************/
 /* IFace ifaces.IBotMentioner */
 /******************************/
@@ -296,46 +350,98 @@ func (b *Bot) Mention() string {
 	return b.session.State.User.String()
 }
 
+/*******************************/
+/* IFace ifaces.IBotAuthorizer */
+/*******************************/
+
+// MemberAuthLevel returns the highest auth level that a user has been granted
+// across the guilds that the bot is in
+func (b *Bot) MemberAuthLevel(uid string) (int, error) {
+	if b.session == nil || !b.session.DataReady {
+		return 0, errors.New("not connected to Discord")
+	}
+
+	var (
+		authlvl = 0
+		found   = false
+	)
+
+	for _, g := range b.session.State.Guilds {
+		if _, lvl, err := b.memberAuthLevel(b.session, g.ID, uid); err == nil {
+			found = true
+			if lvl > authlvl {
+				authlvl = lvl
+			}
+		}
+	}
+
+	if !found {
+		return 0, errors.New("user is not a member of any guild")
+	}
+
+	return authlvl, nil
+}
+
+// statusTarget tracks a status embed that is kept up to date in a channel
+type statusTarget struct {
+	channel func() (string, bool)
+	layout  func() []ifaces.EmbedField
+
+	// clear is set if the channel's history is deleted before the embed is
+	// posted. Only the staff channel is ever cleared.
+	clear func() bool
+
+	cid       string
+	lastcid   string
+	messageid string
+}
+
 func (b *Bot) updateServerStatus(guild string, s *discordgo.Session,
 	gs ifaces.IGameServer) {
+	defer shutdown.Recover()
+
 	b.wg.Add(1)
 	defer b.wg.Done()
 
 	var (
-		ok              bool
-		cid             string
-		lastcid         string
-		laststatus      ifaces.ServerStatus
-		statusmessageid string
+		laststatus ifaces.ServerStatus
+
+		// The detailed embed is meant for staff, while the public embed has its
+		// own layout so that it can be shown to the community
+		targets = []*statusTarget{
+			{channel: b.config.StatusChannel, layout: b.config.StatusLayout,
+				clear: b.config.StatusChannelClear},
+			{channel: b.config.PublicStatusChannel,
+				layout: b.config.PublicStatusLayout,
+				clear:  func() bool { return false }}}
 	)
 
-	updatechan := func(stat ifaces.ServerStatus) {
-		_, err := s.Channel(cid)
-		if err != nil {
-			logger.LogError(b, "Discordgo: "+err.Error())
+	updatechan := func(t *statusTarget, stat ifaces.ServerStatus) {
+		if t.messageid == "" {
 			return
 		}
 
-		tz, err := time.LoadLocation(b.config.TimeZone())
+		_, err := s.Channel(t.cid)
 		if err != nil {
-			logger.LogError(b, "Failed to load timezone from config")
+			logger.LogError(b, "Discordgo: "+err.Error())
 			return
 		}
 
-		_, err = s.ChannelMessageEditEmbed(cid, statusmessageid,
-			generateEmbedStatus(gs.Status(), tz))
+		_, err = s.ChannelMessageEditEmbed(t.cid, t.messageid,
+			generateEmbedStatus(stat, b.config, t.layout()))
 		if err != nil {
 			logger.LogError(b, "Discordgo: "+err.Error())
 		}
 	}
 
-	setupchan := func(stat ifaces.ServerStatus, clear bool) {
-		cid, ok = b.config.StatusChannel()
+	setupchan := func(t *statusTarget, stat ifaces.ServerStatus) {
+		var ok bool
+		t.cid, ok = t.channel()
 		if ok {
-			logger.LogInit(b, "Setting up server status on channel: "+cid)
+			logger.LogInit(b, "Setting up server status on channel: "+t.cid)
 
-			if clear {
-				messages, err := s.ChannelMessages(cid, 100, "", "", "")
+			if t.clear() {
+				messages, err := s.ChannelMessages(t.cid, 100, "", "", "")
 				if err != nil {
 					logger.LogError(b, "Discordgo: "+err.Error())
 					return
@@ -345,7 +451,7 @@ func (b *Bot) updateServerStatus(guild string, s *discordgo.Session,
 				for _, m := range messages {
 					messageids = append(messageids, m.ID)
 				}
-				s.ChannelMessagesBulkDelete(cid, messageids)
+				s.ChannelMessagesBulkDelete(t.cid, messageids)
 
 				if err != nil {
 					logger.LogError(b, "Discordgo: "+err.Error())
@@ -353,21 +459,15 @@ func (b *Bot) updateServerStatus(guild string, s *discordgo.Session,
 				}
 			}
 
-			tz, err := time.LoadLocation(b.config.TimeZone())
-			if err != nil {
-				logger.LogError(b, "Failed to load timezone from config")
-				return
-			}
-
-			m, err := s.ChannelMessageSendEmbed(cid, generateEmbedStatus(
-				stat, tz))
+			m, err := s.ChannelMessageSendEmbed(t.cid,
+				generateEmbedStatus(stat, b.config, t.layout()))
 			if err != nil {
 				logger.LogError(b, "Discordgo: "+err.Error())
 				return
 			}
 
-			statusmessageid = m.ID
-			lastcid = cid
+			t.messageid = m.ID
+			t.lastcid = t.cid
 		}
 	}
 
@@ -376,151 +476,353 @@ func (b *Bot) updateServerStatus(guild string, s *discordgo.Session,
 	logger.LogInit(b, "Starting server updater for "+guild)
 	laststatus = gs.Status()
 	laststatus.Status = ifaces.ServerStarting
-	setupchan(laststatus, b.config.StatusChannelClear())
+	for _, t := range targets {
+		setupchan(t, laststatus)
+	}
 
 	// Make sure that we change the embed to state that the server is
 	// offline when we exit
 	defer func() {
 		laststatus.Status = ifaces.ServerOffline
 		laststatus.PlayersOnline = 0
-		updatechan(laststatus)
+		for _, t := range targets {
+			updatechan(t, laststatus)
+		}
 	}()
 
 	for {
 		select {
 		case <-b.exit:
 			laststatus.Status = ifaces.ServerStopping
-			updatechan(laststatus)
+			for _, t := range targets {
+				updatechan(t, laststatus)
+			}
 			for gs.IsUp() {
 				time.Sleep(time.Second)
 			}
 			return
 
 		case <-time.After(time.Second * 5):
+			stat := gs.Status()
+			b.updatePresence(s, stat)
+
 			// No point in continuing if the server status hasn't changed
-			if stat := gs.Status(); gs.CompareStatus(stat, laststatus) {
+			if gs.CompareStatus(stat, laststatus) {
 				continue
 			} else {
 				logger.LogInfo(b, "Server status updated")
 				laststatus = stat
 			}
 
-			if cid, ok := b.config.StatusChannel(); ok {
-				if lastcid != cid {
-					setupchan(gs.Status(), b.config.StatusChannelClear())
-					continue
-				}
+			for _, t := range targets {
+				if cid, ok := t.channel(); ok {
+					if t.lastcid != cid {
+						setupchan(t, laststatus)
+						continue
+					}
 
-				updatechan(laststatus)
+					updatechan(t, laststatus)
+				}
 			}
 		}
 	}
 }
 
+// updatePresence sets the bots Discord presence from the configured template,
+// counting down to a pending restart if there is one. Discord is only updated
+// when the presence changes.
+func (b *Bot) updatePresence(s *discordgo.Session, stat ifaces.ServerStatus) {
+	presence := fillPresence(b.config.PresenceTemplate(), stat)
+	if !stat.RestartAt.IsZero() {
+		left := time.Until(stat.RestartAt)
+		if left < time.Minute {
+			presence += " | Restarting now"
+		} else {
+			presence += fmt.Sprintf(" | Restart in %dm",
+				int(left.Round(time.Minute).Minutes()))
+		}
+	}
+
+	// Discord rejects activity names longer than 128 characters
+	if r := []rune(presence); len(r) > 128 {
+		presence = string(r[:128])
+	}
+
+	if presence == b.presence {
+		return
+	}
+
+	b.presence = presence
+	if err := s.UpdateStatus(0, presence); err != nil {
+		logger.LogError(b, "Discordgo: "+err.Error())
+	}
+}
+
 /*********/
 /* OTHER */
 /*********/
 
-// onGuildJoin handler
-func onGuildJoin(gid string, s *discordgo.Session, b *Bot, gs ifaces.IGameServer,
-	cache *DataCache) {
-	reg := commands.NewRegistrar(gid, gs)
-	reg.SetLoglevel(b.Loglevel())
-	commands.InitializeCommandRegistry(reg)
-	cache.AddGuild(gid)
+// fillPresence fills in the placeholders of a presence template using the
+// given server status
+func fillPresence(tmpl string, stat ifaces.ServerStatus) string {
+	max := "?"
+	if stat.INI != nil && stat.INI.MaxPlayers > 0 {
+		max = strconv.FormatInt(stat.INI.MaxPlayers, 10)
+	}
 
-	go func() {
-		logger.LogInit(b, "Started bot chat supervisor")
-		b.wg.Add(1)
+	state, _ := ifaces.State(stat.Status)
+	return strings.NewReplacer(
+		"{online}", strconv.Itoa(stat.PlayersOnline),
+		"{max}", max,
+		"{total}", strconv.Itoa(stat.TotalPlayers),
+		"{status}", state,
+		"{name}", stat.Name).Replace(tmpl)
+}
 
-		defer func() {
-			b.wg.Done()
-			logger.LogInfo(b, "Stopped bot chat supervisor")
-		}()
+// superviseChat relays chat and game events published on the event bus to
+// their Discord channels
+func (b *Bot) superviseChat(s *discordgo.Session, gs ifaces.IGameServer) {
+	defer shutdown.Recover()
+
+	chats, unsubChat := b.bus.Subscribe(ifaces.EventTopicChat, 100)
+	logs, unsubLog := b.bus.Subscribe(ifaces.EventTopicLog, 100)
+	inbox, unsubInbox := b.bus.Subscribe(ifaces.EventTopicInbox, 100)
+	alerts, unsubAlerts := b.bus.Subscribe(ifaces.EventTopicAlliance, 100)
+	voice, unsubVoice := b.bus.Subscribe(ifaces.EventTopicVoice, 100)
+	threads := newLogThreads()
+	repeats := newLogRepeats()
+	flush := time.NewTicker(logRepeatFlush)
+
+	logger.LogInit(b, "Started bot chat supervisor")
+	defer func() {
+		unsubChat()
+		unsubLog()
+		unsubInbox()
+		unsubAlerts()
+		unsubVoice()
+		flush.Stop()
+		b.wg.Done()
+		logger.LogInfo(b, "Stopped bot chat supervisor")
+	}()
 
-		for {
-			select {
-			case lm := <-b.config.LogPipe():
-				logger.LogDebug(b, "Processing chat data from server for logging")
-				if b.config.ChatChannel() != "" {
-					// Don't bother with empty messages
-					if len(lm.Msg) == 0 {
-						continue
-					}
+	for {
+		select {
+		case lm := <-logs:
+			logger.LogDebug(b, "Processing chat data from server for logging")
+			if b.config.LogChannel() != "" {
+				// Don't bother with empty messages
+				if len(lm.Msg) == 0 {
+					continue
+				}
 
-					// Default to Avorion
-					if lm.Name == "" {
-						lm.Name = "Avorion"
-					}
+				// Default to Avorion
+				if lm.Name == "" {
+					lm.Name = "Avorion"
+				}
 
-					// Truncate messages larger than 1900 to make sure we have enough room
-					//	for the rest of the message
-					msg := string(lm.Msg)
-					if len(msg) > 1900 {
-						msg = msg[0:1900]
-						msg += "...(truncated)"
-					}
+				// Truncate messages larger than 1900 to make sure we have enough room
+				//	for the rest of the message
+				msg := string(lm.Msg)
+				if len(msg) > 1900 {
+					msg = msg[0:1900]
+					msg += "...(truncated)"
+				}
 
-					// Prevent mentions from in-game
-					msg = strings.ReplaceAll(msg, "@everyone", "everyone")
-					msg = strings.ReplaceAll(msg, "@here", "here")
+				// Prevent mentions from in-game
+				msg = strings.ReplaceAll(msg, "@everyone", "everyone")
+				msg = strings.ReplaceAll(msg, "@here", "here")
+
+				// Collapse repeats of the same event, such as a script that
+				//	errors every tick
+				cid := b.config.LogChannel()
+				key := cid + "\x00" + lm.Thread + "\x00" + msg
+				if repeats.repeat(s, key) {
+					continue
+				}
 
-					embed := &discordgo.MessageEmbed{
-						Title:       "Game Event Logged",
-						Description: msg}
+				embed := &discordgo.MessageEmbed{
+					Title:       "Game Event Logged",
+					Description: msg}
 
-					s.ChannelMessageSendEmbed(b.config.LogChannel(), embed)
+				m, err := threads.send(s, cid, lm.Thread, embed)
+				if err != nil {
+					logger.LogWarning(b, "Failed to log game event: "+err.Error())
 				}
 
-			case cm := <-b.config.ChatPipe():
-				logger.LogDebug(b, "Processing chat data from server")
-				if b.config.ChatChannel() != "" {
-					// Don't bother with empty messages
-					if len(cm.Msg) == 0 {
-						continue
-					}
+				if m != nil {
+					repeats.track(key, m, embed)
+				}
+			}
 
-					// Default to Avorion
-					if cm.Name == "" {
-						cm.Name = "Avorion"
-					}
+		case <-flush.C:
+			repeats.flush(s)
 
-					// Truncate messages larger than 1900 to make sure we have enough room
-					//	for the rest of the message
-					msg := string(cm.Msg)
-					if len(msg) > 1900 {
-						msg = msg[0:1900]
-						msg += "...(truncated)"
-					}
+		case im := <-inbox:
+			logger.LogDebug(b, "Processing staff message from server")
+			if cid := b.config.InboxChannel(); cid != "" && len(im.Msg) > 0 {
+				// Prevent mentions from in-game
+				msg := strings.ReplaceAll(im.Msg, "@everyone", "everyone")
+				msg = strings.ReplaceAll(msg, "@here", "here")
+				msg = reCatchMention.ReplaceAllString(msg, "`(mention blocked)`")
 
-					// Prevent mentions from in-game
-					msg = strings.ReplaceAll(msg, "@everyone", "everyone")
-					msg = strings.ReplaceAll(msg, "@here", "here")
-
-					if reCatchMention.MatchString(msg) {
-						logger.LogDebug(reg, "Found mention in chat string")
-						m := reCatchMention.FindStringSubmatch(msg)
-						for _, caught := range m {
-							logger.LogWarning(reg, "Player attempted to mention: "+caught)
-							msg = strings.ReplaceAll(msg, caught, "`(mention blocked)`")
-						}
-					}
+				embed := &discordgo.MessageEmbed{
+					Title:       "Staff Inbox",
+					Description: msg,
+					Footer: &discordgo.MessageEmbedFooter{
+						Text: "Reply with: reply <message #> <text>"}}
+
+				s.ChannelMessageSendEmbed(cid, embed)
+			}
+
+		case am := <-alerts:
+			logger.LogDebug(b, "Processing alliance alert from server")
+			if am.Channel != "" && len(am.Msg) > 0 {
+				// Ship names come from in-game, so only the alliance's own role
+				//	mention is allowed through
+				msg := strings.ReplaceAll(am.Msg, "@everyone", "everyone")
+				msg = strings.ReplaceAll(msg, "@here", "here")
+
+				if _, err := s.ChannelMessageSend(am.Channel, msg); err != nil {
+					logger.LogWarning(b, "Failed to send alliance alert: "+err.Error())
+				}
+			}
+
+		case vm := <-voice:
+			logger.LogDebug(b, "Processing voice announcement from server")
+			if vm.Channel != "" && len(vm.Msg) > 0 {
+				// The message is read out loud, so markdown and mentions would only
+				//	get in the way
+				msg := reVoiceMarkup.ReplaceAllString(vm.Msg, "")
+				msg = strings.ReplaceAll(msg, "@", "")
+
+				if _, err := s.ChannelMessageSendTTS(vm.Channel, msg); err != nil {
+					logger.LogWarning(b, "Failed to send voice announcement: "+
+						err.Error())
+				}
+			}
+
+		case cm := <-chats:
+			logger.LogDebug(b, "Processing chat data from server")
+			if b.config.ChatChannel() != "" {
+				// Don't bother with empty messages
+				if len(cm.Msg) == 0 {
+					continue
+				}
+
+				// Default to Avorion
+				if cm.Name == "" {
+					cm.Name = "Avorion"
+				}
+
+				// Truncate messages larger than 1900 to make sure we have enough room
+				//	for the rest of the message
+				msg := string(cm.Msg)
+				if len(msg) > 1900 {
+					msg = msg[0:1900]
+					msg += "...(truncated)"
+				}
+
+				// Prevent mentions from in-game
+				msg = strings.ReplaceAll(msg, "@everyone", "everyone")
+				msg = strings.ReplaceAll(msg, "@here", "here")
 
-					if cm.UID != "" {
-						msg = fmt.Sprintf("<@%s>: %s", cm.UID, msg)
-					} else {
-						msg = fmt.Sprintf("▫️ **%s**: %s", cm.Name, msg)
+				if reCatchMention.MatchString(msg) {
+					logger.LogDebug(b, "Found mention in chat string")
+					m := reCatchMention.FindStringSubmatch(msg)
+					for _, caught := range m {
+						logger.LogWarning(b, "Player attempted to mention: "+caught)
+						msg = strings.ReplaceAll(msg, caught, "`(mention blocked)`")
 					}
+				}
 
-					s.ChannelMessageSend(b.config.ChatChannel(), msg)
+				if b.config.ChatMentions() {
+					msg = mentionPlayers(gs, msg, cm.Name)
 				}
-			case <-b.exit:
-				return
-			default:
-				time.Sleep(time.Second)
+
+				switch {
+				case cm.UID != "":
+					msg = fmt.Sprintf("<@%s>: %s", cm.UID, msg)
+				case cm.Kind == ifaces.ChatKindEmote:
+					msg = fmt.Sprintf("▫️ _**%s** %s_", cm.Name, msg)
+				case cm.Kind == ifaces.ChatKindAlliance:
+					msg = fmt.Sprintf("▫️ `[Alliance]` **%s**: %s", cm.Name, msg)
+				default:
+					msg = fmt.Sprintf("▫️ **%s**: %s", cm.Name, msg)
+				}
+
+				s.ChannelMessageSend(b.config.ChatChannel(), msg)
 			}
+		case <-b.exit:
+			return
 		}
-	}()
+	}
+}
+
+// handleCommandResult reacts to a command message with the result of running
+// it, and posts the error and any relevant help if it failed
+func (b *Bot) handleCommandResult(s *discordgo.Session,
+	reg *commands.CommandRegistrar, m *discordgo.Message,
+	cmderr commands.ICommandError) {
+	var cmdhlp *commands.CommandOutput
+
+	if cmderr != nil {
+		s.MessageReactionAdd(m.ChannelID, m.ID, "🚫")
+		cmderr.Emit(s, m.ChannelID)
+		switch cmderr.(type) {
+		case *commands.ErrInvalidArgument:
+			if cmderr.Command() != nil {
+				cmdhlp = cmderr.Command().Help()
+			}
+			if cmdhlp != nil {
+				embed, _, _ := commands.GenerateOutputEmbed(cmdhlp, cmdhlp.ThisPage())
+				s.ChannelMessageSendEmbed(m.ChannelID, embed)
+			}
+
+		case *commands.ErrUnauthorizedUsage:
+			if cmderr.Command() != nil {
+				logger.LogWarning(b, fmt.Sprintf(
+					"%s attempted to run [%s], but wasn't authorized do so",
+					m.Author.String(), cmderr.Command().Name()))
+
+			}
+
+		case *commands.ErrInvalidTimezone:
+			logger.LogError(reg, cmderr.Error())
+
+		case *commands.ErrInvalidCommand:
+
+		case *commands.ErrCommandDisabled:
+
+		case *commands.ErrInvalidAlias:
+
+		case *commands.ErrCommandError:
+
+		case *commands.ErrInvalidSubcommand:
+			if cmderr.Subcommand() != nil {
+				cmdhlp = cmderr.Subcommand().Help()
+			} else if cmderr.Command() != nil {
+				cmdhlp = cmderr.Command().Help()
+			}
+			if cmdhlp != nil {
+				embed, _, _ := commands.GenerateOutputEmbed(cmdhlp, cmdhlp.ThisPage())
+				s.ChannelMessageSendEmbed(m.ChannelID, embed)
+			}
+
+		default:
+			logger.LogError(reg, cmderr.Error())
+		}
+	} else {
+		s.MessageReactionAdd(m.ChannelID, m.ID, "✅")
+	}
+}
+
+// onGuildJoin handler
+func onGuildJoin(gid string, s *discordgo.Session, b *Bot, gs ifaces.IGameServer,
+	cache *DataCache) {
+	reg := commands.NewRegistrar(gid, gs, b.core)
+	reg.SetLoglevel(b.Loglevel())
+	commands.InitializeCommandRegistry(reg)
+	cache.AddGuild(gid)
 
 	go b.updateServerStatus(gid, s, gs)
 
@@ -0,0 +1,117 @@
+package discord
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Links longer than this are shortened before being shown in-game
+const chatLinkMaxLength = 40
+
+// Discord users can wrap links in <> to suppress the embed, so those are
+// matched as part of the link
+var reChatLink = regexp.MustCompile(`<?(?i:https?://[^\s<>]+)>?`)
+
+var attachmentKinds = map[string]string{
+	".png":  "an image",
+	".jpg":  "an image",
+	".jpeg": "an image",
+	".gif":  "an image",
+	".webp": "an image",
+	".mp4":  "a video",
+	".webm": "a video",
+	".mov":  "a video",
+	".mp3":  "an audio clip",
+	".ogg":  "an audio clip",
+	".wav":  "an audio clip",
+	".flac": "an audio clip"}
+
+// bridgeContent returns the in-game representation of a message posted in the
+// chat channel. Links that aren't allowed are removed and the rest are
+// shortened, and attachments and embeds, which have no text of their own, are
+// described so that they aren't silently dropped.
+func bridgeContent(content string, m *discordgo.Message,
+	allow []string) string {
+	content = reChatLink.ReplaceAllStringFunc(content, func(link string) string {
+		u, err := url.Parse(strings.Trim(link, "<>"))
+		if err != nil || !linkAllowed(u.Hostname(), allow) {
+			return "[link removed]"
+		}
+		return shortLink(u)
+	})
+
+	parts := make([]string, 0)
+	if strings.TrimSpace(content) != "" {
+		parts = append(parts, content)
+	}
+
+	for _, a := range m.Attachments {
+		desc := a.Filename
+		if u, err := url.Parse(a.URL); err == nil {
+			desc = shortLink(u)
+		}
+		parts = append(parts, fmt.Sprintf("posted %s: %s",
+			attachmentKind(a.Filename), desc))
+	}
+
+	// Link previews repeat a link that is already in the message, so embeds
+	// are only described when there is nothing else to show
+	if len(parts) == 0 {
+		for _, e := range m.Embeds {
+			if e.Title != "" {
+				parts = append(parts, "posted an embed: "+e.Title)
+			}
+		}
+	}
+
+	return strings.Join(parts, " | ")
+}
+
+// linkAllowed returns true if a host is covered by the allowlist. An empty
+// allowlist allows every host.
+func linkAllowed(host string, allow []string) bool {
+	if len(allow) == 0 {
+		return true
+	}
+
+	host = strings.ToLower(host)
+	for _, a := range allow {
+		if host == a || strings.HasSuffix(host, "."+a) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortLink returns a short, readable form of a link without its scheme. Long
+// links are cut down to the last element of their path.
+func shortLink(u *url.URL) string {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	link := host + strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		link += "?" + u.RawQuery
+	}
+
+	if len(link) <= chatLinkMaxLength {
+		return link
+	}
+
+	base := path.Base(u.Path)
+	if r := []rune(base); len(r) > chatLinkMaxLength/2 {
+		base = string(r[:chatLinkMaxLength/2]) + "…"
+	}
+	return host + "/…/" + base
+}
+
+// attachmentKind describes an attachment by its file extension
+func attachmentKind(filename string) string {
+	if kind, ok := attachmentKinds[strings.ToLower(path.Ext(filename))]; ok {
+		return kind
+	}
+	return "a file"
+}
@@ -56,10 +56,12 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 
 	r.Register("setalias",
 		"Set a command alias",
-		"setalias <command> <alias>",
+		"setalias <command> <alias> (argument) ...",
 		[]CommandArgument{
 			arg("command", "Name of the command the new alias will apply to"),
-			arg("alias", "Name of the alias that is being created")},
+			arg("alias", "Name of the alias that is being created"),
+			arg("argument", "Fixed argument to run the command with. Use $1, $2, "+
+				"etc to insert the arguments the alias is run with, or $@ for all of them")},
 		setaliasCmd)
 
 	// ifaces.Server (Avorion)
@@ -77,12 +79,107 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 		make([]CommandArgument, 0),
 		statusCmnd)
 
+	r.Register("botinfo",
+		"Show the bots version, uptime, and the health of its subsystems",
+		"botinfo",
+		make([]CommandArgument, 0),
+		botInfoCmnd)
+
+	r.Register("debug",
+		"Diagnose problems with the bot itself",
+		"debug <subcommand>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("runtime",
+		"Show goroutine counts, memory usage, and garbage collector statistics",
+		"runtime",
+		make([]CommandArgument, 0),
+		debugRuntimeCmnd, "debug")
+
+	r.Register("selfupdate",
+		"Check for a new release of the bot, or install it and restart",
+		"selfupdate (install)",
+		[]CommandArgument{
+			arg("install", "Download, verify, and install the latest release")},
+		selfUpdateCmnd)
+
+	r.Register("stats",
+		"Show usage statistics for the bot",
+		"stats <commands|combat>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("commands",
+		"Show how often each command is used and how often it fails",
+		"commands (all)",
+		[]CommandArgument{
+			arg("all", "Include usage from every guild, not just this one")},
+		statsCommandsSubCmnd, "stats")
+	r.Register("combat",
+		"Show which ship classes, names, and sectors see the most destruction",
+		"combat",
+		make([]CommandArgument, 0),
+		statsCombatSubCmnd, "stats")
+
+	r.Register("schedule",
+		"Show the automated actions that will run next, or cancel a one-off action",
+		"schedule (cancel <id>)",
+		[]CommandArgument{
+			arg("cancel <id>", "Cancel the one-off action with the given ID")},
+		scheduleCmnd)
+
+	r.Register("privacy",
+		"Hide your jump history from players that are not staff",
+		"privacy (on|off)",
+		[]CommandArgument{
+			arg("on|off", "Hide or show your tracking data (shows the setting if omitted)")},
+		privacyCmnd)
+
+	r.Register("alliancealerts",
+		"Alert your alliance's channel of jumps into contested or claimed sectors",
+		"alliancealerts <show|channel|claim|unclaim>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("show",
+		"Show the alert channel and claimed sectors of the alliance you lead",
+		"show",
+		make([]CommandArgument, 0),
+		allianceAlertsShowSubCmnd, "alliancealerts")
+	r.Register("channel",
+		"Set the channel that your alliance is alerted in",
+		"channel <#channel|off> (@role)",
+		[]CommandArgument{
+			arg("#channel|off", "Channel to send alerts to, or off to stop them"),
+			arg("@role", "Role to mention in each alert")},
+		allianceAlertsChannelSubCmnd, "alliancealerts")
+	r.Register("claim",
+		"Claim a sector, and get alerted when other ships jump into it",
+		"claim <x:y>",
+		[]CommandArgument{
+			arg("x:y", "Coordinates of the sector to claim")},
+		allianceClaimSubCmnd, "alliancealerts")
+	r.Register("unclaim",
+		"Remove your alliance's claim on a sector",
+		"unclaim <x:y>",
+		[]CommandArgument{
+			arg("x:y", "Coordinates of the sector to unclaim")},
+		allianceClaimSubCmnd, "alliancealerts")
+
+	r.Register("chatsearch",
+		"Search the history of chat messages bridged to and from the game",
+		"chatsearch <query> (player) (since)",
+		[]CommandArgument{
+			arg("query", "Word or prefix (word*) to search for"),
+			arg("player", "Only show messages sent by this player or Discord user"),
+			arg("since", "Only show messages newer than this (e.g. 30m, 12h, 7d)")},
+		chatSearchCmnd)
+
 	r.Register("getjumps",
 		"Get the last n jumps for a player or alliance",
-		"getjumps <number> <name>",
+		"getjumps <number> <name> (--export [csv|json])",
 		[]CommandArgument{
 			arg("number", "Number of jumps to list (250 max)"),
-			arg("name", "Player or Alliance name")},
+			arg("name", "Player or Alliance name"),
+			arg("--export", "Attach the full jump history as CSV or JSON instead")},
 		getJumpsCmnd)
 
 	r.Register("getcoordhistory",
@@ -93,6 +190,53 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 			arg("y", "y coordinate for a sector")},
 		getCoordHistoryCmnd)
 
+	r.Register("findship",
+		"Find the owner and last known location of a ship",
+		"findship <name>",
+		[]CommandArgument{
+			arg("name", "Full or partial name of the ship")},
+		findShipCmnd)
+
+	r.Register("search",
+		"Search players, alliances, ships, and sectors by name",
+		"search <term>",
+		[]CommandArgument{
+			arg("term", "Full, partial, or misspelled name to look for")},
+		searchCmnd)
+
+	r.Register("reviews",
+		"List or resolve players queued for review after leaving Discord",
+		"reviews (resolve <index>)",
+		[]CommandArgument{
+			arg("resolve", "Remove a player from the review queue"),
+			arg("index", "Index of the player to remove")},
+		reviewsCmnd)
+
+	r.Register("integrations",
+		"Find and remove integrations of Discord users that have left",
+		"integrations <orphans|prune>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("orphans",
+		"List integrated players whose Discord user is no longer a member",
+		"orphans",
+		make([]CommandArgument, 0),
+		integrationsOrphansSubCmnd, "integrations")
+	r.Register("prune",
+		"Unlink every player whose Discord user is no longer a member",
+		"prune (confirm)",
+		[]CommandArgument{
+			arg("confirm", "Unlink the players instead of describing what happens")},
+		integrationsPruneSubCmnd, "integrations")
+
+	r.Register("reply",
+		"Reply in-game to a message that a player sent to the staff",
+		"reply <message #> <text>",
+		[]CommandArgument{
+			arg("message #", "Number of the message in the staff inbox"),
+			arg("text", "Reply that is sent to the player")},
+		replyCmnd)
+
 	r.Register("getplayers",
 		"List the tracked players",
 		"getplayers",
@@ -105,6 +249,14 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 		make([]CommandArgument, 0),
 		getAlliancesCmnd)
 
+	r.Register("getalliance",
+		"Show an alliance along with its founder, leader, and members",
+		"getalliance <index|name> (contributions)",
+		[]CommandArgument{
+			arg("index|name", "Index or full name of the alliance"),
+			arg("contributions", "Show what each member has donated to the alliance")},
+		getAllianceCmnd)
+
 	r.Register("reload",
 		"Reloads the active configuration from our config file",
 		"reload",
@@ -127,9 +279,11 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 
 	r.Register("setstatuschannel",
 		"Sets the channel in which the server will update it's status embed",
-		"setstatuschannel channelid",
+		"setstatuschannel channelid (public|panel)",
 		[]CommandArgument{
-			arg("channelid", "UID of the channel to send server chat messages to")},
+			arg("channelid", "UID of the channel to send server chat messages to"),
+			arg("public", "Post the public embed (no seed or server config) instead"),
+			arg("panel", "Post the server control panel instead")},
 		setStatusChannelCmnd)
 
 	r.Register("settimezone",
@@ -139,6 +293,13 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 			arg("timezone", "Timezone to set (reference: https://en.wikipedia.org/wiki/List_of_tz_database_time_zones)")},
 		setTimezoneCmnd)
 
+	r.Register("setlocale",
+		"Sets the locale that bot output uses for numbers, dates, and times",
+		"setlocale locale",
+		[]CommandArgument{
+			arg("locale", "Locale to set, such as en-US, en-GB, or de")},
+		setLocaleCmnd)
+
 	r.Register("server",
 		"Control the state of the Avorion server",
 		"server <subcommand>",
@@ -155,10 +316,182 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 		make([]CommandArgument, 0),
 		startServerCmnd, "server")
 	r.Register("restart",
-		"Restart the Avorion server",
-		"restart",
-		make([]CommandArgument, 0),
+		"Restart the Avorion server, optionally after a countdown or at a set time",
+		"restart (minutes|cancel|at <HH:MM> (tomorrow))",
+		[]CommandArgument{
+			arg("minutes", "Minutes to count down before restarting"),
+			arg("cancel", "Cancel a pending restart"),
+			arg("at <HH:MM>", "Restart once at a 24-hour time in the bot's timezone"),
+			arg("tomorrow", "Restart at that time tomorrow instead of next")},
 		restartServerCmnd, "server")
+	r.Register("reap",
+		"Terminate an Avorion process left behind by a previous run",
+		"reap",
+		make([]CommandArgument, 0),
+		reapServerCmnd, "server")
+	r.Register("exits",
+		"Show how the Avorion process exited recently, and why",
+		"exits (count)",
+		[]CommandArgument{
+			arg("count", "Number of exits to show (defaults to 10)")},
+		exitsServerCmnd, "server")
+	r.Register("switch-galaxy",
+		"Switch to a galaxy that was renamed in the config while the server was up",
+		"switch-galaxy (confirm)",
+		[]CommandArgument{
+			arg("confirm", "Stop the server, switch galaxies, and start it again")},
+		switchGalaxyCmnd, "server")
+	r.Register("maintenance",
+		"Restrict the server to whitelisted players, optionally for a time",
+		"maintenance (on|off) (duration)",
+		[]CommandArgument{
+			arg("on", "Kick players that aren't whitelisted and keep them out"),
+			arg("off", "Open the server to everyone again"),
+			arg("duration", "How long maintenance lasts (e.g. 90m or 2h)")},
+		maintenanceCmnd, "server")
+
+	r.Register("migrate",
+		"Move the server to a different game installation",
+		"migrate <install>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("install",
+		"Validate a new Avorion installation, switch to it, and restart",
+		"install <newpath>",
+		[]CommandArgument{
+			arg("newpath", "Path to the new Avorion installation")},
+		migrateInstallSubCmnd, "migrate")
+
+	r.Register("preset",
+		"Apply a named bundle of server settings, mods, and schedules",
+		"preset <list|apply>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("list",
+		"List the configured presets",
+		"list",
+		make([]CommandArgument, 0),
+		presetListSubCmnd, "preset")
+	r.Register("apply",
+		"Preview the changes a preset makes, and apply them once confirmed",
+		"apply <name> (confirm)",
+		[]CommandArgument{
+			arg("name", "Name of the preset to apply"),
+			arg("confirm", "Apply the preset instead of previewing it")},
+		presetApplySubCmnd, "preset")
+
+	r.Register("gameconfig",
+		"Review and undo the changes the bot has made to server.ini",
+		"gameconfig <history|revert>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("history",
+		"List the stored versions of server.ini, newest first",
+		"history (count)",
+		[]CommandArgument{
+			arg("count", "Number of versions to list (defaults to 10)")},
+		gameConfigHistorySubCmnd, "gameconfig")
+	r.Register("revert",
+		"Preview restoring a stored version of server.ini, and restore it once confirmed",
+		"revert <version> (confirm)",
+		[]CommandArgument{
+			arg("version", "Version to restore, from gameconfig history"),
+			arg("confirm", "Restore the version instead of previewing it")},
+		gameConfigRevertSubCmnd, "gameconfig")
+
+	r.Register("backup",
+		"Back up the galaxy, and list or restore its backups",
+		"backup <now|at|list|restore>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("now",
+		"Back up the galaxy now",
+		"now",
+		make([]CommandArgument, 0),
+		backupNowSubCmnd, "backup")
+	r.Register("at",
+		"Back up the galaxy once at a set time",
+		"at <HH:MM> (tomorrow)",
+		[]CommandArgument{
+			arg("HH:MM", "24-hour time in the bot's timezone"),
+			arg("tomorrow", "Back up at that time tomorrow instead of next")},
+		backupAtSubCmnd, "backup")
+	r.Register("list",
+		"List the backups of the galaxy, newest first",
+		"list",
+		make([]CommandArgument, 0),
+		backupListSubCmnd, "backup")
+	r.Register("restore",
+		"Preview restoring a galaxy backup, and restore it once confirmed",
+		"restore <name> (confirm)",
+		[]CommandArgument{
+			arg("name", "Name of the backup, from backup list"),
+			arg("confirm", "Restore the backup instead of previewing it")},
+		backupRestoreSubCmnd, "backup")
+
+	r.Register("playerdb",
+		"Manage the player database",
+		"playerdb <refresh>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("refresh",
+		"Refresh the player database now and report what changed",
+		"refresh",
+		make([]CommandArgument, 0),
+		playerDBRefreshSubCmnd, "playerdb")
+
+	r.Register("db",
+		"Manage the tracking database",
+		"db <status|rebuild|galaxies>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("status",
+		"Show whether the tracking database is working normally",
+		"status",
+		make([]CommandArgument, 0),
+		dbStatusSubCmnd, "db")
+	r.Register("rebuild",
+		"Recreate the tracking database and re-import data from the game",
+		"rebuild (confirm)",
+		[]CommandArgument{
+			arg("confirm", "Rebuild the database instead of describing what happens")},
+		dbRebuildSubCmnd, "db")
+	r.Register("galaxies",
+		"List the galaxies with tracked data, and purge the data of old ones",
+		"galaxies (purge <name> (confirm))",
+		[]CommandArgument{
+			arg("purge", "Delete the tracked data of the named galaxy"),
+			arg("confirm", "Purge the data instead of describing what happens")},
+		dbGalaxiesSubCmnd, "db")
+
+	r.Register("export",
+		"Export tracked data as a CSV or JSON file",
+		"export <players|jumps|chat>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	for _, kind := range []string{"players", "jumps", "chat"} {
+		r.Register(kind,
+			sprintf("Export the tracked %s data", kind),
+			kind+" <csv|json> (player)",
+			[]CommandArgument{
+				arg("csv|json", "Format of the exported file"),
+				arg("player", "Only export data for this player name or index")},
+			exportSubCmnd, "export")
+	}
+
+	r.Register("logs",
+		"Search the bot and game logs",
+		"logs <search>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("search",
+		"Search the bot logs (and optionally the game logs) and attach the matching lines",
+		"search <pattern> (--since <duration>) (--game)",
+		[]CommandArgument{
+			arg("pattern", "Case-insensitive regular expression to search for"),
+			arg("--since", "Only include lines newer than this (e.g. 30m, 12h, 7d)"),
+			arg("--game", "Also search the Avorion server logs")},
+		logsSearchSubCmnd, "logs")
 
 	r.Register("admin",
 		"Configure admin level privileges",
@@ -206,10 +539,36 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 		"delcommand <command>",
 		make([]CommandArgument, 0),
 		showSelfCmndSubCmnd, "admin")
+	r.Register("users",
+		"List users with command overrides",
+		"users",
+		make([]CommandArgument, 0),
+		showUserCmndsSubCmnd, "admin")
+	r.Register("grant",
+		"Allow a user to run a command regardless of their roles",
+		"grant <user> <command>",
+		[]CommandArgument{
+			arg("user", "mention or ID of the user"),
+			arg("command", "command to grant")},
+		grantUserCmndSubCmnd, "admin")
+	r.Register("revoke",
+		"Prevent a user from running a command regardless of their roles",
+		"revoke <user> <command>",
+		[]CommandArgument{
+			arg("user", "mention or ID of the user"),
+			arg("command", "command to revoke")},
+		revokeUserCmndSubCmnd, "admin")
+	r.Register("reset",
+		"Remove a users override for a command",
+		"reset <user> <command>",
+		[]CommandArgument{
+			arg("user", "mention or ID of the user"),
+			arg("command", "command to remove the override for")},
+		resetUserCmndSubCmnd, "admin")
 
 	r.Register("mod",
 		"Configure mods installed on the Avorion server",
-		"mod <add|remove|list>",
+		"mod <add|remove|list|search|deploy>",
 		make([]CommandArgument, 0),
 		proxySubCmnd)
 	r.Register("add",
@@ -241,6 +600,17 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 		"list",
 		make([]CommandArgument, 0),
 		listModsSubCmnd, "mod")
+	r.Register("search",
+		"Search the Steam Workshop for mods to add to the server",
+		"search <query>",
+		[]CommandArgument{
+			arg("query", "text to search the workshop for")},
+		modSearchSubCmnd, "mod")
+	r.Register("deploy",
+		"Install or update the companion mod on the server from the bot",
+		"deploy",
+		make([]CommandArgument, 0),
+		modDeploySubCmnd, "mod")
 
 	r.Register("modlist",
 		"List the workshop mods that are currently configured to be installed",
@@ -261,7 +631,7 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 
 	r.Register("player",
 		"Moderate a given player",
-		"player <kick|ban>",
+		"player <kick|ban|tempban|unban>",
 		make([]CommandArgument, 0),
 		proxySubCmnd)
 	r.Register("kick",
@@ -271,11 +641,48 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 			arg("player index", "Valid player index")},
 		playerKickCmnd, "player")
 	r.Register("ban",
-		"Ban the given player",
-		"ban <player index>",
+		"Ban the given player, or list the bans that were issued",
+		"ban <player index|list> [reason]",
 		[]CommandArgument{
-			arg("player index", "Valid player index")},
+			arg("player index", "Valid player index"),
+			arg("list", "List the bans issued through the bot"),
+			arg("reason", "Reason given to the banned player")},
 		playerBanCmnd, "player")
+	r.Register("tempban",
+		"Ban the given player for a while, lifting the ban once it expires",
+		"tempban <player index> <duration> [reason]",
+		[]CommandArgument{
+			arg("player index", "Valid player index"),
+			arg("duration", "How long to ban the player for, such as 12h or 3d"),
+			arg("reason", "Reason given to the banned player")},
+		playerTempBanCmnd, "player")
+	r.Register("unban",
+		"Lift the ban of the given player",
+		"unban <player index|name>",
+		[]CommandArgument{
+			arg("player index|name", "Index or full name of the banned player")},
+		playerUnbanCmnd, "player")
+
+	r.Register("alliance",
+		"Moderate every member of a given alliance",
+		"alliance <kickall|banall>",
+		make([]CommandArgument, 0),
+		proxySubCmnd)
+	r.Register("kickall",
+		"Kick every online member of the given alliance",
+		"kickall <index|name> [reason]",
+		[]CommandArgument{
+			arg("index|name", "Index or full name of the alliance"),
+			arg("reason", "Reason given to the kicked players")},
+		allianceKickAllCmnd, "alliance")
+	r.Register("banall",
+		"Ban every member of the given alliance, after confirmation",
+		"banall <index|name> [reason]",
+		[]CommandArgument{
+			arg("index|name", "Index or full name of the alliance"),
+			arg("reason", "Reason given to the banned players"),
+			arg("confirm <code>", "Confirm a ban with the code it was given")},
+		allianceBanAllCmnd, "alliance")
 
 	r.Register("showonline",
 		"Show the players that are currently online",
@@ -288,4 +695,61 @@ func InitializeCommandRegistry(r *CommandRegistrar) {
 		"checkhang",
 		make([]CommandArgument, 0),
 		checkHangCmnd)
+
+	// Examples are shown in the help output of each command
+	r.AddExamples("help", "help server restart")
+	r.AddExamples("loglevel", "loglevel 3 rcon status")
+	r.AddExamples("setalias", "setalias getplayers gp")
+	r.AddExamples("rcon", "rcon status", "rcon say The server restarts soon")
+	r.AddExamples("stats commands", "stats commands", "stats commands all")
+	r.AddExamples("privacy", "privacy on")
+	r.AddExamples("backup at", "backup at 03:00", "backup at 03:00 tomorrow")
+	r.AddExamples("schedule", "schedule", "schedule cancel 3")
+	r.AddExamples("backup restore",
+		"backup restore Galaxy-20240101-120000-scheduled.tar.gz confirm")
+	r.AddExamples("gameconfig revert", "gameconfig revert 4",
+		"gameconfig revert 4 confirm")
+	r.AddExamples("alliancealerts channel", "alliancealerts channel #alerts",
+		"alliancealerts channel #alerts @Fleet", "alliancealerts channel off")
+	r.AddExamples("alliancealerts claim", "alliancealerts claim 120:-45")
+	r.AddExamples("chatsearch", "chatsearch pirates", "chatsearch pirates SleepyFugu 2d")
+	r.AddExamples("getjumps", "getjumps 10 SleepyFugu",
+		"getjumps SleepyFugu --export json")
+	r.AddExamples("getcoordhistory", "getcoordhistory 0:0 -150:220")
+	r.AddExamples("findship", "findship Behemoth")
+	r.AddExamples("search", "search Behemoth", "search sleepy")
+	r.AddExamples("reviews", "reviews", "reviews resolve 5")
+	r.AddExamples("reply", "reply 12 Thanks, we'll take a look shortly")
+	r.AddExamples("getalliance", "getalliance 2000005", "getalliance Iron Fleet",
+		"getalliance Iron Fleet contributions")
+	r.AddExamples("setstatuschannel", "setstatuschannel 123456789012345678 public")
+	r.AddExamples("settimezone", "settimezone America/New_York")
+	r.AddExamples("setlocale", "setlocale en-GB", "setlocale de")
+	r.AddExamples("server restart", "server restart", "server restart 15",
+		"server restart cancel", "server restart at 22:30")
+	r.AddExamples("server maintenance", "server maintenance",
+		"server maintenance on 2h", "server maintenance off")
+	r.AddExamples("server exits", "server exits", "server exits 25")
+	r.AddExamples("selfupdate", "selfupdate", "selfupdate install")
+	r.AddExamples("playerdb refresh", "playerdb refresh")
+	r.AddExamples("db rebuild", "db rebuild", "db rebuild confirm")
+	r.AddExamples("db galaxies", "db galaxies", "db galaxies purge Season1",
+		"db galaxies purge Season1@1a2b3c4d confirm")
+	r.AddExamples("migrate install", "migrate install /srv/avorion/server_files_new")
+	r.AddExamples("export players", "export players csv",
+		"export chat json SleepyFugu")
+	r.AddExamples("admin addrole", "admin addrole 123456789012345678 5")
+	r.AddExamples("admin addcommand", "admin addcommand rcon 9")
+	r.AddExamples("admin grant", "admin grant @Helper rcon")
+	r.AddExamples("admin revoke", "admin revoke 123456789012345678 export")
+	r.AddExamples("admin reset", "admin reset @Helper rcon")
+	r.AddExamples("player kick", "player kick 5 Please read the rules")
+	r.AddExamples("player ban", "player ban 5 Griefing", "player ban list")
+	r.AddExamples("player tempban", "player tempban 5 3d Griefing")
+	r.AddExamples("player unban", "player unban 5", "player unban Stylo")
+	r.AddExamples("logs search", "logs search panic --since 12h",
+		"logs search failed to (start|stop) --game")
+	r.AddExamples("alliance kickall", "alliance kickall Iron Fleet Raiding")
+	r.AddExamples("alliance banall", "alliance banall Iron Fleet Raiding",
+		"alliance banall confirm QXRTVB")
 }
@@ -3,6 +3,8 @@ package commands
 import (
 	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -243,3 +245,132 @@ func showSelfCmndSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a Bot
 	out.Construct()
 	return out, nil
 }
+
+var reUserArg = regexp.MustCompile(`^(?:<@!?(\d+)>|(\d+))$`)
+
+// userCmndArgs resolves the member and top level command that a user override
+// subcommand was given
+func userCmndArgs(s *discordgo.Session, a BotArgs,
+	cmd *CommandRegistrant) (*discordgo.Member, string, ICommandError) {
+	reg := cmd.Registrar()
+
+	// Account for the fact that this is a subcommand by passing the
+	//	HasNumArgs function a slice of the args removing the first argument
+	if !HasNumArgs(a[1:], 2, 2) {
+		return nil, "", &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	match := reUserArg.FindStringSubmatch(a[2])
+	if match == nil {
+		return nil, "", &ErrInvalidArgument{
+			message: sprintf("`%s` is not a valid user mention or ID", a[2]),
+			cmd:     cmd}
+	}
+
+	uid := match[1] + match[2]
+	member, err := s.GuildMember(reg.GuildID, uid)
+	if err != nil {
+		return nil, "", &ErrInvalidArgument{
+			message: sprintf("`%s` is not a member of this guild", a[2]),
+			cmd:     cmd}
+	}
+
+	target, err := reg.Command(a[3])
+	if err != nil {
+		return nil, "", &ErrInvalidArgument{
+			message: sprintf("`%s` is not a valid command", a[3]),
+			cmd:     cmd}
+	}
+
+	return member, target.Name(), nil
+}
+
+func grantUserCmndSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	member, name, cmderr := userCmndArgs(s, a, cmd)
+	if cmderr != nil {
+		return nil, cmderr
+	}
+
+	c.SetUserCmndAuth(member.User.ID, name, true)
+	logger.LogInfo(cmd, sprintf("%s granted %s the %s command",
+		m.Author.String(), member.User.String(), name))
+	return nil, nil
+}
+
+func revokeUserCmndSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	member, name, cmderr := userCmndArgs(s, a, cmd)
+	if cmderr != nil {
+		return nil, cmderr
+	}
+
+	c.SetUserCmndAuth(member.User.ID, name, false)
+	logger.LogInfo(cmd, sprintf("%s revoked the %s command from %s",
+		m.Author.String(), name, member.User.String()))
+	return nil, nil
+}
+
+func resetUserCmndSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	member, name, cmderr := userCmndArgs(s, a, cmd)
+	if cmderr != nil {
+		return nil, cmderr
+	}
+
+	if err := c.RemoveUserCmndAuth(member.User.ID, name); err != nil {
+		return nil, &ErrCommandError{
+			message: sprintf("%s does not have an override for `%s`",
+				member.User.String(), name),
+			cmd: cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s removed the %s override for %s",
+		m.Author.String(), name, member.User.String()))
+	return nil, nil
+}
+
+func showUserCmndsSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out       = newCommandOutput(cmd, "Admin User Overrides")
+		overrides = c.UserCmndAuth()
+		uids      = make([]string, 0, len(overrides))
+	)
+
+	for uid := range overrides {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	for _, uid := range uids {
+		granted := make([]string, 0)
+		revoked := make([]string, 0)
+		for name, allowed := range overrides[uid] {
+			if allowed {
+				granted = append(granted, name)
+			} else {
+				revoked = append(revoked, name)
+			}
+		}
+		sort.Strings(granted)
+		sort.Strings(revoked)
+
+		out.AddLine(sprintf("<@%s>", uid))
+		if len(granted) > 0 {
+			out.AddLine("> Granted: `" + strings.Join(granted, "`, `") + "`")
+		}
+		if len(revoked) > 0 {
+			out.AddLine("> Revoked: `" + strings.Join(revoked, "`, `") + "`")
+		}
+	}
+
+	if len(uids) == 0 {
+		out.AddLine("No users have command overrides")
+	}
+
+	out.Construct()
+	return out, nil
+}
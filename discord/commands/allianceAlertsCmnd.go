@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"regexp"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	reChannelArg = regexp.MustCompile(`^(?:<#(\d+)>|(\d+))$`)
+	reRoleArg    = regexp.MustCompile(`^(?:<@&(\d+)>|(\d+))$`)
+	reClaimCoord = regexp.MustCompile(`^(-?[0-9]{1,3}):(-?[0-9]{1,3})$`)
+)
+
+// ledAlliance returns the alliance that the integrated player of a Discord
+// user leads
+func ledAlliance(srv ifaces.IGameServer, uid string,
+	cmd *CommandRegistrant) (ifaces.IAlliance, ICommandError) {
+	p := srv.PlayerFromDiscord(uid)
+	if p == nil {
+		return nil, &ErrCommandError{
+			message: "Your Discord account is not integrated with a player",
+			cmd:     cmd}
+	}
+
+	for _, a := range srv.Alliances() {
+		if a.Leadership().Leader == p.Index() {
+			return a, nil
+		}
+	}
+
+	return nil, &ErrCommandError{
+		message: sprintf("**%s** isn't the leader of an alliance", p.Name()),
+		cmd:     cmd}
+}
+
+func allianceAlertsShowSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Alliance Alerts")
+		srv = cmd.Registrar().server
+	)
+
+	ally, cerr := ledAlliance(srv, m.Author.ID, cmd)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	alerts, _ := srv.AllianceAlerts(ally.Index())
+	out.AddLine(sprintf("**Alliance:** %s", ally.Name()))
+
+	if alerts.Channel == "" {
+		out.AddLine("**Channel:** _off_")
+	} else {
+		out.AddLine(sprintf("**Channel:** <#%s>", alerts.Channel))
+	}
+
+	if alerts.Role != "" {
+		out.AddLine(sprintf("**Role:** <@&%s>", alerts.Role))
+	}
+
+	if regions := c.ContestedRegions(); len(regions) > 0 {
+		out.AddLine("")
+		out.AddLine("**Contested Regions**")
+		for _, r := range regions {
+			out.AddLine(sprintf("%s: `%d:%d` to `%d:%d`", r.Name, r.MinX, r.MinY,
+				r.MaxX, r.MaxY))
+		}
+	}
+
+	out.AddLine("")
+	out.AddLine(sprintf("**Claimed Sectors (%d)**", len(alerts.Claims)))
+	for _, sc := range alerts.Claims {
+		out.AddLine(sprintf("`%d:%d`", sc.X, sc.Y))
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+func allianceAlertsChannelSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Alliance Alert Channel")
+		srv = cmd.Registrar().server
+	)
+
+	if !HasNumArgs(a[1:], 1, 2) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments",
+				cmd.Name()),
+			cmd: cmd}
+	}
+
+	ally, cerr := ledAlliance(srv, m.Author.ID, cmd)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	alerts := ifaces.AllianceAlerts{Alliance: ally.Index()}
+
+	if a[2] != "off" {
+		match := reChannelArg.FindStringSubmatch(a[2])
+		if match == nil {
+			return nil, &ErrInvalidArgument{
+				message: sprintf("Invalid channel: `%s`", a[2]),
+				cmd:     cmd}
+		}
+		alerts.Channel = match[1] + match[2]
+
+		ch, err := s.State.Channel(alerts.Channel)
+		if err != nil || ch.GuildID != m.GuildID ||
+			ch.Type != discordgo.ChannelTypeGuildText {
+			return nil, &ErrInvalidArgument{
+				message: sprintf("Invalid channel: `%s`", a[2]),
+				cmd:     cmd}
+		}
+
+		if len(a) > 3 {
+			match = reRoleArg.FindStringSubmatch(a[3])
+			if match == nil {
+				return nil, &ErrInvalidArgument{
+					message: sprintf("Invalid role: `%s`", a[3]),
+					cmd:     cmd}
+			}
+			alerts.Role = match[1] + match[2]
+
+			if _, err := s.State.Role(m.GuildID, alerts.Role); err != nil {
+				return nil, &ErrInvalidArgument{
+					message: sprintf("Invalid role: `%s`", a[3]),
+					cmd:     cmd}
+			}
+		}
+	}
+
+	if err := srv.SetAllianceAlerts(alerts); err != nil {
+		logger.LogError(cmd, "SetAllianceAlerts: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to save the alert channel",
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s set the alert channel of %s to %q",
+		m.Author.String(), ally.Name(), alerts.Channel))
+
+	if alerts.Channel == "" {
+		out.AddLine(sprintf("Turned off alerts for **%s**", ally.Name()))
+	} else {
+		out.AddLine(sprintf("Alerts for **%s** will be sent to <#%s>",
+			ally.Name(), alerts.Channel))
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+// allianceClaimSubCmnd handles both claiming and unclaiming sectors, depending
+// on the name of the subcommand
+func allianceClaimSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out   = newCommandOutput(cmd, "Claimed Sectors")
+		srv   = cmd.Registrar().server
+		claim = cmd.Name() == "claim"
+	)
+
+	if !HasNumArgs(a[1:], 1, 1) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments",
+				cmd.Name()),
+			cmd: cmd}
+	}
+
+	match := reClaimCoord.FindStringSubmatch(a[2])
+	if match == nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("Invalid coordinate given: `%s`", a[2]),
+			cmd:     cmd}
+	}
+
+	x, _ := strconv.Atoi(match[1])
+	y, _ := strconv.Atoi(match[2])
+	if x > 500 || x < -500 || y > 500 || y < -500 {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("Coordinate is out of range: `%s`", a[2]),
+			cmd:     cmd}
+	}
+
+	ally, cerr := ledAlliance(srv, m.Author.ID, cmd)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if err := srv.ClaimSector(ally.Index(), x, y, claim); err != nil {
+		return nil, &ErrCommandError{
+			message: sprintf("Failed to update `%d:%d`: %s", x, y, err.Error()),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s updated the claim of %s on %d:%d (%t)",
+		m.Author.String(), ally.Name(), x, y, claim))
+
+	if claim {
+		out.AddLine(sprintf("**%s** claimed `%d:%d`", ally.Name(), x, y))
+	} else {
+		out.AddLine(sprintf("**%s** no longer claims `%d:%d`", ally.Name(), x, y))
+	}
+
+	out.Construct()
+	return out, nil
+}
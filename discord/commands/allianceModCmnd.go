@@ -0,0 +1,264 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/randstring"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// allianceBanWindow is how long a requested alliance ban waits for confirmation
+const allianceBanWindow = 2 * time.Minute
+
+// pendingAllianceBan is an alliance ban that is waiting to be confirmed by the
+// staff member that requested it
+type pendingAllianceBan struct {
+	code     string
+	alliance string
+	reason   string
+	expires  time.Time
+
+	// Indexes of the players that were shown to the staff member, so that
+	// confirming bans exactly those players
+	players []string
+}
+
+// allianceFromArgs finds the alliance named by the leading arguments, trying
+// the longest name first so that names with spaces work. Any arguments after
+// the name are returned as the reason.
+func allianceFromArgs(srv ifaces.IGameServer,
+	args []string) (ifaces.IAlliance, string) {
+	for i := len(args); i > 0; i-- {
+		ref := strings.Join(args[:i], " ")
+		alliance := srv.Alliance(ref)
+		if alliance == nil {
+			alliance = srv.AllianceFromName(ref)
+		}
+
+		if alliance != nil {
+			return alliance, strings.Join(args[i:], " ")
+		}
+	}
+	return nil, ""
+}
+
+// allianceMembers returns the tracked players that are currently members of an
+// alliance. The founder isn't included, since they may have left the alliance
+// since it was founded.
+func allianceMembers(srv ifaces.IGameServer,
+	alliance ifaces.IAlliance) []ifaces.IPlayer {
+	var (
+		lead    = alliance.Leadership()
+		seen    = make(map[string]bool)
+		members = make([]ifaces.IPlayer, 0)
+	)
+
+	for _, index := range lead.Members {
+		if index == "" || seen[index] {
+			continue
+		}
+		seen[index] = true
+
+		if p := srv.Player(index); p != nil {
+			members = append(members, p)
+		}
+	}
+	return members
+}
+
+func allianceKickAllCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		reg = cmd.Registrar()
+		srv = reg.server
+		out = newCommandOutput(cmd, "Kick Alliance")
+	)
+
+	out.Quoted = true
+
+	if !HasNumArgs(a[1:], 1, -1) {
+		return nil, &ErrInvalidArgument{
+			message: "Please provide an alliance index or name to kick",
+			cmd:     cmd}
+	}
+
+	if srv == nil || !srv.IsUp() {
+		return nil, &ErrCommandError{
+			message: "Server has not finished initializing",
+			cmd:     cmd}
+	}
+
+	alliance, reason := allianceFromArgs(srv, a[2:])
+	if alliance == nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("%s is an invalid reference to an alliance",
+				strings.Join(a[2:], " ")),
+			cmd: cmd}
+	}
+
+	if reason == "" {
+		reason = `Kicked by an Admin`
+	}
+
+	kicked := 0
+	for _, p := range allianceMembers(srv, alliance) {
+		if !p.Online() {
+			continue
+		}
+
+		p.Kick(reason)
+		out.AddLine(sprintf("Kicked `%s` _(index %s)_", p.Name(), p.Index()))
+		kicked++
+	}
+
+	logger.LogInfo(cmd, sprintf("[%s] kicked %d members of [%s]",
+		m.Author.String(), kicked, alliance.Name()))
+
+	out.Header = sprintf("%s _(index %s)_", alliance.Name(), alliance.Index())
+	if kicked == 0 {
+		out.AddLine("No members of the alliance are online")
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+func allianceBanAllCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		reg = cmd.Registrar()
+		srv = reg.server
+		out = newCommandOutput(cmd, "Ban Alliance")
+	)
+
+	out.Quoted = true
+
+	if !HasNumArgs(a[1:], 1, -1) {
+		return nil, &ErrInvalidArgument{
+			message: "Please provide an alliance index or name to ban",
+			cmd:     cmd}
+	}
+
+	if srv == nil || !srv.IsUp() {
+		return nil, &ErrCommandError{
+			message: "Server has not finished initializing",
+			cmd:     cmd}
+	}
+
+	if a[2] == "confirm" && len(a) == 4 {
+		return confirmAllianceBan(m, a[3], cmd)
+	}
+
+	alliance, reason := allianceFromArgs(srv, a[2:])
+	if alliance == nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("%s is an invalid reference to an alliance",
+				strings.Join(a[2:], " ")),
+			cmd: cmd}
+	}
+
+	members := allianceMembers(srv, alliance)
+	if len(members) == 0 {
+		return nil, &ErrCommandError{
+			message: "No members have been recorded for that alliance",
+			cmd:     cmd}
+	}
+
+	if reason == "" {
+		reason = `Banned by an Admin`
+	}
+
+	// Nothing is banned until the same staff member repeats the code back
+	ban := &pendingAllianceBan{
+		code:     strings.ToUpper(randstring.New(6)),
+		alliance: alliance.Index(),
+		reason:   reason,
+		expires:  time.Now().Add(allianceBanWindow),
+		players:  make([]string, 0, len(members))}
+	for _, p := range members {
+		ban.players = append(ban.players, p.Index())
+	}
+
+	reg.banlock.Lock()
+	reg.bans[m.Author.ID] = ban
+	reg.banlock.Unlock()
+
+	out.Header = sprintf("%s _(index %s)_", alliance.Name(), alliance.Index())
+	out.Status = ifaces.CommandWarning
+	out.AddLine(sprintf("**This will ban %d players:**", len(members)))
+	for _, p := range members {
+		out.AddLine(sprintf("> `%s` _(index %s)_", p.Name(), p.Index()))
+	}
+	out.AddLine("**Reason:** _" + reason + "_")
+	out.AddLine(sprintf("To confirm, run `alliance banall confirm %s` within %s",
+		ban.code, allianceBanWindow))
+
+	out.Construct()
+	return out, nil
+}
+
+// confirmAllianceBan bans the players that were listed when the ban was
+// requested, if the code matches the ban that was last requested by the
+// message author
+func confirmAllianceBan(m *discordgo.MessageCreate, code string,
+	cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		reg = cmd.Registrar()
+		srv = reg.server
+		out = newCommandOutput(cmd, "Ban Alliance")
+	)
+
+	out.Quoted = true
+
+	reg.banlock.Lock()
+	ban := reg.bans[m.Author.ID]
+	if ban != nil && strings.EqualFold(ban.code, code) {
+		delete(reg.bans, m.Author.ID)
+	}
+	reg.banlock.Unlock()
+
+	if ban == nil || !strings.EqualFold(ban.code, code) {
+		return nil, &ErrInvalidArgument{
+			message: "That code does not match a ban you requested",
+			cmd:     cmd}
+	}
+
+	if time.Now().After(ban.expires) {
+		return nil, &ErrCommandError{
+			message: "That ban has expired, please request it again",
+			cmd:     cmd}
+	}
+
+	alliance := srv.Alliance(ban.alliance)
+	if alliance == nil {
+		return nil, &ErrCommandError{
+			message: "That alliance no longer exists",
+			cmd:     cmd}
+	}
+
+	banned := 0
+	for _, index := range ban.players {
+		p := srv.Player(index)
+		if p == nil {
+			out.AddLine(sprintf("Player _(index %s)_ is no longer tracked", index))
+			continue
+		}
+
+		if _, err := srv.BanPlayer(p, m.Author.String(), ban.reason,
+			0); err != nil {
+			logger.LogError(cmd, "BanPlayer: "+err.Error())
+		}
+		out.AddLine(sprintf("Banned `%s` _(index %s)_", p.Name(), p.Index()))
+		banned++
+	}
+
+	logger.LogWarning(cmd, sprintf("[%s] banned %d members of [%s]",
+		m.Author.String(), banned, alliance.Name()))
+
+	out.Header = sprintf("%s _(index %s)_", alliance.Name(), alliance.Index())
+	out.Construct()
+	return out, nil
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dustin/go-humanize"
+)
+
+func backupNowSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Galaxy Backup")
+		srv = cmd.Registrar().server
+	)
+
+	logger.LogInfo(cmd, sprintf("%s started a galaxy backup", m.Author.String()))
+
+	b, err := srv.Backup(ifaces.BackupManual)
+	if err != nil {
+		logger.LogError(cmd, "Backup: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to back up the galaxy: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	out.AddLine(sprintf("Backed up the galaxy to `%s` (%s)", b.Name,
+		humanize.Bytes(uint64(b.Size))))
+	out.Construct()
+	return out, nil
+}
+
+func backupAtSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Scheduled Backup")
+		srv = cmd.Registrar().server
+	)
+
+	if !HasNumArgs(a[1:], 1, 2) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	at, err := parseTimeOfDay(a[2:], c.Location(), time.Now())
+	if err != nil {
+		return nil, &ErrInvalidArgument{
+			message: "Invalid time: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	act, err := srv.ScheduleAction(ifaces.ActionBackup, at, m.Author.String())
+	if err != nil {
+		logger.LogError(cmd, "ScheduleAction: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to schedule the backup: " + ifaces.ErrorMessage(err),
+			cmd:     cmd}
+	}
+
+	out.AddLine(sprintf("The galaxy will be backed up at %s (<t:%d:R>)",
+		c.Locale().DateTimeZone(act.At, c.Location()), act.At.Unix()))
+	out.AddLine(sprintf("Run `schedule cancel %d` to cancel it", act.ID))
+	out.Construct()
+	return out, nil
+}
+
+func backupListSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Galaxy Backups")
+		srv = cmd.Registrar().server
+	)
+
+	backups, err := srv.Backups()
+	if err != nil {
+		logger.LogError(cmd, "Backups: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to list the galaxy backups",
+			cmd:     cmd}
+	}
+
+	if len(backups) == 0 {
+		out.AddLine("There are no backups of the galaxy")
+	}
+
+	for _, b := range backups {
+		out.AddLine(sprintf("`%s` <t:%d:R> (_%s_, %s)", b.Name, b.Time.Unix(),
+			b.Reason, humanize.Bytes(uint64(b.Size))))
+	}
+
+	if d := c.BackupInterval(); d > 0 {
+		out.AddLine("")
+		out.AddLine(sprintf("_Backups are taken every %s while the server is "+
+			"running_", d))
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+func backupRestoreSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Restore Galaxy Backup")
+		srv = cmd.Registrar().server
+	)
+
+	if !HasNumArgs(a[1:], 1, 2) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	name := a[2]
+	if len(a) < 4 || a[3] != "confirm" {
+		out.AddLine(sprintf("This replaces the galaxy with the backup `%s`. "+
+			"Everything that happened in the galaxy since it was taken will be "+
+			"lost. The current galaxy is backed up first, so the restore can be "+
+			"undone.", name))
+		if srv.IsUp() {
+			out.AddLine("**The server has to be stopped before restoring.**")
+		}
+		out.AddLine(sprintf("Run `backup restore %s confirm` to restore it", name))
+		out.Construct()
+		return out, nil
+	}
+
+	logger.LogInfo(cmd, sprintf("%s restored the galaxy backup %s",
+		m.Author.String(), name))
+
+	if err := srv.RestoreBackup(name); err != nil {
+		logger.LogError(cmd, "RestoreBackup: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to restore the backup: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	out.AddLine(sprintf("Restored the galaxy from `%s`. It will be used the "+
+		"next time the server starts", name))
+	out.Construct()
+	return out, nil
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func botInfoCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out  = newCommandOutput(cmd, "Bot Info")
+		core = cmd.Registrar().core
+	)
+
+	if core == nil {
+		return nil, &ErrCommandError{
+			message: "Bot information is not available",
+			cmd:     cmd}
+	}
+
+	stats := core.Stats()
+	out.AddLine(sprintf("**Version:** _%s_", core.Version()))
+	out.AddLine(sprintf("**Uptime:** _%s_", c.Locale().Duration(core.Uptime())))
+	out.AddLine(sprintf("**Goroutines:** _%d_", stats.Goroutines))
+	out.AddLine(sprintf("**Memory:** _%.1f MiB heap, %.1f MiB reserved (%d GCs)_",
+		float64(stats.HeapAlloc)/(1<<20), float64(stats.Sys)/(1<<20), stats.NumGC))
+
+	out.AddLine("")
+	out.AddLine("**Subsystems:**")
+	for _, sub := range core.Health() {
+		icon := "✅"
+		if !sub.Healthy {
+			icon = "❌"
+		}
+		out.AddLine(sprintf("%s %s: _%s_", icon, sub.Name, sub.Detail))
+	}
+
+	out.Construct()
+	return out, nil
+}
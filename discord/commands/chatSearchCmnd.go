@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const chatSearchLimit = 100
+
+var reSinceDuration = regexp.MustCompile(`^([0-9]+)([mhd])$`)
+
+// parseSince converts a relative duration such as 30m, 12h, or 7d into the time
+// that the duration began
+func parseSince(in string) (time.Time, bool) {
+	m := reSinceDuration.FindStringSubmatch(in)
+	if m == nil {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	unit := map[string]time.Duration{
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour}[m[2]]
+
+	return time.Now().Add(-time.Duration(n) * unit), true
+}
+
+func chatSearchCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out   = newCommandOutput(cmd, "Chat Search")
+		srv   = cmd.Registrar().server
+		name  = ""
+		since = time.Time{}
+	)
+
+	if !HasNumArgs(a, 1, 3) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", a[0]),
+			cmd:     cmd}
+	}
+
+	// The optional arguments are a player name and a duration, with the duration
+	// always being last
+	opts := a[2:]
+	if l := len(opts); l > 0 {
+		if t, ok := parseSince(opts[l-1]); ok {
+			since = t
+			opts = opts[:l-1]
+		} else if l > 1 {
+			return nil, &ErrInvalidArgument{
+				message: sprintf("`%s` is not a valid duration (e.g. 12h, 7d)",
+					opts[l-1]),
+				cmd: cmd}
+		}
+	}
+
+	if len(opts) > 0 {
+		name = opts[0]
+	}
+
+	records, err := srv.SearchChat(a[1], name, since, chatSearchLimit)
+	if err != nil {
+		logger.LogError(cmd, "SearchChat: "+err.Error())
+		return nil, &ErrCommandError{
+			message: sprintf("Failed to search chat history: `%s`",
+				ifaces.ErrorMessage(err)),
+			cmd: cmd}
+	}
+
+	loc := c.Location()
+	out.Header = sprintf("Results for `%s`", a[1])
+	out.Quoted = true
+
+	if len(records) == 0 {
+		out.AddLine("No results found")
+	}
+
+	for _, r := range records {
+		out.AddLine(sprintf("**%s | %s** _(%s)_: %s", c.Locale().DateTime(r.Time, loc),
+			r.Name, r.Source, r.Msg))
+	}
+
+	out.Construct()
+	return out, nil
+}
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"avorioncontrol/ifaces"
+	"avorioncontrol/shutdown"
+	"errors"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -56,8 +58,12 @@ func checkHangCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 		// TODO: Might not be a bad idea to add a (very) simple syn/ack command to
 		// the game for this purpose
 		_, err := srv.RunCommand(`echo Server status check`)
-		if err != nil && err.Error() != "Server is not online" {
-			go func() { srv.Restart(); checkingState = false }()
+		if err != nil && !errors.Is(err, ifaces.ErrServerOffline) {
+			go func() {
+				defer shutdown.Recover()
+				srv.Restart()
+				checkingState = false
+			}()
 			srv.Crashed()
 			out.AddLine("Server is hanging or is down, starting restart process")
 		} else {
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// commandEditWindow is how long after a command is run that editing its
+// message will run the command again
+const commandEditWindow = 2 * time.Minute
+
+// commandMessage is a message that ran a command, which is tracked so that an
+// edit to it can run the command again and replace its output
+type commandMessage struct {
+	at      time.Time
+	content string
+
+	// ID of the single page embed that the command responded with, if any
+	reply string
+}
+
+// trackCommand records a message that is running a command, and forgets any
+// messages that can no longer be edited to run a command again
+func (reg *CommandRegistrar) trackCommand(m *discordgo.MessageCreate) {
+	reg.editlock.Lock()
+	defer reg.editlock.Unlock()
+
+	for id, cm := range reg.edits {
+		if time.Since(cm.at) > commandEditWindow {
+			delete(reg.edits, id)
+		}
+	}
+
+	if cm, ok := reg.edits[m.ID]; ok {
+		cm.content = m.Content
+		return
+	}
+
+	reg.edits[m.ID] = &commandMessage{at: time.Now(), content: m.Content}
+}
+
+// commandReply returns the ID of the embed that a command message was last
+// answered with, or an empty string if there isn't one
+func (reg *CommandRegistrar) commandReply(mid string) string {
+	reg.editlock.Lock()
+	defer reg.editlock.Unlock()
+
+	if cm, ok := reg.edits[mid]; ok {
+		return cm.reply
+	}
+	return ""
+}
+
+// setCommandReply records the embed that a command message was answered with
+func (reg *CommandRegistrar) setCommandReply(mid, reply string) {
+	reg.editlock.Lock()
+	defer reg.editlock.Unlock()
+
+	if cm, ok := reg.edits[mid]; ok {
+		cm.reply = reply
+	}
+}
+
+// ReprocessCommand runs a command again when the message that ran it is edited
+// within a short window, replacing the output of the previous run where it
+// can. It returns false if the edit didn't run a command.
+//  @s *discordgo.Session          Discordgo Session
+//  @m *discordgo.MessageUpdate    Discordgo message edit event
+//  @c IConfigurator               Bot configuration pointer
+func (reg *CommandRegistrar) ReprocessCommand(s *discordgo.Session,
+	m *discordgo.MessageUpdate, c ifaces.IConfigurator,
+	exitch chan struct{}) (bool, string, ICommandError) {
+	if m.Message == nil || m.Author == nil {
+		return false, "", nil
+	}
+
+	reg.editlock.Lock()
+	cm, ok := reg.edits[m.ID]
+	ok = ok && time.Since(cm.at) <= commandEditWindow && cm.content != m.Content
+	reg.editlock.Unlock()
+
+	if !ok {
+		return false, "", nil
+	}
+
+	logger.LogInfo(reg, sprintf("%s edited a command, running it again",
+		m.Author.String()))
+
+	for _, r := range []string{"✅", "🚫", queuedReaction} {
+		s.MessageReactionRemove(m.ChannelID, m.ID, r, "@me")
+	}
+
+	name, cmderr := reg.ProcessCommand(s, &discordgo.MessageCreate{
+		Message: m.Message}, c, exitch)
+	return true, name, cmderr
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dustin/go-humanize"
+)
+
+func dbStatusSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Tracking Database")
+		srv = cmd.Registrar().server
+	)
+
+	if reason := srv.DBDegraded(); reason != "" {
+		out.AddLine("⚠️ The tracking database is unavailable: " + reason)
+		out.AddLine("Tracked data is being kept in memory and won't be saved. " +
+			"Run `db rebuild confirm` to recreate the database.")
+	} else {
+		out.AddLine("The tracking database is working normally")
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+func dbRebuildSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Rebuild Tracking Database")
+		srv = cmd.Registrar().server
+	)
+
+	if len(a) < 3 || a[2] != "confirm" {
+		out.AddLine("This moves the current tracking database aside and creates " +
+			"a new one. Integrations, chat history, and other tracked data that " +
+			"can't be re-imported from the game will start over.")
+		if srv.DBDegraded() == "" {
+			out.AddLine("**The tracking database is currently working normally.**")
+		}
+		out.AddLine("Run `db rebuild confirm` to rebuild it")
+		out.Construct()
+		return out, nil
+	}
+
+	logger.LogInfo(cmd, sprintf("%s rebuilt the tracking database",
+		m.Author.String()))
+
+	if err := srv.RebuildDB(); err != nil {
+		logger.LogError(cmd, "RebuildDB: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to rebuild the tracking database: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	out.AddLine("Rebuilt the tracking database")
+	if !srv.IsUp() {
+		out.AddLine("Player data will be imported from the game once the server " +
+			"is started")
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+func dbGalaxiesSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Tracked Galaxies")
+		srv = cmd.Registrar().server
+	)
+
+	if len(a) > 2 {
+		return dbPurgeGalaxy(m, a, out, cmd)
+	}
+
+	galaxies, err := srv.TrackedGalaxies()
+	if err != nil {
+		logger.LogError(cmd, "TrackedGalaxies: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to list the tracked galaxies",
+			cmd:     cmd}
+	}
+
+	if len(galaxies) == 0 {
+		out.AddLine("No galaxies have tracked data")
+	}
+
+	for _, g := range galaxies {
+		line := sprintf("`%s`", g.Name)
+		if g.Seed != "" {
+			line += sprintf(" seed `%s`", g.Seed)
+		}
+		line += sprintf(", last used <t:%d:R> (%s)", g.Modified.Unix(),
+			humanize.Bytes(uint64(g.Size)))
+		if g.Current {
+			line += " **(current)**"
+		}
+		out.AddLine(line)
+	}
+
+	if len(galaxies) > 1 {
+		out.AddLine("")
+		out.AddLine("_Run `db galaxies purge <name>` to delete the data of a " +
+			"galaxy that is no longer run_")
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+// dbPurgeGalaxy handles `db galaxies purge <name> (confirm)`
+func dbPurgeGalaxy(m *discordgo.MessageCreate, a BotArgs, out *CommandOutput,
+	cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	srv := cmd.Registrar().server
+
+	if a[2] != "purge" || len(a) < 4 || len(a) > 5 {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed invalid arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	name := a[3]
+	if len(a) < 5 || a[4] != "confirm" {
+		out.AddLine(sprintf("This permanently deletes the jumps, chat history, "+
+			"integrations, and everything else tracked for `%s`.", name))
+		out.AddLine(sprintf("Run `db galaxies purge %s confirm` to purge it", name))
+		out.Construct()
+		return out, nil
+	}
+
+	if err := srv.PurgeGalaxy(name); err != nil {
+		logger.LogError(cmd, "PurgeGalaxy: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to purge the tracked data: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s purged the tracked data of %s",
+		m.Author.String(), name))
+	out.AddLine(sprintf("Purged the tracked data of `%s`", name))
+	out.Construct()
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func debugRuntimeCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out  = newCommandOutput(cmd, "Runtime Diagnostics")
+		core = cmd.Registrar().core
+		loc  = c.Locale()
+	)
+
+	if core == nil {
+		return nil, &ErrCommandError{
+			message: "Runtime diagnostics are not available",
+			cmd:     cmd}
+	}
+
+	mib := func(b uint64) string {
+		return sprintf("%.1f MiB", float64(b)/(1<<20))
+	}
+
+	stats := core.Stats()
+	out.AddLine(sprintf("**Goroutines:** _%s_", loc.Number(int64(stats.Goroutines))))
+	out.AddLine(sprintf("**Heap:** _%s allocated, %s in use, %s objects_",
+		mib(stats.HeapAlloc), mib(stats.HeapInuse),
+		loc.Number(int64(stats.HeapObjects))))
+	out.AddLine(sprintf("**Stacks:** _%s_", mib(stats.StackInuse)))
+	out.AddLine(sprintf("**Reserved:** _%s_", mib(stats.Sys)))
+	out.AddLine(sprintf("**Allocated since start:** _%s_", mib(stats.TotalAlloc)))
+
+	out.AddLine("")
+	out.AddLine("**Garbage Collection:**")
+	out.AddLine(sprintf("Collections: _%s_", loc.Number(int64(stats.NumGC))))
+	if !stats.LastGC.IsZero() {
+		out.AddLine(sprintf("Last run: _%s ago, paused for %s_",
+			loc.Duration(time.Since(stats.LastGC)), stats.LastPause))
+	}
+	out.AddLine(sprintf("Total pause: _%s_", stats.PauseTotal.Round(time.Microsecond)))
+	out.AddLine(sprintf("Next run at: _%s heap_", mib(stats.NextGC)))
+	out.AddLine(sprintf("CPU used: _%.3f%%_", stats.GCCPU*100))
+
+	out.AddLine("")
+	if c.PProfEnabled() && c.HealthAddress() != "" {
+		out.AddLine(sprintf("Profiles are served at `%s/debug/pprof/`",
+			c.HealthAddress()))
+	} else {
+		out.AddLine("Profiling is disabled, set `API.pprof` to enable it")
+	}
+
+	out.Construct()
+	return out, nil
+}
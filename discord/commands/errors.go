@@ -204,9 +204,10 @@ func (e *ErrUnauthorizedUsage) Emit(s *discordgo.Session, channel string) {
 // ErrInvalidAlias describes an attempt to use an alias that doesn't
 // exist
 type ErrInvalidAlias struct {
-	cmd   *CommandRegistrant
-	sub   *CommandRegistrant
-	alias string
+	cmd     *CommandRegistrant
+	sub     *CommandRegistrant
+	alias   string
+	message string
 }
 
 // Command returns the command object that encountered an error
@@ -220,6 +221,10 @@ func (e *ErrInvalidAlias) Subcommand() *CommandRegistrant {
 }
 
 func (e *ErrInvalidAlias) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+
 	if e.alias == "" {
 		return "Invalid command alias"
 	}
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Exports larger than this are written to the export directory instead of
+// being attached, since Discord rejects larger uploads
+const exportAttachLimit = 8 << 20
+
+// exportSubCmnd exports the tracked data named by the subcommand (players,
+// jumps, or chat) as a CSV or JSON file
+func exportSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out    = newCommandOutput(cmd, "Data Export")
+		srv    = cmd.Registrar().server
+		kind   = cmd.Name()
+		player = ""
+	)
+
+	if !HasNumArgs(a[1:], 1, -1) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	format := strings.ToLower(a[2])
+	if format != "csv" && format != "json" {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` is not a valid export format (csv or json)", a[2]),
+			cmd:     cmd}
+	}
+
+	if len(a) > 3 {
+		player = strings.Join(a[3:], " ")
+		// Chat is only stored by name, so resolve player indexes
+		if p := srv.Player(player); p != nil && kind == "chat" {
+			player = p.Name()
+		}
+	}
+
+	cols, rows, err := srv.ExportData(kind, player)
+	if err != nil {
+		logger.LogError(cmd, "ExportData: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to export data:\n```" + ifaces.ErrorMessage(err) + "```",
+			cmd:     cmd}
+	}
+
+	hidden := 0
+	if kind == "jumps" {
+		rows, hidden = hidePrivateJumps(s, m, c, cmd, cols, rows)
+	}
+
+	name := sprintf("%s-%s.%s", kind, time.Now().Format("20060102-150405"), format)
+	logger.LogInfo(cmd, sprintf("%s exported %d %s records (player: %q)",
+		m.Author.String(), len(rows), kind, player))
+
+	dest, cmderr := sendExport(s, m, c, cmd, name,
+		encodeExport(format, cols, rows))
+	if cmderr != nil {
+		return nil, cmderr
+	}
+
+	out.AddLine(sprintf("Exported %d %s records %s", len(rows), kind, dest))
+	if hidden > 0 {
+		out.AddLine(sprintf("_%d jump(s) hidden by player privacy settings_", hidden))
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+// hidePrivateJumps removes the exported jumps of players whose tracking data
+// the user may not view, and returns the remaining rows along with how many
+// were removed
+func hidePrivateJumps(s *discordgo.Session, m *discordgo.MessageCreate,
+	c ifaces.IConfigurator, cmd *CommandRegistrant, cols []string,
+	rows [][]string) ([][]string, int) {
+	var (
+		reg     = cmd.Registrar()
+		col     = -1
+		visible = make(map[string]bool)
+		kept    = make([][]string, 0, len(rows))
+	)
+
+	for i, name := range cols {
+		if name == "faction_id" {
+			col = i
+		}
+	}
+
+	if col < 0 {
+		return rows, 0
+	}
+
+	for _, row := range rows {
+		id := row[col]
+		ok, seen := visible[id]
+		if !seen {
+			// Alliance jumps aren't covered by the privacy setting
+			p := reg.server.Player(id)
+			ok = p == nil || canViewPlayer(s, reg.GuildID, m.Author.ID, c, p)
+			visible[id] = ok
+		}
+
+		if ok {
+			kept = append(kept, row)
+		}
+	}
+
+	return kept, len(rows) - len(kept)
+}
+
+// encodeExport encodes exported rows as either CSV or JSON
+func encodeExport(format string, cols []string, rows [][]string) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	switch format {
+	case "csv":
+		w := csv.NewWriter(&buf)
+		w.Write(cols)
+		w.WriteAll(rows)
+
+	case "json":
+		records := make([]map[string]string, 0)
+		for _, row := range rows {
+			rec := make(map[string]string, len(cols))
+			for i, col := range cols {
+				rec[col] = row[i]
+			}
+			records = append(records, rec)
+		}
+
+		data, _ := json.MarshalIndent(records, "", "  ")
+		buf.Write(data)
+	}
+
+	return &buf
+}
+
+// sendExport attaches an export to the channel that requested it, or writes
+// it to the export directory if it is too large to attach. It returns a
+// description of where the export was sent.
+func sendExport(s *discordgo.Session, m *discordgo.MessageCreate,
+	c ifaces.IConfigurator, cmd *CommandRegistrant, name string,
+	buf *bytes.Buffer) (string, ICommandError) {
+	if buf.Len() <= exportAttachLimit {
+		if _, err := s.ChannelFileSend(m.ChannelID, name, buf); err != nil {
+			logger.LogError(cmd, "discordgo: "+err.Error())
+			return "", &ErrCommandError{
+				message: "Failed to upload the export: " + err.Error(),
+				cmd:     cmd}
+		}
+
+		return sprintf("as `%s`", name), nil
+	}
+
+	dir := c.ExportPath()
+	path := dir + "/" + name
+	err := os.MkdirAll(dir, 0700)
+	if err == nil {
+		err = ioutil.WriteFile(path, buf.Bytes(), 0600)
+	}
+
+	if err != nil {
+		logger.LogError(cmd, "Export: "+err.Error())
+		return "", &ErrCommandError{
+			message: "Failed to write the export:\n```" + err.Error() + "```",
+			cmd:     cmd}
+	}
+
+	return sprintf("to `%s` (too large to attach)", path), nil
+}
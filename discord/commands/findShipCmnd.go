@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func findShipCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		reg    = cmd.Registrar()
+		out    = newCommandOutput(cmd, "Ship Search")
+		srv    = reg.server
+		loc    = c.Location()
+		hidden = 0
+	)
+
+	if !HasNumArgs(a, 1, -1) {
+		return nil, &ErrInvalidArgument{
+			message: "Please provide a ship name to search for",
+			cmd:     cmd}
+	}
+
+	name := strings.Join(a[1:], " ")
+	ships, err := srv.FindShips(name)
+	if err != nil {
+		logger.LogError(cmd, "FindShips: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to search the ship registry:\n```" +
+				ifaces.ErrorMessage(err) + "```",
+			cmd: cmd}
+	}
+
+	if len(ships) == 0 {
+		out.AddLine(sprintf("No ships matching `%s` have been seen", name))
+		out.Construct()
+		return out, nil
+	}
+
+	out.Header = sprintf("Ships matching `%s` (times are in %s)", name, loc)
+	for _, ship := range ships {
+		// Alliance ships aren't covered by the privacy setting
+		if p := srv.Player(ship.OwnerIndex); p != nil &&
+			!canViewPlayer(s, reg.GuildID, m.Author.ID, c, p) {
+			hidden++
+			continue
+		}
+
+		owner := ship.Owner
+		if owner == "" {
+			owner = "Unknown"
+		}
+
+		out.AddLine(sprintf("**%s** owned by `%s` _(index %s)_", ship.Name, owner,
+			ship.OwnerIndex))
+		out.AddLine(sprintf("> Last seen in `%d:%d` at %s", ship.X, ship.Y,
+			c.Locale().DateTime(ship.Seen, loc)))
+	}
+
+	if hidden > 0 {
+		out.AddLine(sprintf("_%d ship(s) hidden by player privacy settings_", hidden))
+	}
+
+	out.Construct()
+	return out, nil
+}
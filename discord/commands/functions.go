@@ -2,13 +2,17 @@ package commands
 
 import (
 	"avorioncontrol/ifaces"
-	"log"
+	"avorioncontrol/shutdown"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/bwmarrin/discordgo"
 )
 
-func init() {
-	time.LoadLocation("America/New_York")
-}
+var reAliasPlaceholder = regexp.MustCompile(`^\$([0-9]+)$`)
 
 // HasNumArgs - Determine if a set of command arguments is between min and max
 //  @a BotArgs    Argument set to process
@@ -19,7 +23,7 @@ func init() {
 //  for that range.
 func HasNumArgs(a BotArgs, min, max int) bool {
 	if len(a) == 0 || len(a[0]) == 0 {
-		log.Fatal("Empty argument list passed to commands.HasNumArgs")
+		shutdown.Fatal("Empty argument list passed to commands.HasNumArgs")
 		return false
 	}
 
@@ -63,3 +67,169 @@ func reverseJumps(j []*ifaces.JumpInfo) []*ifaces.JumpInfo {
 func newArgument(a, b string) CommandArgument {
 	return CommandArgument{a, b}
 }
+
+// expandAliasTemplate - Expand an alias template into a full set of arguments
+//  @t string     Template that the alias was configured with
+//  @a BotArgs    Arguments that the alias was invoked with (including the alias)
+//
+//  Placeholders in the form of $1, $2, ... are replaced with the matching
+//  argument, and $@ is replaced with all of the arguments that remain. If the
+//  template has no placeholders, any extra arguments are appended to the end.
+func expandAliasTemplate(t string, a BotArgs) (BotArgs, error) {
+	var (
+		out  = make(BotArgs, 0)
+		used = 0
+		held = false
+	)
+
+	for _, tok := range strings.Fields(t) {
+		switch m := reAliasPlaceholder.FindStringSubmatch(tok); {
+		case tok == "$@":
+			held = true
+			if used+1 < len(a) {
+				out = append(out, a[used+1:]...)
+			}
+			used = len(a) - 1
+
+		case m != nil:
+			held = true
+			n, _ := strconv.Atoi(m[1])
+			if n < 1 || n >= len(a) {
+				return nil, errors.New(sprintf(
+					"`%s` requires at least %d argument(s)", a[0], n))
+			}
+			out = append(out, a[n])
+			if n > used {
+				used = n
+			}
+
+		default:
+			out = append(out, tok)
+		}
+	}
+
+	if !held && len(a) > 1 {
+		out = append(out, a[1:]...)
+	}
+
+	if len(out) == 0 {
+		return nil, errors.New(sprintf("`%s` has an empty alias template", a[0]))
+	}
+
+	return out, nil
+}
+
+// nextZoneTransition - Find the next time that the UTC offset of a location
+// changes (such as the start or end of daylight saving time)
+//  @loc *time.Location    Location to check
+//  @from time.Time        Time to begin searching from
+//
+//  Returns false if the offset does not change within the next year.
+func nextZoneTransition(loc *time.Location, from time.Time) (time.Time, bool) {
+	var (
+		start    = from.In(loc)
+		_, first = start.Zone()
+	)
+
+	// Step forward a day at a time until the offset changes, then narrow the
+	// transition down to the minute
+	for day := 1; day <= 366; day++ {
+		next := start.AddDate(0, 0, day)
+		if _, off := next.Zone(); off != first {
+			lo, hi := next.AddDate(0, 0, -1), next
+			for hi.Sub(lo) > time.Minute {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, off := mid.Zone(); off == first {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			return hi.Truncate(time.Minute).In(loc), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// authLevel - Return the highest authorization level granted to a guild member
+//  @s *discordgo.Session    Discordgo Session
+//  @gid string              ID of the guild the member belongs to
+//  @uid string              ID of the Discord user
+//  @c IConfigurator         Bot configuration pointer
+func authLevel(s *discordgo.Session, gid, uid string, c ifaces.IConfigurator) int {
+	var (
+		authlvl = 0
+		member  *discordgo.Member
+		err     = discordgo.ErrStateNotFound
+	)
+
+	// Prefer the cached member, since this can be called for every row of a
+	// command's output
+	if s.State != nil {
+		member, err = s.State.Member(gid, uid)
+	}
+
+	if err != nil {
+		if member, err = s.GuildMember(gid, uid); err != nil {
+			return authlvl
+		}
+	}
+
+	for _, r := range member.Roles {
+		if l := c.GetRoleAuth(r); l > authlvl {
+			authlvl = l
+		}
+	}
+
+	return authlvl
+}
+
+// canViewPlayer - Determine if the user may view the tracking data for a player.
+// Staff (any user with an authorization level) can always view tracking data,
+// and players can always view their own.
+func canViewPlayer(s *discordgo.Session, gid, uid string, c ifaces.IConfigurator,
+	p ifaces.IPlayer) bool {
+	if !p.Private() || p.DiscordUID() == uid {
+		return true
+	}
+
+	return authLevel(s, gid, uid, c) > 0
+}
+
+// parseTimeOfDay - Parse a time of day into the next time that it occurs
+//  @a []string             Arguments to parse, such as "22:30" or "03:00 tomorrow"
+//  @loc *time.Location     Location the time is given in
+//  @now time.Time          Time to begin searching from
+//
+//  A time that has already passed today refers to tomorrow.
+func parseTimeOfDay(a []string, loc *time.Location, now time.Time) (time.Time, error) {
+	if len(a) == 0 || len(a) > 2 {
+		return time.Time{}, errors.New("expected a time such as `22:30` or " +
+			"`03:00 tomorrow`")
+	}
+
+	clock, err := time.Parse("15:04", a[0])
+	if err != nil {
+		return time.Time{}, errors.New(sprintf("`%s` is not a valid time, use "+
+			"a 24-hour time such as `22:30`", a[0]))
+	}
+
+	now = now.In(loc)
+	at := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(),
+		clock.Minute(), 0, 0, loc)
+
+	switch {
+	case len(a) == 2 && a[1] == "tomorrow":
+		at = time.Date(now.Year(), now.Month(), now.Day()+1, clock.Hour(),
+			clock.Minute(), 0, 0, loc)
+	case len(a) == 2:
+		return time.Time{}, errors.New(sprintf("`%s` is not a valid day, only "+
+			"`tomorrow` is supported", a[1]))
+	case !at.After(now):
+		at = time.Date(now.Year(), now.Month(), now.Day()+1, clock.Hour(),
+			clock.Minute(), 0, 0, loc)
+	}
+
+	return at, nil
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func gameConfigHistorySubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out   = newCommandOutput(cmd, "server.ini History")
+		srv   = cmd.Registrar().server
+		limit = 10
+	)
+
+	if !HasNumArgs(a[1:], 0, 1) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	if len(a) > 2 {
+		n, err := strconv.Atoi(a[2])
+		if err != nil || n < 1 {
+			return nil, &ErrInvalidArgument{
+				message: sprintf("Invalid number of versions: `%s`", a[2]),
+				cmd:     cmd}
+		}
+		limit = n
+	}
+
+	versions, err := srv.GameConfigHistory(limit)
+	if err != nil {
+		logger.LogError(cmd, "GameConfigHistory: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to get the server.ini history",
+			cmd:     cmd}
+	}
+
+	if len(versions) == 0 {
+		out.AddLine("No versions of server.ini have been stored yet. A version " +
+			"is stored whenever the bot changes it.")
+		out.Construct()
+		return out, nil
+	}
+
+	for _, v := range versions {
+		out.AddLine(sprintf("**v%d** <t:%d:R> by _%s_: %s", v.Version,
+			v.Time.Unix(), v.Author, v.Reason))
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+func gameConfigRevertSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Revert server.ini")
+		srv = cmd.Registrar().server
+	)
+
+	if !HasNumArgs(a[1:], 1, 2) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	version, err := strconv.ParseInt(a[2], 10, 64)
+	if err != nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("Invalid version: `%s`", a[2]),
+			cmd:     cmd}
+	}
+
+	if len(a) < 4 || a[3] != "confirm" {
+		target, err := srv.GameConfigVersion(version)
+		if err != nil {
+			return nil, &ErrInvalidArgument{
+				message: sprintf("There is no stored version `%d`", version),
+				cmd:     cmd}
+		}
+
+		changes, err := c.GameConfigChanges([]byte(target.Content))
+		if err != nil {
+			logger.LogError(cmd, "GameConfigChanges: "+err.Error())
+			return nil, &ErrCommandError{
+				message: "Failed to compare the stored version: " + err.Error(),
+				cmd:     cmd}
+		}
+
+		if len(changes) == 0 {
+			out.AddLine(sprintf("server.ini already matches version %d", version))
+			out.Construct()
+			return out, nil
+		}
+
+		out.Header = sprintf("Reverting to version %d will change:", version)
+		for _, ch := range changes {
+			old, new := ch.Old, ch.New
+			if old == "" {
+				old = "(unset)"
+			}
+			if new == "" {
+				new = "(unset)"
+			}
+			out.AddLine(sprintf("**%s:** `%s` → `%s`", ch.Setting, old, new))
+		}
+		out.AddLine(sprintf("Run `gameconfig revert %d confirm` to revert", version))
+		out.Construct()
+		return out, nil
+	}
+
+	stored, err := srv.RevertGameConfig(version, m.Author.String())
+	if err != nil {
+		logger.LogError(cmd, "RevertGameConfig: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Error reverting server.ini: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s reverted server.ini to version %d",
+		m.Author.String(), version))
+	out.AddLine(sprintf("Reverted server.ini to version %d, and stored the "+
+		"result as version %d. The changes take effect the next time the server "+
+		"starts", version, stored))
+	out.Construct()
+	return out, nil
+}
@@ -2,6 +2,9 @@ package commands
 
 import (
 	"avorioncontrol/ifaces"
+	"avorioncontrol/locale"
+	"avorioncontrol/logger"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -34,3 +37,135 @@ func getAlliancesCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArg
 	out.Construct()
 	return out, nil
 }
+
+func getAllianceCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		reg = cmd.Registrar()
+		out = newCommandOutput(cmd, "Alliance")
+		loc = c.Location()
+		lc  = c.Locale()
+	)
+
+	if !HasNumArgs(a, 1, -1) {
+		return nil, &ErrInvalidArgument{
+			message: "Please provide an alliance index or name",
+			cmd:     cmd}
+	}
+
+	if reg.server == nil || !reg.server.IsUp() {
+		return nil, &ErrCommandError{
+			message: "Server has not finished initializing",
+			cmd:     cmd}
+	}
+
+	ref := strings.Join(a[1:], " ")
+	alliance := reg.server.Alliance(ref)
+	if alliance == nil {
+		alliance = reg.server.AllianceFromName(ref)
+	}
+
+	// A trailing "contributions" shows what the members have donated instead
+	if alliance == nil && len(a) > 2 && a[len(a)-1] == "contributions" {
+		ref = strings.Join(a[1:len(a)-1], " ")
+		if alliance = reg.server.Alliance(ref); alliance == nil {
+			alliance = reg.server.AllianceFromName(ref)
+		}
+
+		if alliance != nil {
+			return allianceContributions(alliance, lc, cmd)
+		}
+	}
+
+	if alliance == nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("%s is an invalid reference to an alliance", ref),
+			cmd:     cmd}
+	}
+
+	// describe formats a player index with the players name, and their Discord
+	// user if they have integrated so that staff know who to contact
+	describe := func(index string) string {
+		if index == "" {
+			return "Unknown"
+		}
+
+		p := reg.server.Player(index)
+		if p == nil {
+			return sprintf("`#%s`", index)
+		}
+
+		if uid := p.DiscordUID(); uid != "" {
+			return sprintf("`%s` _(index %s)_ <@%s>", p.Name(), index, uid)
+		}
+		return sprintf("`%s` _(index %s)_", p.Name(), index)
+	}
+
+	lead := alliance.Leadership()
+	out.Header = sprintf("%s _(index %s)_", alliance.Name(), alliance.Index())
+
+	if lead.Updated.IsZero() {
+		out.AddLine("Leadership has not been recorded for this alliance yet")
+		out.Construct()
+		return out, nil
+	}
+
+	out.AddLine("**Founder:** " + describe(lead.Founder))
+	out.AddLine("**Leader:** " + describe(lead.Leader))
+	out.AddLine(sprintf("**Members:** _%s_", lc.Number(int64(len(lead.Members)))))
+	for _, index := range lead.Members {
+		if rank, ok := lead.Ranks[index]; ok {
+			out.AddLine(sprintf("> %s rank %d", describe(index), rank))
+		} else {
+			out.AddLine("> " + describe(index))
+		}
+	}
+	out.AddLine(sprintf("_Last updated %s_", lc.DateTimeZone(lead.Updated, loc)))
+
+	out.Construct()
+	return out, nil
+}
+
+// allianceContributions lists what each member of an alliance has donated,
+// grouped by resource
+func allianceContributions(alliance ifaces.IAlliance, lc *locale.Locale,
+	cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		reg = cmd.Registrar()
+		out = newCommandOutput(cmd, "Alliance Contributions")
+	)
+
+	stats, err := reg.server.AllianceDonations(alliance.Index())
+	if err != nil {
+		logger.LogError(cmd, "AllianceDonations: "+err.Error())
+		return nil, &ErrCommandError{
+			message: ifaces.ErrorMessage(err),
+			cmd:     cmd}
+	}
+
+	out.Header = sprintf("%s _(index %s)_", alliance.Name(), alliance.Index())
+	if len(stats) == 0 {
+		out.AddLine("No donations have been recorded for this alliance")
+		out.Construct()
+		return out, nil
+	}
+
+	resource := ""
+	for _, st := range stats {
+		if st.Resource != resource {
+			resource = st.Resource
+			out.AddLine(sprintf("**%s:**", strings.Title(resource)))
+		}
+
+		name := sprintf("`#%s`", st.Player)
+		if p := reg.server.Player(st.Player); p != nil {
+			name = sprintf("`%s`", p.Name())
+		}
+
+		out.AddLine(sprintf("> %s: _%s_ (%s donations)", name,
+			lc.Number(st.Amount), lc.Number(st.Count)))
+	}
+
+	out.Construct()
+	return out, nil
+}
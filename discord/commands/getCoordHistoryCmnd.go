@@ -5,7 +5,6 @@ import (
 	"avorioncontrol/logger"
 	"regexp"
 	"strconv"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -13,9 +12,10 @@ import (
 func getCoordHistoryCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
 	var (
-		out   = newCommandOutput(cmd, "Coordinate History")
-		reg   = cmd.Registrar()
-		match []string
+		out    = newCommandOutput(cmd, "Coordinate History")
+		reg    = cmd.Registrar()
+		match  []string
+		hidden = 0
 	)
 
 	// Require at least one set of coords
@@ -79,24 +79,29 @@ func getCoordHistoryCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a Bot
 		return out, nil
 	}
 
-	loc, _ := time.LoadLocation(c.TimeZone())
+	loc := c.Location()
 
 	for _, j := range jumps {
 		var obj ifaces.IHaveShips
 		fid := strconv.FormatInt(int64(j.FID), 10)
-		tl := j.Time.In(loc)
-		t := sprintf("%d/%02d/%02d %02d:%02d", tl.Year(), tl.Month(), tl.Day(),
-			tl.Hour(), tl.Minute())
+		t := c.Locale().DateTime(j.Time, loc)
 
 		switch j.Kind {
 		case "player":
-			if obj = reg.server.Player(fid); obj == nil {
+			p := reg.server.Player(fid)
+			if p == nil {
 				logger.LogError(cmd, "(player) Got an invalid ifaces.IHaveShips object")
 				return nil, &ErrCommandError{
 					message: "Error, bad data type encountered. Please review the logs.",
 					cmd:     cmd}
 			}
 
+			if !canViewPlayer(s, reg.GuildID, m.Author.ID, c, p) {
+				hidden++
+				continue
+			}
+			obj = p
+
 		case "alliance":
 			if obj = reg.server.Alliance(fid); obj == nil {
 				logger.LogError(cmd, "(alliance) Got an invalid ifaces.IHaveShips object")
@@ -116,6 +121,10 @@ func getCoordHistoryCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a Bot
 			t, j.X, j.Y, obj.Name(), j.Kind, j.Name))
 	}
 
+	if hidden > 0 {
+		out.AddLine(sprintf("_%d jump(s) hidden by player privacy settings_", hidden))
+	}
+
 	out.Header = "Results"
 	out.Quoted = true
 	out.Construct()
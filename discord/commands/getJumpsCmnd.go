@@ -2,6 +2,7 @@ package commands
 
 import (
 	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +20,10 @@ func getJumpsCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 		cnt int
 	)
 
+	if args, format := jumpExportFlag(a); format != "" {
+		return exportJumpsCmnd(s, m, args, c, cmd, format)
+	}
+
 	// Make sure we have the args we need
 	if !HasNumArgs(a, 2, -1) {
 		return nil, &ErrInvalidArgument{
@@ -36,31 +41,21 @@ func getJumpsCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 			cmd:     cmd}
 	}
 
-	if p := reg.server.PlayerFromName(ref); p != nil {
-		obj = p
-	} else if p := reg.server.PlayerFromDiscord(ref); p != nil {
-		obj = p
-	} else if p := reg.server.Player(ref); p != nil {
-		obj = p
-	} else if a := reg.server.Alliance(ref); a != nil {
-		obj = a
-	} else if a := reg.server.AllianceFromName(ref); a != nil {
-		obj = a
-	}
-
-	if obj == nil {
+	if obj = jumpOwner(reg, ref); obj == nil {
 		return nil, &ErrInvalidArgument{
 			message: sprintf("`%s` is not a valid player or alliance reference", ref),
 			cmd:     cmd}
 	}
 
-	loc, err := time.LoadLocation(c.TimeZone())
-	if err != nil {
-		return nil, &ErrInvalidTimezone{
-			tz:  c.TimeZone(),
-			cmd: cmd}
+	if p, ok := obj.(ifaces.IPlayer); ok &&
+		!canViewPlayer(s, reg.GuildID, m.Author.ID, c, p) {
+		return nil, &ErrCommandError{
+			message: sprintf("**%s** has opted out of public jump tracking", p.Name()),
+			cmd:     cmd}
 	}
 
+	loc := c.Location()
+
 	out.Header = "Jumps for " + obj.Name()
 	if cnt > 250 {
 		cnt = 250
@@ -68,10 +63,8 @@ func getJumpsCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 
 	if jumps := obj.GetLastJumps(cnt); len(jumps) > 0 {
 		for _, j := range jumps {
-			t := j.Time.In(loc)
-			out.AddLine(sprintf("**%d/%02d/%02d %02d:%02d | %d:%d** %s",
-				t.Year(), t.Month(), t.Day(),
-				t.Hour(), t.Minute(), j.X, j.Y, j.Name))
+			out.AddLine(sprintf("**%s | %d:%d** %s", c.Locale().DateTime(j.Time, loc),
+				j.X, j.Y, j.Name))
 		}
 	} else {
 		out.AddLine(sprintf("Player **%s** has no recorded jump history", ref))
@@ -80,3 +73,119 @@ func getJumpsCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 	out.Construct()
 	return out, nil
 }
+
+// exportJumpsCmnd sends the full jump history of a player or alliance as a
+// CSV or JSON attachment
+func exportJumpsCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant,
+	format string) (*CommandOutput, ICommandError) {
+	var (
+		reg = cmd.Registrar()
+		out = newCommandOutput(cmd, "Export Jump History")
+		obj ifaces.IHaveShips
+		ref string
+	)
+
+	if !HasNumArgs(a, 1, -1) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", a[0]),
+			cmd:     cmd}
+	}
+
+	// The number of jumps doesn't apply to exports, so allow it to be left in
+	if _, err := strconv.Atoi(a[1]); err == nil && len(a) > 2 {
+		a = append(BotArgs{a[0]}, a[2:]...)
+	}
+
+	ref = strings.Join(a[1:], " ")
+	if obj = jumpOwner(reg, ref); obj == nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` is not a valid player or alliance reference", ref),
+			cmd:     cmd}
+	}
+
+	index := obj.Name()
+	if p, ok := obj.(ifaces.IPlayer); ok {
+		if !canViewPlayer(s, reg.GuildID, m.Author.ID, c, p) {
+			return nil, &ErrCommandError{
+				message: sprintf("**%s** has opted out of public jump tracking",
+					p.Name()),
+				cmd: cmd}
+		}
+		index = p.Index()
+	} else if al, ok := obj.(ifaces.IAlliance); ok {
+		index = al.Index()
+	}
+
+	cols, rows, err := reg.server.ExportData("jumps", index)
+	if err != nil {
+		logger.LogError(cmd, "ExportData: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to export jump history:\n```" +
+				ifaces.ErrorMessage(err) + "```",
+			cmd: cmd}
+	}
+
+	if len(rows) == 0 {
+		out.AddLine(sprintf("**%s** has no recorded jump history", obj.Name()))
+		out.Construct()
+		return out, nil
+	}
+
+	name := sprintf("jumps-%s-%s.%s", index, time.Now().Format("20060102-150405"),
+		format)
+	logger.LogInfo(cmd, sprintf("%s exported %d jumps for %s", m.Author.String(),
+		len(rows), obj.Name()))
+
+	dest, cmderr := sendExport(s, m, c, cmd, name,
+		encodeExport(format, cols, rows))
+	if cmderr != nil {
+		return nil, cmderr
+	}
+
+	out.AddLine(sprintf("Exported %d jumps for **%s** %s", len(rows), obj.Name(),
+		dest))
+	out.Construct()
+	return out, nil
+}
+
+// jumpExportFlag removes the --export flag and its optional format (csv or
+// json) from the arguments, and returns the requested format. The format is
+// empty if the flag wasn't given.
+func jumpExportFlag(a BotArgs) (BotArgs, string) {
+	for i, arg := range a {
+		if arg != "--export" {
+			continue
+		}
+
+		format := "csv"
+		rest := a[i+1:]
+		if len(rest) > 0 {
+			if f := strings.ToLower(rest[0]); f == "csv" || f == "json" {
+				format = f
+				rest = rest[1:]
+			}
+		}
+
+		return append(append(BotArgs{}, a[:i]...), rest...), format
+	}
+
+	return a, ""
+}
+
+// jumpOwner resolves a reference to a player or alliance by name, index, or
+// integrated Discord user
+func jumpOwner(reg *CommandRegistrar, ref string) ifaces.IHaveShips {
+	if p := reg.server.PlayerFromName(ref); p != nil {
+		return p
+	} else if p := reg.server.PlayerFromDiscord(ref); p != nil {
+		return p
+	} else if p := reg.server.Player(ref); p != nil {
+		return p
+	} else if a := reg.server.Alliance(ref); a != nil {
+		return a
+	} else if a := reg.server.AllianceFromName(ref); a != nil {
+		return a
+	}
+	return nil
+}
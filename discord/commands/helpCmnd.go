@@ -38,8 +38,8 @@ func helpCmd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 		}
 	}
 
-	authreq := c.GetCmndAuth(maincmd.Name())
-	if c.CommandDisabled(maincmd.Name()) || authlvl < authreq {
+	if c.CommandDisabled(maincmd.Name()) ||
+		!c.CommandAllowed(m.Author.ID, authlvl, maincmd.Name()) {
 		return nil, &ErrCommandDisabled{cmd: maincmd}
 	}
 
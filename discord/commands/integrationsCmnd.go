@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func integrationsOrphansSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Orphaned Integrations")
+		srv = cmd.Registrar().server
+	)
+
+	orphans := srv.OrphanedIntegrations()
+	if len(orphans) == 0 {
+		out.AddLine("Every integrated player is linked to a current member")
+		out.Construct()
+		return out, nil
+	}
+
+	out.Header = sprintf("%d players are linked to Discord users that are no "+
+		"longer members", len(orphans))
+	for _, o := range orphans {
+		out.AddLine(sprintf("**%s** (`%s`) - <@%s> (`%s`), gone since <t:%d:R>",
+			o.Name, o.Index, o.DiscordID, o.DiscordID, o.Since.Unix()))
+	}
+	out.AddLine("Run `integrations prune confirm` to unlink them")
+
+	out.Construct()
+	return out, nil
+}
+
+func integrationsPruneSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Prune Integrations")
+		srv = cmd.Registrar().server
+	)
+
+	orphans := srv.OrphanedIntegrations()
+	if len(orphans) == 0 {
+		out.AddLine("There are no orphaned integrations to prune")
+		out.Construct()
+		return out, nil
+	}
+
+	if len(a) < 3 || a[2] != "confirm" {
+		out.AddLine(sprintf("This unlinks the %d players listed by "+
+			"`integrations orphans` from their Discord accounts, in the tracking "+
+			"database and in-game.", len(orphans)))
+		out.AddLine("Run `integrations prune confirm` to unlink them")
+		out.Construct()
+		return out, nil
+	}
+
+	pruned, err := srv.PruneIntegrations()
+	logger.LogInfo(cmd, sprintf("%s pruned %d orphaned integrations",
+		m.Author.String(), pruned))
+
+	if err != nil {
+		logger.LogError(cmd, "PruneIntegrations: "+err.Error())
+		return nil, &ErrCommandError{
+			message: sprintf("Unlinked %d players, but %s", pruned, err.Error()),
+			cmd:     cmd}
+	}
+
+	out.AddLine(sprintf("Unlinked %d players from Discord accounts that are no "+
+		"longer members", pruned))
+	out.Construct()
+	return out, nil
+}
@@ -6,23 +6,35 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
+// unavailableReason returns why a user can't run a command, or an empty string
+// if they can
+func unavailableReason(c ifaces.IConfigurator, uid string, authlvl int,
+	name string) string {
+	switch {
+	case c.CommandDisabled(name):
+		return "disabled on this server"
+	case c.CommandAllowed(uid, authlvl, name):
+		return ""
+	}
+
+	if allowed, ok := c.UserCmndAuth()[uid][name]; ok && !allowed {
+		return "revoked for you"
+	}
+
+	return sprintf("requires authorization level %d (you have %d)",
+		c.GetCmndAuth(name), authlvl)
+}
+
 func listCmd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
 	var (
-		out     = newCommandOutput(cmd, "Command Listing")
-		reg     = cmd.Registrar()
-		authlvl = 0
-		cnt     = 0
+		out         = newCommandOutput(cmd, "Command Listing")
+		reg         = cmd.Registrar()
+		authlvl     = authLevel(s, reg.GuildID, m.Author.ID, c)
+		unavailable = make([]string, 0)
+		cnt         = 0
 	)
 
-	// Get the users authorization level
-	member, _ := s.GuildMember(reg.GuildID, m.Author.ID)
-	for _, r := range member.Roles {
-		if l := c.GetRoleAuth(r); l > authlvl {
-			authlvl = l
-		}
-	}
-
 	// Setting the description manually here, as helpCmd references this function
 	// directly when there are no commands provided.
 	out.Description = "List available commands for this user"
@@ -33,8 +45,10 @@ func listCmd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 
 	for _, n := range cmnds {
 		cmd, _ := reg.Command(n)
-		authreq := c.GetCmndAuth(cmd.Name())
-		if c.CommandDisabled(cmd.Name()) || authlvl < authreq {
+		if reason := unavailableReason(c, m.Author.ID, authlvl,
+			cmd.Name()); reason != "" {
+			unavailable = append(unavailable, sprintf("~~%s~~ - _%s_", cmd.Name(),
+				reason))
 			continue
 		}
 		out.AddLine(sprintf("**_%s_** - _%s_", cmd.Name(), cmd.description))
@@ -43,8 +57,14 @@ func listCmd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 
 	if cnt < 1 {
 		out.AddLine("No commands available")
-		out.Construct()
-		return out, nil
+	}
+
+	if len(unavailable) > 0 {
+		out.AddLine("")
+		out.AddLine(sprintf("**Unavailable to you (%d)**", len(unavailable)))
+		for _, line := range unavailable {
+			out.AddLine(line)
+		}
 	}
 
 	out.Construct()
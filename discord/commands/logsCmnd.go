@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// logSearchLimit is the most matching lines that a log search returns
+const logSearchLimit = 5000
+
+// Bot log lines start with the timestamp written by the log package
+const botLogTimeLayout = "2006/01/02 15:04:05"
+
+// logSearchFiles returns the log files to search, newest first. Files that
+// were last written before since are skipped, since none of their lines can
+// be recent enough.
+func logSearchFiles(c ifaces.IConfigurator, game bool,
+	since time.Time) []string {
+	patterns := make([]string, 0)
+	if f := c.LogFile(); f != "" {
+		// Rotated copies are named after the log file, such as bot.log.1 or
+		// bot.log.2.gz
+		patterns = append(patterns, f, f+".*")
+	}
+
+	if game {
+		dir := strings.TrimSuffix(c.DataPath(), "/") + "/" + c.Galaxy()
+		patterns = append(patterns, dir+"/serverlog*", dir+"/logs/serverlog*")
+	}
+
+	type logFile struct {
+		path    string
+		modtime time.Time
+	}
+
+	files := make([]logFile, 0)
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			fi, err := os.Stat(path)
+			if err != nil || fi.IsDir() || fi.ModTime().Before(since) {
+				continue
+			}
+			files = append(files, logFile{path, fi.ModTime()})
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modtime.After(files[j].modtime)
+	})
+
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// searchLogFile returns the newest lines of a log file that match a pattern,
+// up to limit, and whether any older matches were left out. Lines of the bot
+// log that are older than since are skipped; other lines are only filtered by
+// file.
+func searchLogFile(path string, re *regexp.Regexp, since time.Time,
+	limit int) ([]string, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, false, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var (
+		lines     = make([]string, 0)
+		truncated = false
+		name      = filepath.Base(path)
+		scanner   = bufio.NewScanner(r)
+	)
+
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !re.MatchString(line) {
+			continue
+		}
+
+		if len(line) >= len(botLogTimeLayout) {
+			t, err := time.ParseInLocation(botLogTimeLayout,
+				line[:len(botLogTimeLayout)], time.Local)
+			if err == nil && t.Before(since) {
+				continue
+			}
+		}
+
+		// The file is read from the start, so the oldest match is dropped to
+		// make room for a newer one
+		if len(lines) >= limit {
+			lines = lines[1:]
+			truncated = true
+		}
+		lines = append(lines, name+": "+line)
+	}
+
+	return lines, truncated, scanner.Err()
+}
+
+func logsSearchSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	// The logs include RCON output and Discord IDs, so they can't be searched
+	// until the command has been restricted
+	if c.GetCmndAuth("logs") == 0 && !c.UserCmndAuth()[m.Author.ID]["logs"] {
+		logger.LogWarning(cmd, sprintf("%s tried to search the logs, but no "+
+			"authorization level is configured for the logs command",
+			m.Author.String()))
+		return nil, &ErrUnauthorizedUsage{cmd: cmd}
+	}
+
+	var (
+		out   = newCommandOutput(cmd, "Log Search")
+		since = time.Time{}
+		game  = false
+		terms = make([]string, 0)
+	)
+
+	for i := 2; i < len(a); i++ {
+		switch a[i] {
+		case "--game":
+			game = true
+
+		case "--since":
+			if i+1 >= len(a) {
+				return nil, &ErrInvalidArgument{
+					message: "`--since` needs a duration (e.g. 30m, 12h, 7d)",
+					cmd:     cmd}
+			}
+
+			t, ok := parseSince(a[i+1])
+			if !ok {
+				return nil, &ErrInvalidArgument{
+					message: sprintf("`%s` is not a valid duration (e.g. 12h, 7d)",
+						a[i+1]),
+					cmd: cmd}
+			}
+			since = t
+			i++
+
+		default:
+			terms = append(terms, a[i])
+		}
+	}
+
+	if len(terms) == 0 {
+		return nil, &ErrInvalidArgument{
+			message: "Please provide a pattern to search for",
+			cmd:     cmd}
+	}
+
+	pattern := strings.Join(terms, " ")
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` is not a valid pattern: %s", pattern,
+				err.Error()),
+			cmd: cmd}
+	}
+
+	files := logSearchFiles(c, game, since)
+	if len(files) == 0 {
+		out.AddLine("There are no log files to search")
+		if c.LogFile() == "" {
+			out.AddLine("_The bot only logs to the console, since no log file " +
+				"is configured_")
+		}
+		out.Construct()
+		return out, nil
+	}
+
+	logger.LogInfo(cmd, sprintf("%s searched %d log files for %q",
+		m.Author.String(), len(files), pattern))
+
+	var (
+		buf       bytes.Buffer
+		found     int
+		truncated bool
+		matches   = make([][]string, 0)
+	)
+
+	// The newest files are searched first, so that the matches that are left
+	// out once the limit is reached are the oldest ones
+	for _, path := range files {
+		if found >= logSearchLimit {
+			truncated = true
+			break
+		}
+
+		lines, more, err := searchLogFile(path, re, since, logSearchLimit-found)
+		if err != nil {
+			logger.LogWarning(cmd, sprintf("Failed to search %s: %s", path,
+				err.Error()))
+			out.AddLine(sprintf("Failed to search `%s`", filepath.Base(path)))
+		}
+
+		matches = append(matches, lines)
+		found += len(lines)
+		truncated = truncated || more
+	}
+
+	// Matches are written oldest first, so that they read in order
+	for i := len(matches) - 1; i >= 0; i-- {
+		for _, line := range matches[i] {
+			buf.WriteString(line + "\n")
+		}
+	}
+
+	out.Header = sprintf("Results for `%s`", pattern)
+	if found == 0 {
+		out.AddLine(sprintf("No matching lines in %d log files", len(files)))
+		out.Construct()
+		return out, nil
+	}
+
+	name := sprintf("logs-%s.txt", time.Now().Format("20060102-150405"))
+	dest, cmderr := sendExport(s, m, c, cmd, name, &buf)
+	if cmderr != nil {
+		return nil, cmderr
+	}
+
+	out.AddLine(sprintf("Found %d matching lines in %d log files %s", found,
+		len(files), dest))
+	if truncated {
+		out.Status = ifaces.CommandWarning
+		out.AddLine(sprintf("_Only the newest %d matches are included_",
+			logSearchLimit))
+	}
+
+	out.Construct()
+	return out, nil
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// parseMaintenanceDuration parses a duration such as 90m or 2h, or a plain
+// number of minutes
+func parseMaintenanceDuration(s string) (time.Duration, bool) {
+	if mins, err := strconv.Atoi(s); err == nil {
+		return time.Duration(mins) * time.Minute, mins > 0
+	}
+
+	d, err := time.ParseDuration(s)
+	return d, err == nil && d > 0
+}
+
+// maintenanceCmnd turns whitelist-only maintenance mode on or off, or reports
+// whether it is on
+func maintenanceCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Maintenance Mode")
+		srv = cmd.Registrar().server
+	)
+
+	if len(a) > 3 {
+		return nil, &ErrInvalidArgument{
+			message: "Too many arguments",
+			cmd:     cmd}
+	}
+
+	if len(a) == 1 {
+		until, on := srv.Maintenance()
+		switch {
+		case !on:
+			out.AddLine("Maintenance mode is off")
+		case until.IsZero():
+			out.AddLine("Maintenance mode is on until it is turned off")
+		default:
+			out.AddLine(sprintf("Maintenance mode is on until <t:%d:t> (<t:%d:R>)",
+				until.Unix(), until.Unix()))
+		}
+		out.Construct()
+		return out, nil
+	}
+
+	switch a[1] {
+	case "on":
+		var d time.Duration
+		if len(a) == 3 {
+			var ok bool
+			if d, ok = parseMaintenanceDuration(a[2]); !ok {
+				return nil, &ErrInvalidArgument{
+					message: sprintf("`%s` is not a valid duration", a[2]),
+					cmd:     cmd}
+			}
+		}
+
+		if !srv.IsUp() {
+			return nil, &ErrCommandError{
+				message: ifaces.ErrorMessage(ifaces.ErrServerOffline),
+				cmd:     cmd}
+		}
+
+		srv.StartMaintenance(d)
+		logger.LogInfo(cmd, sprintf("%s started maintenance mode (%s)",
+			m.Author.String(), d))
+
+		if d > 0 {
+			out.AddLine(sprintf("Maintenance mode is on for %s", d))
+		} else {
+			out.AddLine("Maintenance mode is on until it is turned off")
+		}
+		out.AddLine("Players that aren't whitelisted have been kicked")
+
+	case "off":
+		if len(a) == 3 {
+			return nil, &ErrInvalidArgument{
+				message: "`maintenance off` doesn't take a duration",
+				cmd:     cmd}
+		}
+
+		if !srv.EndMaintenance() {
+			return nil, &ErrCommandError{
+				message: "Maintenance mode isn't on",
+				cmd:     cmd}
+		}
+
+		logger.LogInfo(cmd, sprintf("%s ended maintenance mode", m.Author.String()))
+		out.AddLine("Maintenance mode is off")
+
+	default:
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` is not a valid option", a[1]),
+			cmd:     cmd}
+	}
+
+	out.Construct()
+	return out, nil
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func migrateInstallSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Migrate Installation")
+		srv = cmd.Registrar().server
+	)
+
+	if !HasNumArgs(a[1:], 1, 1) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	path := strings.TrimSuffix(a[2], "/")
+	oldpath := strings.TrimSuffix(c.InstallPath(), "/")
+	if path == oldpath {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("Avorion is already installed at `%s`", path),
+			cmd:     cmd}
+	}
+
+	// Validate the new installation before anything is changed
+	version, err := srv.InstallVersion(path)
+	if err != nil {
+		logger.LogError(cmd, "Migration: "+err.Error())
+		return nil, &ErrCommandError{
+			message: sprintf("`%s` is not a valid Avorion installation:\n```%s```",
+				path, err.Error()),
+			cmd: cmd}
+	}
+
+	out.AddLine(sprintf("**Old Path:** `%s`", oldpath))
+	out.AddLine(sprintf("**New Path:** `%s`", path))
+	out.AddLine(sprintf("**Old Version:** _%s_", strings.TrimSpace(srv.Version())))
+	out.AddLine(sprintf("**New Version:** _%s_", version))
+
+	if version == strings.TrimSpace(srv.Version()) {
+		out.AddLine("The new installation is running the same version of Avorion")
+	}
+
+	c.SetInstallPath(path)
+	c.SaveConfiguration()
+	logger.LogInfo(cmd, sprintf("%s migrated the Avorion installation to %s",
+		m.Author.String(), path))
+
+	if err := srv.MigrateInstall(path); err != nil {
+		logger.LogError(cmd, "Migration: "+err.Error())
+		out.Status = ifaces.CommandWarning
+		out.AddLine(sprintf("Configuration was updated, but the restart failed: `%s`",
+			err.Error()))
+	} else if srv.IsUp() {
+		out.AddLine("Restarted Avorion using the new installation")
+	} else {
+		out.AddLine("The new installation will be used the next time Avorion starts")
+	}
+
+	out.Construct()
+	return out, nil
+}
@@ -15,10 +15,11 @@ func modAddSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
 
 	var (
-		out     = newCommandOutput(cmd, "Add Server Mods")
-		failed  = make([]string, 0)
-		reason  = make([]string, 0)
-		success = make([]int64, 0)
+		out      = newCommandOutput(cmd, "Add Server Mods")
+		failed   = make([]string, 0)
+		reason   = make([]string, 0)
+		success  = make([]int64, 0)
+		warnings = make([]string, 0)
 	)
 
 	if !HasNumArgs(a[1:], 1, -1) {
@@ -37,13 +38,16 @@ func modAddSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 
 	for _, mod := range a[2:] {
 		if id, err := strconv.ParseInt(mod, 10, 64); err == nil {
-			if err := c.AddServerMod(id); err != nil {
+			if warning, err := c.AddServerMod(id); err != nil {
 				failed = append(failed, mod)
 				reason = append(reason, err.Error())
 			} else {
 				logger.LogInfo(cmd, sprintf("%s added %d to the mod configuration",
 					m.Author.String(), id))
 				success = append(success, id)
+				if warning != "" {
+					warnings = append(warnings, sprintf("%d: %s", id, warning))
+				}
 			}
 		} else {
 			failed = append(failed, mod)
@@ -58,6 +62,14 @@ func modAddSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 		}
 	}
 
+	if len(warnings) > 0 {
+		out.Status = ifaces.CommandWarning
+		out.AddLine("**Warnings**")
+		for _, w := range warnings {
+			out.AddLine("> " + w)
+		}
+	}
+
 	if len(failed) > 0 {
 		out.Status = ifaces.CommandWarning
 		out.AddLine("**Failed to Add**")
@@ -152,10 +164,11 @@ func modAllowSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs
 	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
 
 	var (
-		out     = newCommandOutput(cmd, "Allow Clientside Mods")
-		failed  = make([]string, 0)
-		reason  = make([]string, 0)
-		success = make([]int64, 0)
+		out      = newCommandOutput(cmd, "Allow Clientside Mods")
+		failed   = make([]string, 0)
+		reason   = make([]string, 0)
+		success  = make([]int64, 0)
+		warnings = make([]string, 0)
 	)
 
 	if !HasNumArgs(a[1:], 1, -1) {
@@ -174,13 +187,16 @@ func modAllowSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs
 
 	for _, mod := range a[2:] {
 		if id, err := strconv.ParseInt(mod, 10, 64); err == nil {
-			if err := c.AddClientMod(id); err != nil {
+			if warning, err := c.AddClientMod(id); err != nil {
 				failed = append(failed, mod)
 				reason = append(reason, err.Error())
 			} else {
 				logger.LogInfo(cmd, sprintf("%s added %d to the mod configuration",
 					m.Author.String(), id))
 				success = append(success, id)
+				if warning != "" {
+					warnings = append(warnings, sprintf("%d: %s", id, warning))
+				}
 			}
 		} else {
 			failed = append(failed, mod)
@@ -195,6 +211,14 @@ func modAllowSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs
 		}
 	}
 
+	if len(warnings) > 0 {
+		out.Status = ifaces.CommandWarning
+		out.AddLine("**Warnings**")
+		for _, w := range warnings {
+			out.AddLine("> " + w)
+		}
+	}
+
 	if len(failed) > 0 {
 		out.Status = ifaces.CommandWarning
 		out.AddLine("**Failed to Add**")
@@ -313,3 +337,35 @@ func listModsSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs
 	out.Construct()
 	return out, nil
 }
+
+func modDeploySubCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Deploy Companion Mod")
+		srv = cmd.Registrar().server
+	)
+
+	d, err := c.DeployCompanionMod()
+	if err != nil {
+		logger.LogError(cmd, "DeployCompanionMod: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to deploy the companion mod: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s deployed the companion mod", m.Author.String()))
+
+	out.AddLine(sprintf("Deployed the companion mod to `%s`", d.Path))
+	out.AddLine(sprintf("> %d files written, %d unchanged, %d removed",
+		d.Written, d.Unchanged, d.Removed))
+	out.AddLine("modconfig.lua now loads the deployed copy instead of the " +
+		"Workshop one")
+
+	if srv.IsUp() && d.Written+d.Removed > 0 {
+		out.Status = ifaces.CommandWarning
+		out.AddLine("**Restart the server for the changes to take effect**")
+	}
+
+	out.Construct()
+	return out, nil
+}
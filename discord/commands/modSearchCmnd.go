@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	modSearchPrev = "◀️"
+	modSearchNext = "▶️"
+
+	// How long the browser waits for a reaction before it expires
+	modSearchIdle = 2 * time.Minute
+	modSearchMax  = 10 * time.Minute
+)
+
+// Reactions that add the matching search result to the server mods
+var modSearchPicks = []string{"1️⃣", "2️⃣", "3️⃣", "4️⃣", "5️⃣"}
+
+// modBrowser is a page of Workshop search results that admins can page through
+// and add mods from by reacting to it
+type modBrowser struct {
+	query string
+	page  int
+	pages int
+	items []ifaces.WorkshopItem
+	notes []string
+}
+
+// load fetches the given page of search results
+func (b *modBrowser) load(c ifaces.IConfigurator, page int) error {
+	items, total, err := c.SearchWorkshop(b.query, page)
+	if err != nil {
+		return err
+	}
+
+	b.page, b.items = page, items
+	b.pages = (total + ifaces.WorkshopPageSize - 1) / ifaces.WorkshopPageSize
+	return nil
+}
+
+// embed returns the embed for the current page of results
+func (b *modBrowser) embed() *discordgo.MessageEmbed {
+	lines := make([]string, 0, len(b.items)+len(b.notes)+1)
+	for i, it := range b.items {
+		lines = append(lines, sprintf("%s [%s](%s%d) — %d subscribers",
+			modSearchPicks[i], it.Title, modURLBase, it.ID, it.Subscriptions))
+	}
+
+	if len(b.items) == 0 {
+		lines = append(lines, "No mods matched the search")
+	}
+
+	if len(b.notes) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, b.notes...)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       "Workshop Search: " + b.query,
+		Description: strings.Join(lines, "\n"),
+		Footer: &discordgo.MessageEmbedFooter{Text: sprintf(
+			"Page %d of %d | React with a number to add that mod to the server",
+			b.page, b.pages)}}
+}
+
+// react resets the reactions on the browser message to match the current page
+func (b *modBrowser) react(s *discordgo.Session, cid, mid string) {
+	s.MessageReactionsRemoveAll(cid, mid)
+	if b.page > 1 {
+		s.MessageReactionAdd(cid, mid, modSearchPrev)
+	}
+	for i := range b.items {
+		s.MessageReactionAdd(cid, mid, modSearchPicks[i])
+	}
+	if b.page < b.pages {
+		s.MessageReactionAdd(cid, mid, modSearchNext)
+	}
+}
+
+func modSearchSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	if !HasNumArgs(a[1:], 1, -1) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	b := &modBrowser{query: strings.Join(a[2:], " ")}
+	if err := b.load(c, 1); err != nil {
+		logger.LogError(cmd, "SearchWorkshop: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to search the Workshop: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	msg, err := s.ChannelMessageSendEmbed(m.ChannelID, b.embed())
+	if err != nil {
+		logger.LogError(cmd, "discordgo: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to post the search results",
+			cmd:     cmd}
+	}
+
+	b.react(s, msg.ChannelID, msg.ID)
+	go browseWorkshop(b, s, msg, m.GuildID, c, cmd)
+	return nil, nil
+}
+
+// browseWorkshop watches the reactions on the search results, paging through
+// them and adding mods that authorized admins pick, until the browser expires
+func browseWorkshop(b *modBrowser, s *discordgo.Session, msg *discordgo.Message,
+	gid string, c ifaces.IConfigurator, cmd *CommandRegistrant) {
+	defer shutdown.Recover()
+
+	var (
+		cid, mid = msg.ChannelID, msg.ID
+		inactive = time.NewTimer(modSearchIdle)
+		deadline = time.After(modSearchMax)
+	)
+
+	defer func() {
+		embed := b.embed()
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: "(expired)"}
+		s.ChannelMessageEditEmbed(cid, mid, embed)
+		s.MessageReactionsRemoveAll(cid, mid)
+	}()
+
+	for {
+		select {
+		case <-deadline:
+			return
+		case <-inactive.C:
+			return
+		case <-time.After(time.Second):
+		}
+
+		m, err := s.ChannelMessage(cid, mid)
+		if err != nil || m == nil {
+			return
+		}
+
+		for _, r := range m.Reactions {
+			if r.Count < 2 || !r.Me {
+				continue
+			}
+
+			emoji := r.Emoji.MessageFormat()
+			switch {
+			case emoji == modSearchNext || emoji == modSearchPrev:
+				page := b.page + 1
+				if emoji == modSearchPrev {
+					page = b.page - 1
+				}
+
+				if err := b.load(c, page); err != nil {
+					logger.LogError(cmd, "SearchWorkshop: "+err.Error())
+					b.notes = append(b.notes, "Failed to load the page: "+err.Error())
+				} else {
+					b.notes = nil
+				}
+
+			default:
+				pick := -1
+				for i, p := range modSearchPicks {
+					if p == emoji && i < len(b.items) {
+						pick = i
+					}
+				}
+
+				if pick < 0 {
+					continue
+				}
+
+				b.addPick(s, cid, mid, gid, emoji, b.items[pick], c, cmd)
+			}
+
+			s.ChannelMessageEditEmbed(cid, mid, b.embed())
+			b.react(s, cid, mid)
+			inactive.Reset(modSearchIdle)
+			break
+		}
+	}
+}
+
+// addPick adds a picked mod to the server mods if one of the users that
+// picked it is allowed to manage mods
+func (b *modBrowser) addPick(s *discordgo.Session, cid, mid, gid, emoji string,
+	it ifaces.WorkshopItem, c ifaces.IConfigurator, cmd *CommandRegistrant) {
+	users, err := s.MessageReactions(cid, mid, emoji, 10, "", "")
+	if err != nil {
+		logger.LogError(cmd, "discordgo: "+err.Error())
+		return
+	}
+
+	for _, u := range users {
+		if u.Bot || !c.CommandAllowed(u.ID, authLevel(s, gid, u.ID, c), "mod") {
+			continue
+		}
+
+		warning, err := c.AddServerMod(it.ID)
+		switch {
+		case err != nil:
+			b.notes = append(b.notes, sprintf("Failed to add **%s**: %s",
+				it.Title, err.Error()))
+		default:
+			c.SaveConfiguration()
+			logger.LogInfo(cmd, sprintf("%s added %d to the mod configuration",
+				u.String(), it.ID))
+			b.notes = append(b.notes, sprintf("%s added **%s**", u.Mention(),
+				it.Title))
+			if warning != "" {
+				b.notes = append(b.notes, "> "+warning)
+			}
+		}
+		return
+	}
+}
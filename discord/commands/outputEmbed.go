@@ -3,6 +3,7 @@ package commands
 import (
 	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
 	"log"
 	"time"
 
@@ -90,6 +91,7 @@ func GenerateOutputEmbed(out *CommandOutput, page *Page) (*discordgo.MessageEmbe
 // are doP and doN respectively.
 func CreatePagedEmbed(out *CommandOutput, s *discordgo.Session,
 	m *discordgo.MessageCreate, expirech chan struct{}, exitch chan struct{}) {
+	defer shutdown.Recover()
 
 	nextReact := "▶️"
 	prevReact := "◀️"
@@ -3,7 +3,9 @@ package commands
 import (
 	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -54,45 +56,159 @@ func playerKickCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 
 func playerBanCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	if len(a) > 2 && a[2] == "list" {
+		return banListCmnd(a, c, cmd)
+	}
+
+	if !HasNumArgs(a[1:], 1, -1) {
+		return nil, &ErrInvalidArgument{
+			message: "Please provide a player index to ban",
+			cmd:     cmd}
+	}
+
+	return banPlayer(m, a[2], 0, a[3:], cmd)
+}
+
+func playerTempBanCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	if !HasNumArgs(a[1:], 2, -1) {
+		return nil, &ErrInvalidArgument{
+			message: "Please provide a player index and how long to ban them for",
+			cmd:     cmd}
+	}
+
+	d, ok := parseBanDuration(a[3])
+	if !ok {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` is not a valid duration (such as 12h or 3d)",
+				a[3]),
+			cmd: cmd}
+	}
+
+	return banPlayer(m, a[2], d, a[4:], cmd)
+}
+
+// parseBanDuration parses a duration such as 90m, 12h, or 3d
+func parseBanDuration(s string) (time.Duration, bool) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		return time.Duration(days) * 24 * time.Hour, err == nil && days > 0
+	}
+
+	d, err := time.ParseDuration(s)
+	return d, err == nil && d > 0
+}
+
+// banPlayer bans a player for the given duration, or permanently if it's zero
+func banPlayer(m *discordgo.MessageCreate, ref string, d time.Duration,
+	words []string, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
 	var (
 		reason = `Banned by an Admin`
-		reg    = cmd.Registrar()
-		srv    = reg.server
-		out    = newCommandOutput(cmd, "Kick Player")
+		srv    = cmd.Registrar().server
+		out    = newCommandOutput(cmd, "Ban Player")
+	)
 
-		obj ifaces.IPlayer
+	out.Quoted = true
+
+	if len(words) > 0 {
+		reason = strings.Join(words, " ")
+	}
+
+	p := srv.Player(ref)
+	if p == nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("%s is an invalid reference to a player", ref),
+			cmd:     cmd}
+	}
+
+	b, err := srv.BanPlayer(p, m.Author.String(), reason, d)
+	if err != nil && d > 0 {
+		logger.LogError(cmd, "BanPlayer: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to ban the player: " + ifaces.ErrorMessage(err),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("[%s] banned [%s]", m.Author.String(), p.Name()))
+	if d > 0 {
+		out.AddLine(sprintf("Banned player %s until <t:%d:f> (<t:%d:R>)", p.Name(),
+			b.Expires.Unix(), b.Expires.Unix()))
+	} else {
+		out.AddLine(sprintf("Banned player %s", p.Name()))
+	}
+
+	if err != nil {
+		out.Status = ifaces.CommandWarning
+		out.AddLine("The ban could not be recorded: " + ifaces.ErrorMessage(err))
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+func playerUnbanCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		srv = cmd.Registrar().server
+		out = newCommandOutput(cmd, "Unban Player")
 	)
 
 	out.Quoted = true
 
-	if !HasNumArgs(a, 1, -1) {
+	if !HasNumArgs(a[1:], 1, -1) {
 		return nil, &ErrInvalidArgument{
-			message: "Please provide a player index to kick",
+			message: "Please provide the index or name of a player to unban",
 			cmd:     cmd}
 	}
 
-	ref := a[2]
+	ref := strings.Join(a[2:], " ")
+	b, err := srv.UnbanPlayer(ref)
+	if err != nil {
+		logger.LogError(cmd, "UnbanPlayer: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to unban the player: " + ifaces.ErrorMessage(err),
+			cmd:     cmd}
+	}
 
-	if len(a) > 2 {
-		reason = strings.Join(a[3:], " ")
+	logger.LogInfo(cmd, sprintf("[%s] unbanned [%s]", m.Author.String(), b.Name))
+	out.AddLine(sprintf("Unbanned player %s", b.Name))
+	out.Construct()
+	return out, nil
+}
+
+// banListCmnd lists the bans that were issued through the bot
+func banListCmnd(a BotArgs, c ifaces.IConfigurator,
+	cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		srv = cmd.Registrar().server
+		out = newCommandOutput(cmd, "Bans")
+	)
+
+	bans, err := srv.Bans()
+	if err != nil {
+		logger.LogError(cmd, "Bans: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to list the bans: " + ifaces.ErrorMessage(err),
+			cmd:     cmd}
 	}
 
-	if p := srv.Player(ref); p != nil {
-		obj = p
+	if len(bans) == 0 {
+		out.AddLine("There are no recorded bans")
 	}
 
-	if obj != nil {
-		obj.Ban(reason)
-		logger.LogInfo(cmd, sprintf("[%s] banned [%s]", m.Author.String(),
-			obj.Name()))
-		out.AddLine(sprintf("Banned player %s", obj.Name()))
-		out.Construct()
-		return out, nil
+	for _, b := range bans {
+		expiry := "permanent"
+		if !b.Expires.IsZero() {
+			expiry = sprintf("expires <t:%d:R>", b.Expires.Unix())
+		}
+
+		out.AddLine(sprintf("`%s` _(index %s)_, %s", b.Name, b.Index, expiry))
+		out.AddLine(sprintf("> _%s_, by %s <t:%d:R>", b.Reason, b.Author,
+			b.Time.Unix()))
 	}
 
-	return nil, &ErrInvalidArgument{
-		message: sprintf("%s is an invalid reference to a player", ref),
-		cmd:     cmd}
+	out.Construct()
+	return out, nil
 }
 
 func showOnlinePlayersCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
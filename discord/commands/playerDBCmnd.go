@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	playerDBEditInterval = 2 * time.Second
+	playerDBListLimit    = 15
+)
+
+// playerDBProgressLine formats the progress of a player database refresh
+func playerDBProgressLine(p ifaces.PlayerDBProgress) string {
+	state := "Refreshing player database..."
+	if p.Done {
+		state = "Player database refresh complete"
+	}
+	return sprintf("%s\n**Players:** %d | **Alliances:** %d | **Processed:** %d",
+		state, p.Players, p.Alliances, p.Processed)
+}
+
+// addPlayerDBList adds a list of names to the output, truncating it if needed
+func addPlayerDBList(out *CommandOutput, title string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	out.AddLine(sprintf("**%s (%d):**", title, len(names)))
+	if len(names) > playerDBListLimit {
+		names = append(names[:playerDBListLimit:playerDBListLimit],
+			sprintf("_...and %d more_", len(names)-playerDBListLimit))
+	}
+	out.AddLine("    " + strings.Join(names, "\n    "))
+}
+
+// playerDBRefreshSubCmnd runs a player database refresh on demand, editing a
+// message with its progress as it runs
+func playerDBRefreshSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Player Database Refresh")
+		srv = cmd.Registrar().server
+
+		last time.Time
+	)
+
+	if !srv.IsUp() {
+		return nil, &ErrCommandError{
+			message: ifaces.ErrorMessage(ifaces.ErrServerOffline),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s started a player database refresh",
+		m.Author.String()))
+
+	msg, err := s.ChannelMessageSend(m.ChannelID,
+		playerDBProgressLine(ifaces.PlayerDBProgress{}))
+	if err != nil {
+		logger.LogWarning(cmd, "Failed to send progress message: "+err.Error())
+	}
+
+	result, err := srv.RefreshPlayerDatabase(func(p ifaces.PlayerDBProgress) {
+		if msg == nil || (!p.Done && time.Since(last) < playerDBEditInterval) {
+			return
+		}
+		last = time.Now()
+		s.ChannelMessageEdit(msg.ChannelID, msg.ID, playerDBProgressLine(p))
+	})
+
+	if err != nil {
+		logger.LogError(cmd, "RefreshPlayerDatabase: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to refresh the player database: " +
+				ifaces.ErrorMessage(err),
+			cmd: cmd}
+	}
+
+	out.AddLine(sprintf("**Players:** %d", result.Players))
+	out.AddLine(sprintf("**Alliances:** %d", result.Alliances))
+	addPlayerDBList(out, "New Players", result.NewPlayers)
+	addPlayerDBList(out, "New Alliances", result.NewAlliances)
+	addPlayerDBList(out, "Renamed Players", result.Renamed)
+
+	if len(result.NewPlayers)+len(result.NewAlliances)+len(result.Renamed) == 0 {
+		out.AddLine("No players or alliances changed")
+	}
+
+	out.Construct()
+	return out, nil
+}
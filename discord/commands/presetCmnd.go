@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// pendingPreset is the set of changes that a preset would make, as it was
+// previewed to the staff member that requested it
+type pendingPreset struct {
+	name    string
+	changes []ifaces.SettingChange
+}
+
+// matches reports whether or not the previewed changes are still the changes
+// that applying the preset would make
+func (p *pendingPreset) matches(name string,
+	changes []ifaces.SettingChange) bool {
+	if p == nil || p.name != name || len(p.changes) != len(changes) {
+		return false
+	}
+
+	for i := range changes {
+		if p.changes[i] != changes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func presetListSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	out := newCommandOutput(cmd, "Configuration Presets")
+
+	presets := c.Presets()
+	if len(presets) == 0 {
+		out.AddLine("No presets are configured")
+		out.Construct()
+		return out, nil
+	}
+
+	for _, name := range presets {
+		out.AddLine(sprintf("`%s`", name))
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+func presetApplySubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Apply Preset")
+		reg = cmd.Registrar()
+		srv = reg.server
+	)
+
+	if !HasNumArgs(a[1:], 1, 2) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	name := strings.ToLower(a[2])
+	changes, err := c.PresetChanges(name)
+	if err != nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` is not a configured preset", a[2]),
+			cmd:     cmd}
+	}
+
+	if len(changes) == 0 {
+		out.AddLine(sprintf("The configuration already matches the `%s` preset",
+			name))
+		out.Construct()
+		return out, nil
+	}
+
+	if len(a) < 4 || a[3] != "confirm" {
+		out.Header = sprintf("Applying `%s` will change:", name)
+		for _, ch := range changes {
+			old := ch.Old
+			if old == "" {
+				old = "(unset)"
+			}
+			out.AddLine(sprintf("**%s:** `%s` → `%s`", ch.Setting, old, ch.New))
+		}
+		out.AddLine(sprintf("Run `preset apply %s confirm` to apply", name))
+
+		reg.presetlock.Lock()
+		reg.presets[m.Author.ID] = &pendingPreset{name: name, changes: changes}
+		reg.presetlock.Unlock()
+
+		out.Construct()
+		return out, nil
+	}
+
+	reg.presetlock.Lock()
+	preview := reg.presets[m.Author.ID]
+	delete(reg.presets, m.Author.ID)
+	reg.presetlock.Unlock()
+
+	// Only apply what was shown, in case the configuration or the preset has
+	//	changed since the preview
+	if !preview.matches(name, changes) {
+		return nil, &ErrCommandError{
+			message: sprintf("The changes no longer match the preview, run "+
+				"`preset apply %s` to see them again", name),
+			cmd: cmd}
+	}
+
+	// Avorion writes server.ini when it stops, which would undo the preset
+	if srv.IsUp() {
+		return nil, &ErrCommandError{
+			message: "Stop the server before applying a preset",
+			cmd:     cmd}
+	}
+
+	// Keep the settings from before the preset, in case server.ini was edited
+	//	by hand since the last stored version
+	if _, err := srv.RecordGameConfig(m.Author.String(),
+		"before preset "+name); err != nil {
+		logger.LogWarning(cmd, "RecordGameConfig: "+err.Error())
+	}
+
+	if err := c.ApplyPreset(name); err != nil {
+		logger.LogError(cmd, "ApplyPreset: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Error applying preset: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s applied the preset %s", m.Author.String(),
+		name))
+
+	if _, err := srv.RecordGameConfig(m.Author.String(),
+		"applied preset "+name); err != nil {
+		logger.LogWarning(cmd, "RecordGameConfig: "+err.Error())
+	}
+	out.AddLine(sprintf("Applied the `%s` preset (%d changes). They take effect "+
+		"the next time the server starts", name, len(changes)))
+	out.Construct()
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func privacyCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Tracking Privacy")
+		srv = cmd.Registrar().server
+	)
+
+	if !HasNumArgs(a, 0, 1) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", a[0]),
+			cmd:     cmd}
+	}
+
+	p := srv.PlayerFromDiscord(m.Author.ID)
+	if p == nil {
+		return nil, &ErrCommandError{
+			message: "Your Discord account is not integrated with a player",
+			cmd:     cmd}
+	}
+
+	if len(a) > 1 {
+		var hidden bool
+
+		switch a[1] {
+		case "on":
+			hidden = true
+		case "off":
+			hidden = false
+		default:
+			return nil, &ErrInvalidArgument{
+				message: sprintf("Invalid privacy setting: `%s`", a[1]),
+				cmd:     cmd}
+		}
+
+		if err := p.SetPrivate(hidden); err != nil {
+			logger.LogError(cmd, "SetPrivate: "+err.Error())
+			return nil, &ErrCommandError{
+				message: "Failed to save your privacy setting",
+				cmd:     cmd}
+		}
+	}
+
+	if p.Private() {
+		out.AddLine(sprintf("Tracking data for **%s** is hidden from the public",
+			p.Name()))
+		out.AddLine("_Staff can still view your jump history_")
+	} else {
+		out.AddLine(sprintf("Tracking data for **%s** is publicly visible", p.Name()))
+	}
+
+	out.Construct()
+	return out, nil
+}
@@ -0,0 +1,243 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/locale"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	testGuild     = "guild"
+	testStranger  = "stranger"
+	testOwner     = "owner"
+	testStaff     = "staff"
+	testStaffRole = "moderators"
+)
+
+type testConfig struct {
+	ifaces.IConfigurator
+}
+
+func (testConfig) Location() *time.Location { return time.UTC }
+func (testConfig) Locale() *locale.Locale   { return locale.Get("en-US") }
+
+func (testConfig) GetRoleAuth(role string) int {
+	if role == testStaffRole {
+		return 5
+	}
+	return 0
+}
+
+type testPlayer struct {
+	ifaces.IPlayer
+	index   string
+	name    string
+	discord string
+	private bool
+	jumps   []ifaces.ShipCoordData
+}
+
+func (p *testPlayer) Index() string      { return p.index }
+func (p *testPlayer) Name() string       { return p.name }
+func (p *testPlayer) DiscordUID() string { return p.discord }
+func (p *testPlayer) Private() bool      { return p.private }
+
+func (p *testPlayer) GetLastJumps(n int) []ifaces.ShipCoordData {
+	return p.jumps
+}
+
+type testServer struct {
+	ifaces.IGameServer
+	players []*testPlayer
+	sector  *ifaces.Sector
+	ships   []ifaces.ShipRecord
+}
+
+func (s *testServer) Player(ref string) ifaces.IPlayer {
+	for _, p := range s.players {
+		if p.index == ref {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *testServer) PlayerFromName(name string) ifaces.IPlayer {
+	for _, p := range s.players {
+		if p.name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *testServer) PlayerFromDiscord(string) ifaces.IPlayer  { return nil }
+func (s *testServer) Alliance(string) ifaces.IAlliance         { return nil }
+func (s *testServer) AllianceFromName(string) ifaces.IAlliance { return nil }
+func (s *testServer) Sector(x, y int) *ifaces.Sector           { return s.sector }
+
+func (s *testServer) FindShips(string) ([]ifaces.ShipRecord, error) {
+	return s.ships, nil
+}
+
+func (s *testServer) Search(term string,
+	visible func(ifaces.IPlayer) bool) ([]ifaces.SearchResult, error) {
+	results := make([]ifaces.SearchResult, 0)
+	for _, r := range s.ships {
+		if p := s.Player(r.OwnerIndex); p != nil && !visible(p) {
+			continue
+		}
+		results = append(results, ifaces.SearchResult{Kind: ifaces.SearchShip,
+			Name: r.Name, Ref: r.OwnerIndex,
+			Detail: sprintf("%s, last seen in %d:%d", r.Owner, r.X, r.Y)})
+	}
+	return results, nil
+}
+
+// newPrivacyTest returns a server with a public and a private player, each
+// with a ship in 10:10, and a session that knows the staff member's roles
+func newPrivacyTest(t *testing.T) (*discordgo.Session, *CommandRegistrar) {
+	t.Helper()
+
+	now := time.Now()
+	public := &testPlayer{index: "1", name: "Public",
+		jumps: []ifaces.ShipCoordData{{X: 10, Y: 10, Name: "Sparrow", Time: now}}}
+	private := &testPlayer{index: "2", name: "Private", discord: testOwner,
+		private: true,
+		jumps:   []ifaces.ShipCoordData{{X: 10, Y: 10, Name: "Hidden", Time: now}}}
+
+	srv := &testServer{
+		players: []*testPlayer{public, private},
+		sector: &ifaces.Sector{X: 10, Y: 10, Jumphistory: []*ifaces.JumpInfo{
+			{Time: now, Name: "Sparrow", Kind: "player", FID: 1, X: 10, Y: 10},
+			{Time: now, Name: "Hidden", Kind: "player", FID: 2, X: 10, Y: 10}}},
+		ships: []ifaces.ShipRecord{
+			{Name: "Sparrow", Owner: "Public", OwnerIndex: "1", X: 10, Y: 10,
+				Seen: now},
+			{Name: "Hidden", Owner: "Private", OwnerIndex: "2", X: 10, Y: 10,
+				Seen: now}}}
+
+	s := &discordgo.Session{State: discordgo.NewState()}
+	if err := s.State.GuildAdd(&discordgo.Guild{ID: testGuild}); err != nil {
+		t.Fatal(err)
+	}
+
+	for uid, roles := range map[string][]string{
+		testStranger: {},
+		testOwner:    {},
+		testStaff:    {testStaffRole}} {
+		if err := s.State.MemberAdd(&discordgo.Member{GuildID: testGuild,
+			User: &discordgo.User{ID: uid}, Roles: roles}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return s, &CommandRegistrar{GuildID: testGuild, server: srv}
+}
+
+// runPrivacyTest runs a command as the given user and returns its output
+func runPrivacyTest(t *testing.T, s *discordgo.Session, reg *CommandRegistrar,
+	uid string, fn BotCommand, a ...string) string {
+	t.Helper()
+
+	cmd := &CommandRegistrant{name: a[0], registrar: reg}
+	m := &discordgo.MessageCreate{Message: &discordgo.Message{
+		GuildID: testGuild, Author: &discordgo.User{ID: uid}}}
+
+	out, err := fn(s, m, BotArgs(a), testConfig{}, cmd)
+	if err != nil {
+		return err.Error()
+	}
+	return strings.Join(out.lines, "\n")
+}
+
+func TestGetJumpsPrivacy(t *testing.T) {
+	s, reg := newPrivacyTest(t)
+
+	if out := runPrivacyTest(t, s, reg, testStranger, getJumpsCmnd,
+		"getjumps", "10", "Private"); strings.Contains(out, "10:10") {
+		t.Errorf("private jumps were shown to a stranger: %s", out)
+	}
+
+	for _, uid := range []string{testOwner, testStaff} {
+		if out := runPrivacyTest(t, s, reg, uid, getJumpsCmnd,
+			"getjumps", "10", "Private"); !strings.Contains(out, "Hidden") {
+			t.Errorf("private jumps were hidden from %s: %s", uid, out)
+		}
+	}
+
+	if out := runPrivacyTest(t, s, reg, testStranger, getJumpsCmnd,
+		"getjumps", "10", "Public"); !strings.Contains(out, "Sparrow") {
+		t.Errorf("public jumps were hidden: %s", out)
+	}
+}
+
+func TestGetCoordHistoryPrivacy(t *testing.T) {
+	s, reg := newPrivacyTest(t)
+
+	out := runPrivacyTest(t, s, reg, testStranger, getCoordHistoryCmnd,
+		"getcoordhistory", "10:10")
+	if strings.Contains(out, "Hidden") || !strings.Contains(out, "Sparrow") {
+		t.Errorf("expected only public jumps for a stranger: %s", out)
+	}
+
+	out = runPrivacyTest(t, s, reg, testStaff, getCoordHistoryCmnd,
+		"getcoordhistory", "10:10")
+	if !strings.Contains(out, "Hidden") {
+		t.Errorf("private jumps were hidden from staff: %s", out)
+	}
+}
+
+func TestFindShipPrivacy(t *testing.T) {
+	s, reg := newPrivacyTest(t)
+
+	out := runPrivacyTest(t, s, reg, testStranger, findShipCmnd, "findship", "a")
+	if strings.Contains(out, "Hidden") || !strings.Contains(out, "Sparrow") {
+		t.Errorf("expected only public ships for a stranger: %s", out)
+	}
+
+	out = runPrivacyTest(t, s, reg, testOwner, findShipCmnd, "findship", "a")
+	if !strings.Contains(out, "Hidden") {
+		t.Errorf("a private ship was hidden from its owner: %s", out)
+	}
+}
+
+func TestSearchPrivacy(t *testing.T) {
+	s, reg := newPrivacyTest(t)
+
+	out := runPrivacyTest(t, s, reg, testStranger, searchCmnd, "search", "a")
+	if strings.Contains(out, "Hidden") || !strings.Contains(out, "Sparrow") {
+		t.Errorf("expected only public ships for a stranger: %s", out)
+	}
+
+	out = runPrivacyTest(t, s, reg, testStaff, searchCmnd, "search", "a")
+	if !strings.Contains(out, "Hidden") {
+		t.Errorf("a private ship was hidden from staff: %s", out)
+	}
+}
+
+func TestExportJumpsPrivacy(t *testing.T) {
+	s, reg := newPrivacyTest(t)
+
+	var (
+		cmd  = &CommandRegistrant{name: "jumps", registrar: reg}
+		cols = []string{"time", "faction_id", "owner"}
+		rows = [][]string{{"t", "1", "Public"}, {"t", "2", "Private"},
+			{"t", "3", "Some Alliance"}}
+	)
+
+	for uid, want := range map[string]int{testStranger: 1, testOwner: 0,
+		testStaff: 0} {
+		m := &discordgo.MessageCreate{Message: &discordgo.Message{
+			GuildID: testGuild, Author: &discordgo.User{ID: uid}}}
+
+		kept, hidden := hidePrivateJumps(s, m, testConfig{}, cmd, cols, rows)
+		if hidden != want || len(kept) != len(rows)-want {
+			t.Errorf("%s: expected %d hidden jumps, got %d", uid, want, hidden)
+		}
+	}
+}
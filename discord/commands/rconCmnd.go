@@ -31,8 +31,9 @@ func rconCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 
 	if rconout, err = srv.RunCommand(rcmd); err != nil {
 		return nil, &ErrCommandError{
-			message: sprintf("Failed to run `%s`. Error:\n```%s```", rcmd, err.Error()),
-			cmd:     cmd}
+			message: sprintf("Failed to run `%s`. Error:\n```%s```", rcmd,
+				ifaces.ErrorMessage(err)),
+			cmd: cmd}
 	}
 
 	if strings.ReplaceAll(rconout, " ", "") == "" {
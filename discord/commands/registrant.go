@@ -11,7 +11,9 @@ type CommandRegistrant struct {
 
 	exec         BotCommand
 	cmdlets      []*CommandRegistrant
+	parent       *CommandRegistrant
 	args         []CommandArgument
+	examples     []string
 	usage        string
 	hasauthlevel bool
 	registrar    *CommandRegistrar
@@ -70,6 +72,48 @@ func (c *CommandRegistrant) Help() *CommandOutput {
 		}
 	}
 
+	if len(c.examples) > 0 {
+		out.AddLine("**Examples**")
+		for _, ex := range c.examples {
+			out.AddLine(sprintf("> `%s`", ex))
+		}
+	}
+
+	// Authorization is checked against the top level command, so subcommands
+	// share the permissions of their parent
+	if c.registrar.server != nil {
+		root := c
+		for root.parent != nil {
+			root = root.parent
+		}
+
+		conf := c.registrar.server.Config()
+		out.AddLine("**Permissions**")
+		switch lvl := conf.GetCmndAuth(root.Name()); {
+		case conf.CommandDisabled(root.Name()):
+			out.AddLine("> This command is disabled")
+		case lvl > 0:
+			out.AddLine(sprintf("> Requires authorization level %d", lvl))
+		default:
+			out.AddLine("> Anyone can use this command")
+		}
+
+		granted, revoked := 0, 0
+		for _, cmds := range conf.UserCmndAuth() {
+			if allowed, ok := cmds[root.Name()]; ok && allowed {
+				granted++
+			} else if ok {
+				revoked++
+			}
+		}
+		if granted > 0 {
+			out.AddLine(sprintf("> Granted directly to %d user(s)", granted))
+		}
+		if revoked > 0 {
+			out.AddLine(sprintf("> Revoked from %d user(s)", revoked))
+		}
+	}
+
 	out.Construct()
 	return out
 }
@@ -3,12 +3,13 @@ package commands
 import (
 	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
 	"errors"
 	"fmt"
-	"log"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -27,7 +28,24 @@ type CommandRegistrar struct {
 	commandnames []string
 	loglevel     int
 	server       ifaces.IGameServer
+	core         ifaces.ICore
 	embeds       []chan struct{}
+
+	// Commands waiting for the server to finish starting
+	queuelock sync.Mutex
+	queued    []*queuedCommand
+
+	// Recent command messages, so that editing them runs the command again
+	editlock sync.Mutex
+	edits    map[string]*commandMessage
+
+	// Alliance bans waiting to be confirmed, by the ID of the requester
+	banlock sync.Mutex
+	bans    map[string]*pendingAllianceBan
+
+	// Preset changes that were previewed, by the ID of the requester
+	presetlock sync.Mutex
+	presets    map[string]*pendingPreset
 }
 
 // SetLoglevel - Set the current loglevel
@@ -43,13 +61,20 @@ func (reg *CommandRegistrar) UUID() string {
 
 // NewRegistrar - Create and return a new instance of CommandRegistrar
 //  @gid string    ID string of the guild the CommandRegistrar belongs to
-func NewRegistrar(gid string, gs ifaces.IGameServer) *CommandRegistrar {
+//  @gs IGameServer The game server that commands act upon
+//  @core ICore     The coordinator that reports on the bots health
+func NewRegistrar(gid string, gs ifaces.IGameServer,
+	core ifaces.ICore) *CommandRegistrar {
 	registrars[gid] = &CommandRegistrar{
 		GuildID:  gid,
 		commands: make(map[string]*CommandRegistrant, 10),
 		server:   gs,
+		core:     core,
 		loglevel: 1,
-		embeds:   make([]chan struct{}, 0)}
+		embeds:   make([]chan struct{}, 0),
+		edits:    make(map[string]*commandMessage),
+		bans:     make(map[string]*pendingAllianceBan),
+		presets:  make(map[string]*pendingPreset)}
 
 	return registrars[gid]
 }
@@ -86,7 +111,7 @@ func (reg *CommandRegistrar) Register(n, d, u string, a []CommandArgument,
 	f BotCommand, owners ...string) error {
 	// Does it exist?
 	if reg.IsRegistered(n) && len(owners) < 1 {
-		log.Fatal(fmt.Errorf("command %s was already defined", n))
+		shutdown.Fatal(fmt.Errorf("command %s was already defined", n))
 	}
 
 	if len(a) == 0 {
@@ -107,9 +132,10 @@ func (reg *CommandRegistrar) Register(n, d, u string, a []CommandArgument,
 	if len(owners) > 0 {
 		for _, owner := range owners {
 			if !reg.IsRegistered(owner) {
-				log.Fatal("Invalid subcommand owner passed to commands.Register")
+				shutdown.Fatal("Invalid subcommand owner passed to commands.Register")
 			}
 
+			registrant.parent = reg.commands[owner]
 			reg.commands[owner].cmdlets = append(reg.commands[owner].cmdlets,
 				registrant)
 			logger.LogDebug(reg, sprintf("Registered subcommand %s to %s ", n,
@@ -129,6 +155,39 @@ func (reg *CommandRegistrar) Register(n, d, u string, a []CommandArgument,
 	return nil
 }
 
+// AddExamples - Add usage examples to a command, which are shown in its help
+//  @path string          Command name, followed by subcommand names if any
+//  @examples ...string   Example invocations of the command
+func (reg *CommandRegistrar) AddExamples(path string, examples ...string) error {
+	names := strings.Fields(path)
+	if len(names) == 0 {
+		return errors.New("no command was given")
+	}
+
+	cmd, err := reg.Command(names[0])
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names[1:] {
+		var next *CommandRegistrant
+		for _, sub := range cmd.cmdlets {
+			if sub.Name() == name {
+				next = sub
+				break
+			}
+		}
+
+		if next == nil {
+			return errors.New(sprintf("%s has no subcommand %s", cmd.Name(), name))
+		}
+		cmd = next
+	}
+
+	cmd.examples = append(cmd.examples, examples...)
+	return nil
+}
+
 // IsRegistered - Return true if the command is registered, false if its not
 //  @n string    Name of a command
 func (reg *CommandRegistrar) IsRegistered(n string) bool {
@@ -186,8 +245,27 @@ func (reg *CommandRegistrar) ProcessCommand(s *discordgo.Session,
 		return "empty", nil
 	}
 
+	reg.trackCommand(m)
+
 	name := args[0]
 
+	// Guild specific alias templates expand into a full command invocation, so
+	// we replace the arguments before the command lookup takes place.
+	if !reg.IsRegistered(name) {
+		if ok, tmpl := c.AliasTemplate(reg.GuildID, name); ok {
+			if args, err = expandAliasTemplate(tmpl, args); err != nil {
+				if f := strings.Fields(tmpl); len(f) > 0 {
+					cmd, _ = reg.Command(f[0])
+				}
+				return name, &ErrInvalidAlias{alias: name, cmd: cmd,
+					message: err.Error()}
+			}
+			logger.LogDebug(reg, sprintf("Expanded alias %s to: %s", name,
+				strings.Join(args, " ")))
+			name = args[0]
+		}
+	}
+
 	// If the command doesn't exist, check if what was passed was an alias. If
 	// it was, then we just reference the command that was aliased
 	// TODO: Refactor this if CommandRegistrar.GetAliasedCommand and
@@ -216,20 +294,17 @@ func (reg *CommandRegistrar) ProcessCommand(s *discordgo.Session,
 			cmd:     cmd}
 	}
 
-	authreq := c.GetCmndAuth(cmd.Name())
 	authlvl := 0
-
-	if authreq > 0 {
-		for _, r := range member.Roles {
-			if l := c.GetRoleAuth(r); l > authlvl {
-				authlvl = l
-			}
-		}
-		if authlvl < authreq {
-			return cmd.Name(), &ErrUnauthorizedUsage{cmd: cmd}
+	for _, r := range member.Roles {
+		if l := c.GetRoleAuth(r); l > authlvl {
+			authlvl = l
 		}
 	}
 
+	if !c.CommandAllowed(m.Author.ID, authlvl, cmd.Name()) {
+		return cmd.Name(), &ErrUnauthorizedUsage{cmd: cmd}
+	}
+
 	if cmd.exec == nil {
 		logger.LogError(cmd, sprintf("Can't execute %s (missing exec field)",
 			cmd.Name()))
@@ -239,39 +314,90 @@ func (reg *CommandRegistrar) ProcessCommand(s *discordgo.Session,
 	// Update our arguments with the full command name
 	args[0] = cmd.Name()
 
+	if c.StartupQueue() && startupQueueable[cmd.Name()] &&
+		serverStarting(reg.server.Status().Status) {
+		reg.queueCommand(s, m, args, c, cmd, exitch)
+		return cmd.Name(), nil
+	}
+
 	if len(args) > 1 && args[1] == "help" && cmd.Name() != "rcon" {
 		out = cmd.Help()
 	} else {
 		out, cmderr = cmd.exec(s, m, args, c, cmd)
+		reg.server.RecordCommand(reg.GuildID, statName(cmd, args), cmderr != nil)
 	}
 
 	if cmderr != nil {
 		s.MessageReactionAdd(m.ChannelID, m.ID, "🚫")
 	} else {
 		s.MessageReactionAdd(m.ChannelID, m.ID, "✅")
-		if out != nil {
-			// Get the number of pages and use that to determine if we need a pager
-			if _, max := out.Index(); max > 0 {
-				if len(reg.embeds) > 4 {
-					close(reg.embeds[0])
-					reg.embeds[0] = nil
-					reg.embeds = reg.embeds[1:]
-				}
+		reg.sendOutput(s, m, cmd, out, exitch)
+	}
 
-				expirech := make(chan struct{})
-				reg.embeds = append(reg.embeds, expirech)
+	return cmd.Name(), cmderr
+}
 
-				logger.LogDebug(reg, "Starting a multipage embed goroutine")
-				go CreatePagedEmbed(out, s, m, expirech, exitch)
-			} else {
-				logger.LogDebug(reg, "Generating a single page embed")
-				embed, _, _ := GenerateOutputEmbed(out, out.ThisPage())
-				if _, err := s.ChannelMessageSendEmbed(m.ChannelID, embed); err != nil {
-					logger.LogError(cmd, "discordgo: "+err.Error())
-				}
+// sendOutput posts the output of a command to the channel that it was run in,
+// using a pager if the output has multiple pages
+func (reg *CommandRegistrar) sendOutput(s *discordgo.Session,
+	m *discordgo.MessageCreate, cmd *CommandRegistrant, out *CommandOutput,
+	exitch chan struct{}) {
+	if out == nil {
+		return
+	}
+
+	// Output from an edited command replaces the embed from its previous run
+	reply := reg.commandReply(m.ID)
+
+	// Get the number of pages and use that to determine if we need a pager
+	if _, max := out.Index(); max > 0 {
+		if reply != "" {
+			s.ChannelMessageDelete(m.ChannelID, reply)
+			reg.setCommandReply(m.ID, "")
+		}
+
+		if len(reg.embeds) > 4 {
+			close(reg.embeds[0])
+			reg.embeds[0] = nil
+			reg.embeds = reg.embeds[1:]
+		}
+
+		expirech := make(chan struct{})
+		reg.embeds = append(reg.embeds, expirech)
+
+		logger.LogDebug(reg, "Starting a multipage embed goroutine")
+		go CreatePagedEmbed(out, s, m, expirech, exitch)
+	} else {
+		logger.LogDebug(reg, "Generating a single page embed")
+		embed, _, _ := GenerateOutputEmbed(out, out.ThisPage())
+		if reply != "" {
+			if _, err := s.ChannelMessageEditEmbed(m.ChannelID, reply, embed); err == nil {
+				return
 			}
 		}
+
+		msg, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
+		if err != nil {
+			logger.LogError(cmd, "discordgo: "+err.Error())
+			return
+		}
+		reg.setCommandReply(m.ID, msg.ID)
 	}
+}
 
-	return cmd.Name(), cmderr
+// statName returns the name that a command invocation is recorded under in
+// the usage statistics, which includes the subcommand if one was used
+func statName(cmd *CommandRegistrant, a BotArgs) string {
+	if len(a) < 2 {
+		return cmd.Name()
+	}
+
+	_, cmdlets := cmd.Subcommands()
+	for _, cmdlet := range cmdlets {
+		if a[1] == cmdlet.Name() {
+			return cmd.Name() + " " + cmdlet.Name()
+		}
+	}
+
+	return cmd.Name()
 }
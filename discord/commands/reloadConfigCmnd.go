@@ -18,6 +18,10 @@ func reloadConfigCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArg
 	}
 
 	out.AddLine("Reloaded bot configuration")
+	if name, ok := c.PendingGalaxy(); ok {
+		out.AddLine(sprintf("The galaxy was changed to %s while %s is running, "+
+			"use `server switch-galaxy confirm` to switch to it", name, c.Galaxy()))
+	}
 
 	cmd.Registrar().server.InitializeEvents()
 	out.Construct()
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func replyCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Staff Reply")
+		srv = cmd.Registrar().server
+	)
+
+	if !HasNumArgs(a, 2, -1) {
+		return nil, &ErrInvalidArgument{
+			message: "Usage: `reply <message #> <text>`",
+			cmd:     cmd}
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(a[1], "#"), 10, 64)
+	if err != nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` is not a valid message number", a[1]),
+			cmd:     cmd}
+	}
+
+	text := strings.Join(a[2:], " ")
+	msg, err := srv.ReplyStaffMessage(id, m.Author.Username, text)
+	if err != nil {
+		return nil, &ErrCommandError{
+			message: ifaces.ErrorMessage(err),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s replied to staff message #%d from %s",
+		m.Author.String(), id, msg.Name))
+	out.AddLine(sprintf("Sent your reply to `%s`", msg.Name))
+	out.AddLine("> " + text)
+	out.Construct()
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func reviewsCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Review Queue")
+		srv = cmd.Registrar().server
+		loc = c.Location()
+		lc  = c.Locale()
+	)
+
+	if len(a) > 1 {
+		if a[1] != "resolve" || len(a) != 3 {
+			return nil, &ErrInvalidArgument{
+				message: "Usage: `reviews (resolve <index>)`",
+				cmd:     cmd}
+		}
+
+		if err := srv.ResolveReview(a[2]); err != nil {
+			return nil, &ErrCommandError{
+				message: ifaces.ErrorMessage(err),
+				cmd:     cmd}
+		}
+
+		logger.LogInfo(cmd, sprintf("%s resolved the review for player %s",
+			m.Author.String(), a[2]))
+		out.AddLine(sprintf("Removed player `%s` from the review queue", a[2]))
+		out.Construct()
+		return out, nil
+	}
+
+	reviews, err := srv.ReviewQueue()
+	if err != nil {
+		logger.LogError(cmd, "ReviewQueue: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to get the review queue:\n```" + ifaces.ErrorMessage(err) + "```",
+			cmd:     cmd}
+	}
+
+	if len(reviews) == 0 {
+		out.AddLine("There are no players waiting to be reviewed")
+		out.Construct()
+		return out, nil
+	}
+
+	out.Header = "Times are in " + loc.String()
+	for _, r := range reviews {
+		out.AddLine(sprintf("**%s** _(index %s)_ <@%s>", r.Name, r.Index,
+			r.DiscordID))
+		out.AddLine(sprintf("> %s at %s", r.Reason, lc.DateTime(r.Time, loc)))
+	}
+
+	out.Construct()
+	return out, nil
+}
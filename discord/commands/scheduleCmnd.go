@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func scheduleCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Upcoming Actions")
+		srv = cmd.Registrar().server
+	)
+
+	if len(a) > 1 {
+		return cancelScheduledAction(m, a, cmd)
+	}
+
+	loc := c.Location()
+	lc := c.Locale()
+
+	actions := srv.Schedule()
+	if len(actions) == 0 {
+		out.AddLine("There are no actions scheduled while the server is offline")
+		out.Construct()
+		return out, nil
+	}
+
+	out.Header = "Times are in " + loc.String()
+	for _, act := range actions {
+		out.AddLine(sprintf("**%s** %s _(in %s)_", lc.DateTime(act.Next, loc),
+			act.Name, lc.Duration(time.Until(act.Next))))
+	}
+
+	if len(srv.OneShotActions()) > 0 {
+		out.AddLine("")
+		out.AddLine("_Run `schedule cancel <id>` to cancel a one-off action_")
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+// cancelScheduledAction cancels a one-off action by the ID shown in the
+// schedule
+func cancelScheduledAction(m *discordgo.MessageCreate, a BotArgs,
+	cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Cancel Scheduled Action")
+		srv = cmd.Registrar().server
+	)
+
+	if len(a) != 3 || a[1] != "cancel" {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(a[2], "#"), 10, 64)
+	if err != nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` is not a valid action ID", a[2]),
+			cmd:     cmd}
+	}
+
+	if err := srv.CancelAction(id); err != nil {
+		return nil, &ErrCommandError{
+			message: "Failed to cancel the action: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s cancelled the scheduled action #%d",
+		m.Author.String(), id))
+	out.AddLine(sprintf("Cancelled the scheduled action #%d", id))
+	out.Construct()
+	return out, nil
+}
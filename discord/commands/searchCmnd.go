@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// searchHeadings are the headings that search results are grouped under
+var searchHeadings = map[string]string{
+	ifaces.SearchPlayer:   "Players",
+	ifaces.SearchAlliance: "Alliances",
+	ifaces.SearchShip:     "Ships",
+	ifaces.SearchSector:   "Sectors"}
+
+// searchResultLine describes a search result, along with the command that
+// shows more about it
+func searchResultLine(r ifaces.SearchResult) string {
+	switch r.Kind {
+	case ifaces.SearchPlayer:
+		return sprintf("> **%s** _(index %s)_ - `getjumps 10 %s`", r.Name, r.Ref,
+			r.Name)
+	case ifaces.SearchAlliance:
+		return sprintf("> **%s** _(index %s)_ - `getalliance %s`", r.Name, r.Ref,
+			r.Ref)
+	case ifaces.SearchShip:
+		return sprintf("> **%s** (%s) - `findship %s`", r.Name, r.Detail, r.Name)
+	default:
+		return sprintf("> **%s** (%s) - `getcoordhistory %s`", r.Name, r.Detail,
+			r.Ref)
+	}
+}
+
+func searchCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		reg = cmd.Registrar()
+		out = newCommandOutput(cmd, "Search")
+		srv = reg.server
+	)
+
+	if !HasNumArgs(a, 1, -1) {
+		return nil, &ErrInvalidArgument{
+			message: "Please provide a name to search for",
+			cmd:     cmd}
+	}
+
+	term := strings.Join(a[1:], " ")
+	results, err := srv.Search(term, func(p ifaces.IPlayer) bool {
+		return canViewPlayer(s, reg.GuildID, m.Author.ID, c, p)
+	})
+	if err != nil {
+		logger.LogError(cmd, "Search: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to search the tracked data: " + ifaces.ErrorMessage(err),
+			cmd:     cmd}
+	}
+
+	if len(results) == 0 {
+		out.AddLine(sprintf("Nothing matching `%s` has been seen", term))
+		out.Construct()
+		return out, nil
+	}
+
+	out.Header = sprintf("Results for `%s`, best matches first", term)
+
+	kind := ""
+	for _, r := range results {
+		if r.Kind != kind {
+			kind = r.Kind
+			out.AddLine("**" + searchHeadings[kind] + "**")
+		}
+		out.AddLine(searchResultLine(r))
+	}
+
+	out.Construct()
+	return out, nil
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// selfUpdateCmnd checks for a newer release of the bot, and installs it and
+// restarts into it when given the install argument
+func selfUpdateCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out  = newCommandOutput(cmd, "Self Update")
+		core = cmd.Registrar().core
+	)
+
+	if core == nil {
+		return nil, &ErrCommandError{
+			message: "Bot information is not available",
+			cmd:     cmd}
+	}
+
+	if len(a) > 2 || (len(a) == 2 && a[1] != "install") {
+		return nil, &ErrInvalidArgument{
+			message: "Usage: `selfupdate (install)`",
+			cmd:     cmd}
+	}
+
+	if len(a) == 1 {
+		latest, newer, err := core.CheckUpdate()
+		if err != nil {
+			logger.LogError(cmd, "CheckUpdate: "+err.Error())
+			return nil, &ErrCommandError{
+				message: "Failed to check for updates:\n```" + err.Error() + "```",
+				cmd:     cmd}
+		}
+
+		out.AddLine(sprintf("**Running:** _%s_", core.Version()))
+		out.AddLine(sprintf("**Latest:** _%s_", latest))
+		if newer {
+			out.AddLine("Run `selfupdate install` to update and restart the bot")
+		} else {
+			out.AddLine("The bot is up to date")
+		}
+
+		out.Construct()
+		return out, nil
+	}
+
+	logger.LogInfo(cmd, sprintf("%s started a self update", m.Author.String()))
+	latest, err := core.SelfUpdate()
+	if err != nil {
+		logger.LogError(cmd, "SelfUpdate: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to update the bot:\n```" + err.Error() + "```",
+			cmd:     cmd}
+	}
+
+	out.AddLine(sprintf("Installed version **%s** (was _%s_)", latest,
+		core.Version()))
+	out.AddLine("The bot will restart shortly. Avorion will be stopped and " +
+		"started again by the new version.")
+	out.Construct()
+	return out, nil
+}
@@ -3,6 +3,8 @@ package commands
 import (
 	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
+	"strconv"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -10,10 +12,75 @@ import (
 func restartServerCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
 	reg := cmd.Registrar()
+
+	if len(a) > 5 || (len(a) > 3 && a[2] != "at") {
+		return nil, &ErrInvalidArgument{
+			message: "Too many arguments",
+			cmd:     cmd}
+	}
+
+	// Schedule a one-off restart at a time of day
+	if len(a) > 2 && a[2] == "at" {
+		out := newCommandOutput(cmd, "Scheduled Restart")
+
+		at, err := parseTimeOfDay(a[3:], c.Location(), time.Now())
+		if err != nil {
+			return nil, &ErrInvalidArgument{
+				message: "Invalid time: " + err.Error(),
+				cmd:     cmd}
+		}
+
+		act, err := reg.server.ScheduleAction(ifaces.ActionRestart, at,
+			m.Author.String())
+		if err != nil {
+			logger.LogError(cmd, "ScheduleAction: "+err.Error())
+			return nil, &ErrCommandError{
+				message: "Failed to schedule the restart: " + ifaces.ErrorMessage(err),
+				cmd:     cmd}
+		}
+
+		out.AddLine(sprintf("The server will restart at %s (<t:%d:R>)",
+			c.Locale().DateTimeZone(act.At, c.Location()), act.At.Unix()))
+		out.AddLine(sprintf("Run `schedule cancel %d` to cancel it", act.ID))
+		out.Construct()
+		return out, nil
+	}
+
+	// With an argument, schedule or cancel a countdown instead of restarting
+	if len(a) == 3 {
+		out := newCommandOutput(cmd, "Scheduled Restart")
+
+		if a[2] == "cancel" {
+			if !reg.server.CancelRestart() {
+				return nil, &ErrCommandError{
+					message: "There is no restart pending",
+					cmd:     cmd}
+			}
+
+			out.AddLine("Cancelled the pending restart")
+			out.Construct()
+			return out, nil
+		}
+
+		mins, err := strconv.Atoi(a[2])
+		if err != nil || mins < 1 {
+			return nil, &ErrInvalidArgument{
+				message: sprintf("`%s` is not a valid number of minutes", a[2]),
+				cmd:     cmd}
+		}
+
+		reg.server.ScheduleRestart(time.Duration(mins) * time.Minute)
+		logger.LogInfo(cmd, sprintf("%s scheduled a restart in %d minutes",
+			m.Author.String(), mins))
+		out.AddLine(sprintf("The server will restart in %d minutes", mins))
+		out.Construct()
+		return out, nil
+	}
+
 	if err := reg.server.Restart(); err != nil {
 		logger.LogError(cmd, "Avorion: "+err.Error())
 		return nil, &ErrCommandError{
-			message: "Error restarting Avorion: " + err.Error(),
+			message: "Error restarting Avorion: " + ifaces.ErrorMessage(err),
 			cmd:     cmd}
 	}
 
@@ -27,7 +94,7 @@ func stopServerCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 		if err := reg.server.Stop(true); err != nil {
 			logger.LogError(cmd, "Avorion: "+err.Error())
 			return nil, &ErrCommandError{
-				message: "Error stopping Avorion: " + err.Error(),
+				message: "Error stopping Avorion: " + ifaces.ErrorMessage(err),
 				cmd:     cmd}
 		}
 	}
@@ -44,10 +111,124 @@ func startServerCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs
 				"Encountered an error starting the server:\n```%s\n```\n", err.Error()))
 			logger.LogError(cmd, "Avorion: "+err.Error())
 			return nil, &ErrCommandError{
-				message: "Error starting Avorion: " + err.Error(),
+				message: "Error starting Avorion: " + ifaces.ErrorMessage(err),
 				cmd:     cmd}
 		}
 	}
 
 	return nil, nil
 }
+
+func switchGalaxyCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Switch Galaxy")
+		reg = cmd.Registrar()
+	)
+
+	pending, ok := c.PendingGalaxy()
+	if !ok {
+		return nil, &ErrCommandError{
+			message: "The galaxy name in the config hasn't changed",
+			cmd:     cmd}
+	}
+
+	if len(a) < 2 || a[1] != "confirm" {
+		out.AddLine(sprintf("The config now names the galaxy `%s`, but `%s` is "+
+			"in use", pending, c.Galaxy()))
+		if reg.server.IsUp() {
+			out.AddLine("Switching will stop the server, and start it again on " +
+				"the new galaxy")
+		}
+		out.AddLine("Run `server switch-galaxy confirm` to switch")
+		out.Construct()
+		return out, nil
+	}
+
+	name, err := reg.server.SwitchGalaxy()
+	if err != nil {
+		logger.LogError(cmd, "Avorion: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Error switching galaxy: " + ifaces.ErrorMessage(err),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s switched the galaxy to %s",
+		m.Author.String(), name))
+	out.AddLine(sprintf("Switched to the galaxy `%s`", name))
+	out.Construct()
+	return out, nil
+}
+
+func reapServerCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Reap Orphaned Server")
+		reg = cmd.Registrar()
+	)
+
+	pid, err := reg.server.ReapOrphan()
+	if err != nil {
+		logger.LogError(cmd, "Avorion: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Error terminating orphaned Avorion process: " + err.Error(),
+			cmd:     cmd}
+	}
+
+	logger.LogInfo(cmd, sprintf("%s terminated the orphaned Avorion process %d",
+		m.Author.String(), pid))
+	out.AddLine(sprintf("Terminated the orphaned Avorion process (PID `%d`)", pid))
+	out.Construct()
+	return out, nil
+}
+
+func exitsServerCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out   = newCommandOutput(cmd, "Server Exits")
+		reg   = cmd.Registrar()
+		limit = 10
+	)
+
+	if !HasNumArgs(a[1:], 0, 1) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	if len(a) > 2 {
+		n, err := strconv.Atoi(a[2])
+		if err != nil || n < 1 {
+			return nil, &ErrInvalidArgument{
+				message: sprintf("Invalid number of exits: `%s`", a[2]),
+				cmd:     cmd}
+		}
+		limit = n
+	}
+
+	exits, err := reg.server.ExitHistory(limit)
+	if err != nil {
+		logger.LogError(cmd, "ExitHistory: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to get the exit history: " + ifaces.ErrorMessage(err),
+			cmd:     cmd}
+	}
+
+	if len(exits) == 0 {
+		out.AddLine("Avorion hasn't exited since exits started being recorded")
+		out.Construct()
+		return out, nil
+	}
+
+	for _, e := range exits {
+		how := sprintf("status code `%d`", e.Code)
+		if e.Signal != "" {
+			how = sprintf("signal `%s`", e.Signal)
+		}
+		out.AddLine(sprintf("<t:%d:f> **%s** (%s): %s", e.Time.Unix(), e.Class,
+			how, e.Explanation))
+	}
+
+	out.Construct()
+	return out, nil
+}
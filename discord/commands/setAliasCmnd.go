@@ -4,6 +4,7 @@ import (
 	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
 	"regexp"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -12,7 +13,7 @@ func setaliasCmd(s *discordgo.Session, m *discordgo.MessageCreate,
 	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
 	reg := cmd.Registrar()
 
-	if !HasNumArgs(a, 2, 2) {
+	if !HasNumArgs(a, 2, -1) {
 		return nil, &ErrInvalidArgument{
 			message: sprintf(`%s was passed the wrong number of arguments`, cmd.Name()),
 			cmd:     cmd}
@@ -30,6 +31,30 @@ func setaliasCmd(s *discordgo.Session, m *discordgo.MessageCreate,
 			cmd:  cmd}
 	}
 
+	if reg.IsRegistered(a[2]) {
+		return nil, &ErrInvalidAlias{
+			alias:   a[2],
+			message: sprintf("`%s` is already a registered command", a[2]),
+			cmd:     cmd}
+	}
+
+	// Aliases that are given fixed arguments or placeholders are stored as a
+	// template for this guild only
+	if len(a) > 3 {
+		tmpl := a[1] + " " + strings.Join(a[3:], " ")
+		if err := c.SetAliasTemplate(reg.GuildID, a[2], tmpl); err != nil {
+			logger.LogError(cmd, err.Error())
+			return nil, &ErrCommandError{
+				message: "Failed to configure Alias: " + err.Error(),
+				cmd:     cmd}
+		}
+
+		logger.LogInfo(cmd, sprintf("%s aliased %s to [%s]", m.Author.String(),
+			a[2], tmpl))
+		c.SaveConfiguration()
+		return nil, nil
+	}
+
 	if err := c.SetAliasCommand(a[1], a[2]); err != nil {
 		logger.LogError(cmd, err.Error())
 		return nil, &ErrCommandError{
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func setLocaleCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
+	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Update Locale")
+	)
+
+	if !HasNumArgs(a, 1, 1) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf(`%s was passed the wrong number of arguments`, a[0]),
+			cmd:     cmd}
+	}
+
+	if err := c.SetLocale(a[1]); err != nil {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("Incorrect locale: `%s` (%s)", a[1], err.Error()),
+			cmd:     cmd}
+	}
+
+	c.SaveConfiguration()
+	logger.LogInfo(cmd, sprintf(
+		"User %s changed the locale to %s", m.Author.String(), c.Locale().Tag()))
+
+	// Preview the new locale so that admins can confirm it reads as expected
+	lc := c.Locale()
+	out.AddLine(sprintf("Set the locale to `%s`", lc.Tag()))
+	out.AddLine(sprintf("**Current time:** %s", lc.DateTimeZone(time.Now(),
+		c.Location())))
+	out.AddLine(sprintf("**Numbers:** %s", lc.Number(1234567)))
+	out.AddLine(sprintf("**Durations:** %s", lc.Duration(26*time.Hour+
+		15*time.Minute)))
+
+	out.Construct()
+	return out, nil
+}
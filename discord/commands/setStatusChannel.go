@@ -16,12 +16,23 @@ func setStatusChannelCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
 		out = newCommandOutput(cmd, "Update Status Channel")
 	)
 
-	if !HasNumArgs(a, 1, 1) {
+	if !HasNumArgs(a, 1, 2) {
 		return nil, &ErrInvalidArgument{
 			message: sprintf(`%s was passed the wrong number of arguments`, cmd.Name()),
 			cmd:     cmd}
 	}
 
+	kind := ""
+	if len(a) > 2 {
+		kind = a[2]
+	}
+
+	if kind != "" && kind != "public" && kind != "panel" {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("Invalid status embed type: `%s`", a[2]),
+			cmd:     cmd}
+	}
+
 	if channels, err = s.GuildChannels(m.GuildID); err != nil {
 		logger.LogError(cmd, err.Error())
 		return nil, &ErrCommandError{
@@ -32,11 +43,26 @@ func setStatusChannelCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
 	for _, dch := range channels {
 		logger.LogDebug(cmd, sprintf("Checking channel ID %s against %s", dch.ID, a[1]))
 		if dch.ID == a[1] && dch.Type == discordgo.ChannelTypeGuildText {
-			c.SetStatusChannel(a[1])
+			switch kind {
+			case "public":
+				c.SetPublicStatusChannel(a[1])
+				logger.LogInfo(cmd, sprintf("%s set the public status channel to %s",
+					m.Author.String(), dch.ID))
+				out.AddLine(sprintf("Set the public game status to channel %s",
+					dch.Mention()))
+			case "panel":
+				c.SetControlPanelChannel(a[1])
+				logger.LogInfo(cmd, sprintf("%s set the control panel channel to %s",
+					m.Author.String(), dch.ID))
+				out.AddLine(sprintf("Set the server control panel to channel %s",
+					dch.Mention()))
+			default:
+				c.SetStatusChannel(a[1])
+				logger.LogInfo(cmd, sprintf(
+					"%s set the status channel to %s", m.Author.String(), dch.ID))
+				out.AddLine(sprintf("Set the game status to channel %s", dch.Mention()))
+			}
 			c.SaveConfiguration()
-			logger.LogInfo(cmd, sprintf(
-				"%s set the status channel to %s", m.Author.String(), dch.ID))
-			out.AddLine(sprintf("Set the game status to channel %s", dch.Mention()))
 			out.Construct()
 			return out, nil
 		}
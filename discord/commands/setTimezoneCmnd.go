@@ -10,22 +10,43 @@ import (
 
 func setTimezoneCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 	c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Update Timezone")
+	)
+
 	if !HasNumArgs(a, 1, 1) {
 		return nil, &ErrInvalidArgument{
 			message: sprintf(`%s was passed the wrong number of arguments`, a[0]),
 			cmd:     cmd}
 	}
 
-	if _, err := time.LoadLocation(a[1]); err != nil {
+	if err := c.SetTimeZone(a[1]); err != nil {
 		return nil, &ErrCommandError{
 			message: sprintf("Incorrect timezone: `%s` (%s)", a[1], err.Error()),
 			cmd:     cmd}
 	}
 
-	c.SetTimeZone(a[1])
 	c.SaveConfiguration()
 	logger.LogInfo(cmd, sprintf(
 		"User %s changed the timezone to %s", m.Author.String(), a[1]))
 
-	return nil, nil
+	// Preview the new timezone so that admins can confirm it behaves as expected
+	loc := c.Location()
+	now := time.Now().In(loc)
+	name, offset := now.Zone()
+
+	out.AddLine(sprintf("Set the timezone to `%s`", loc.String()))
+	out.AddLine(sprintf("**Current time:** %s", c.Locale().DateTime(now, loc)))
+	out.AddLine(sprintf("**Offset:** %s (UTC%+.1f)", name, float64(offset)/3600))
+
+	if next, ok := nextZoneTransition(loc, now); ok {
+		nname, noffset := next.Zone()
+		out.AddLine(sprintf("**Next change:** %s, to %s (UTC%+.1f)",
+			c.Locale().DateTime(next, loc), nname, float64(noffset)/3600))
+	} else {
+		out.AddLine("**Next change:** _this timezone does not observe DST_")
+	}
+
+	out.Construct()
+	return out, nil
 }
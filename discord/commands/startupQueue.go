@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	queuedReaction  = "⏳"
+	queueTimeout    = 15 * time.Minute
+	queuePollPeriod = 5 * time.Second
+)
+
+// startupQueueable are the commands that are queued, rather than failed, when
+// they are run while the server is starting
+var startupQueueable = map[string]bool{
+	"rcon":   true,
+	"player": true}
+
+// queuedCommand is a command invocation that is waiting for the server to
+// finish starting
+type queuedCommand struct {
+	s    *discordgo.Session
+	m    *discordgo.MessageCreate
+	args BotArgs
+	c    ifaces.IConfigurator
+	cmd  *CommandRegistrant
+}
+
+// serverStarting returns true if the given server status is one in which the
+// server will shortly be ready for commands
+func serverStarting(status int) bool {
+	if status >= ifaces.ServerCrashedOffline {
+		status -= ifaces.ServerCrashedOffline
+	}
+	return status == ifaces.ServerStarting || status == ifaces.ServerRestarting
+}
+
+// queueCommand holds a command until the server is online, marking the message
+// that ran it so that the user knows it hasn't been lost. The first command to
+// be queued starts the goroutine that runs the queue.
+func (reg *CommandRegistrar) queueCommand(s *discordgo.Session,
+	m *discordgo.MessageCreate, a BotArgs, c ifaces.IConfigurator,
+	cmd *CommandRegistrant, exitch chan struct{}) {
+	reg.queuelock.Lock()
+	defer reg.queuelock.Unlock()
+
+	logger.LogInfo(cmd, sprintf("Queued %s from %s until the server is ready",
+		cmd.Name(), m.Author.String()))
+	s.MessageReactionAdd(m.ChannelID, m.ID, queuedReaction)
+
+	reg.queued = append(reg.queued, &queuedCommand{s: s, m: m, args: a, c: c,
+		cmd: cmd})
+	if len(reg.queued) == 1 {
+		go reg.runQueue(exitch)
+	}
+}
+
+// runQueue waits for the server to finish starting, then runs the queued
+// commands in the order that they were received. If the server doesn't come
+// online, the queued commands are failed instead.
+func (reg *CommandRegistrar) runQueue(exitch chan struct{}) {
+	defer shutdown.Recover()
+
+	deadline := time.Now().Add(queueTimeout)
+
+	for serverStarting(reg.server.Status().Status) && time.Now().Before(deadline) {
+		select {
+		case <-exitch:
+			return
+		case <-time.After(queuePollPeriod):
+		}
+	}
+
+	reg.queuelock.Lock()
+	queued := reg.queued
+	reg.queued = nil
+	reg.queuelock.Unlock()
+
+	online := reg.server.IsUp() && !serverStarting(reg.server.Status().Status)
+	for _, q := range queued {
+		q.s.MessageReactionRemove(q.m.ChannelID, q.m.ID, queuedReaction, "@me")
+
+		if !online {
+			q.s.MessageReactionAdd(q.m.ChannelID, q.m.ID, "🚫")
+			(&ErrCommandError{
+				message: "The server didn't come online, so this command was not run",
+				cmd:     q.cmd}).Emit(q.s, q.m.ChannelID)
+			continue
+		}
+
+		out, cmderr := q.cmd.exec(q.s, q.m, q.args, q.c, q.cmd)
+		reg.server.RecordCommand(reg.GuildID, statName(q.cmd, q.args),
+			cmderr != nil)
+
+		if cmderr != nil {
+			q.s.MessageReactionAdd(q.m.ChannelID, q.m.ID, "🚫")
+			cmderr.Emit(q.s, q.m.ChannelID)
+			continue
+		}
+
+		q.s.MessageReactionAdd(q.m.ChannelID, q.m.ID, "✅")
+		reg.sendOutput(q.s, q.m, q.cmd, out, exitch)
+	}
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Number of entries shown for each group of combat statistics
+const combatStatsLimit = 5
+
+func statsCommandsSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out   = newCommandOutput(cmd, "Command Usage")
+		reg   = cmd.Registrar()
+		guild = reg.GuildID
+	)
+
+	if !HasNumArgs(a[1:], 0, 1) {
+		return nil, &ErrInvalidArgument{
+			message: sprintf("`%s` was passed the wrong number of arguments", cmd.Name()),
+			cmd:     cmd}
+	}
+
+	if len(a) > 2 {
+		if a[2] != "all" {
+			return nil, &ErrInvalidArgument{
+				message: sprintf("`%s` is not a valid argument", a[2]),
+				cmd:     cmd}
+		}
+		guild = ""
+	}
+
+	stats, err := reg.server.CommandStats(guild)
+	if err != nil {
+		logger.LogError(cmd, "CommandStats: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to fetch command statistics:\n```" + err.Error() + "```",
+			cmd:     cmd}
+	}
+
+	if len(stats) == 0 {
+		out.AddLine("No commands have been recorded yet")
+		out.Construct()
+		return out, nil
+	}
+
+	if guild == "" {
+		out.Header = "Usage across all guilds"
+	}
+
+	for _, st := range stats {
+		out.AddLine(sprintf("**%s**: %d uses, %d failed _(%.1f%%)_", st.Name,
+			st.Uses, st.Failures, 100*float64(st.Failures)/float64(st.Uses)))
+	}
+
+	out.Construct()
+	return out, nil
+}
+
+func statsCombatSubCmnd(s *discordgo.Session, m *discordgo.MessageCreate,
+	a BotArgs, c ifaces.IConfigurator, cmd *CommandRegistrant) (*CommandOutput, ICommandError) {
+	var (
+		out = newCommandOutput(cmd, "Combat Statistics")
+		reg = cmd.Registrar()
+	)
+
+	stats, err := reg.server.CombatStats(combatStatsLimit)
+	if err != nil {
+		logger.LogError(cmd, "CombatStats: "+err.Error())
+		return nil, &ErrCommandError{
+			message: "Failed to fetch combat statistics:\n```" + err.Error() + "```",
+			cmd:     cmd}
+	}
+
+	if stats.Total == 0 {
+		out.AddLine("No ships have been destroyed yet")
+		out.Construct()
+		return out, nil
+	}
+
+	out.Header = sprintf("%d ships destroyed", stats.Total)
+	for _, group := range []struct {
+		title string
+		stats []ifaces.CombatStat
+	}{
+		{"Losses by ship class", stats.ByClass},
+		{"Kills by ship class", stats.ByKillerClass},
+		{"Most destroyed ship names", stats.ByName},
+		{"Deadliest sectors", stats.BySector}} {
+		out.AddLine("**" + group.title + "**")
+		for _, st := range group.stats {
+			out.AddLine(sprintf("`%s`: %d", st.Name, st.Count))
+		}
+	}
+
+	out.Construct()
+	return out, nil
+}
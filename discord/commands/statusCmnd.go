@@ -1,8 +1,8 @@
 package commands
 
 import (
+	"avorioncontrol/avorion/rcon"
 	"avorioncontrol/ifaces"
-	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -27,10 +27,19 @@ func statusCmnd(s *discordgo.Session, m *discordgo.MessageCreate, a BotArgs,
 			cmd:     cmd}
 	}
 
-	if strings.ReplaceAll(ret, " ", "") != "" {
-		for _, line := range strings.Split(ret, "\n")[0:10] {
+	if st := rcon.ParseStatus(ret); len(st.Lines) > 0 {
+		if len(st.Lines) > 10 {
+			st.Lines = st.Lines[:10]
+		}
+
+		for _, line := range st.Lines {
 			out.AddLine(line)
 		}
+
+		depth := srv.CommandQueueDepth()
+		out.AddLine(sprintf("RCON Queue: %d health, %d user, %d background",
+			depth[ifaces.CommandPriorityHealth], depth[ifaces.CommandPriorityUser],
+			depth[ifaces.CommandPriorityBackground]))
 	} else {
 		return nil, &ErrCommandError{
 			message: "Invalid output recieved from status",
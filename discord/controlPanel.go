@@ -0,0 +1,317 @@
+package discord
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	panelStart   = "▶️"
+	panelStop    = "⏹️"
+	panelRestart = "🔄"
+	panelBackup  = "💾"
+	panelRefresh = "🔃"
+
+	panelHelpTemplate = "> %s Start the server\n> %s Stop the server\n" +
+		"> %s Restart the server\n> %s Back up the galaxy\n" +
+		"> %s Refresh this panel"
+	noticePanelAction = "**Control Panel**: %s requested **%s**"
+	noticePanelFailed = "**Control Panel**: **%s** failed:\n```%s```"
+)
+
+// panelAction is a single button on the server control panel. Buttons use
+// the auth level of the command that they mirror.
+type panelAction struct {
+	emoji string
+	label string
+	cmnd  string
+	run   func(ifaces.IGameServer) error
+}
+
+var panelActions = []panelAction{
+	{panelStart, "Start", "server", func(gs ifaces.IGameServer) error {
+		if gs.IsUp() {
+			return nil
+		}
+		return gs.Start(true)
+	}},
+	{panelStop, "Stop", "server", func(gs ifaces.IGameServer) error {
+		if !gs.IsUp() {
+			return nil
+		}
+		return gs.Stop(true)
+	}},
+	{panelRestart, "Restart", "server", func(gs ifaces.IGameServer) error {
+		return gs.Restart()
+	}},
+	{panelBackup, "Backup", "backup", func(gs ifaces.IGameServer) error {
+		_, err := gs.Backup(ifaces.BackupManual)
+		return err
+	}},
+	{panelRefresh, "Refresh", "status", nil}}
+
+// controlPanel tracks the control panel message, and the last action that
+// was taken with it
+type controlPanel struct {
+	mutex     *sync.Mutex
+	cid       string
+	messageid string
+	busy      bool
+	last      string
+	refresh   chan struct{}
+}
+
+func newControlPanel() *controlPanel {
+	return &controlPanel{
+		mutex:   new(sync.Mutex),
+		refresh: make(chan struct{}, 1),
+		last:    "None"}
+}
+
+// generateEmbedControlPanel returns the embed for the control panel message
+func generateEmbedControlPanel(s ifaces.ServerStatus,
+	tc ifaces.ITimeConfigurator, last string) *discordgo.MessageEmbed {
+	stat, color := ifaces.State(s.Status)
+
+	embed := discordgo.MessageEmbed{
+		Type:      discordgo.EmbedTypeRich,
+		Title:     "Server Control Panel",
+		Color:     color,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer:    updatedFooter(tc),
+		Fields: []*discordgo.MessageEmbedField{
+			{Inline: true, Name: "State", Value: stat},
+			{Inline: true, Name: "Players Online",
+				Value: tc.Locale().Number(int64(s.PlayersOnline))},
+			{Inline: false, Name: "Controls", Value: fmt.Sprintf(panelHelpTemplate,
+				panelStart, panelStop, panelRestart, panelBackup, panelRefresh)},
+			{Inline: false, Name: "Last Action", Value: last}}}
+
+	return &embed
+}
+
+// superviseControlPanel keeps the control panel message posted in the
+// configured channel, and up to date with the server status
+func (b *Bot) superviseControlPanel(s *discordgo.Session, gs ifaces.IGameServer) {
+	defer shutdown.Recover()
+
+	b.wg.Add(1)
+	defer b.wg.Done()
+
+	var (
+		p          = b.panel
+		laststatus ifaces.ServerStatus
+	)
+
+	post := func(cid string, stat ifaces.ServerStatus) {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		embed := generateEmbedControlPanel(stat, b.config, p.last)
+
+		// Reuse the panel from the last run if it's still in the channel, so
+		// that every start doesn't leave another panel behind
+		if mid := b.config.ControlPanelMessage(); mid != "" {
+			if _, err := s.ChannelMessageEditEmbed(cid, mid, embed); err == nil {
+				for _, act := range panelActions {
+					s.MessageReactionAdd(cid, mid, act.emoji)
+				}
+
+				logger.LogInit(b, "Reusing server control panel in channel: "+cid)
+				p.cid = cid
+				p.messageid = mid
+				return
+			}
+		}
+
+		m, err := s.ChannelMessageSendEmbed(cid, embed)
+		if err != nil {
+			logger.LogError(b, "Discordgo: "+err.Error())
+			return
+		}
+
+		for _, act := range panelActions {
+			s.MessageReactionAdd(cid, m.ID, act.emoji)
+		}
+
+		logger.LogInit(b, "Posted server control panel to channel: "+cid)
+		p.cid = cid
+		p.messageid = m.ID
+
+		b.config.SetControlPanelMessage(m.ID)
+		if err := b.config.SaveConfiguration(); err != nil {
+			logger.LogError(b, "Failed to save the control panel message: "+
+				err.Error())
+		}
+	}
+
+	update := func(stat ifaces.ServerStatus) {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		if p.messageid == "" {
+			return
+		}
+
+		_, err := s.ChannelMessageEditEmbed(p.cid, p.messageid,
+			generateEmbedControlPanel(stat, b.config, p.last))
+		if err != nil {
+			logger.LogError(b, "Discordgo: "+err.Error())
+		}
+	}
+
+	defer func() {
+		laststatus.Status = ifaces.ServerOffline
+		update(laststatus)
+		if p.messageid != "" {
+			s.MessageReactionsRemoveAll(p.cid, p.messageid)
+		}
+	}()
+
+	for {
+		select {
+		case <-b.exit:
+			return
+
+		case <-p.refresh:
+			laststatus = gs.Status()
+			update(laststatus)
+
+		case <-time.After(time.Second * 5):
+			cid, ok := b.config.ControlPanelChannel()
+			if !ok {
+				continue
+			}
+
+			stat := gs.Status()
+			if cid != p.cid {
+				laststatus = stat
+				post(cid, stat)
+				continue
+			}
+
+			if !gs.CompareStatus(stat, laststatus) {
+				laststatus = stat
+				update(stat)
+			}
+		}
+	}
+}
+
+// onControlPanelReact runs the action for a reaction that was added to the
+// control panel, provided that the user is authorized to use it
+func (b *Bot) onControlPanelReact(s *discordgo.Session,
+	r *discordgo.MessageReactionAdd, gs ifaces.IGameServer) {
+	p := b.panel
+
+	p.mutex.Lock()
+	mid := p.messageid
+	p.mutex.Unlock()
+
+	if mid == "" || r.MessageID != mid || r.UserID == s.State.User.ID {
+		return
+	}
+
+	// Clear the reaction so that the button can be pressed again
+	s.MessageReactionRemove(r.ChannelID, r.MessageID, r.Emoji.Name, r.UserID)
+
+	var act *panelAction
+	for i := range panelActions {
+		if panelActions[i].emoji == r.Emoji.Name {
+			act = &panelActions[i]
+		}
+	}
+
+	if act == nil {
+		return
+	}
+
+	member, authlvl, err := b.memberAuthLevel(s, r.GuildID, r.UserID)
+	if err != nil {
+		logger.LogError(b, "Discordgo: "+err.Error())
+		return
+	}
+
+	if !b.config.CommandAllowed(r.UserID, authlvl, act.cmnd) {
+		logger.LogWarning(b, fmt.Sprintf(
+			"%s attempted to use the control panel action %s without authorization",
+			member.User.String(), act.label))
+		return
+	}
+
+	if act.run == nil {
+		select {
+		case p.refresh <- struct{}{}:
+		default:
+		}
+		return
+	}
+
+	p.mutex.Lock()
+	if p.busy {
+		p.mutex.Unlock()
+		logger.LogInfo(b, "Ignoring control panel action while another is running")
+		return
+	}
+	p.busy = true
+	now := time.Now().In(b.config.Location())
+	p.last = fmt.Sprintf("%s by %s at %s %s", act.label, member.User.String(),
+		b.config.Locale().Clock(now, now.Location()), now.Format("MST"))
+	p.mutex.Unlock()
+
+	logger.LogInfo(b, fmt.Sprintf("%s used the control panel to %s the server",
+		member.User.String(), act.label))
+	b.sendLog(fmt.Sprintf(noticePanelAction, member.User.Mention(), act.label))
+
+	go func() {
+		defer shutdown.Recover()
+		defer func() {
+			p.mutex.Lock()
+			p.busy = false
+			p.mutex.Unlock()
+
+			select {
+			case p.refresh <- struct{}{}:
+			default:
+			}
+		}()
+
+		if err := act.run(gs); err != nil {
+			logger.LogError(b, "Avorion: "+err.Error())
+			b.sendLog(fmt.Sprintf(noticePanelFailed, act.label, err.Error()))
+		}
+	}()
+}
+
+// sendLog posts a message to the log channel by way of the event bus
+func (b *Bot) sendLog(msg string) {
+	if !b.bus.Publish(ifaces.EventTopicLog,
+		ifaces.ChatData{Name: "Discord", Msg: msg}, time.Second*5) {
+		logger.LogWarning(b, "Failed to send control panel log message")
+	}
+}
+
+// memberAuthLevel returns a guild member along with the highest auth level of
+// their roles
+func (b *Bot) memberAuthLevel(s *discordgo.Session, gid,
+	uid string) (*discordgo.Member, int, error) {
+	member, err := s.GuildMember(gid, uid)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	authlvl := 0
+	for _, role := range member.Roles {
+		if l := b.config.GetRoleAuth(role); l > authlvl {
+			authlvl = l
+		}
+	}
+
+	return member, authlvl, nil
+}
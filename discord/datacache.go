@@ -64,6 +64,11 @@ func (d *DataCache) UpdateCache(s *discordgo.Session, gs ifaces.IGameServer) {
 
 	newnamecache := make(map[string]map[string]string, 0)
 	newcolorcache := make(map[string]map[string]CachedColor, 0)
+	newrolecache := make(map[string][]string, 0)
+
+	// Integrations are only checked against a complete member list, since a
+	//	failed fetch would make every member look like they had left
+	complete := true
 
 GUILDGET:
 	for gid, doupdate := range d.guildcache {
@@ -104,6 +109,7 @@ GUILDGET:
 		guildroles, err = s.GuildRoles(gid)
 		if err != nil {
 			logger.LogError(d, "discordgo (*Session).GuildRoles: ")
+			complete = false
 			continue GUILDGET
 		}
 
@@ -114,6 +120,7 @@ GUILDGET:
 			_members, err := s.GuildMembers(gid, last, 1000)
 			if err != nil {
 				logger.LogError(d, "discordgo (*Session).GuildMembers: "+err.Error())
+				complete = false
 				continue GUILDGET
 			}
 
@@ -138,6 +145,7 @@ GUILDGET:
 				name = pname
 			}
 
+			newrolecache[m.User.ID] = append(newrolecache[m.User.ID], m.Roles...)
 			userroles := make([]*discordgo.Role, 0)
 
 			for _, rid := range m.Roles {
@@ -205,6 +213,18 @@ GUILDGET:
 	logger.LogDebug(d, `UpdateCache() unlocked`)
 	d.mutex.name.Unlock()
 	d.mutex.color.Unlock()
+
+	if gs != nil {
+		gs.SetMemberRoles(newrolecache)
+
+		if complete && len(newrolecache) > 0 {
+			members := make(map[string]bool, len(newrolecache))
+			for uid := range newrolecache {
+				members[uid] = true
+			}
+			gs.CheckIntegrations(members)
+		}
+	}
 }
 
 // GetName returns the cached nickname for that guildmember
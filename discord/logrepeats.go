@@ -0,0 +1,88 @@
+package discord
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// Identical log events within this long of the first one are collapsed
+	// into its message
+	logRepeatWindow = time.Minute
+
+	// How often the repeat counters of collapsed messages are updated, so that
+	// a flood of events doesn't turn into a flood of edits
+	logRepeatFlush = 5 * time.Second
+)
+
+type logRepeat struct {
+	cid   string
+	mid   string
+	embed *discordgo.MessageEmbed
+	first time.Time
+	count int
+	dirty bool
+}
+
+// logRepeats collapses identical log events into the message that was posted
+// for the first of them, which keeps a count of the repeats instead of
+// flooding the log channel and running into Discord's rate limits
+type logRepeats struct {
+	repeats map[string]*logRepeat
+}
+
+func newLogRepeats() *logRepeats {
+	return &logRepeats{repeats: make(map[string]*logRepeat)}
+}
+
+// repeat counts an event against a recent identical one, and returns false if
+// there isn't one and the event has to be posted
+func (r *logRepeats) repeat(s *discordgo.Session, key string) bool {
+	lr, ok := r.repeats[key]
+	if !ok {
+		return false
+	}
+
+	if time.Since(lr.first) > logRepeatWindow {
+		r.update(s, lr)
+		delete(r.repeats, key)
+		return false
+	}
+
+	lr.count++
+	lr.dirty = true
+	return true
+}
+
+// track records a posted event so that identical events can be collapsed into
+// its message
+func (r *logRepeats) track(key string, m *discordgo.Message,
+	embed *discordgo.MessageEmbed) {
+	r.repeats[key] = &logRepeat{cid: m.ChannelID, mid: m.ID, embed: embed,
+		first: time.Now(), count: 1}
+}
+
+// flush updates the repeat counter of any message that has collapsed events
+// since the last flush, and forgets events that are too old to collapse into
+func (r *logRepeats) flush(s *discordgo.Session) {
+	for key, lr := range r.repeats {
+		r.update(s, lr)
+		if time.Since(lr.first) > logRepeatWindow {
+			delete(r.repeats, key)
+		}
+	}
+}
+
+// update edits the repeat counter into a message if it has changed
+func (r *logRepeats) update(s *discordgo.Session, lr *logRepeat) {
+	if !lr.dirty {
+		return
+	}
+
+	lr.dirty = false
+	lr.embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: fmt.Sprintf("x%d in the last minute", lr.count)}
+	s.ChannelMessageEditEmbed(lr.cid, lr.mid, lr.embed)
+}
@@ -0,0 +1,80 @@
+package discord
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// A burst of events is considered over once its thread has been quiet for
+	// this long, and the next event starts a new thread
+	logThreadIdle = 15 * time.Minute
+
+	// Minutes of inactivity before Discord archives a thread
+	logThreadArchive = 60
+
+	logThreadNameLen = 100
+)
+
+type logThread struct {
+	id     string
+	parent string
+	last   time.Time
+}
+
+// logThreads groups related log messages into Discord threads. The first
+// message of a burst is posted to the log channel as usual and a thread is
+// started from it, so that the rest of the burst doesn't bury other events.
+type logThreads struct {
+	threads map[string]*logThread
+}
+
+func newLogThreads() *logThreads {
+	return &logThreads{threads: make(map[string]*logThread)}
+}
+
+// send posts an embed to the thread with the given name, starting the thread
+// if there isn't an active one, and returns the posted message. Messages
+// without a thread name are posted to the channel directly.
+func (t *logThreads) send(s *discordgo.Session, cid, name string,
+	embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
+	if name == "" {
+		return s.ChannelMessageSendEmbed(cid, embed)
+	}
+
+	now := time.Now()
+	for n, th := range t.threads {
+		if now.Sub(th.last) > logThreadIdle {
+			delete(t.threads, n)
+		}
+	}
+
+	// If the thread was deleted or the log channel has since changed, fall
+	// through and start a new one
+	if th, ok := t.threads[name]; ok && th.parent == cid {
+		if m, err := s.ChannelMessageSendEmbed(th.id, embed); err == nil {
+			th.last = now
+			return m, nil
+		}
+	}
+	delete(t.threads, name)
+
+	m, err := s.ChannelMessageSendEmbed(cid, embed)
+	if err != nil {
+		return nil, err
+	}
+
+	title := name
+	if r := []rune(title); len(r) > logThreadNameLen {
+		title = string(r[:logThreadNameLen])
+	}
+
+	ch, err := s.MessageThreadStart(cid, m.ID, title, logThreadArchive)
+	if err != nil {
+		return m, err
+	}
+
+	t.threads[name] = &logThread{id: ch.ID, parent: cid, last: now}
+	return m, nil
+}
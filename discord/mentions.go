@@ -0,0 +1,68 @@
+package discord
+
+import (
+	"avorioncontrol/ifaces"
+	"regexp"
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Names shorter than this are too likely to appear in normal conversation to
+// be converted to mentions
+const mentionMinLength = 3
+
+var reUserMention = regexp.MustCompile(`<@!?(\d+)>`)
+
+// mentionPlayers converts the names of integrated players in a message from
+// the game into Discord mentions. The speaker is never mentioned, and names
+// are only matched as whole words.
+func mentionPlayers(gs ifaces.IGameServer, msg, speaker string) string {
+	players := make([]ifaces.IPlayer, 0)
+	for _, p := range gs.Players() {
+		if p.DiscordUID() != "" && p.Name() != speaker &&
+			len(p.Name()) >= mentionMinLength {
+			players = append(players, p)
+		}
+	}
+
+	// Match longer names first, so that a name containing another is preferred
+	sort.Slice(players, func(i, j int) bool {
+		return len(players[i].Name()) > len(players[j].Name())
+	})
+
+	for _, p := range players {
+		re, err := regexp.Compile(`(?i)(^|[^\w@])@?` + regexp.QuoteMeta(p.Name()) +
+			`($|[^\w])`)
+		if err != nil {
+			continue
+		}
+		msg = re.ReplaceAllString(msg, "${1}<@"+p.DiscordUID()+">${2}")
+	}
+
+	return msg
+}
+
+// plainMentions converts the Discord mentions in a message into names that
+// make sense in-game. Integrated users are named after their player, and
+// everyone else by their name in the guild.
+func plainMentions(s *discordgo.Session, m *discordgo.MessageCreate,
+	gs ifaces.IGameServer, cache *DataCache) string {
+	return reUserMention.ReplaceAllStringFunc(m.Content, func(mention string) string {
+		uid := reUserMention.FindStringSubmatch(mention)[1]
+		if p := gs.PlayerFromDiscord(uid); p != nil {
+			return "@" + p.Name()
+		}
+
+		if name, ok := cache.GetName(s, m.GuildID, uid); ok {
+			return "@" + name
+		}
+
+		for _, u := range m.Mentions {
+			if u.ID == uid {
+				return "@" + u.Username
+			}
+		}
+		return mention
+	})
+}
@@ -0,0 +1,131 @@
+package discord
+
+import (
+	"avorioncontrol/discord/commands"
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Guilds that the bot joined within this window are treated as new invites.
+// Discord sends a guild create event for every guild when the bot connects,
+// so this keeps the setup message from being posted on every start.
+const onboardingWindow = 10 * time.Minute
+
+// onboardingChannels are the channels that the bot needs, with the command
+// that configures each and the words used to suggest an existing channel
+var onboardingChannels = []struct {
+	command  string
+	purpose  string
+	keywords []string
+}{
+	{"setchatchannel", "relay in-game chat", []string{"chat", "game", "avorion"}},
+	{"setlogchannel", "post server events", []string{"log", "event", "admin"}},
+	{"setstatuschannel", "show the server status", []string{"status", "server"}}}
+
+// onboardingStatusMode is the status channel mode suggested to new guilds
+const onboardingStatusMode = "public"
+
+// onGuildCreate registers a guild that the bot hasn't seen yet, and posts the
+// setup message if the bot was just invited to it
+func (b *Bot) onGuildCreate(s *discordgo.Session, g *discordgo.GuildCreate,
+	gs ifaces.IGameServer, cache *DataCache) {
+	if g.Guild == nil || g.Unavailable {
+		return
+	}
+
+	if _, err := commands.Registrar(g.ID); err != nil {
+		onGuildJoin(g.ID, s, b, gs, cache)
+	}
+
+	joined, err := g.JoinedAt.Parse()
+	if err != nil || time.Since(joined) > onboardingWindow {
+		return
+	}
+
+	logger.LogInfo(b, "Joined a new guild: "+g.Name)
+	b.postOnboarding(s, g.Guild)
+}
+
+// postOnboarding sends the setup message for a new guild to its system
+// channel, or its first text channel. If the bot can't post in either, the
+// message is sent to the guild owner instead.
+func (b *Bot) postOnboarding(s *discordgo.Session, g *discordgo.Guild) {
+	msg := onboardingMessage(b.config.Prefix(), g.Channels)
+
+	cid := g.SystemChannelID
+	if cid == "" {
+		for _, ch := range g.Channels {
+			if ch.Type == discordgo.ChannelTypeGuildText {
+				cid = ch.ID
+				break
+			}
+		}
+	}
+
+	if cid != "" {
+		if _, err := s.ChannelMessageSend(cid, msg); err == nil {
+			return
+		}
+	}
+
+	ch, err := s.UserChannelCreate(g.OwnerID)
+	if err == nil {
+		_, err = s.ChannelMessageSend(ch.ID, msg)
+	}
+
+	if err != nil {
+		logger.LogError(b, "Failed to send the setup message: "+err.Error())
+	}
+}
+
+// onboardingMessage builds the setup message for a new guild, suggesting
+// existing channels for the bots output where their names fit
+func onboardingMessage(prefix string, channels []*discordgo.Channel) string {
+	lines := []string{
+		"**Thanks for adding the Avorion bot!**",
+		"Commands start with " + prefix + ", for example `help` lists every command.",
+		"",
+		"**Getting started:**"}
+
+	for _, oc := range onboardingChannels {
+		cid, hint := "<channel id>", ""
+		if ch := suggestChannel(channels, oc.keywords); ch != nil {
+			cid, hint = ch.ID, " _(#"+ch.Name+")_"
+		}
+
+		if oc.command == "setstatuschannel" {
+			cid += " " + onboardingStatusMode
+		}
+
+		lines = append(lines, fmt.Sprintf("• To %s: %s %s %s%s", oc.purpose,
+			prefix, oc.command, cid, hint))
+	}
+
+	lines = append(lines,
+		"• To set the timezone used in output: "+prefix+" settimezone <timezone>",
+		"• To let a role use staff commands: "+prefix+" admin addrole <role id> <level>",
+		"",
+		"_Run_ "+prefix+" help <command> _for examples of each command._")
+
+	return strings.Join(lines, "\n")
+}
+
+// suggestChannel returns the first text channel whose name contains one of
+// the given keywords
+func suggestChannel(channels []*discordgo.Channel,
+	keywords []string) *discordgo.Channel {
+	for _, kw := range keywords {
+		for _, ch := range channels {
+			if ch.Type == discordgo.ChannelTypeGuildText &&
+				strings.Contains(strings.ToLower(ch.Name), kw) {
+				return ch
+			}
+		}
+	}
+	return nil
+}
@@ -2,7 +2,9 @@ package discord
 
 import (
 	"avorioncontrol/ifaces"
+	"avorioncontrol/locale"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -14,121 +16,240 @@ var (
 	galaxyFieldTemplate    string
 	configOneFieldTemplate string
 	configTwoFieldTemplate string
+	publicFieldTemplate    string
+
+	// statusFieldPresets are the built-in fields that status embed layouts can
+	// use, other than the restart and maintenance fields which are only shown
+	// when they apply
+	statusFieldPresets map[string]embedPreset
 )
 
 const (
 	authorName = "@SleepyFugu#3611"
 	authorIcon = "https://avatars2.githubusercontent.com/u/17704274?s=400&u=3897048ff3956501c2850214d235f5ac6520dd40&v=4"
 	authorURL  = "https://github.com/SleepyFugu"
+
+	// Discord rejects embeds with more fields than this
+	embedMaxFields = 25
 )
 
+type embedPreset struct {
+	name  string
+	value string
+}
+
 func init() {
-	configOneFieldTemplate = "> • **Version**: _%s_\n" +
-		"> • **Seed**: _%s_\n" +
+	configOneFieldTemplate = "> • **Version**: _{version}_\n" +
+		"> • **Seed**: _{seed}_\n" +
 		"> \n" +
-		"> • **Difficulty**: _%s_\n" +
-		"> • **Collision**: _%s_\n" +
-		"> • **PVP**: _%s_\n" +
+		"> • **Difficulty**: _{difficulty}_\n" +
+		"> • **Collision**: _{collision}_\n" +
+		"> • **PVP**: _{pvp}_\n" +
 		"> \n" +
-		"> • **Block Limit**: _%d_\n" +
-		"> • **Volume Limit**: _%d_\n"
+		"> • **Block Limit**: _{block_limit}_\n" +
+		"> • **Volume Limit**: _{volume_limit}_\n"
 
 	configTwoFieldTemplate = "> **_Players_**\n" +
-		"> • **Max Slots**: _%d_\n" +
-		"> • **Max Stations**: _%d_\n" +
-		"> • **Max Ships**: _%d_\n" +
+		"> • **Max Slots**: _{player_slots}_\n" +
+		"> • **Max Stations**: _{player_stations}_\n" +
+		"> • **Max Ships**: _{player_ships}_\n" +
 		"> \n" +
 		"> **_Alliances_**\n" +
-		"> • **Max Slots**: _%d_\n" +
-		"> • **Max Stations**: _%d_\n" +
-		"> • **Max Ships**: _%d_\n"
-
-	galaxyFieldTemplate = "> **Alliances**: _%d_\n" +
-		"> **Total Players**:  _%d_\n" +
-		"> **Total Sectors**:  _%d_\n" +
-		"> **Players Online**: _%d_"
+		"> • **Max Slots**: _{alliance_slots}_\n" +
+		"> • **Max Stations**: _{alliance_stations}_\n" +
+		"> • **Max Ships**: _{alliance_ships}_\n"
+
+	publicFieldTemplate = "> • **Version**: _{version}_\n" +
+		"> • **Difficulty**: _{difficulty}_\n" +
+		"> • **PVP**: _{pvp}_\n"
+
+	galaxyFieldTemplate = "> **Alliances**: _{alliances}_\n" +
+		"> **Total Players**:  _{total}_\n" +
+		"> **Total Sectors**:  _{sectors}_\n" +
+		"> **Players Online**: _{online}_"
+
+	statusFieldPresets = map[string]embedPreset{
+		"state":         {"State", "{status}"},
+		"server_info":   {"Server Info", publicFieldTemplate},
+		"server_config": {"Server Config", configOneFieldTemplate},
+		"player_config": {"Player Config", configTwoFieldTemplate},
+		"galaxy":        {"Galaxy Information", galaxyFieldTemplate}}
 }
 
-func generateEmbedStatus(s ifaces.ServerStatus, tz *time.Location) *discordgo.MessageEmbed {
+// generateEmbedStatus returns a status embed with the fields of the given
+// layout. Built-in fields can have their name and value overridden, and the
+// names and values of all fields can use the placeholders of statusReplacer.
+func generateEmbedStatus(s ifaces.ServerStatus, tc ifaces.ITimeConfigurator,
+	layout []ifaces.EmbedField) *discordgo.MessageEmbed {
 	var (
-		color          int
-		stat           string
-		statusField    *discordgo.MessageEmbedField
-		galaxyField    *discordgo.MessageEmbedField
-		configOneField *discordgo.MessageEmbedField
-		configTwoField *discordgo.MessageEmbedField
-
-		version      = "1"
-		collision    = "1"
-		name         = "Avorion Server"
-		pvp          = true
-		pvpString    = "Enabled"
-		seed         = ""
-		difLevel     = 0
-		blkLimit     = int64(0)
-		volLimit     = int64(0)
-		pMaxSlots    = int64(0)
-		pMaxShips    = int64(0)
-		pMaxStations = int64(0)
-		aMaxSlots    = int64(0)
-		aMaxShips    = int64(0)
-		aMaxStations = int64(0)
+		name        = "Avorion Server"
+		stat, color = ifaces.State(s.Status)
+		fill        = statusReplacer(s, stat, tc.Locale())
 	)
 
-	stat, color = ifaces.State(s.Status)
+	if s.INI != nil {
+		name = s.INI.Name
+	}
 
 	embed := discordgo.MessageEmbed{
 		Type:      discordgo.EmbedTypeRich,
+		Title:     name + " Status",
 		Color:     color,
 		Timestamp: time.Now().Format(time.RFC3339),
 		Fields:    make([]*discordgo.MessageEmbedField, 0)}
 
-	statusField = &discordgo.MessageEmbedField{
-		Inline: false, Value: stat, Name: "State"}
+	embed.Footer = updatedFooter(tc)
+
+	for _, f := range layout {
+		if f.Hidden || len(embed.Fields) == embedMaxFields {
+			continue
+		}
+
+		var field *discordgo.MessageEmbedField
+		switch f.Field {
+		case "restart":
+			field = restartField(s)
+		case "maintenance":
+			field = maintenanceField(s)
+		case "query":
+			field = queryField(s)
+		case "":
+			field = &discordgo.MessageEmbedField{Name: f.Name, Value: f.Value}
+		default:
+			p, ok := statusFieldPresets[f.Field]
+			if !ok {
+				continue
+			}
+
+			field = &discordgo.MessageEmbedField{Name: p.name, Value: p.value}
+			if f.Value != "" {
+				field.Value = f.Value
+			}
+		}
+
+		if field == nil {
+			continue
+		}
+
+		if f.Name != "" {
+			field.Name = f.Name
+		}
+
+		field.Inline = f.Inline
+		field.Name = fill.Replace(field.Name)
+		field.Value = fill.Replace(field.Value)
+
+		// Discord rejects fields without a name or value
+		if strings.TrimSpace(field.Name) == "" ||
+			strings.TrimSpace(field.Value) == "" {
+			continue
+		}
+
+		embed.Fields = append(embed.Fields, field)
+	}
 
-	configOneField = &discordgo.MessageEmbedField{
-		Inline: true, Name: "Server Config", Value: configOneFieldTemplate}
+	return &embed
+}
 
-	configTwoField = &discordgo.MessageEmbedField{
-		Inline: true, Name: "Player Config", Value: configTwoFieldTemplate}
+// statusReplacer returns a replacer for the placeholders that status embed
+// fields can use
+func statusReplacer(s ifaces.ServerStatus, stat string,
+	lc *locale.Locale) *strings.Replacer {
+	var (
+		ini = s.INI
+		max = "?"
+		pvp = "Enabled"
+	)
 
-	if s.INI != nil {
-		version = s.INI.Version
-		collision = s.INI.Collision
-		name = s.INI.Name
-		pvp = s.INI.PVP
-		seed = s.INI.Seed
-		difLevel = s.INI.Difficulty
-		blkLimit = s.INI.BlockLimit
-		volLimit = s.INI.VolumeLimit
-		pMaxSlots = s.INI.MaxPlayerSlots
-		pMaxShips = s.INI.MaxPlayerShips
-		pMaxStations = s.INI.MaxPlayerStations
-		aMaxSlots = s.INI.MaxAllianceSlots
-		aMaxShips = s.INI.MaxAllianceShips
-		aMaxStations = s.INI.MaxAllianceStations
+	// Placeholders fall back to the same values as an unconfigured server
+	if ini == nil {
+		ini = &ifaces.ServerGameConfig{Version: "1", Collision: "1",
+			Name: "Avorion Server", PVP: true}
+	}
+
+	if ini.MaxPlayers > 0 {
+		max = lc.Number(ini.MaxPlayers)
 	}
 
-	if !pvp {
-		pvpString = "Disabled"
+	if !ini.PVP {
+		pvp = "Disabled"
 	}
 
-	embed.Title = name + " Status"
+	return strings.NewReplacer(
+		"{status}", stat,
+		"{name}", ini.Name,
+		"{version}", ini.Version,
+		"{seed}", ini.Seed,
+		"{difficulty}", ifaces.Difficulty(ini.Difficulty),
+		"{collision}", ini.Collision,
+		"{pvp}", pvp,
+		"{block_limit}", lc.Number(ini.BlockLimit),
+		"{volume_limit}", lc.Number(ini.VolumeLimit),
+		"{player_slots}", lc.Number(ini.MaxPlayerSlots),
+		"{player_stations}", lc.Number(ini.MaxPlayerStations),
+		"{player_ships}", lc.Number(ini.MaxPlayerShips),
+		"{alliance_slots}", lc.Number(ini.MaxAllianceSlots),
+		"{alliance_stations}", lc.Number(ini.MaxAllianceStations),
+		"{alliance_ships}", lc.Number(ini.MaxAllianceShips),
+		"{alliances}", lc.Number(int64(s.Alliances)),
+		"{total}", lc.Number(int64(s.TotalPlayers)),
+		"{sectors}", lc.Number(int64(s.Sectors)),
+		"{online}", lc.Number(int64(s.PlayersOnline)),
+		"{max}", max)
+}
 
-	configOneField.Value = fmt.Sprintf(configOneField.Value, version,
-		seed, ifaces.Difficulty(difLevel), collision, pvpString, blkLimit,
-		volLimit)
+// restartField returns an embed field counting down to a pending restart, or
+// nil if no restart is pending. Discord renders the relative timestamp as a
+// live countdown, so the embed doesn't need to be edited every second.
+func restartField(s ifaces.ServerStatus) *discordgo.MessageEmbedField {
+	if s.RestartAt.IsZero() {
+		return nil
+	}
 
-	configTwoField.Value = fmt.Sprintf(configTwoField.Value, pMaxSlots,
-		pMaxStations, pMaxShips, aMaxSlots, aMaxShips, aMaxStations)
+	unix := s.RestartAt.Unix()
+	return &discordgo.MessageEmbedField{
+		Inline: false, Name: "Scheduled Restart",
+		Value: fmt.Sprintf("> Restarting <t:%d:R> _(<t:%d:t>)_", unix, unix)}
+}
 
-	galaxyField = &discordgo.MessageEmbedField{
-		Inline: false, Name: "Galaxy Information", Value: galaxyFieldTemplate}
+// maintenanceField returns an embed field noting that the server is in
+// maintenance mode, or nil if it isn't
+func maintenanceField(s ifaces.ServerStatus) *discordgo.MessageEmbedField {
+	if !s.Maintenance {
+		return nil
+	}
 
-	galaxyField.Value = fmt.Sprintf(galaxyField.Value, s.Alliances,
-		s.TotalPlayers, s.Sectors, s.PlayersOnline)
+	value := "> Only whitelisted players can join"
+	if !s.MaintUntil.IsZero() {
+		unix := s.MaintUntil.Unix()
+		value += fmt.Sprintf("\n> Ends <t:%d:R> _(<t:%d:t>)_", unix, unix)
+	}
 
-	embed.Fields = append(embed.Fields, statusField, configOneField,
-		configTwoField, galaxyField)
-	return &embed
+	return &discordgo.MessageEmbedField{
+		Inline: false, Name: "Maintenance", Value: value}
+}
+
+// updatedFooter returns an embed footer with the last update time in the
+// configured timezone and locale
+func updatedFooter(tc ifaces.ITimeConfigurator) *discordgo.MessageEmbedFooter {
+	return &discordgo.MessageEmbedFooter{
+		Text: "Last updated " + tc.Locale().DateTimeZone(time.Now(), tc.Location())}
+}
+
+// queryField returns an embed field comparing the player count reported by the
+// Steam query port with the tracked count, or nil if the query port hasn't
+// answered
+func queryField(s ifaces.ServerStatus) *discordgo.MessageEmbedField {
+	if !s.QueryOK {
+		return nil
+	}
+
+	value := fmt.Sprintf("> **Steam Query**: _%d/%d_\n> **Tracked**: _%d_",
+		s.QueryPlayers, s.QuerySlots, s.PlayersOnline)
+	if s.QueryPlayers != s.PlayersOnline {
+		value += "\n> ⚠️ _The counts differ, tracking may be out of date_"
+	}
+
+	return &discordgo.MessageEmbedField{
+		Inline: false, Name: "Player Count", Value: value}
 }
@@ -0,0 +1,146 @@
+package discord
+
+import (
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	advisoryRestart = "🔄"
+
+	noticeVersionChanged = "**Server Advisory**: A different version of Avorion " +
+		"has been installed\n**Running:** `%s`\n**Installed:** `%s`\n" +
+		"_React with %s to restart the server onto the new version._"
+	noticeVersionApplied = "**Server Advisory**: Avorion `%s` is now running"
+	noticeAdvisoryAction = "**Server Advisory**: %s restarted the server to " +
+		"apply Avorion `%s`"
+)
+
+// versionAdvisory tracks the advisory message posted for an installed version
+// of Avorion that isn't running yet
+type versionAdvisory struct {
+	mutex     sync.Mutex
+	cid       string
+	messageid string
+	version   string
+	busy      bool
+}
+
+// superviseVersionAdvisory posts an advisory to the log channel when the
+// Avorion binary on disk differs from the running server, and marks it as
+// resolved once the new version is running
+func (b *Bot) superviseVersionAdvisory(s *discordgo.Session,
+	gs ifaces.IGameServer) {
+	defer shutdown.Recover()
+
+	defer b.wg.Done()
+
+	adv := b.advisory
+	for {
+		select {
+		case <-b.exit:
+			return
+
+		case <-time.After(time.Second * 30):
+		}
+
+		stat := gs.Status()
+
+		adv.mutex.Lock()
+		switch {
+		case stat.Installed != "" && stat.Installed != adv.version:
+			if adv.messageid != "" {
+				s.MessageReactionsRemoveAll(adv.cid, adv.messageid)
+			}
+
+			adv.cid, adv.messageid, adv.version = "", "", stat.Installed
+			cid := b.config.LogChannel()
+			if cid == "" {
+				break
+			}
+
+			m, err := s.ChannelMessageSend(cid, fmt.Sprintf(noticeVersionChanged,
+				stat.Version, stat.Installed, advisoryRestart))
+			if err != nil {
+				logger.LogError(b, "Discordgo: "+err.Error())
+				break
+			}
+
+			s.MessageReactionAdd(cid, m.ID, advisoryRestart)
+			adv.cid, adv.messageid = cid, m.ID
+
+		case stat.Installed == "" && adv.version != "" &&
+			stat.Status == ifaces.ServerOnline:
+			if adv.messageid != "" {
+				s.MessageReactionsRemoveAll(adv.cid, adv.messageid)
+				s.ChannelMessageEdit(adv.cid, adv.messageid,
+					fmt.Sprintf(noticeVersionApplied, stat.Version))
+			}
+			adv.cid, adv.messageid, adv.version = "", "", ""
+		}
+		adv.mutex.Unlock()
+	}
+}
+
+// onVersionAdvisoryReact restarts the server when an authorized user reacts
+// to the version advisory
+func (b *Bot) onVersionAdvisoryReact(s *discordgo.Session,
+	r *discordgo.MessageReactionAdd, gs ifaces.IGameServer) {
+	adv := b.advisory
+
+	adv.mutex.Lock()
+	mid, version := adv.messageid, adv.version
+	adv.mutex.Unlock()
+
+	if mid == "" || r.MessageID != mid || r.UserID == s.State.User.ID ||
+		r.Emoji.Name != advisoryRestart {
+		return
+	}
+
+	s.MessageReactionRemove(r.ChannelID, r.MessageID, r.Emoji.Name, r.UserID)
+
+	member, authlvl, err := b.memberAuthLevel(s, r.GuildID, r.UserID)
+	if err != nil {
+		logger.LogError(b, "Discordgo: "+err.Error())
+		return
+	}
+
+	if !b.config.CommandAllowed(r.UserID, authlvl, "server") {
+		logger.LogWarning(b, fmt.Sprintf(
+			"%s attempted to restart from the version advisory without authorization",
+			member.User.String()))
+		return
+	}
+
+	adv.mutex.Lock()
+	if adv.busy {
+		adv.mutex.Unlock()
+		return
+	}
+	adv.busy = true
+	adv.mutex.Unlock()
+
+	logger.LogInfo(b, fmt.Sprintf("%s restarted the server to apply %s",
+		member.User.String(), version))
+	b.sendLog(fmt.Sprintf(noticeAdvisoryAction, member.User.Mention(), version))
+
+	go func() {
+		defer shutdown.Recover()
+		defer func() {
+			adv.mutex.Lock()
+			adv.busy = false
+			adv.mutex.Unlock()
+		}()
+
+		if err := gs.Restart(); err != nil {
+			logger.LogError(b, "Avorion: "+err.Error())
+			b.sendLog(fmt.Sprintf(noticePanelFailed, "Restart", err.Error()))
+		}
+	}()
+}
@@ -0,0 +1,135 @@
+package eventbus
+
+import (
+	"avorioncontrol/ifaces"
+	"sync"
+	"time"
+)
+
+// Bus is a publish/subscribe event bus that passes game and bot events to any
+// number of subscribers. Each subscriber gets its own buffered channel, so a
+// slow consumer can only delay publishers by the timeout given to Publish.
+type Bus struct {
+	mutex sync.RWMutex
+	subs  map[string]map[*subscription]struct{}
+}
+
+// subscription is a single subscriber. Its channel is only closed while
+// holding its mutex, so that Publish can send to it without holding the bus
+// lock. Closing done first wakes up any sends that are waiting on it.
+type subscription struct {
+	mutex  sync.RWMutex
+	ch     chan ifaces.ChatData
+	done   chan struct{}
+	closed bool
+	once   sync.Once
+}
+
+// New returns a new event bus with no subscribers
+func New() *Bus {
+	return &Bus{subs: make(map[string]map[*subscription]struct{})}
+}
+
+// Subscribe returns a channel that receives events published to the given
+// topic, and a function that ends the subscription and closes the channel.
+//	@topic string		Topic to subscribe to
+//	@buffer int		Number of events to buffer for the subscriber
+func (b *Bus) Subscribe(topic string, buffer int) (<-chan ifaces.ChatData, func()) {
+	sub := &subscription{ch: make(chan ifaces.ChatData, buffer),
+		done: make(chan struct{})}
+
+	b.mutex.Lock()
+	if b.subs[topic] == nil {
+		b.subs[topic] = make(map[*subscription]struct{})
+	}
+	b.subs[topic][sub] = struct{}{}
+	b.mutex.Unlock()
+
+	return sub.ch, func() {
+		sub.once.Do(func() {
+			b.mutex.Lock()
+			delete(b.subs[topic], sub)
+			b.mutex.Unlock()
+
+			close(sub.done)
+			sub.mutex.Lock()
+			sub.closed = true
+			close(sub.ch)
+			sub.mutex.Unlock()
+		})
+	}
+}
+
+// Publish sends an event to every subscriber of the given topic, waiting up to
+// timeout in total for subscribers with a full buffer. It returns false if the
+// event was dropped for any subscriber, or if there were none.
+func (b *Bus) Publish(topic string, data ifaces.ChatData,
+	timeout time.Duration) bool {
+	// The subscribers are copied so that a slow subscriber doesn't hold the
+	// lock, which would block Subscribe and unsubscribing until the timeout
+	b.mutex.RLock()
+	subs := make([]*subscription, 0, len(b.subs[topic]))
+	for sub := range b.subs[topic] {
+		subs = append(subs, sub)
+	}
+	b.mutex.RUnlock()
+
+	if len(subs) == 0 {
+		return false
+	}
+
+	var (
+		delivered = true
+		expired   = false
+		deadline  = time.NewTimer(timeout)
+	)
+	defer deadline.Stop()
+
+	for _, sub := range subs {
+		if !sub.send(data, deadline.C, &expired) {
+			delivered = false
+		}
+	}
+
+	return delivered
+}
+
+// send delivers an event to the subscriber, waiting for room in its buffer
+// until the deadline expires. It returns false if the event was dropped, or if
+// the subscriber has unsubscribed.
+func (sub *subscription) send(data ifaces.ChatData, deadline <-chan time.Time,
+	expired *bool) bool {
+	sub.mutex.RLock()
+	defer sub.mutex.RUnlock()
+
+	if sub.closed {
+		return false
+	}
+
+	select {
+	case sub.ch <- data:
+		return true
+	default:
+	}
+
+	if *expired {
+		return false
+	}
+
+	select {
+	case sub.ch <- data:
+		return true
+	case <-sub.done:
+		return false
+	case <-deadline:
+		*expired = true
+		return false
+	}
+}
+
+// Subscribers returns the number of subscribers to a topic
+func (b *Bus) Subscribers(topic string) int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return len(b.subs[topic])
+}
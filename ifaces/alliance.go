@@ -19,5 +19,8 @@ type ITrackedAlliance interface {
 	AddJump(ShipCoordData)
 
 	Update() error
-	UpdateFromData([13]string) error
+	UpdateFromData(AllianceData) error
+
+	Leadership() AllianceLeadership
+	SetLeadership(AllianceLeadership)
 }
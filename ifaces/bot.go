@@ -3,8 +3,11 @@ package ifaces
 // IDiscordBot describes an interface to a discord bot
 type IDiscordBot interface {
 	IBotMentioner
-	IBotChatter
 	IBotStarter
+	IBotAuthorizer
+	IHealthReporter
+
+	SetCore(ICore)
 }
 
 // IBotMentioner describes an interface to a Bot that can provide a
@@ -13,13 +16,13 @@ type IBotMentioner interface {
 	Mention() string
 }
 
+// IBotAuthorizer describes a Bot that can look up the auth level that a
+// 	Discord user has been granted by their roles
+type IBotAuthorizer interface {
+	MemberAuthLevel(string) (int, error)
+}
+
 // IBotStarter describes a bot that can start
 type IBotStarter interface {
 	Start(IGameServer)
 }
-
-// IBotChatter describes an interface to a bot that can chat
-type IBotChatter interface {
-	SetChatPipe(chan ChatData)
-	ChatPipe() chan ChatData
-}
@@ -1,7 +1,10 @@
 package ifaces
 
 import (
+	"avorioncontrol/locale"
 	"avorioncontrol/logger"
+	"net"
+	"regexp"
 	"time"
 )
 
@@ -11,6 +14,7 @@ type IConfigurator interface {
 
 	IDatabaseConfigurator
 	IDiscordConfigurator
+	ICoreConfigurator
 	ICommandConfigurator
 	IGalaxyConfigurator
 	IEventConfigurator
@@ -18,8 +22,12 @@ type IConfigurator interface {
 	IGameConfigurator
 	ITimeConfigurator
 	IChatConfigurator
+	IModerationConfigurator
 	IConfigSaveLoader
 	IModConfigurator
+	IPresetConfigurator
+	IBackupConfigurator
+	IRestartConfigurator
 	logger.ILogger
 }
 
@@ -33,7 +41,21 @@ type IDiscordConfigurator interface {
 	SetBotsAllowed(bool)
 	StatusChannel() (string, bool)
 	SetStatusChannel(string)
+	PublicStatusChannel() (string, bool)
+	SetPublicStatusChannel(string)
+	StatusLayout() []EmbedField
+	PublicStatusLayout() []EmbedField
+	ControlPanelChannel() (string, bool)
+	SetControlPanelChannel(string)
+	ControlPanelMessage() string
+	SetControlPanelMessage(string)
+	InboxChannel() string
+	SetInboxChannel(string)
 	StatusChannelClear() bool
+	StartupQueue() bool
+	PresenceTemplate() string
+	MemberLeaveActions() []string
+	MemberLeaveCommand() string
 }
 
 // IGameConfigurator describes an interface to a games configuration
@@ -43,13 +65,28 @@ type IGameConfigurator interface {
 	DataPath() string
 	RCONAddr() string
 	RCONPass() string
+	GamePort() int
+	QueryPort() int
+	ContestedRegions() []Region
+	PublicAddress() string
+	EventFile() string
 	InstallPath() string
+	SetInstallPath(string)
 	LoadGameConfig() error
 	GameConfig() (*ServerGameConfig, bool)
+	GameSettings() map[string]string
+	GameConfigFile() ([]byte, error)
+	WriteGameConfig([]byte) error
+	GameConfigChanges([]byte) ([]SettingChange, error)
+	WrapperCommand() string
+	GameEnvironment() map[string]string
 	PostUpCommand() string
 	PostDownCommand() string
 	HangTimeDuration() time.Duration
 	DBUpdateTimeDuration() time.Duration
+	JumpAnomalyRate() int64
+	ErrorSummaryDuration() time.Duration
+	JumpRetention() time.Duration
 }
 
 // IGalaxyConfigurator describes an interface to an object that can configure a
@@ -57,6 +94,9 @@ type IGameConfigurator interface {
 type IGalaxyConfigurator interface {
 	SetGalaxy(string)
 	Galaxy() string
+	LockGalaxy(bool)
+	PendingGalaxy() (string, bool)
+	SwitchGalaxy() (string, bool)
 }
 
 // ICommandConfigurator describes an interface to an object that can configure
@@ -69,6 +109,9 @@ type ICommandConfigurator interface {
 	GetAliasedCommand(string) (bool, string)
 	CommandAliases(string) (bool, []string)
 
+	SetAliasTemplate(string, string, string) error
+	AliasTemplate(string, string) (bool, string)
+
 	SetPrefix(string)
 	Prefix() string
 
@@ -78,16 +121,53 @@ type ICommandConfigurator interface {
 
 // IChatConfigurator describes an interface to an object that can configure chats
 type IChatConfigurator interface {
-	ChatPipe() chan ChatData
-	SetChatChannel(string) chan ChatData
+	SetChatChannel(string)
 	ChatChannel() string
 	ReactConfirm() bool
+	AllianceChatRelay() bool
+	ChatMentions() bool
+	ChatLinkAllowlist() []string
+	PlayerMilestones() []int64
+	MilestoneMessages() (string, string)
+	ReturningPlayerAbsence() time.Duration
+	ReturningPlayerMessages() (string, string)
+	VoiceChannel() string
+	VoiceAnnounces(string) bool
+}
+
+// IModerationConfigurator describes an interface to the chat moderation policy
+type IModerationConfigurator interface {
+	ChatFilter() []*regexp.Regexp
+	ModerationPolicy() []string
+	ModerationWindow() time.Duration
+	MuteDuration() time.Duration
+	TempBanDuration() time.Duration
+
+	IdleKickDuration() time.Duration
+	IdleKickFreeSlots() int64
+	IdleKickExemptRoles() []string
+	IdleKickExemptTags() []string
+
+	RestartVoteQuorum() int64
+	RestartVoteMinPlayers() int64
+	RestartVoteDuration() time.Duration
+	RestartVoteCooldown() time.Duration
+
+	MaintenanceWhitelist() []string
+	MaintenanceRoles() []string
+	MaintenanceMOTD() string
+
+	IntegrationPinAttempts() int64
+	IntegrationPinLockout() time.Duration
 }
 
 // ITimeConfigurator describes an interface to the configured timezone
 type ITimeConfigurator interface {
 	TimeZone() string
 	SetTimeZone(string) error
+	Location() *time.Location
+	Locale() *locale.Locale
+	SetLocale(string) error
 }
 
 // IAuthConfigurator describes an interface to an authorization object
@@ -99,6 +179,11 @@ type IAuthConfigurator interface {
 	AddCmndAuth(string, int)
 	GetCmndAuth(string) int
 	RemoveCmndAuth(string) error
+
+	SetUserCmndAuth(string, string, bool)
+	RemoveUserCmndAuth(string, string) error
+	UserCmndAuth() map[string]map[string]bool
+	CommandAllowed(string, int, string) bool
 }
 
 // IConfigSaveLoader describes an interface to a an object that saves
@@ -113,21 +198,68 @@ type IDatabaseConfigurator interface {
 	DBName() string
 }
 
+// ICoreConfigurator describes an interface to the bots own configuration
+type ICoreConfigurator interface {
+	HealthAddress() string
+	TLSFiles() (string, string)
+	AutocertDomains() ([]string, string)
+	TrustedProxies() []*net.IPNet
+	APIAuthEnabled() bool
+	APITokenScopes(string) ([]string, bool)
+	APIUser(string) (APIUser, bool)
+	APIOAuth() (APIOAuth, bool)
+	APIPlaceholders() []string
+	PProfEnabled() bool
+	ExportPath() string
+	UpdateSource() (string, string)
+	AutoUpdate() bool
+	LogFile() string
+}
+
+// IPresetConfigurator describes an interface to an object that can apply named
+//	configuration presets
+type IPresetConfigurator interface {
+	Presets() []string
+	PresetChanges(string) ([]SettingChange, error)
+	ApplyPreset(string) error
+}
+
+// IBackupConfigurator describes an interface to the configuration of galaxy
+//	backups
+type IBackupConfigurator interface {
+	BackupPath() string
+	BackupInterval() time.Duration
+	BackupOnShutdown() bool
+	BackupRetention() (int, time.Duration)
+	BackupCompress() bool
+}
+
+// IRestartConfigurator describes an interface to the configuration of
+//	scheduled restarts
+type IRestartConfigurator interface {
+	RestartSchedule() []string
+}
+
 // IModConfigurator describes an interface to a modconfig builder
 type IModConfigurator interface {
 	BuildModConfig() error
-	AddServerMod(int64) error
+	AddServerMod(int64) (string, error)
 	RemoveServerMod(int64) error
-	AddClientMod(int64) error
+	AddClientMod(int64) (string, error)
 	RemoveClientMod(int64) error
 	ListServerMods() []int64
 	ListClientMods() []int64
+	SearchWorkshop(string, int) ([]WorkshopItem, int, error)
+	CompanionModPath() string
+	DeployCompanionMod() (ModDeployment, error)
+	SteamCMD() string
+	ModUpdateCheck() (time.Duration, bool)
+	WorkshopUpdateTimes([]int64) (map[int64]time.Time, error)
 }
 
 // IEventConfigurator describes a configuration object that has LoggedServerEvents
 type IEventConfigurator interface {
 	GetEvents() []*LoggedServerEvent
-	LogPipe() chan ChatData
-	SetLogChannel(string) chan ChatData
+	SetLogChannel(string)
 	LogChannel() string
 }
@@ -0,0 +1,19 @@
+package ifaces
+
+import "time"
+
+// ICore describes an interface to the object that coordinates the bot and the
+//	game server
+type ICore interface {
+	Version() string
+	Uptime() time.Duration
+	Stats() CoreStats
+	Health() []SubsystemHealth
+	CheckUpdate() (string, bool, error)
+	SelfUpdate() (string, error)
+}
+
+// IHealthReporter describes a subsystem that can report on its own health
+type IHealthReporter interface {
+	Health() SubsystemHealth
+}
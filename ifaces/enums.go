@@ -39,6 +39,42 @@ const (
 	CommandFailure = 1
 	CommandWarning = 2
 
+	ChatKindGlobal   = 0
+	ChatKindEmote    = 1
+	ChatKindAlliance = 2
+
+	ChatSourceGame    = "game"
+	ChatSourceDiscord = "discord"
+
+	ModerationWarn    = "warn"
+	ModerationMute    = "mute"
+	ModerationKick    = "kick"
+	ModerationTempBan = "tempban"
+	ModerationBan     = "ban"
+
+	MemberLeaveUnlink  = "unlink"
+	MemberLeaveCommand = "command"
+	MemberLeaveReview  = "review"
+
+	CommandPriorityHealth     = 0
+	CommandPriorityUser       = 1
+	CommandPriorityBackground = 2
+
+	BackupManual     = "manual"
+	BackupScheduled  = "scheduled"
+	BackupShutdown   = "shutdown"
+	BackupPreRestore = "prerestore"
+
+	ActionRestart = "restart"
+	ActionBackup  = "backup"
+
+	VoiceRestart = "restart"
+
+	SearchPlayer   = "player"
+	SearchAlliance = "alliance"
+	SearchShip     = "ship"
+	SearchSector   = "sector"
+
 	difficultyBeginner = -3
 	difficultyEasy     = -2
 	difficultyNormal   = -1
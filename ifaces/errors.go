@@ -0,0 +1,58 @@
+package ifaces
+
+import "errors"
+
+// Errors returned by IGameServer implementations and their tracking databases.
+// They are usually wrapped with more detail, so compare them using errors.Is
+// rather than by their message.
+var (
+	// ErrServerOffline is returned when an action requires the game to be up
+	ErrServerOffline = errors.New("server is not online")
+
+	// ErrPlayerNotFound is returned when a player can't be found
+	ErrPlayerNotFound = errors.New("player not found")
+
+	// ErrRCONTimeout is returned when an RCON command couldn't be run in time,
+	// either because the queue was backed up or the game didn't answer
+	ErrRCONTimeout = errors.New("timed out waiting for RCON")
+
+	// ErrOperationInProgress is returned when the server is asked to start,
+	// stop, or restart while another of those operations is still running
+	ErrOperationInProgress = errors.New("another lifecycle operation is in progress")
+
+	// ErrDataUnavailable is returned when tracking data is requested before the
+	// tracking database has been opened
+	ErrDataUnavailable = errors.New("tracking data is not available yet")
+
+	// ErrInvalidPin is returned when an integration PIN doesn't match a request
+	ErrInvalidPin = errors.New("invalid integration pin")
+
+	// ErrPinLocked is returned when a Discord user has entered too many wrong
+	// integration PINs and has to wait before trying again
+	ErrPinLocked = errors.New("too many failed integration attempts")
+)
+
+// ErrorMessage returns a message for an error that is suitable for showing to
+// users. Errors that aren't one of the above are returned as they are.
+func ErrorMessage(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrServerOffline):
+		return "The server is offline, please try again once it has started"
+	case errors.Is(err, ErrRCONTimeout):
+		return "The server is busy and didn't respond in time, please try again shortly"
+	case errors.Is(err, ErrPlayerNotFound):
+		return "That player could not be found"
+	case errors.Is(err, ErrOperationInProgress):
+		return "The server is already starting, stopping, or restarting, please " +
+			"wait for that to finish"
+	case errors.Is(err, ErrDataUnavailable):
+		return "Tracking data isn't available until the server has started"
+	case errors.Is(err, ErrInvalidPin):
+		return "That integration PIN isn't valid"
+	case errors.Is(err, ErrPinLocked):
+		return "Too many failed integration attempts, please try again later"
+	}
+	return err.Error()
+}
@@ -0,0 +1,29 @@
+package ifaces
+
+import "time"
+
+// Topics published to the event bus
+const (
+	// EventTopicChat carries chat that should be relayed to Discord
+	EventTopicChat = "chat"
+
+	// EventTopicLog carries game events that should be logged to Discord
+	EventTopicLog = "log"
+
+	// EventTopicInbox carries private messages from players to the staff
+	EventTopicInbox = "inbox"
+
+	// EventTopicAlliance carries alerts for the channels linked to alliances
+	EventTopicAlliance = "alliance"
+
+	// EventTopicVoice carries short announcements for the voice channel
+	EventTopicVoice = "voice"
+)
+
+// IEventBus describes a publish/subscribe bus that carries events between the
+// game server, the bot, and any other consumers
+type IEventBus interface {
+	Publish(string, ChatData, time.Duration) bool
+	Subscribe(string, int) (<-chan ChatData, func())
+	Subscribers(string) int
+}
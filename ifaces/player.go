@@ -3,6 +3,7 @@ package ifaces
 import (
 	"avorioncontrol/logger"
 	"net"
+	"time"
 )
 
 // IPlayer describes a an IGameServer player
@@ -11,6 +12,7 @@ type IPlayer interface {
 	IModeratablePlayer
 	ITrackedPlayer
 	ISteamPlayer
+	IPrivatePlayer
 
 	INetPlayer
 }
@@ -24,7 +26,7 @@ type ITrackedPlayer interface {
 	AddJump(ShipCoordData)
 
 	Update() error
-	UpdateFromData([15]string) error
+	UpdateFromData(PlayerData) error
 }
 
 // INetPlayer describes a an interface to a player that can connect
@@ -35,6 +37,9 @@ type INetPlayer interface {
 
 	Online() bool
 	SetOnline(bool)
+
+	LastActive() time.Time
+	SetActive()
 }
 
 // IModeratablePlayer describes an interface to a player that can be
@@ -51,6 +56,13 @@ type IDiscordIntegratedPlayer interface {
 	SetDiscordUID(string)
 }
 
+// IPrivatePlayer describes an interface to a player that can opt out of the
+//	public display of their tracking data
+type IPrivatePlayer interface {
+	Private() bool
+	SetPrivate(bool) error
+}
+
 // ISteamPlayer describes an interface to a player that has a SteamID
 type ISteamPlayer interface {
 	SteamUID() int64
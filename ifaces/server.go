@@ -2,6 +2,7 @@ package ifaces
 
 import (
 	"avorioncontrol/logger"
+	"time"
 )
 
 // IGameServer describes an interface to a server with full capability
@@ -14,6 +15,30 @@ type IGameServer interface {
 	IPlayableServer
 	IVersionedServer
 	ICommandableServer
+	IScheduledServer
+	IMaintainableServer
+	IMigratableServer
+	IReapableServer
+	IChatLoggedServer
+	ICommandStatsServer
+	ICombatStatsServer
+	ISectorActivityServer
+	IDonationServer
+	IStaffInboxServer
+	IModeratedServer
+	IBanServer
+	IVotingServer
+	IShipRegistryServer
+	IExportableServer
+	IScriptErrorServer
+	IHealthReporter
+	IDatabaseServer
+	IAllianceAlertServer
+	IGameConfigHistoryServer
+	IBackupServer
+	IIntegrationAuditServer
+	IExitHistoryServer
+	ISearchableServer
 	IDiscordIntegratedServer
 }
 
@@ -37,13 +62,14 @@ type IServer interface {
 // IGalaxyServer describes an interface to a server with a sectored galaxy
 type IGalaxyServer interface {
 	Sector(int, int) *Sector
+	SetSectorFaction(int, int, int, string)
 }
 
 // IPlayableServer defines an object that can track the players that have joined
 type IPlayableServer interface {
 	Players() []IPlayer
 	RemovePlayer(string)
-	NewPlayer(string, []string) IPlayer
+	NewPlayer(string) IPlayer
 
 	Player(string) IPlayer
 	PlayerFromName(string) IPlayer
@@ -52,10 +78,12 @@ type IPlayableServer interface {
 	Alliance(string) IAlliance
 	AllianceFromName(string) IAlliance
 	Alliances() []IAlliance
-	NewAlliance(string, []string) IAlliance
+	NewAlliance(string) IAlliance
 
 	AddPlayerOnline()
 	SubPlayerOnline()
+
+	RefreshPlayerDatabase(func(PlayerDBProgress)) (PlayerDBProgress, error)
 }
 
 // IVersionedServer describes an interface to an IGameserver's version information
@@ -74,6 +102,184 @@ type ILockableServer interface {
 //	game commands
 type ICommandableServer interface {
 	RunCommand(string) (string, error)
+	RunCommandPriority(string, int) (string, error)
+	CommandQueueDepth() []int
+}
+
+// IScheduledServer describes an interface to an IGameServer that runs actions
+//	on a schedule
+type IScheduledServer interface {
+	Schedule() []ScheduledAction
+	ScheduleRestart(time.Duration)
+	CancelRestart() bool
+	PendingRestart() (time.Time, bool)
+	ScheduleAction(string, time.Time, string) (OneShotAction, error)
+	OneShotActions() []OneShotAction
+	CancelAction(int64) error
+}
+
+// IMaintainableServer describes an interface to an IGameServer that can be put
+//	into a whitelist-only maintenance mode
+type IMaintainableServer interface {
+	StartMaintenance(time.Duration)
+	EndMaintenance() bool
+	Maintenance() (time.Time, bool)
+}
+
+// IMigratableServer describes an interface to an IGameServer that can be moved
+//	to a different game installation
+type IMigratableServer interface {
+	InstallVersion(string) (string, error)
+	MigrateInstall(string) error
+	SwitchGalaxy() (string, error)
+}
+
+// IReapableServer describes an interface to an IGameServer that can terminate
+//	game processes that were left behind by a previous run
+type IReapableServer interface {
+	ReapOrphan() (int, error)
+}
+
+// IChatLoggedServer describes an interface to an IGameServer that keeps a
+//	searchable history of bridged chat messages
+type IChatLoggedServer interface {
+	RecordChat(ChatData, string)
+	SearchChat(string, string, time.Time, int) ([]ChatRecord, error)
+}
+
+// ICommandStatsServer describes an interface to an IGameServer that keeps
+//	usage statistics for bot commands
+type ICommandStatsServer interface {
+	RecordCommand(string, string, bool)
+	CommandStats(string) ([]CommandStat, error)
+}
+
+// ICombatStatsServer describes an interface to an IGameServer that keeps
+//	statistics on the ships that have been destroyed
+type ICombatStatsServer interface {
+	CombatStats(int) (CombatStats, error)
+}
+
+// ISectorActivityServer describes an interface to an IGameServer that keeps
+//	track of how busy its sectors have been
+type ISectorActivityServer interface {
+	SectorActivity(time.Time) ([]SectorActivity, error)
+}
+
+// IStaffInboxServer describes an interface to a server that relays private
+//	messages between players and the staff
+type IStaffInboxServer interface {
+	RecordStaffMessage(string, string)
+	ReplyStaffMessage(int64, string, string) (StaffMessage, error)
+}
+
+// IDonationServer describes an interface to an IGameServer that tracks what
+//	players donate to their alliances
+type IDonationServer interface {
+	RecordDonation(string, string, string, int64)
+	AllianceDonations(string) ([]DonationStat, error)
+}
+
+// IModeratedServer describes an interface to an IGameServer that moderates
+//	player chat
+type IModeratedServer interface {
+	ModerateChat(ChatData) bool
+}
+
+// IBanServer describes an interface to an IGameServer that records the bans
+//	that it issues, and lifts temporary bans once they expire
+type IBanServer interface {
+	BanPlayer(IPlayer, string, string, time.Duration) (BanRecord, error)
+	UnbanPlayer(string) (BanRecord, error)
+	Bans() ([]BanRecord, error)
+}
+
+// IVotingServer describes an interface to an IGameServer that lets players
+//	vote on server actions in-game
+type IVotingServer interface {
+	PlayerVote(string, string, string)
+}
+
+// IShipRegistryServer describes an interface to an IGameServer that keeps a
+//	registry of the ships owned by players and alliances
+type IShipRegistryServer interface {
+	TrackShip(string, ShipCoordData)
+	FindShips(string) ([]ShipRecord, error)
+}
+
+// IDatabaseServer describes an interface to an IGameServer whose tracking
+//	database can be rebuilt if it becomes unavailable
+type IDatabaseServer interface {
+	DBDegraded() string
+	RebuildDB() error
+	CloseDB()
+	TrackedGalaxies() ([]TrackedGalaxy, error)
+	PurgeGalaxy(string) error
+}
+
+// IAllianceAlertServer describes an interface to an IGameServer that alerts
+//	alliances of ship jumps into contested regions and their claimed sectors
+type IAllianceAlertServer interface {
+	AllianceAlerts(string) (AllianceAlerts, bool)
+	SetAllianceAlerts(AllianceAlerts) error
+	ClaimSector(string, int, int, bool) error
+}
+
+// IGameConfigHistoryServer describes an interface to an IGameServer that keeps
+//	a history of the changes made to server.ini
+type IGameConfigHistoryServer interface {
+	RecordGameConfig(string, string) (int64, error)
+	GameConfigHistory(int) ([]GameConfigVersion, error)
+	GameConfigVersion(int64) (GameConfigVersion, error)
+	RevertGameConfig(int64, string) (int64, error)
+}
+
+// IIntegrationAuditServer describes an interface to an IGameServer that can
+//	find and remove the integrations of Discord users that have left
+type IIntegrationAuditServer interface {
+	CheckIntegrations(map[string]bool)
+	OrphanedIntegrations() []OrphanedIntegration
+	PruneIntegrations() (int, error)
+}
+
+// ISearchableServer describes an interface to an IGameServer that can search
+//	its tracked data by name. Players that the visible function rejects don't
+//	have their ships' locations included.
+type ISearchableServer interface {
+	Search(string, func(IPlayer) bool) ([]SearchResult, error)
+}
+
+// IExitHistoryServer describes an interface to an IGameServer that keeps track
+//	of how its process exited
+type IExitHistoryServer interface {
+	ExitHistory(int) ([]ServerExit, error)
+}
+
+// IBackupServer describes an interface to an IGameServer that can back up and
+//	restore its galaxy
+type IBackupServer interface {
+	Backup(string) (BackupInfo, error)
+	Backups() ([]BackupInfo, error)
+	RestoreBackup(string) error
+}
+
+// IExportableServer describes an interface to an IGameServer that can export
+//	its tracked data
+type IExportableServer interface {
+	ExportData(string, string) ([]string, [][]string, error)
+}
+
+// IScriptErrorServer describes an interface to an IGameServer that aggregates
+//	the script errors raised by the game
+type IScriptErrorServer interface {
+	RecordScriptError(string, string)
+}
+
+// ILoadTestableServer describes an interface to an IGameServer that can inject
+//	synthetic output into its event pipeline. This is a developer tool, and so
+//	is not part of IGameServer.
+type ILoadTestableServer interface {
+	LoadTest(int, time.Duration) LoadTestResult
 }
 
 // IMOTDServer describes an interface to a server that can set an MOTD
@@ -92,7 +298,11 @@ type ISeededServer interface {
 //	with Discord
 type IDiscordIntegratedServer interface {
 	AddIntegrationRequest(string, string)
-	ValidateIntegrationPin(string, string) bool
+	ValidateIntegrationPin(string, string) error
+	DiscordMemberLeft(string, bool)
+	SetMemberRoles(map[string][]string)
+	ReviewQueue() ([]ReviewEntry, error)
+	ResolveReview(string) error
 	SendChat(ChatData)
 	SendLog(ChatData)
 }
@@ -10,6 +10,14 @@ type ChatData struct {
 	Name string
 	UID  string
 	Msg  string
+	Kind int
+
+	// Thread groups related log messages, such as a battle in one sector. Log
+	// messages that share a thread are posted to a Discord thread by that name.
+	Thread string
+
+	// Channel is the Discord channel that an alliance alert is posted to
+	Channel string
 }
 
 // JumpInfo describes a ship jump
@@ -37,6 +45,10 @@ type Sector struct {
 	X     int
 	Y     int
 
+	// NPC faction that controls the sector (0 if unclaimed)
+	Faction     int
+	FactionName string
+
 	Jumphistory []*JumpInfo
 }
 
@@ -53,6 +65,26 @@ type ServerStatus struct {
 	Alliances     int
 	Sectors       int
 
+	// RestartAt is the time of a pending restart, or the zero time if there
+	// isn't one
+	RestartAt time.Time
+
+	// Maintenance is true while only whitelisted players may join. MaintUntil
+	// is when it ends, or the zero time if it lasts until it is ended
+	Maintenance bool
+	MaintUntil  time.Time
+
+	// Version is the version of the running server. Installed is the version
+	// of the binary on disk if it differs, meaning that a restart is needed
+	Version   string
+	Installed string
+
+	// QueryPlayers and QuerySlots are the player count and slots reported by
+	// the Steam query port. QueryOK is false if it hasn't answered.
+	QueryPlayers int
+	QuerySlots   int
+	QueryOK      bool
+
 	INI *ServerGameConfig
 }
 
@@ -68,6 +100,7 @@ type ServerGameConfig struct {
 	Difficulty          int
 	BlockLimit          int64
 	VolumeLimit         int64
+	MaxPlayers          int64
 	MaxPlayerShips      int64
 	MaxPlayerSlots      int64
 	MaxPlayerStations   int64
@@ -76,9 +109,392 @@ type ServerGameConfig struct {
 	MaxAllianceStations int64
 }
 
+// ChatRecord describes a chat message that was bridged between the game and
+//	Discord
+type ChatRecord struct {
+	Time   time.Time
+	Name   string
+	Msg    string
+	Source string
+}
+
+// CoreStats describes the runtime statistics of the bot process
+type CoreStats struct {
+	Goroutines int
+	HeapAlloc  uint64
+	Sys        uint64
+	NumGC      uint32
+
+	HeapInuse   uint64
+	HeapObjects uint64
+	StackInuse  uint64
+	TotalAlloc  uint64
+	NextGC      uint64
+	LastGC      time.Time
+	LastPause   time.Duration
+	PauseTotal  time.Duration
+	GCCPU       float64
+}
+
+// SubsystemHealth describes the health of one of the bots subsystems
+type SubsystemHealth struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Detail  string `json:"detail"`
+}
+
+// CommandStat describes how often a bot command has been used, and how often
+//	it failed
+type CommandStat struct {
+	Name     string
+	Uses     int64
+	Failures int64
+}
+
+// DonationStat describes the total that a player has donated of a resource to
+//	their alliance. Credits are recorded as the "credits" resource.
+type DonationStat struct {
+	Player   string
+	Resource string
+	Amount   int64
+	Count    int64
+	Last     time.Time
+}
+
+// WorkshopPageSize is the number of mods in a page of Workshop search results
+const WorkshopPageSize = 5
+
+// WorkshopItem describes a mod found by searching the Steam Workshop
+type WorkshopItem struct {
+	ID            int64
+	Title         string
+	Subscriptions int64
+}
+
+// ShipKill describes a ship that was destroyed. The class of a ship is named
+//	after its size, such as "Frigate" or "Cruiser", and its volume is given in
+//	cubic meters.
+type ShipKill struct {
+	Faction     int
+	Killer      int
+	Name        string
+	Class       string
+	Volume      float64
+	KillerClass string
+	X           int
+	Y           int
+	Time        time.Time
+}
+
+// CombatStat describes how many ships were destroyed in a group, such as a
+//	ship class or a sector
+type CombatStat struct {
+	Name  string
+	Count int64
+}
+
+// CombatStats describes the ships that have been destroyed, grouped by the
+//	class of the ship, the class of the ship that destroyed it, the name of the
+//	ship, and the sector it was destroyed in. Each group is sorted from the
+//	largest count to the smallest.
+type CombatStats struct {
+	Total         int64
+	ByClass       []CombatStat
+	ByKillerClass []CombatStat
+	ByName        []CombatStat
+	BySector      []CombatStat
+}
+
+// PlayerData describes a player as reported by getplayerdata. Resources are
+//	keyed by their lowercased name, with money recorded as "credits".
+type PlayerData struct {
+	Index     string
+	Name      string
+	X         int
+	Y         int
+	Ships     int
+	Stations  int
+	Resources map[string]int64
+}
+
+// AllianceData describes an alliance as reported by getplayerdata
+type AllianceData struct {
+	Index     string
+	Name      string
+	Ships     int
+	Stations  int
+	Resources map[string]int64
+}
+
+// StaffMessage describes a private message that a player sent to the staff
+type StaffMessage struct {
+	ID    int64
+	Index string
+	Name  string
+	Msg   string
+	Time  time.Time
+}
+
+// ShipRecord describes the last known location of a ship in the ship registry
+type ShipRecord struct {
+	Name       string
+	Owner      string
+	OwnerIndex string
+	X          int
+	Y          int
+	Seen       time.Time
+}
+
+// SectorCoord describes the coordinates of a sector
+type SectorCoord struct {
+	X int
+	Y int
+}
+
+// Region describes a rectangle of sectors, such as a contested region
+type Region struct {
+	Name string
+	MinX int
+	MinY int
+	MaxX int
+	MaxY int
+}
+
+// Contains returns true if the sector at the given coordinates is within the
+//	region
+func (r Region) Contains(x, y int) bool {
+	return x >= r.MinX && x <= r.MaxX && y >= r.MinY && y <= r.MaxY
+}
+
+// AllianceAlerts describes where an alliance is notified of ship jumps that
+//	concern it, and the sectors that it has claimed. Alerts are posted to
+//	Channel, mentioning Role if one is set.
+type AllianceAlerts struct {
+	Alliance string
+	Channel  string
+	Role     string
+	Claims   []SectorCoord
+}
+
+// AllianceLeadership describes the founder, leader, and members of an alliance
+// by their player indexes. Ranks are only set for members whose rank the game
+// reported.
+type AllianceLeadership struct {
+	Founder string
+	Leader  string
+	Members []string
+	Ranks   map[string]int
+	Updated time.Time
+}
+
+// LoadTestResult describes how the event pipeline coped with a load test
+type LoadTestResult struct {
+	Lines       int
+	Matched     int
+	Relayed     int
+	Dropped     int
+	Elapsed     time.Duration
+	ParseRate   float64
+	AvgSendWait time.Duration
+	MaxSendWait time.Duration
+}
+
+// ReviewEntry describes a player that has been queued for review by staff
+type ReviewEntry struct {
+	Index     string
+	Name      string
+	DiscordID string
+	Reason    string
+	Time      time.Time
+}
+
+// Preset describes a named bundle of server.ini settings (keyed by
+//	"Section.Key"), mod lists, and schedule intervals. Nil mod lists and zero
+//	intervals leave the current configuration as it is.
+type Preset struct {
+	Name       string
+	Settings   map[string]string
+	ServerMods []int64
+	ClientMods []int64
+
+	DBUpdate  time.Duration
+	HangCheck time.Duration
+}
+
+// SettingChange describes a setting that would be changed by applying a preset
+type SettingChange struct {
+	Setting string
+	Old     string
+	New     string
+}
+
+// OrphanedIntegration describes an integrated player whose Discord user is no
+//	longer a member of the guild
+type OrphanedIntegration struct {
+	Index     string
+	Name      string
+	DiscordID string
+	Since     time.Time
+}
+
+// BackupInfo describes a backup of the galaxy directory
+type BackupInfo struct {
+	Name   string
+	Time   time.Time
+	Reason string
+	Size   int64
+}
+
+// SearchResult describes a player, alliance, ship, or sector that matched a
+//	search. Ref is the index of the player or alliance (the owner, for ships),
+//	or the coordinates of a sector.
+type SearchResult struct {
+	Kind   string
+	Name   string
+	Ref    string
+	Detail string
+	Score  int
+}
+
+// ModDeployment describes the changes made by deploying the companion mod
+type ModDeployment struct {
+	Path      string
+	Written   int
+	Unchanged int
+	Removed   int
+}
+
+// BanRecord describes a ban issued through the bot. Expires is zero for bans
+//	that are permanent.
+type BanRecord struct {
+	Index   string
+	Name    string
+	Steam64 string
+	Author  string
+	Reason  string
+	Time    time.Time
+	Expires time.Time
+}
+
+// TrackedGalaxy describes the data tracked for a galaxy. Archived seasons of
+//	a galaxy are named after the galaxy and the seed that they were run with.
+type TrackedGalaxy struct {
+	Name     string
+	Galaxy   string
+	Seed     string
+	Size     int64
+	Modified time.Time
+	Current  bool
+}
+
+// ServerExit describes an exit of the Avorion process, and what caused it
+type ServerExit struct {
+	Time time.Time
+
+	// Code is -1 when the process was ended by a signal
+	Code   int
+	Signal string
+
+	Class       string
+	Explanation string
+}
+
+// GameConfigVersion describes a stored copy of server.ini, and the change
+//	that produced it
+type GameConfigVersion struct {
+	Version int64
+	Time    time.Time
+	Author  string
+	Reason  string
+	Content string
+}
+
+// ScheduledAction describes an automated action and the next time it will run
+type ScheduledAction struct {
+	Name string
+	Next time.Time
+}
+
+// OneShotAction describes an action that was scheduled to run once, at a set
+//	time
+type OneShotAction struct {
+	ID     int64
+	Action string
+	At     time.Time
+	Author string
+}
+
 // LoggedServerEvent describes an event that can be tracked and logged
 type LoggedServerEvent struct {
 	Name    string
 	FString string
 	Regex   *regexp.Regexp
 }
+
+// PlayerDBProgress describes the progress and result of a player database
+//	refresh
+type PlayerDBProgress struct {
+	Players   int
+	Alliances int
+	Processed int
+	Done      bool
+
+	NewPlayers   []string
+	NewAlliances []string
+	Renamed      []string
+}
+
+// JumpBucket describes the number of jumps into a sector within an hour
+type JumpBucket struct {
+	X     int
+	Y     int
+	Hour  time.Time
+	Jumps int64
+}
+
+// SectorActivity describes how busy a sector has been. Score weighs recent
+//	jumps more heavily than older ones.
+type SectorActivity struct {
+	X     int
+	Y     int
+	Jumps int64
+	Score float64
+}
+
+// StatusEmbedFields lists the built-in fields that a status embed layout can
+//	include
+var StatusEmbedFields = []string{"state", "server_info", "server_config",
+	"player_config", "galaxy", "restart", "maintenance", "query"}
+
+// EmbedField describes one field of a status embed layout. Field names one of
+//	StatusEmbedFields, whose name and value can be overridden by Name and
+//	Value. Without it, Name and Value describe a custom field. Hidden fields
+//	are kept in the layout, but aren't shown.
+type EmbedField struct {
+	Field  string
+	Name   string
+	Value  string
+	Inline bool
+	Hidden bool
+}
+
+// APIRoles lists the roles that can be granted to users of the HTTP API, in
+//	increasing order of access
+var APIRoles = []string{"read-only", "moderator", "admin"}
+
+// APIUser describes an account that can log in to the HTTP API with basic
+//	auth. Password is a bcrypt hash.
+type APIUser struct {
+	Password string
+	Role     string
+}
+
+// APIOAuth describes the Discord OAuth2 application used to log in to the
+//	HTTP API. Levels maps an API role to the minimum Discord auth level that
+//	grants it.
+type APIOAuth struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Levels       map[string]int
+}
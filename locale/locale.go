@@ -0,0 +1,193 @@
+package locale
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTag is the locale used when none is configured, or the configured one
+// isn't known
+const DefaultTag = "en-US"
+
+// Locale describes how numbers, dates, and times of day are written in a
+// language
+type Locale struct {
+	tag   string
+	group string
+	date  string
+	clock string
+}
+
+// Non-breaking spaces keep grouped numbers from wrapping in embeds
+const (
+	nbsp       = "\u00a0"
+	narrowNbsp = "\u202f"
+)
+
+var locales = map[string]*Locale{
+	"en-US": {"en-US", ",", "01/02/2006", "3:04 PM"},
+	"en-GB": {"en-GB", ",", "02/01/2006", "15:04"},
+	"de":    {"de", ".", "02.01.2006", "15:04"},
+	"fr":    {"fr", narrowNbsp, "02/01/2006", "15:04"},
+	"es-ES": {"es-ES", ".", "02/01/2006", "15:04"},
+	"it":    {"it", ".", "02/01/2006", "15:04"},
+	"nl":    {"nl", ".", "02-01-2006", "15:04"},
+	"pt-BR": {"pt-BR", ".", "02/01/2006", "15:04"},
+	"pl":    {"pl", nbsp, "02.01.2006", "15:04"},
+	"ru":    {"ru", nbsp, "02.01.2006", "15:04"},
+	"sv-SE": {"sv-SE", nbsp, "2006-01-02", "15:04"},
+	"ja":    {"ja", ",", "2006/01/02", "15:04"},
+	"ko":    {"ko", ",", "2006. 01. 02.", "15:04"},
+	"zh-CN": {"zh-CN", ",", "2006/01/02", "15:04"},
+}
+
+// Regions used when a tag only names a language, or names a region that isn't
+// known
+var languages = map[string]string{
+	"en": "en-US",
+	"es": "es-ES",
+	"pt": "pt-BR",
+	"sv": "sv-SE",
+	"zh": "zh-CN",
+}
+
+// Lookup returns the locale for a tag such as "de" or "en-GB". Tags are matched
+// case insensitively, and a regional tag falls back to its language if the
+// region isn't known.
+func Lookup(tag string) (*Locale, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	for k, l := range locales {
+		if strings.EqualFold(k, tag) {
+			return l, true
+		}
+	}
+
+	lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+	if l, ok := locales[lang]; ok {
+		return l, true
+	}
+	if l, ok := locales[languages[lang]]; ok {
+		return l, true
+	}
+
+	return nil, false
+}
+
+// Get returns the locale for a tag, or the default locale if the tag is
+// unknown
+func Get(tag string) *Locale {
+	if l, ok := Lookup(tag); ok {
+		return l
+	}
+	return locales[DefaultTag]
+}
+
+// Tags returns the tags of every known locale
+func Tags() []string {
+	tags := make([]string, 0, len(locales))
+	for k := range locales {
+		tags = append(tags, k)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Tag returns the tag that the locale is known by
+func (l *Locale) Tag() string {
+	return l.tag
+}
+
+// Number formats an integer with the locales digit grouping
+func (l *Locale) Number(n int64) string {
+	var (
+		digits = strconv.FormatInt(n, 10)
+		sign   = ""
+	)
+
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(sign)
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteString(l.group)
+		}
+		sb.WriteRune(d)
+	}
+
+	return sb.String()
+}
+
+// Date formats the date of t in the given timezone
+func (l *Locale) Date(t time.Time, tz *time.Location) string {
+	return t.In(zone(tz)).Format(l.date)
+}
+
+// Clock formats the time of day of t in the given timezone
+func (l *Locale) Clock(t time.Time, tz *time.Location) string {
+	return t.In(zone(tz)).Format(l.clock)
+}
+
+// DateTime formats t as a date and time of day in the given timezone
+func (l *Locale) DateTime(t time.Time, tz *time.Location) string {
+	return t.In(zone(tz)).Format(l.date + " " + l.clock)
+}
+
+// DateTimeZone formats t like DateTime, followed by the timezone abbreviation
+func (l *Locale) DateTimeZone(t time.Time, tz *time.Location) string {
+	return t.In(zone(tz)).Format(l.date + " " + l.clock + " MST")
+}
+
+// Duration formats a duration as its two largest units, such as "3d 4h" or
+// "5m 10s". Durations under a second are written as "0s".
+func (l *Locale) Duration(d time.Duration) string {
+	var (
+		sign  = ""
+		parts = make([]string, 0, 2)
+	)
+
+	if d < 0 {
+		sign, d = "-", -d
+	}
+
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"}}
+
+	for _, u := range units {
+		if d >= u.size || len(parts) > 0 {
+			parts = append(parts, l.Number(int64(d/u.size))+u.name)
+			d %= u.size
+		}
+		if len(parts) == 2 {
+			break
+		}
+	}
+
+	if len(parts) == 0 {
+		return "0s"
+	}
+
+	// Drop a trailing zero unit, "2h" reads better than "2h 0m"
+	if len(parts) == 2 && strings.HasPrefix(parts[1], "0") {
+		parts = parts[:1]
+	}
+
+	return sign + strings.Join(parts, " ")
+}
+
+func zone(tz *time.Location) *time.Location {
+	if tz == nil {
+		return time.UTC
+	}
+	return tz
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/gookit/color"
 )
@@ -33,6 +35,9 @@ const (
 var (
 	spf     = fmt.Sprintf
 	logfile *os.File
+
+	// Reverse proxies whose X-Forwarded-For headers we trust
+	trustedProxies []*net.IPNet
 )
 
 // formatResponseCode return string formatting for the given response code
@@ -78,8 +83,9 @@ func LogOutput(l ILogger, m string, chs ...chan []byte) {
 	log.Output(1, spf("[%s] [%s] %s", soutPrefix, l.UUID(), m))
 }
 
-// LogError logs an error.
+// LogError logs an error, and reports it if error reporting is enabled
 func LogError(l ILogger, m string, chs ...chan []byte) {
+	errReport.report(l, "error", m)
 	m = spf("[%s] [%s] %s", errorPrefix, l.UUID(), m)
 	log.Output(1, m)
 	sendToChans(m, chs)
@@ -130,19 +136,77 @@ func LogChat(l ILogger, m string, chs ...chan []byte) {
 	sendToChans(spf("[%s] [%s] %s", chatPrefix, l.UUID(), m), chs)
 }
 
-// LogHTTP logs an HTTP response code and string. Provides formatting for the
-// response, and will output if the loglevel of the object is 1 or greater
-func LogHTTP(l ILogger, rc int, r *http.Request, chs ...chan []byte) {
+// LogHTTP logs an HTTP response code and string, along with the principal that
+// made the request ("-" if the request was not authenticated). Provides
+// formatting for the response, and will output if the loglevel of the object
+// is 1 or greater
+func LogHTTP(l ILogger, rc int, r *http.Request, principal string,
+	chs ...chan []byte) {
 	if l.Loglevel() >= infoLevel {
+		if principal == "" {
+			principal = "-"
+		}
+
 		rcs := formatResponseHeader(rc, r.Method)
-		rinfo := spf("%s - %s %s",
-			r.RemoteAddr,
+		rinfo := spf("%s %s %s %s",
+			ClientAddr(r),
+			principal,
 			r.Host,
 			r.RequestURI)
 		log.Output(1, spf("[%s] %s %s", l.UUID(), rcs, rinfo))
 	}
 }
 
+// SetTrustedProxies sets the reverse proxies whose X-Forwarded-For headers are
+// used to determine the client address of an HTTP request
+func SetTrustedProxies(nets []*net.IPNet) {
+	trustedProxies = nets
+}
+
+// isTrustedProxy returns true if the address belongs to a trusted proxy
+func isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientAddr returns the address of the client that made an HTTP request. If
+// the request came through trusted proxies, the X-Forwarded-For header is
+// walked from the right to find the first untrusted address.
+func ClientAddr(r *http.Request) string {
+	addr := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	if !isTrustedProxy(addr) {
+		return addr
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+
+		addr = hop
+		if !isTrustedProxy(hop) {
+			break
+		}
+	}
+
+	return addr
+}
+
 // SetFile sets the programs logfile when provided a string
 func SetFile(path string) {
 	if err := touch(path); err != nil {
@@ -158,6 +222,8 @@ func SetFile(path string) {
 // CloseLog closes the open file handler for our current logfile
 func CloseLog() {
 	if logfile != nil {
+		log.SetOutput(os.Stdout)
 		logfile.Close()
+		logfile = nil
 	}
 }
@@ -0,0 +1,204 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	reportQueueLen = 64
+	reportTimeout  = 10 * time.Second
+
+	// How long a panic waits for its report to be sent before the bot exits
+	panicReportWait = 5 * time.Second
+)
+
+// reporter ships errors to a Sentry-compatible endpoint. Reports are queued
+// and sent in the background, and are dropped if the queue is full so that a
+// burst of errors can't slow down the bot.
+type reporter struct {
+	mutex sync.RWMutex
+	store string
+	auth  string
+	env   string
+	tags  map[string]string
+
+	queue chan reportEvent
+	once  sync.Once
+}
+
+// reportEvent is the subset of a Sentry event that the bot reports
+type reportEvent struct {
+	EventID    string            `json:"event_id"`
+	Timestamp  string            `json:"timestamp"`
+	Level      string            `json:"level"`
+	Logger     string            `json:"logger"`
+	Platform   string            `json:"platform"`
+	ServerName string            `json:"server_name,omitempty"`
+	Release    string            `json:"release,omitempty"`
+	Env        string            `json:"environment,omitempty"`
+	Message    string            `json:"message"`
+	Tags       map[string]string `json:"tags,omitempty"`
+
+	sent chan struct{}
+}
+
+var errReport = &reporter{tags: make(map[string]string)}
+
+// SetErrorReporting enables reporting errors to the Sentry-compatible endpoint
+// described by the given DSN, or disables it if the DSN is empty
+//	@dsn string		DSN in the form https://key@host/project
+//	@env string		Environment that reports are tagged with
+func SetErrorReporting(dsn, env string) error {
+	r := errReport
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.store, r.auth, r.env = "", "", env
+	if dsn == "" {
+		return nil
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || u.Host == "" {
+		return errors.New("invalid error reporting DSN")
+	}
+
+	project := strings.TrimPrefix(u.Path, "/")
+	if i := strings.LastIndex(project, "/"); i >= 0 {
+		u.Path = "/" + project[:i]
+		project = project[i+1:]
+	} else {
+		u.Path = ""
+	}
+
+	if project == "" {
+		return errors.New("error reporting DSN has no project")
+	}
+
+	r.auth = "Sentry sentry_version=7, sentry_client=avorioncontrol, " +
+		"sentry_key=" + u.User.Username()
+	u.User = nil
+	r.store = u.String() + "/api/" + project + "/store/"
+
+	r.once.Do(func() {
+		r.queue = make(chan reportEvent, reportQueueLen)
+		go r.run()
+	})
+
+	return nil
+}
+
+// SetReportTag sets a tag that is sent with every error report, such as the
+// version of the bot or the galaxy that it is running
+func SetReportTag(key, value string) {
+	errReport.mutex.Lock()
+	errReport.tags[key] = value
+	errReport.mutex.Unlock()
+}
+
+// ReportPanic reports a panic, and waits a short time for the report to be sent
+// since the bot is about to exit
+func ReportPanic(l ILogger, m string) {
+	if sent := errReport.report(l, "fatal", m); sent != nil {
+		select {
+		case <-sent:
+		case <-time.After(panicReportWait):
+		}
+	}
+}
+
+// report queues an error report, and returns a channel that is closed once it
+// has been sent. Nothing is reported if reporting is disabled.
+func (r *reporter) report(l ILogger, level, m string) chan struct{} {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.store == "" {
+		return nil
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	host, _ := os.Hostname()
+
+	tags := map[string]string{"uuid": l.UUID()}
+	for k, v := range r.tags {
+		tags[k] = v
+	}
+
+	ev := reportEvent{
+		EventID:    hex.EncodeToString(id),
+		Timestamp:  time.Now().UTC().Format("2006-01-02T15:04:05"),
+		Level:      level,
+		Logger:     l.UUID(),
+		Platform:   "go",
+		ServerName: host,
+		Release:    r.tags["version"],
+		Env:        r.env,
+		Message:    m,
+		Tags:       tags,
+		sent:       make(chan struct{})}
+
+	select {
+	case r.queue <- ev:
+		return ev.sent
+	default:
+		return nil
+	}
+}
+
+// run sends queued error reports. Failures are only written to the local log,
+// since reporting them would queue yet another report.
+func (r *reporter) run() {
+	client := &http.Client{Timeout: reportTimeout}
+	for ev := range r.queue {
+		r.mutex.RLock()
+		store, auth := r.store, r.auth
+		r.mutex.RUnlock()
+
+		if store != "" {
+			if err := send(client, store, auth, ev); err != nil {
+				log.Output(1, spf("[%s] [Logger] Failed to report error: %s",
+					warnPrefix, err.Error()))
+			}
+		}
+		close(ev.sent)
+	}
+}
+
+// send posts an error report to the store endpoint
+func send(client *http.Client, store, auth string, ev reportEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, store, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", auth)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
@@ -4,24 +4,34 @@ import (
 	"avorioncontrol/avorion"
 	"avorioncontrol/configuration"
 	"avorioncontrol/discord"
+	"avorioncontrol/eventbus"
 	"avorioncontrol/ifaces"
 	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"runtime/debug"
 	"sync"
 	"syscall"
+	"time"
 )
 
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 var (
 	showhelp bool
 	loglevel int
 	token    string
 	prefix   string
+	loadrate int
+	loadtime time.Duration
 
 	config *configuration.Conf
+	bus    *eventbus.Bus
 	server ifaces.IGameServer
 	disbot ifaces.IDiscordBot
 	core   *Core
@@ -30,11 +40,16 @@ var (
 func init() {
 	var configFile string
 	config = configuration.New()
+	logger.SetReportTag("version", version)
 
 	flag.IntVar(&loglevel, "l", 0, "Log level")
 	flag.BoolVar(&showhelp, "h", false, "Show help text")
 	flag.StringVar(&token, "t", "", "Bot token")
 	flag.StringVar(&configFile, "c", "", "Configuration file")
+	flag.IntVar(&loadrate, "loadtest", 0,
+		"(Developer) Inject this many synthetic log lines per second once ready")
+	flag.DurationVar(&loadtime, "loadtest-duration", time.Minute,
+		"(Developer) How long to run the load test for")
 	flag.Parse()
 
 	if configFile != "" {
@@ -71,51 +86,100 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// This has to happen before an update renames the running binary
+	if err := resolveExecutable(); err != nil {
+		log.Printf("Failed to resolve the path of the bot: %s", err.Error())
+	}
+
+	// Read the previous run's log before this run adds to it
+	lastrun, crashed := markRunning()
+	lastlines := lastLogLines(crashLogLines)
+
 	sc := make(chan os.Signal, 1)
 	exit := make(chan struct{})
 
-	core = &Core{loglevel: config.Loglevel()}
-	server = avorion.New(config, &wg, exit)
-	disbot = discord.New(config, &wg, exit)
+	// Exit hooks run last to first. The run marker is kept after a failure so
+	// that the next run reports it as a crash.
+	shutdown.OnExit("logfile", func(int) { logger.CloseLog() })
+	shutdown.OnExit("run marker", func(code int) {
+		if code == 0 {
+			markStopped()
+		}
+	})
+	shutdown.OnExit("configuration", func(int) {
+		if err := config.SaveConfiguration(); err != nil {
+			logger.LogError(config, "Failed to save the configuration: "+
+				err.Error())
+		}
+	})
+
+	bus = eventbus.New()
+	server = avorion.New(config, bus, &wg, exit)
+	disbot = discord.New(config, bus, &wg, exit)
+	core = NewCore(server, disbot)
+	disbot.SetCore(core)
+	core.ServeAPI(&wg, exit)
+
+	shutdown.OnExit("tracking database", func(int) { server.CloseDB() })
+	shutdown.OnExit("goroutines", func(int) { stopRoutines(exit, &wg) })
+
+	// Panics in any goroutine that defers shutdown.Recover end up here
+	shutdown.OnPanic(func(r interface{}) {
+		fmt.Printf("Panic Caught: %v", r)
+		logger.ReportPanic(core, fmt.Sprintf("panic: %v\n%s", r, debug.Stack()))
+		if server.IsUp() {
+			fmt.Printf("Attempting to shut down Avorion safely...\n")
+			if err := server.Stop(true); err != nil {
+				logger.LogError(server, err.Error())
+			}
+			fmt.Printf("Avorion stopped gracefully.")
+		}
+	})
+	defer shutdown.Recover()
 
 	// We start this early to prevent an errant os.Interrupt from leaving the
 	// AvorionServer process running.
 	signal.Notify(sc)
 	disbot.Start(server)
 
-	// FIXME: This needs to be handled on the object level
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Printf("Panic Caught: %v", r)
-			if server.IsUp() {
-				fmt.Printf("Attempting to shut down Avorion safely...\n")
-				if err := server.Stop(true); err != nil {
-					logger.LogError(server, err.Error())
-				}
-				fmt.Printf("Avorion stopped gracefully.")
-			}
-			os.Exit(1)
-		}
-	}()
+	if crashed {
+		logger.LogWarning(core, "The previous run did not shut down cleanly")
+		go noticeCrash(bus, lastrun, lastlines)
+	}
+
+	checkForUpdate()
 
 	if err := server.Start(true); err != nil {
 		logger.LogError(core, "Avorion: "+err.Error())
-		os.Exit(1)
+		shutdown.Exit(1)
+	}
+
+	if lt, ok := server.(ifaces.ILoadTestableServer); ok && loadrate > 0 {
+		go func() {
+			defer shutdown.Recover()
+			res := lt.LoadTest(loadrate, loadtime)
+			logger.LogInfo(core, fmt.Sprintf("Load test: %+v", res))
+		}()
 	}
 
 	logger.LogInit(core, "Completed init, awaiting termination signal.")
-	for sig := range sc {
+	for {
+		var sig os.Signal
+		select {
+		case sig = <-sc:
+		case <-core.restart:
+			logger.LogInfo(core, "Restarting into the updated binary")
+			restartBot()
+		}
+
 		switch sig {
 		case os.Interrupt, syscall.SIGTERM:
 			logger.LogInfo(core, "Caught termination signal. Gracefully stopping")
-			close(exit)
-			wg.Wait()
-			config.SaveConfiguration()
-			os.Exit(0)
+			shutdown.Exit(0)
 
 		case syscall.SIGUSR1:
 			logger.LogInfo(core, "Caught SIGUSR1, performing server reload+restart")
-			config.LoadConfiguration()
+			reloadConfiguration()
 			if err := server.Restart(); err != nil {
 				logger.LogError(server, err.Error())
 			}
@@ -125,7 +189,76 @@ func main() {
 			if err := server.Stop(true); err != nil {
 				logger.LogError(server, err.Error())
 			}
-			config.LoadConfiguration()
+			reloadConfiguration()
 		}
 	}
 }
+
+// reloadConfiguration loads the configuration file again, keeping the running
+// configuration if the file is invalid
+func reloadConfiguration() {
+	if err := config.LoadConfiguration(); err != nil {
+		logger.LogError(config, "Failed to reload the configuration: "+
+			err.Error())
+	}
+}
+
+// checkForUpdate looks for a newer release when the bot starts. If automatic
+// updates are enabled, the update is installed and the bot restarts into it
+// before Avorion is started; otherwise the update is only logged.
+func checkForUpdate() {
+	if url, _ := config.UpdateSource(); url == "" || version == "dev" {
+		return
+	}
+
+	// Checksums alone don't prove where a release came from, so a release is
+	// only installed without a person asking for it when it's signed
+	auto := config.AutoUpdate()
+	if _, key := config.UpdateSource(); auto && key == "" {
+		logger.LogWarning(core, "Automatic updates require Core.update.public_key "+
+			"to be set, so updates will only be logged")
+		auto = false
+	}
+
+	if !auto {
+		go func() {
+			defer shutdown.Recover()
+			latest, newer, err := core.CheckUpdate()
+			if err == nil && newer {
+				logger.LogInfo(core, fmt.Sprintf(
+					"Version %s is available (running %s)", latest, version))
+			}
+		}()
+		return
+	}
+
+	latest, err := core.SelfUpdate()
+	if err != nil {
+		logger.LogInfo(core, "No update installed: "+err.Error())
+		return
+	}
+
+	logger.LogInfo(core, fmt.Sprintf("Updated to %s", latest))
+	restartBot()
+}
+
+// restartBot stops the bot gracefully and replaces the process with the
+// binary that is now installed at the same path, keeping the same arguments.
+// Avorion is a child of the bot, so it is stopped and started again by the
+// new process.
+func restartBot() {
+	shutdown.Cleanup(0)
+
+	var err error
+	exe := executable
+	if exe == "" {
+		exe, err = os.Executable()
+	}
+
+	if err == nil {
+		err = syscall.Exec(exe, os.Args, os.Environ())
+	}
+
+	logger.LogError(core, "Failed to restart: "+err.Error())
+	os.Exit(1)
+}
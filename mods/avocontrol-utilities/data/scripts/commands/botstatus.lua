@@ -0,0 +1,40 @@
+--[[
+
+  AvorionControl - data/scripts/commands/botstatus.lua
+  ----------------------------------------------------
+
+  This command is for use by the bot, and is used to announce lifecycle
+  transitions (online, stopping, restarting) to other server-side mods.
+
+  The current status is stored as the "avocontrol_status" server value, and
+  the "onAvoControlStatus" callback is sent with the new status so that mods
+  can react to a controlled shutdown (pause spawners, lock sectors, etc).
+
+  License: BSD-3-Clause
+  https://opensource.org/licenses/BSD-3-Clause
+
+]]
+
+local statuses = {online = true, stopping = true, restarting = true}
+
+function execute(user, cmd, status)
+  if type(user) ~= "nil" then
+    return 1, "\\c(f00)Do not run this please.", ""
+  end
+
+  if not statuses[status] then
+    return 1, "Invalid bot status: "..tostring(status), ""
+  end
+
+  Server():setValue("avocontrol_status", status)
+  Server():sendCallback("onAvoControlStatus", status)
+
+  return 0, "Bot status set to "..status, ""
+end
+
+function getDescription()
+  return "(Bot only) This announces bot lifecycle changes to server mods"
+end
+
+function getHelp()
+end
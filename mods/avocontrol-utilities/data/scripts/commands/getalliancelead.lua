@@ -0,0 +1,57 @@
+--[[
+
+  AvorionControl - data/scripts/commands/getalliancelead.lua
+  ----------------------------------------------------------
+
+  This command is for use by the bot, and lists the leader and members of
+  each of the given alliances. Member ranks are included when the game
+  provides them.
+
+  Output is one line per alliance:
+    "alliancelead: <alliance index> <leader index> <member>[:<rank>],..."
+
+  License: BSD-3-Clause
+  https://opensource.org/licenses/BSD-3-Clause
+
+]]
+
+package.path = package.path .. ";data/scripts/lib/?.lua"
+include("stringutility")
+
+local function memberRank(alliance, index)
+  local ok, rank = pcall(alliance.getMemberRank, alliance, index)
+  if ok and type(tonumber(rank)) == "number" then
+    return ":"..tostring(tonumber(rank))
+  end
+  return ""
+end
+
+function execute(user, cmd, ...)
+  if type(user) ~= "nil" then
+    return 1, "This command is only intended for bot use", ""
+  end
+
+  local output = ""
+
+  for _, index in ipairs({...}) do
+    local alliance = Alliance(tonumber(index))
+    if alliance then
+      local members = {}
+      for _, m in ipairs({alliance:getMembers()}) do
+        table.insert(members, tostring(m)..memberRank(alliance, m))
+      end
+
+      output = output.."alliancelead: ${ai} ${li} ${ms}\n"%_T % {
+        ai=alliance.index, li=alliance.leader, ms=table.concat(members, ",")}
+    end
+  end
+
+  return 0, output, ""
+end
+
+function getDescription()
+  return "(Bot only) Lists the leader and members of the given alliances"
+end
+
+function getHelp()
+end
@@ -0,0 +1,57 @@
+--[[
+
+  AvorionControl - data/scripts/commands/getshipdata.lua
+  ------------------------------------------------------
+
+  This command is for use by the bot, and lists the ships owned by every
+  online player and their alliances, along with the sector each ship is in.
+  The bot uses this to keep its ship registry current for ships that have
+  not jumped since the registry last saw them.
+
+  Output is one line per ship: "ship: <faction index> <x>:<y> <name>"
+
+  License: BSD-3-Clause
+  https://opensource.org/licenses/BSD-3-Clause
+
+]]
+
+package.path = package.path .. ";data/scripts/lib/?.lua"
+include("stringutility")
+
+local function listShips(faction)
+  local out = ""
+  for _, name in ipairs({faction:getShipNames()}) do
+    local x, y = faction:getShipPosition(name)
+    out = out.."ship: ${fi} ${x}:${y} ${sn}\n"%_T % {
+      fi=faction.index, x=x, y=y, sn=name}
+  end
+  return out
+end
+
+function execute(user, cmd)
+  if type(user) ~= "nil" then
+    return 1, "This command is only intended for bot use", ""
+  end
+
+  local output = ""
+  local seen   = {}
+
+  for _, player in ipairs({Server():getPlayers()}) do
+    output = output..listShips(player)
+
+    local alliance = player.alliance
+    if alliance and not seen[alliance.index] then
+      seen[alliance.index] = true
+      output = output..listShips(alliance)
+    end
+  end
+
+  return 0, output, ""
+end
+
+function getDescription()
+  return "(Bot only) Lists the ships owned by online players and alliances"
+end
+
+function getHelp()
+end
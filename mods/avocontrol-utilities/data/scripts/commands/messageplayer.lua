@@ -0,0 +1,34 @@
+--[[
+
+  AvorionControl - data/scripts/commands/messageplayer.lua
+  --------------------------------------------------------
+
+  This command is for use by the bot, and sends a warning chat message to a
+  single player (used for moderation notices).
+
+  License: BSD-3-Clause
+  https://opensource.org/licenses/BSD-3-Clause
+
+]]
+
+function execute(user, cmd, index, message)
+  if type(user) ~= "nil" then
+    return 1, "This command is only intended for bot use", ""
+  end
+
+  local player = Player(tonumber(index))
+  if type(player) == "nil" then
+    return 1, "", "Invalid player index: "..tostring(index)
+  end
+
+  player:sendChatMessage("Server", ChatMessageType.Warning,
+    message or "Bad message (file a bug report please)")
+  return 0, "Sent message to "..player.name, ""
+end
+
+function getDescription()
+  return "(Bot only) Sends a warning message to a single player"
+end
+
+function getHelp()
+end
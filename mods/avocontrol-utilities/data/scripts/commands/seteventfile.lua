@@ -0,0 +1,34 @@
+--[[
+
+  AvorionControl - data/scripts/commands/seteventfile.lua
+  -------------------------------------------------------
+
+  This command is for use by the bot, and sets the JSONL file that structured
+  events are written to. Running it without a path disables the event file,
+  and events are printed to stdout instead.
+
+  License: BSD-3-Clause
+  https://opensource.org/licenses/BSD-3-Clause
+
+]]
+
+function execute(user, cmd, path)
+  if type(user) ~= "nil" then
+    return 1, "\\c(f00)Do not run this please.", ""
+  end
+
+  if path == nil or path == "" then
+    Server():setValue("avocontrol_eventfile", nil)
+    return 0, "Event file disabled", ""
+  end
+
+  Server():setValue("avocontrol_eventfile", path)
+  return 0, "Event file set to "..path, ""
+end
+
+function getDescription()
+  return "(Bot only) This sets the file that mod events are written to"
+end
+
+function getHelp()
+end
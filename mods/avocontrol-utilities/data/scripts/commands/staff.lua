@@ -0,0 +1,48 @@
+--[[
+
+  AvorionControl - data/scripts/commands/staff.lua
+  ------------------------------------------------
+
+  Lets players send a private message to the server staff. The message is
+  printed as an event for the bot, which posts it to the staff inbox channel.
+  Staff replies are delivered back to the player in-game.
+
+  License: BSD-3-Clause
+  https://opensource.org/licenses/BSD-3-Clause
+
+]]
+
+-- Seconds that a player has to wait between messages
+local cooldown = 30
+
+-- getDescription returns this commands description. For use with /help
+function getDescription()
+  return "Sends a private message to the server staff"
+end
+
+-- getHelp returns this commands help syntax. For use with /help
+function getHelp()
+  return "Usage: /staff <message>"
+end
+
+-- execute is the main function that is run when this command is run
+function execute(sender, _, ...)
+  if type(sender) == "nil" then
+    return 1, "This command can only be used by players", ""
+  end
+
+  local message = table.concat({...}, " "):gsub("[\r\n]", " ")
+  if message == "" then
+    return 1, "", getHelp()
+  end
+
+  local player = Player(sender)
+  local last = player:getValue("avocontrol_staffmsg") or 0
+  if os.time() - last < cooldown then
+    return 1, "", "Please wait before sending the staff another message"
+  end
+
+  player:setValue("avocontrol_staffmsg", os.time())
+  print("staffMessageEvent: ${i} ${m}"%_T % {i=player.index, m=message})
+  return 0, "Your message was sent to the staff", ""
+end
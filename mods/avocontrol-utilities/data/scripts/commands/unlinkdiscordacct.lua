@@ -0,0 +1,33 @@
+--[[
+
+  AvorionControl - data/scripts/commands/unlinkdiscordacct.lua
+  ------------------------------------------------------------
+
+  This command is for use by the bot, and removes the Discord integration
+  from a player (used when an integrated user leaves the Discord server).
+
+  License: BSD-3-Clause
+  https://opensource.org/licenses/BSD-3-Clause
+
+]]
+
+function execute(user, cmd, index)
+  if type(user) ~= "nil" then
+    return 1, "This command is only intended for bot use", ""
+  end
+
+  local player = Player(tonumber(index))
+  if type(player) == "nil" then
+    return 1, "", "Invalid player index: "..tostring(index)
+  end
+
+  player:setValue("discorduserid", nil)
+  return 0, "Removed Discord integration from "..player.name, ""
+end
+
+function getDescription()
+  return "(Bot only) Removes the Discord integration from a player"
+end
+
+function getHelp()
+end
@@ -3,7 +3,10 @@
   AvorionControl - data/scripts/entity/avocontrol-shiptracker.lua
   ---------------------------------------------------------------
 
-  Emit ship jump and deletion events to stdout for players and alliances
+  Emit ship jump and destruction events for players and alliances, as well as
+  the NPC faction that controls the sectors that they visit. Jumps and
+  destructions are written to the bot's event file when one is set, and jumps
+  are printed to stdout otherwise.
 
   License: BSD-3-Clause
   https://opensource.org/licenses/BSD-3-Clause
@@ -16,6 +19,24 @@ local index = ""
 
 package.path = package.path .. ";data/scripts/lib/?.lua"
 include("stringutility")
+include("avocontrol-events")
+include("shiputility")
+
+-- Name the class of a ship after its size, like the game does for NPC ships
+local function shipClass(ship)
+  return ShipUtility.getMilitaryNameByVolume(ship.volume)
+end
+
+-- Emit the NPC faction that controls the given sector (index 0 if unclaimed)
+local function emitSectorControl(x, y)
+  local faction = Galaxy():getControllingFaction(x, y)
+  local fi, fn = 0, ""
+  if faction and faction.isAIFaction then
+    fi, fn = faction.index, faction.name
+  end
+  print("sectorControlEvent: ${x}:${y} ${fi} ${fn}"%_T % {
+    x=x, y=y, fi=fi, fn=fn})
+end
 
 function AvorionControlShipTracker.initialize()
   if onServer() then
@@ -24,6 +45,7 @@ function AvorionControlShipTracker.initialize()
     index = Uuid(ship.index).number
     print("shipTrackInitEvent: ${oi} ${x}:${y} ${sn}"%_T % {
       oi=ship.factionIndex, x=x, y=y, sn=ship.name})
+    ship:registerCallback("onDestroyed", "onDestroyed")
   end
 end
 
@@ -31,7 +53,31 @@ function AvorionControlShipTracker.onSectorChanged()
   if onServer() then
     local ship  = Entity()
     local x, y  = Sector():getCoordinates()
-    print("shipJumpEvent: ${oi} ${x}:${y} ${sn}"%_T % {
-      oi=ship.factionIndex, x=x, y=y, sn=ship.name})
+    local sent  = EmitEvent("jump", {
+      faction=ship.factionIndex, x=x, y=y, name=ship.name})
+    if not sent then
+      print("shipJumpEvent: ${oi} ${x}:${y} ${sn}"%_T % {
+        oi=ship.factionIndex, x=x, y=y, sn=ship.name})
+    end
+    emitSectorControl(x, y)
+  end
+end
+
+function AvorionControlShipTracker.onDestroyed(index, lastDamageInflictor)
+  if onServer() then
+    local ship     = Entity()
+    local x, y     = Sector():getCoordinates()
+    local killer   = 0
+    local kclass   = ""
+    local attacker = lastDamageInflictor and Entity(lastDamageInflictor)
+    if attacker then
+      killer = attacker.factionIndex
+      if attacker.isShip then
+        kclass = shipClass(attacker)
+      end
+    end
+    EmitEvent("destroyed", {
+      faction=ship.factionIndex, x=x, y=y, name=ship.name, killer=killer,
+      class=shipClass(ship), volume=ship.volume, killer_class=kclass})
   end
 end
\ No newline at end of file
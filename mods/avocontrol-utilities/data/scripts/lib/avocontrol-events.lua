@@ -0,0 +1,63 @@
+--[[
+
+  AvorionControl - data/scripts/lib/avocontrol-events.lua
+  -------------------------------------------------------
+
+  Writes structured events to the JSONL event file that the bot follows. The
+  bot sets the file with the seteventfile command. When no file is set, the
+  caller is expected to fall back to printing the event to stdout.
+
+  License: BSD-3-Clause
+  https://opensource.org/licenses/BSD-3-Clause
+
+]]
+
+-- Major version of the event format, bumped on incompatible changes
+local eventVersion = 1
+
+-- jsonString quotes and escapes a string for JSON output
+local function jsonString(s)
+  s = tostring(s):gsub('[%c"\\]', function(c)
+    if c == '"' then return '\\"' end
+    if c == "\\" then return "\\\\" end
+    if c == "\n" then return "\\n" end
+    if c == "\t" then return "\\t" end
+    return string.format("\\u%04x", string.byte(c))
+  end)
+  return '"'..s..'"'
+end
+
+-- EmitEvent appends an event of the given type to the event file. Fields must
+--  be a flat table of strings, numbers and booleans.
+--
+-- Returns:
+--  @1    Boolean
+function EmitEvent(kind, fields)
+  local path = Server():getValue("avocontrol_eventfile")
+  if type(path) ~= "string" or path == "" then
+    return false
+  end
+
+  local parts = {
+    '"v":'..eventVersion,
+    '"type":'..jsonString(kind),
+    '"time":'..os.time()}
+
+  for k, v in pairs(fields) do
+    if type(v) == "number" or type(v) == "boolean" then
+      table.insert(parts, jsonString(k)..":"..tostring(v))
+    else
+      table.insert(parts, jsonString(k)..":"..jsonString(v))
+    end
+  end
+
+  local f = io.open(path, "a")
+  if f == nil then
+    print("avocontrol-events: EmitEvent: unable to open "..path)
+    return false
+  end
+
+  f:write("{"..table.concat(parts, ",").."}\n")
+  f:close()
+  return true
+end
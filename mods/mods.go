@@ -0,0 +1,124 @@
+// Package mods embeds the companion mod that the integration features of
+// AvorionControl rely on, so that it can be installed without the Workshop
+package mods
+
+import (
+	"crypto/sha256"
+	"embed"
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// CompanionName is the name of the companion mod, and of its directory
+const CompanionName = "avocontrol-utilities"
+
+//go:embed avocontrol-utilities
+var companion embed.FS
+
+// Deployment describes the changes made by deploying the companion mod
+type Deployment struct {
+	Written   int
+	Unchanged int
+	Removed   int
+}
+
+// Deploy installs the companion mod into a directory, replacing the files that
+// differ from the embedded copy and removing the files that it no longer has.
+// Every file that is written is read back and checked against the checksum of
+// the embedded copy.
+func Deploy(dir string) (Deployment, error) {
+	var (
+		d     Deployment
+		files = make(map[string]bool)
+	)
+
+	err := fs.WalkDir(companion, CompanionName, func(path string, e fs.DirEntry,
+		err error) error {
+		if err != nil || e.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(CompanionName, filepath.FromSlash(path))
+		if err != nil {
+			return err
+		}
+		files[rel] = true
+
+		data, err := companion.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		dest := filepath.Join(dir, rel)
+		sum := sha256.Sum256(data)
+		if existing, err := ioutil.ReadFile(dest); err == nil &&
+			sha256.Sum256(existing) == sum {
+			d.Unchanged++
+			return nil
+		}
+
+		if err := writeFile(dest, data); err != nil {
+			return err
+		}
+
+		written, err := ioutil.ReadFile(dest)
+		if err != nil {
+			return err
+		}
+
+		if sha256.Sum256(written) != sum {
+			return errors.New("checksum mismatch after writing " + rel)
+		}
+
+		d.Written++
+		return nil
+	})
+	if err != nil {
+		return d, err
+	}
+
+	// Files left behind by an older version of the mod could still be loaded
+	err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || files[rel] {
+			return err
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+
+		d.Removed++
+		return nil
+	})
+
+	return d, err
+}
+
+// Deployed returns whether or not the companion mod has been deployed into a
+// directory
+func Deployed(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "modinfo.lua"))
+	return err == nil
+}
+
+// writeFile replaces a file, so that Avorion never reads a partial copy
+func writeFile(dest string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(dest+".tmp", data, 0644); err != nil {
+		os.Remove(dest + ".tmp")
+		return err
+	}
+
+	return os.Rename(dest+".tmp", dest)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"avorioncontrol/eventbus"
+	"avorioncontrol/ifaces"
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	runMarkerName = "avorioncontrol.running"
+
+	// How many lines of the previous run's log to include in the crash notice
+	crashLogLines = 15
+
+	// How long to wait for the Discord bot to start relaying log messages
+	crashNoticeWait = time.Minute
+
+	// How long to wait for the goroutines to stop when the bot exits. An exit
+	// can start from one of those goroutines, which would otherwise wait on
+	// itself forever.
+	stopRoutinesWait = 3 * time.Minute
+
+	noticeBotCrashed = "**Bot Notice**: avorioncontrol did not shut down " +
+		"cleanly during its last run (started %s), and has recovered."
+)
+
+// runMarker returns the path to the file that exists while the bot is running
+func runMarker() string {
+	return strings.TrimSuffix(config.DataPath(), "/") + "/" + runMarkerName
+}
+
+// markRunning records that the bot is running, and returns the time the
+// previous run started if it did not shut down cleanly
+func markRunning() (string, bool) {
+	last, err := ioutil.ReadFile(runMarker())
+	crashed := err == nil
+
+	started := []byte(time.Now().Format(time.RFC1123))
+	if err := ioutil.WriteFile(runMarker(), started, 0600); err != nil {
+		logger.LogWarning(config, "Failed to write run marker: "+err.Error())
+	}
+
+	return strings.TrimSpace(string(last)), crashed
+}
+
+// markStopped removes the run marker once the bot has shut down cleanly
+func markStopped() {
+	if err := os.Remove(runMarker()); err != nil && !os.IsNotExist(err) {
+		logger.LogWarning(core, "Failed to remove run marker: "+err.Error())
+	}
+}
+
+// stopRoutines signals every goroutine to stop, which stops Avorion along with
+// them, and waits for them to finish
+func stopRoutines(exit chan struct{}, wg *sync.WaitGroup) {
+	close(exit)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(stopRoutinesWait):
+		logger.LogWarning(core, "Timed out waiting for goroutines to stop")
+	}
+}
+
+// lastLogLines returns up to n of the last lines in the configured log file
+func lastLogLines(n int) []string {
+	if config.LogFile() == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(config.LogFile())
+	if err != nil {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return lines
+}
+
+// noticeCrash posts a notice to the log channel once the Discord bot is
+// relaying log messages, including the last lines the previous run logged
+func noticeCrash(bus *eventbus.Bus, started string, lines []string) {
+	defer shutdown.Recover()
+
+	msg := fmt.Sprintf(noticeBotCrashed, started)
+
+	// Keep the newest lines when they won't all fit in one Discord message
+	for len(lines) > 0 && len(msg)+len(strings.Join(lines, "\n")) > 1800 {
+		lines = lines[1:]
+	}
+
+	if len(lines) > 0 {
+		msg += "\n```\n" + strings.Join(lines, "\n") + "\n```"
+	}
+
+	deadline := time.Now().Add(crashNoticeWait)
+	for bus.Subscribers(ifaces.EventTopicLog) == 0 {
+		if time.Now().After(deadline) {
+			logger.LogWarning(core, "Failed to send the bot crash notice")
+			return
+		}
+		time.Sleep(time.Second)
+	}
+
+	if !bus.Publish(ifaces.EventTopicLog, ifaces.ChatData{Name: "Core",
+		Thread: "Bot crash", Msg: msg}, time.Second*5) {
+		logger.LogWarning(core, "Failed to send the bot crash notice")
+	}
+}
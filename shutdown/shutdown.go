@@ -0,0 +1,101 @@
+// Package shutdown runs every exit of the bot through one place, so that the
+// configuration is saved and open files are closed no matter how it exits
+package shutdown
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// hook is a function that is run when the bot exits
+type hook struct {
+	name string
+	fn   func(code int)
+}
+
+var (
+	lock   sync.Mutex
+	hooks  []hook
+	once   sync.Once
+	report func(r interface{})
+)
+
+// OnExit registers a function to run when the bot exits. Hooks are run in the
+// reverse of the order that they were registered in, so that anything a hook
+// depends on is still available when it runs. The exit code is passed along
+// so that hooks can tell a clean shutdown from a failure.
+func OnExit(name string, fn func(code int)) {
+	lock.Lock()
+	defer lock.Unlock()
+	hooks = append(hooks, hook{name, fn})
+}
+
+// Cleanup runs the exit hooks without exiting. It only has an effect the first
+// time that it's called, and callers that arrive while it's running wait for
+// it to finish.
+func Cleanup(code int) {
+	once.Do(func() {
+		lock.Lock()
+		run := make([]hook, len(hooks))
+		copy(run, hooks)
+		lock.Unlock()
+
+		for i := len(run) - 1; i >= 0; i-- {
+			runHook(run[i], code)
+		}
+	})
+}
+
+// runHook runs a single exit hook, and keeps a panic in it from stopping the
+// hooks after it
+func runHook(h hook, code int) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Exit hook %s panicked: %v", h.name, r)
+		}
+	}()
+
+	h.fn(code)
+}
+
+// Exit runs the exit hooks and exits with the given code
+func Exit(code int) {
+	Cleanup(code)
+	os.Exit(code)
+}
+
+// Fatal logs a message and exits with a status code of 1, in place of
+// log.Fatal
+func Fatal(v ...interface{}) {
+	log.Print(fmt.Sprint(v...))
+	Exit(1)
+}
+
+// OnPanic sets the function that Recover hands panics to before exiting. It
+// is called from the panicking goroutine, so it can capture the stack.
+func OnPanic(fn func(r interface{})) {
+	lock.Lock()
+	defer lock.Unlock()
+	report = fn
+}
+
+// Recover exits with a status code of 1 if the calling goroutine is panicking,
+// after handing the panic to the function set with OnPanic. It has to be
+// deferred, and should be deferred by every goroutine that the bot starts,
+// since a panic in any of them would otherwise exit without the exit hooks.
+func Recover() {
+	if r := recover(); r != nil {
+		lock.Lock()
+		fn := report
+		lock.Unlock()
+
+		if fn != nil {
+			fn(r)
+		} else {
+			log.Printf("panic: %v", r)
+		}
+		Exit(1)
+	}
+}
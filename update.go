@@ -0,0 +1,339 @@
+package main
+
+import (
+	"avorioncontrol/logger"
+	"avorioncontrol/shutdown"
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	updateChecksums    = "SHA256SUMS"
+	updateSignature    = "SHA256SUMS.sig"
+	updateTimeout      = 5 * time.Minute
+	updateRestartDelay = 5 * time.Second
+)
+
+// executable is the path of the running binary. It is resolved when the bot
+// starts, since an update renames the running binary and os.Executable would
+// then return the path of the old one.
+var executable string
+
+// resolveExecutable records the path of the running binary, following any
+// symlinks so that an update replaces the binary and not the link
+func resolveExecutable() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	executable, err = filepath.EvalSymlinks(exe)
+	return err
+}
+
+// release is the subset of a GitHub release that self updates use
+type release struct {
+	Tag    string `json:"tag_name"`
+	Assets []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// releaseBinary returns the name of the release asset for this platform
+func releaseBinary() string {
+	return fmt.Sprintf("avorioncontrol-%s-%s", runtime.GOOS, runtime.GOARCH)
+}
+
+// assetURL returns the download URL for the named release asset
+func (r *release) assetURL(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+// parseVersion parses a version of the form [v]MAJOR.MINOR.PATCH, along with
+// any pre-release suffix (1.2.0-rc1). Build metadata (+...) is ignored.
+func parseVersion(v string) ([3]int, string, error) {
+	var nums [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+
+	pre := ""
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return nums, "", fmt.Errorf("%q is not a valid version", v)
+	}
+
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nums, "", fmt.Errorf("%q is not a valid version", v)
+		}
+		nums[i] = n
+	}
+
+	return nums, pre, nil
+}
+
+// newerVersion reports whether or not the latest version is newer than the
+// current one. A pre-release is older than the release it leads up to, and
+// two pre-releases of the same version are never considered newer.
+func newerVersion(latest, current string) (bool, error) {
+	l, lpre, err := parseVersion(latest)
+	if err != nil {
+		return false, err
+	}
+
+	c, cpre, err := parseVersion(current)
+	if err != nil {
+		return false, fmt.Errorf("the running version can't be compared (%s)",
+			err.Error())
+	}
+
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i], nil
+		}
+	}
+
+	return lpre == "" && cpre != "", nil
+}
+
+// CheckUpdate returns the latest released version, and whether or not it is
+// newer than the running version
+func (c *Core) CheckUpdate() (string, bool, error) {
+	r, err := c.latestRelease()
+	if err != nil {
+		return "", false, err
+	}
+
+	latest := strings.TrimPrefix(r.Tag, "v")
+	newer, err := newerVersion(latest, version)
+	if err != nil {
+		return "", false, err
+	}
+
+	return latest, newer, nil
+}
+
+// SelfUpdate downloads the latest release, verifies it against the release
+// checksums (and their signature, if a key is configured), and swaps it in
+// for the running binary. The bot then restarts into the new version after a
+// short delay, so that the caller can report the result first.
+//
+// Without a key, the checksums come from the same place as the binary, so they
+// only catch a corrupted download and say nothing about who built the release.
+// Avorion is stopped while the bot restarts, since it is a child of the bot.
+func (c *Core) SelfUpdate() (string, error) {
+	r, err := c.latestRelease()
+	if err != nil {
+		return "", err
+	}
+
+	// Never install an older release, so that a stale or replayed release
+	// can't be used to downgrade the bot
+	latest := strings.TrimPrefix(r.Tag, "v")
+	newer, err := newerVersion(latest, version)
+	if err != nil {
+		return "", err
+	}
+	if !newer {
+		return "", fmt.Errorf("version %s is not newer than %s", latest, version)
+	}
+
+	if err := c.installRelease(r); err != nil {
+		return "", err
+	}
+
+	logger.LogInfo(c, fmt.Sprintf("Installed version %s, restarting", latest))
+	go func() {
+		defer shutdown.Recover()
+		time.Sleep(updateRestartDelay)
+		c.restartOnce.Do(func() { close(c.restart) })
+	}()
+
+	return latest, nil
+}
+
+// latestRelease fetches the latest release from the configured release API
+func (c *Core) latestRelease() (*release, error) {
+	url, _ := config.UpdateSource()
+	if url == "" {
+		return nil, errors.New("no update URL is configured")
+	}
+
+	data, err := download(url)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &release{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("invalid release data: %s", err.Error())
+	}
+
+	if r.Tag == "" {
+		return nil, errors.New("release has no version tag")
+	}
+
+	return r, nil
+}
+
+// installRelease downloads and verifies the binary for this platform from a
+// release, and then replaces the running executable with it. The previous
+// binary is kept alongside it with an .old suffix.
+func (c *Core) installRelease(r *release) error {
+	name := releaseBinary()
+	binURL, ok := r.assetURL(name)
+	if !ok {
+		return fmt.Errorf("release %s has no binary for this platform (%s)",
+			r.Tag, name)
+	}
+
+	sumURL, ok := r.assetURL(updateChecksums)
+	if !ok {
+		return fmt.Errorf("release %s has no %s file", r.Tag, updateChecksums)
+	}
+
+	sums, err := download(sumURL)
+	if err != nil {
+		return err
+	}
+
+	if _, key := config.UpdateSource(); key != "" {
+		sigURL, ok := r.assetURL(updateSignature)
+		if !ok {
+			return fmt.Errorf("release %s is not signed", r.Tag)
+		}
+
+		sig, err := download(sigURL)
+		if err != nil {
+			return err
+		}
+
+		if err := verifySignature(key, r.Tag, sums, sig); err != nil {
+			return err
+		}
+	}
+
+	want, err := findChecksum(sums, name)
+	if err != nil {
+		return err
+	}
+
+	bin, err := download(binURL)
+	if err != nil {
+		return err
+	}
+
+	if got := sha256.Sum256(bin); hex.EncodeToString(got[:]) != want {
+		return fmt.Errorf("checksum mismatch for %s", name)
+	}
+
+	exe := executable
+	if exe == "" {
+		return errors.New("the path of the running binary is unknown")
+	}
+
+	// Write the new binary next to the old one so that the swap is a rename
+	tmp := exe + ".new"
+	if err := ioutil.WriteFile(tmp, bin, 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(exe, exe+".old"); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, exe); err != nil {
+		os.Rename(exe+".old", exe)
+		return err
+	}
+
+	return nil
+}
+
+// verifySignature checks an ed25519 signature of the release version and
+// checksums. The signed payload is the release tag on its own line followed by
+// the contents of SHA256SUMS, so that the checksums of one release can't be
+// passed off as another. The signature may either be raw or base64 encoded.
+func verifySignature(key, tag string, sums, sig []byte) error {
+	pub, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return errors.New("the configured update public key is invalid")
+	}
+
+	if len(sig) != ed25519.SignatureSize {
+		if sig, err = base64.StdEncoding.DecodeString(
+			strings.TrimSpace(string(sig))); err != nil {
+			return errors.New("the release signature is malformed")
+		}
+	}
+
+	payload := append([]byte(tag+"\n"), sums...)
+	if !ed25519.Verify(ed25519.PublicKey(pub), payload, sig) {
+		return errors.New("the release signature is invalid")
+	}
+
+	return nil
+}
+
+// findChecksum returns the checksum of the named file from a sha256sum style
+// checksums file
+func findChecksum(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no checksum for %s", updateChecksums, name)
+}
+
+func download(url string) ([]byte, error) {
+	client := &http.Client{Timeout: updateTimeout}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "avorioncontrol/"+version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s (%s)", url, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}